@@ -19,6 +19,7 @@ package main
 import (
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/kelseyhightower/envconfig"
@@ -36,8 +37,10 @@ import (
 	"knative.dev/pkg/tracing"
 	tracingconfig "knative.dev/pkg/tracing/config"
 
-	broker "knative.dev/eventing/cmd/broker"
+	cmdbroker "knative.dev/eventing/cmd/broker"
+	broker "knative.dev/eventing/pkg/broker"
 	"knative.dev/eventing/pkg/broker/filter"
+	"knative.dev/eventing/pkg/kncloudevents"
 	"knative.dev/eventing/pkg/reconciler/names"
 
 	eventingclientset "knative.dev/eventing/pkg/client/clientset/versioned"
@@ -55,6 +58,10 @@ type envConfig struct {
 	PodName       string `envconfig:"POD_NAME" required:"true"`
 	ContainerName string `envconfig:"CONTAINER_NAME" required:"true"`
 	Port          int    `envconfig:"FILTER_PORT" default:"8080"`
+
+	// LimiterMaxWait is the maximum time an event waits for the rate limit or the maximum number
+	// of events in flight of its subscriber before being rejected.
+	LimiterMaxWait time.Duration `envconfig:"LIMITER_MAX_WAIT" default:"10s"`
 }
 
 func main() {
@@ -73,7 +80,7 @@ func main() {
 	ctx, _ = injection.Default.SetupInformers(ctx, cfg)
 	kubeClient := kubeclient.Get(ctx)
 
-	loggingConfig, err := broker.GetLoggingConfig(ctx, system.Namespace(), logging.ConfigMapName())
+	loggingConfig, err := cmdbroker.GetLoggingConfig(ctx, system.Namespace(), logging.ConfigMapName())
 	if err != nil {
 		log.Fatal("Error loading/parsing logging configuration:", err)
 	}
@@ -87,6 +94,8 @@ func main() {
 	eventingFactory := eventinginformers.NewSharedInformerFactory(eventingClient,
 		controller.GetResyncPeriod(ctx))
 	triggerInformer := eventingFactory.Eventing().V1().Triggers()
+	brokerInformer := eventingFactory.Eventing().V1().Brokers()
+	eventTypeInformer := eventingFactory.Eventing().V1beta1().EventTypes()
 
 	// Watch the logging config map and dynamically update logging levels.
 	configMapWatcher := configmap.NewInformedWatcher(kubeClient, system.Namespace())
@@ -110,12 +119,37 @@ func main() {
 
 	reporter := filter.NewStatsReporter(env.ContainerName, kmeta.ChildName(env.PodName, uuid.New().String()))
 
+	// Setup the limiters of the subscribers before creating the handler, which shares them.
+	if env.LimiterMaxWait <= 0 {
+		logger.Fatal("LIMITER_MAX_WAIT must be greater than 0", zap.Duration("value", env.LimiterMaxWait))
+	}
+	kncloudevents.ConfigureLimiters(kncloudevents.LimiterConfig{
+		MaxWait: env.LimiterMaxWait,
+		OnStateChange: func(destination string, state kncloudevents.LimiterState) {
+			_ = reporter.ReportLimiterState(destination, state)
+		},
+		OnReject: func(destination string) {
+			_ = reporter.ReportLimiterRejection(destination)
+		},
+	})
+
+	// Index the EventTypes by Broker and type for the schema validation.
+	if err := eventTypeInformer.Informer().AddIndexers(broker.EventTypeIndexers); err != nil {
+		logger.Fatal("Failed to index the EventTypes", zap.Error(err))
+	}
+
 	// We are running both the receiver (takes messages in from the Broker) and the dispatcher (send
 	// the messages to the triggers' subscribers) in this binary.
-	handler, err := filter.NewHandler(logger, triggerInformer.Lister(), reporter, env.Port)
+	handler, err := filter.NewHandler(logger, triggerInformer.Lister(), brokerInformer.Lister(), eventTypeInformer.Informer().GetIndexer(), reporter, env.Port)
 	if err != nil {
 		logger.Fatal("Error creating Handler", zap.Error(err))
 	}
+	// Keep the cached filters and Trigger indexes in sync with the Triggers.
+	triggerInformer.Informer().AddEventHandler(handler.TriggerEventHandler())
+	// Resolve the limits of the Triggers again when their Broker changes.
+	brokerInformer.Informer().AddEventHandler(handler.BrokerEventHandler())
+	// Evict the compiled JSON schemas of the deleted EventTypes.
+	eventTypeInformer.Informer().AddEventHandler(handler.EventTypeEventHandler())
 
 	// configMapWatcher does not block, so start it first.
 	if err = configMapWatcher.Start(ctx.Done()); err != nil {
@@ -19,6 +19,7 @@ package main
 import (
 	"fmt"
 	"log"
+	"time"
 
 	// Uncomment the following line to load the gcp plugin (only required to authenticate against GKE clusters).
 	// _ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
@@ -42,13 +43,16 @@ import (
 	cmdbroker "knative.dev/eventing/cmd/broker"
 	broker "knative.dev/eventing/pkg/broker"
 	"knative.dev/eventing/pkg/broker/ingress"
+	eventingclient "knative.dev/eventing/pkg/client/injection/client"
 	brokerinformer "knative.dev/eventing/pkg/client/injection/informers/eventing/v1/broker"
+	eventtypeinformer "knative.dev/eventing/pkg/client/injection/informers/eventing/v1beta1/eventtype"
 	"knative.dev/eventing/pkg/kncloudevents"
 	"knative.dev/eventing/pkg/reconciler/names"
 )
 
 // TODO make these constants configurable (either as env variables, config map, or part of broker spec).
-//  Issue: https://github.com/knative/eventing/issues/1777
+//
+//	Issue: https://github.com/knative/eventing/issues/1777
 const (
 	// Constants for the underlying HTTP Client transport. These would enable better connection reuse.
 	// Purposely set them to be equal, as the ingress only connects to its channel.
@@ -66,6 +70,12 @@ type envConfig struct {
 	ContainerName string `envconfig:"CONTAINER_NAME" required:"true"`
 	Port          int    `envconfig:"INGRESS_PORT" default:"8080"`
 	MaxTTL        int    `envconfig:"MAX_TTL" default:"255"`
+
+	// EventTypeSampleRate is the fraction of the received events whose type is recorded to
+	// register it as an EventType. The event types aren't recorded when it's 0.
+	EventTypeSampleRate float64 `envconfig:"EVENT_TYPE_SAMPLE_RATE" default:"0"`
+	// EventTypeFlushInterval is the period between two registrations of the recorded event types.
+	EventTypeFlushInterval time.Duration `envconfig:"EVENT_TYPE_FLUSH_INTERVAL" default:"30s"`
 }
 
 func main() {
@@ -85,6 +95,10 @@ func main() {
 		log.Fatalf("Invalid MaxTTL value, must be >=0, was: %d", env.MaxTTL)
 	}
 
+	if env.EventTypeSampleRate < 0 || env.EventTypeSampleRate > 1 {
+		log.Fatalf("Invalid EventTypeSampleRate value, must be between 0 and 1, was: %v", env.EventTypeSampleRate)
+	}
+
 	log.Printf("Using TTL of %d", env.MaxTTL)
 	log.Printf("Registering %d clients", len(injection.Default.GetClients()))
 	log.Printf("Registering %d informer factories", len(injection.Default.GetInformerFactories()))
@@ -102,6 +116,11 @@ func main() {
 	logger.Info("Starting the Broker Ingress")
 
 	brokerLister := brokerinformer.Get(ctx).Lister()
+	eventTypeInformer := eventtypeinformer.Get(ctx)
+	eventTypeLister := eventTypeInformer.Lister()
+	if err := eventTypeInformer.Informer().AddIndexers(broker.EventTypeIndexers); err != nil {
+		logger.Fatal("Failed to index the EventTypes", zap.Error(err))
+	}
 
 	// Watch the logging config map and dynamically update logging levels.
 	configMapWatcher := configmap.NewInformedWatcher(kubeclient.Get(ctx), system.Namespace())
@@ -136,12 +155,20 @@ func main() {
 	reporter := ingress.NewStatsReporter(env.ContainerName, kmeta.ChildName(env.PodName, uuid.New().String()))
 
 	h := &ingress.Handler{
-		Receiver:     kncloudevents.NewHTTPMessageReceiver(env.Port),
-		Sender:       sender,
-		Defaulter:    broker.TTLDefaulter(logger, int32(env.MaxTTL)),
-		Reporter:     reporter,
-		Logger:       logger,
-		BrokerLister: brokerLister,
+		Receiver:         kncloudevents.NewHTTPMessageReceiver(env.Port),
+		Sender:           sender,
+		Defaulter:        broker.TTLDefaulter(logger, int32(env.MaxTTL)),
+		Reporter:         reporter,
+		Logger:           logger,
+		BrokerLister:     brokerLister,
+		EventTypeIndexer: eventTypeInformer.Informer().GetIndexer(),
+		SchemaCache:      broker.NewSchemaCache(),
+	}
+	eventTypeInformer.Informer().AddEventHandler(h.SchemaCache.EventTypeEventHandler())
+
+	if env.EventTypeSampleRate > 0 {
+		h.EventTypeRecorder = ingress.NewEventTypeRecorder(eventingclient.Get(ctx), brokerLister, eventTypeLister,
+			env.EventTypeSampleRate, env.EventTypeFlushInterval, logger)
 	}
 
 	// configMapWatcher does not block, so start it first.
@@ -155,6 +182,10 @@ func main() {
 		logger.Fatal("Failed to start informers", zap.Error(err))
 	}
 
+	if h.EventTypeRecorder != nil {
+		go h.EventTypeRecorder.Start(ctx)
+	}
+
 	// Start blocks forever.
 	if err = h.Start(ctx); err != nil {
 		logger.Error("ingress.Start() returned an error", zap.Error(err))
@@ -18,14 +18,17 @@ package apiserver
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"time"
 
 	cloudevents "github.com/cloudevents/sdk-go/v2"
 	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
@@ -70,6 +73,14 @@ func (a *apiServerAdapter) start(ctx context.Context, stopCh <-chan struct{}) er
 		ref:    a.config.EventMode == v1.ReferenceMode,
 	}
 
+	if len(a.config.Operations) > 0 {
+		a.logger.Infow("will be filtered", zap.Strings("Operations", a.config.Operations))
+		delegate = &operationFilter{
+			operations: sets.NewString(a.config.Operations...),
+			delegate:   delegate,
+		}
+	}
+
 	if a.config.ResourceOwner != nil {
 		a.logger.Infow("will be filtered",
 			zap.String("APIVersion", a.config.ResourceOwner.APIVersion),
@@ -81,6 +92,18 @@ func (a *apiServerAdapter) start(ctx context.Context, stopCh <-chan struct{}) er
 		}
 	}
 
+	if a.config.NamespaceSelector != "" {
+		a.logger.Infow("will be filtered", zap.String("NamespaceSelector", a.config.NamespaceSelector))
+		namespaces, err := a.watchNamespaces(ctx, stop, stopCh, resyncPeriod)
+		if err != nil {
+			return err
+		}
+		delegate = &namespaceFilter{
+			namespaces: namespaces,
+			delegate:   delegate,
+		}
+	}
+
 	a.logger.Infof("STARTING -- %#v", a.config)
 
 	for _, configRes := range a.config.Resources {
@@ -96,15 +119,18 @@ func (a *apiServerAdapter) start(ctx context.Context, stopCh <-chan struct{}) er
 			if apires.Name == configRes.GVR.Resource {
 
 				var res dynamic.ResourceInterface
-				if apires.Namespaced {
+				if apires.Namespaced && a.config.NamespaceSelector != "" {
+					// The namespaceFilter drops the resources of the other namespaces.
+					res = a.k8s.Resource(configRes.GVR).Namespace(metav1.NamespaceAll)
+				} else if apires.Namespaced {
 					res = a.k8s.Resource(configRes.GVR).Namespace(a.config.Namespace)
 				} else {
 					res = a.k8s.Resource(configRes.GVR)
 				}
 
 				lw := &cache.ListWatch{
-					ListFunc:  asUnstructuredLister(ctx, res.List, configRes.LabelSelector),
-					WatchFunc: asUnstructuredWatcher(ctx, res.Watch, configRes.LabelSelector),
+					ListFunc:  asUnstructuredLister(ctx, res.List, configRes.LabelSelector, configRes.FieldSelector),
+					WatchFunc: asUnstructuredWatcher(ctx, res.Watch, configRes.LabelSelector, configRes.FieldSelector),
 				}
 
 				reflector := cache.NewReflector(lw, &unstructured.Unstructured{}, delegate, resyncPeriod)
@@ -128,18 +154,38 @@ func (a *apiServerAdapter) start(ctx context.Context, stopCh <-chan struct{}) er
 	go srv.ListenAndServe()
 
 	<-stopCh
-	stop <- struct{}{}
+	close(stop)
 	srv.Shutdown(ctx)
 	return nil
 }
 
+// watchNamespaces keeps track of the namespaces matching the namespace selector, returning once
+// they're listed.
+func (a *apiServerAdapter) watchNamespaces(ctx context.Context, stop, stopCh <-chan struct{}, resyncPeriod time.Duration) (cache.Store, error) {
+	res := a.k8s.Resource(corev1.SchemeGroupVersion.WithResource("namespaces"))
+	lw := &cache.ListWatch{
+		ListFunc:  asUnstructuredLister(ctx, res.List, a.config.NamespaceSelector, ""),
+		WatchFunc: asUnstructuredWatcher(ctx, res.Watch, a.config.NamespaceSelector, ""),
+	}
+
+	namespaces, informer := cache.NewInformer(lw, &unstructured.Unstructured{}, resyncPeriod, cache.ResourceEventHandlerFuncs{})
+	go informer.Run(stop)
+	if !cache.WaitForCacheSync(stopCh, informer.HasSynced) {
+		return nil, errors.New("failed to list the namespaces matching the selector")
+	}
+	return namespaces, nil
+}
+
 type unstructuredLister func(context.Context, metav1.ListOptions) (*unstructured.UnstructuredList, error)
 
-func asUnstructuredLister(ctx context.Context, ulist unstructuredLister, selector string) cache.ListFunc {
+func asUnstructuredLister(ctx context.Context, ulist unstructuredLister, selector, fieldSelector string) cache.ListFunc {
 	return func(opts metav1.ListOptions) (runtime.Object, error) {
 		if selector != "" && opts.LabelSelector == "" {
 			opts.LabelSelector = selector
 		}
+		if fieldSelector != "" && opts.FieldSelector == "" {
+			opts.FieldSelector = fieldSelector
+		}
 		ul, err := ulist(ctx, opts)
 		if err != nil {
 			return nil, err
@@ -150,11 +196,14 @@ func asUnstructuredLister(ctx context.Context, ulist unstructuredLister, selecto
 
 type structuredWatcher func(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error)
 
-func asUnstructuredWatcher(ctx context.Context, wf structuredWatcher, selector string) cache.WatchFunc {
+func asUnstructuredWatcher(ctx context.Context, wf structuredWatcher, selector, fieldSelector string) cache.WatchFunc {
 	return func(lo metav1.ListOptions) (watch.Interface, error) {
 		if selector != "" && lo.LabelSelector == "" {
 			lo.LabelSelector = selector
 		}
+		if fieldSelector != "" && lo.FieldSelector == "" {
+			lo.FieldSelector = fieldSelector
+		}
 		return wf(ctx, lo)
 	}
 }
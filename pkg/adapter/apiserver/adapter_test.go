@@ -18,9 +18,11 @@ package apiserver
 
 import (
 	"context"
+	"sort"
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
@@ -28,6 +30,7 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/discovery"
 	discoveryfake "k8s.io/client-go/discovery/fake"
 	"k8s.io/client-go/dynamic"
@@ -181,6 +184,104 @@ func TestAdapter_StartNonNamespacedResource(t *testing.T) {
 	}
 }
 
+func TestAdapter_StartNamespaces(t *testing.T) {
+	ce := adaptertest.NewTestClient()
+
+	config := Config{
+		Namespace:         "default",
+		NamespaceSelector: "env=test",
+		Resources: []ResourceWatch{{
+			GVR: schema.GroupVersionResource{
+				Version:  "v1",
+				Resource: "pods",
+			},
+		}},
+		EventMode: "Resource",
+	}
+	ctx, _ := pkgtesting.SetupFakeContext(t)
+	k8s := makeDynamicClient(
+		labeledNamespace("ns1", "test"),
+		labeledNamespace("ns2", "test"),
+		labeledNamespace("ns3", "prod"),
+	)
+
+	a := &apiServerAdapter{
+		ce:     ce,
+		logger: logging.FromContext(ctx),
+		config: config,
+
+		discover: makeDiscoveryClient(),
+		k8s:      k8s,
+		source:   "unit-test",
+		name:     "unittest",
+	}
+
+	err := errors.New("test never ran")
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		err = a.Start(ctx)
+		close(done)
+	}()
+
+	// Wait for the reflectors to be fully initialized.
+	time.Sleep(1 * time.Second)
+
+	// Only the pods of the namespaces matching the selector are tracked.
+	pods := schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+	for _, ns := range []string{"ns1", "ns2", "ns3"} {
+		if _, err := k8s.Resource(pods).Namespace(ns).Create(ctx, simplePod("foo", ns), metav1.CreateOptions{}); err != nil {
+			t.Fatal("Failed to create a pod:", err)
+		}
+	}
+	time.Sleep(500 * time.Millisecond)
+
+	cancel()
+	<-done
+
+	if err != nil {
+		t.Error("Did not expect an error, but got:", err)
+	}
+	var got []string
+	for _, event := range ce.Sent() {
+		got = append(got, event.Subject())
+	}
+	sort.Strings(got)
+	want := []string{
+		"/apis/v1/namespaces/ns1/pods/foo",
+		"/apis/v1/namespaces/ns2/pods/foo",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Error("Unexpected events (-want, +got) =", diff)
+	}
+}
+
+func TestAsUnstructuredSelectors(t *testing.T) {
+	var got metav1.ListOptions
+	list := asUnstructuredLister(context.Background(), func(_ context.Context, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+		got = opts
+		return &unstructured.UnstructuredList{}, nil
+	}, "app=foo", "status.phase=Running")
+	watcher := asUnstructuredWatcher(context.Background(), func(_ context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+		got = opts
+		return watch.NewEmptyWatch(), nil
+	}, "app=foo", "status.phase=Running")
+
+	want := metav1.ListOptions{LabelSelector: "app=foo", FieldSelector: "status.phase=Running"}
+	if _, err := list(metav1.ListOptions{}); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Error("Unexpected list options (-want, +got) =", diff)
+	}
+	if _, err := watcher(metav1.ListOptions{}); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Error("Unexpected watch options (-want, +got) =", diff)
+	}
+}
+
 // Common methods:
 
 // GetDynamicClient returns the mockDynamicClient to use for this test case.
@@ -246,6 +347,12 @@ func simpleNamespace(name string) *unstructured.Unstructured {
 	}
 }
 
+func labeledNamespace(name, env string) *unstructured.Unstructured {
+	ns := simpleNamespace(name)
+	ns.SetLabels(map[string]string{"env": env})
+	return ns
+}
+
 func simpleOwnedPod(name, namespace string) *unstructured.Unstructured {
 	return &unstructured.Unstructured{
 		Object: map[string]interface{}{
@@ -28,6 +28,11 @@ type ResourceWatch struct {
 	// label selector.
 	// +optional
 	LabelSelector string `json:"selector,omitempty"`
+
+	// FieldSelector filters this source to objects to those resources pass the
+	// field selector.
+	// +optional
+	FieldSelector string `json:"fieldSelector,omitempty"`
 }
 
 type Config struct {
@@ -35,6 +40,11 @@ type Config struct {
 	// +required
 	Namespace string `json:"namespace"`
 
+	// NamespaceSelector tracks the resources of all the namespaces matching
+	// the label selector instead of Namespace.
+	// +optional
+	NamespaceSelector string `json:"namespaceSelector,omitempty"`
+
 	// Resource is the resource this source will track and send related
 	// lifecycle events from the Kubernetes ApiServer.
 	// +required
@@ -52,4 +62,9 @@ type Config struct {
 	// Defaults to `Reference`
 	// +optional
 	EventMode string `json:"mode,omitempty"`
+
+	// Operations are the operations on the resources sending events, all of
+	// them if empty.
+	// +optional
+	Operations []string `json:"operations,omitempty"`
 }
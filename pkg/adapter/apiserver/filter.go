@@ -19,7 +19,10 @@ package apiserver
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/tools/cache"
+
+	v1 "knative.dev/eventing/pkg/apis/sources/v1"
 )
 
 // controllerFilter by apiVersion and/or kind
@@ -95,3 +98,146 @@ func (c *controllerFilter) Replace([]interface{}, string) error {
 func (c *controllerFilter) Resync() error {
 	return nil
 }
+
+// operationFilter by the operation on the resource
+type operationFilter struct {
+	operations sets.String
+	delegate   cache.Store
+}
+
+var _ cache.Store = (*operationFilter)(nil)
+
+// Implements Store
+
+func (c *operationFilter) Add(obj interface{}) error {
+	if !c.operations.Has(v1.AddOperation) {
+		return nil
+	}
+
+	return c.delegate.Add(obj)
+}
+
+func (c *operationFilter) Update(obj interface{}) error {
+	if !c.operations.Has(v1.UpdateOperation) {
+		return nil
+	}
+
+	return c.delegate.Update(obj)
+}
+
+func (c *operationFilter) Delete(obj interface{}) error {
+	if !c.operations.Has(v1.DeleteOperation) {
+		return nil
+	}
+
+	return c.delegate.Delete(obj)
+}
+
+// Stub cache.Store impl
+
+// Implements cache.Store
+func (c *operationFilter) List() []interface{} {
+	return nil
+}
+
+// Implements cache.Store
+func (c *operationFilter) ListKeys() []string {
+	return nil
+}
+
+// Implements cache.Store
+func (c *operationFilter) Get(obj interface{}) (item interface{}, exists bool, err error) {
+	return nil, false, nil
+}
+
+// Implements cache.Store
+func (c *operationFilter) GetByKey(key string) (item interface{}, exists bool, err error) {
+	return nil, false, nil
+}
+
+// Implements cache.Store
+func (c *operationFilter) Replace([]interface{}, string) error {
+	return nil
+}
+
+// Implements cache.Store
+func (c *operationFilter) Resync() error {
+	return nil
+}
+
+// namespaceFilter by the namespace of the resource
+type namespaceFilter struct {
+	// namespaces holds the namespaces whose resources are tracked.
+	namespaces cache.Store
+	delegate   cache.Store
+}
+
+var _ cache.Store = (*namespaceFilter)(nil)
+
+// Implements Store
+
+func (c *namespaceFilter) Add(obj interface{}) error {
+	if c.filtered(obj) {
+		return nil
+	}
+
+	return c.delegate.Add(obj)
+}
+
+func (c *namespaceFilter) Update(obj interface{}) error {
+	if c.filtered(obj) {
+		return nil
+	}
+
+	return c.delegate.Update(obj)
+}
+
+func (c *namespaceFilter) Delete(obj interface{}) error {
+	if c.filtered(obj) {
+		return nil
+	}
+
+	return c.delegate.Delete(obj)
+}
+
+func (c *namespaceFilter) filtered(obj interface{}) bool {
+	namespace := obj.(*unstructured.Unstructured).GetNamespace()
+	if namespace == "" {
+		// Cluster-scoped resource.
+		return false
+	}
+	_, exists, _ := c.namespaces.GetByKey(namespace)
+	return !exists
+}
+
+// Stub cache.Store impl
+
+// Implements cache.Store
+func (c *namespaceFilter) List() []interface{} {
+	return nil
+}
+
+// Implements cache.Store
+func (c *namespaceFilter) ListKeys() []string {
+	return nil
+}
+
+// Implements cache.Store
+func (c *namespaceFilter) Get(obj interface{}) (item interface{}, exists bool, err error) {
+	return nil, false, nil
+}
+
+// Implements cache.Store
+func (c *namespaceFilter) GetByKey(key string) (item interface{}, exists bool, err error) {
+	return nil, false, nil
+}
+
+// Implements cache.Store
+func (c *namespaceFilter) Replace([]interface{}, string) error {
+	return nil
+}
+
+// Implements cache.Store
+func (c *namespaceFilter) Resync() error {
+	return nil
+}
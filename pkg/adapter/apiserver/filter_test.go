@@ -19,8 +19,12 @@ package apiserver
 import (
 	"testing"
 
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+
 	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
 	sources "knative.dev/eventing/pkg/apis/sources"
+	v1 "knative.dev/eventing/pkg/apis/sources/v1"
 )
 
 func TestControllerAddEventWithNoController(t *testing.T) {
@@ -95,6 +99,62 @@ func TestControllerDeleteEventWithGoodControllerNoAPIVersion(t *testing.T) {
 	validateSent(t, tc, sources.ApiServerSourceDeleteRefEventType)
 }
 
+func TestOperationAddEventFiltered(t *testing.T) {
+	c, tc := makeOperationFilter(v1.UpdateOperation, v1.DeleteOperation)
+	c.Add(simplePod("unit", "test"))
+	validateNotSent(t, tc, sources.ApiServerSourceAddRefEventType)
+}
+
+func TestOperationAddEvent(t *testing.T) {
+	c, tc := makeOperationFilter(v1.AddOperation)
+	c.Add(simplePod("unit", "test"))
+	validateSent(t, tc, sources.ApiServerSourceAddRefEventType)
+}
+
+func TestOperationUpdateEventFiltered(t *testing.T) {
+	c, tc := makeOperationFilter(v1.AddOperation, v1.DeleteOperation)
+	c.Update(simplePod("unit", "test"))
+	validateNotSent(t, tc, sources.ApiServerSourceUpdateRefEventType)
+}
+
+func TestOperationUpdateEvent(t *testing.T) {
+	c, tc := makeOperationFilter(v1.UpdateOperation)
+	c.Update(simplePod("unit", "test"))
+	validateSent(t, tc, sources.ApiServerSourceUpdateRefEventType)
+}
+
+func TestOperationDeleteEventFiltered(t *testing.T) {
+	c, tc := makeOperationFilter(v1.AddOperation, v1.UpdateOperation)
+	c.Delete(simplePod("unit", "test"))
+	validateNotSent(t, tc, sources.ApiServerSourceDeleteRefEventType)
+}
+
+func TestOperationDeleteEvent(t *testing.T) {
+	c, tc := makeOperationFilter(v1.DeleteOperation)
+	c.Delete(simplePod("unit", "test"))
+	validateSent(t, tc, sources.ApiServerSourceDeleteRefEventType)
+}
+
+func TestNamespaceEventFiltered(t *testing.T) {
+	c, tc := makeNamespaceFilter("selected")
+	c.Add(simplePod("unit", "test"))
+	c.Update(simplePod("unit", "test"))
+	c.Delete(simplePod("unit", "test"))
+	validateNotSent(t, tc, sources.ApiServerSourceAddRefEventType)
+}
+
+func TestNamespaceEvent(t *testing.T) {
+	c, tc := makeNamespaceFilter("test")
+	c.Add(simplePod("unit", "test"))
+	validateSent(t, tc, sources.ApiServerSourceAddRefEventType)
+}
+
+func TestNamespaceClusterScopedEvent(t *testing.T) {
+	c, tc := makeNamespaceFilter("test")
+	c.Add(simpleNamespace("unit"))
+	validateSent(t, tc, sources.ApiServerSourceAddRefEventType)
+}
+
 func makeController(apiVersion, kind string) (*controllerFilter, *adaptertest.TestCloudEventsClient) {
 	delegate, tc := makeRefAndTestingClient()
 	return &controllerFilter{
@@ -103,3 +163,23 @@ func makeController(apiVersion, kind string) (*controllerFilter, *adaptertest.Te
 		delegate:   delegate,
 	}, tc
 }
+
+func makeOperationFilter(operations ...string) (*operationFilter, *adaptertest.TestCloudEventsClient) {
+	delegate, tc := makeRefAndTestingClient()
+	return &operationFilter{
+		operations: sets.NewString(operations...),
+		delegate:   delegate,
+	}, tc
+}
+
+func makeNamespaceFilter(namespaces ...string) (*namespaceFilter, *adaptertest.TestCloudEventsClient) {
+	delegate, tc := makeRefAndTestingClient()
+	store := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	for _, ns := range namespaces {
+		store.Add(simpleNamespace(ns))
+	}
+	return &namespaceFilter{
+		namespaces: store,
+		delegate:   delegate,
+	}, tc
+}
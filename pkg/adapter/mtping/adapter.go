@@ -27,12 +27,17 @@ import (
 	cloudevents "github.com/cloudevents/sdk-go/v2"
 	"github.com/robfig/cron/v3"
 	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
 
 	kubeclient "knative.dev/pkg/client/injection/kube/client"
 	"knative.dev/pkg/logging"
+	"knative.dev/pkg/reconciler"
+	"knative.dev/pkg/system"
 
 	"knative.dev/eventing/pkg/adapter/v2"
 	sourcesv1 "knative.dev/eventing/pkg/apis/sources/v1"
+	eventingclient "knative.dev/eventing/pkg/client/injection/client"
 )
 
 const (
@@ -43,6 +48,7 @@ const (
 type mtpingAdapter struct {
 	logger    *zap.SugaredLogger
 	runner    CronJobRunner
+	ticks     *tickStore
 	entryidMu sync.RWMutex
 	entryids  map[string]cron.EntryID // key: resource namespace/name
 }
@@ -59,10 +65,13 @@ func NewEnvConfig() adapter.EnvConfigAccessor {
 func NewAdapter(ctx context.Context, _ adapter.EnvConfigAccessor, ceClient cloudevents.Client) adapter.Adapter {
 	logger := logging.FromContext(ctx)
 	runner := NewCronJobsRunner(ceClient, kubeclient.Get(ctx), logging.FromContext(ctx))
+	runner.ticks = newTickStore(kubeclient.Get(ctx).CoreV1().ConfigMaps(system.Namespace()), logger)
+	runner.sourcesClient = eventingclient.Get(ctx).SourcesV1()
 
 	return &mtpingAdapter{
 		logger:    logger,
 		runner:    runner,
+		ticks:     runner.ticks,
 		entryidMu: sync.RWMutex{},
 		entryids:  make(map[string]cron.EntryID),
 	}
@@ -71,6 +80,9 @@ func NewAdapter(ctx context.Context, _ adapter.EnvConfigAccessor, ceClient cloud
 // Start implements adapter.Adapter
 func (a *mtpingAdapter) Start(ctx context.Context) error {
 	a.logger.Info("Starting job runner...")
+	if a.ticks != nil {
+		go a.ticks.start(ctx.Done(), ticksFlushInterval)
+	}
 	a.runner.Start(ctx.Done())
 	defer a.runner.Stop()
 
@@ -127,14 +139,29 @@ func (a *mtpingAdapter) Remove(source *sourcesv1.PingSource) {
 		delete(a.entryids, key)
 		a.entryidMu.Unlock()
 	}
+	if a.ticks != nil {
+		a.ticks.remove(tickKey(source))
+	}
 }
 
-func (a *mtpingAdapter) RemoveAll(ctx context.Context) {
+func (a *mtpingAdapter) RemoveBucket(ctx context.Context, bucket reconciler.Bucket) {
 	a.entryidMu.Lock()
-	defer a.entryidMu.Unlock()
-
-	for _, id := range a.entryids {
+	var keys []string
+	for key, id := range a.entryids {
+		namespace, name, err := cache.SplitMetaNamespaceKey(key)
+		if err != nil || !bucket.Has(types.NamespacedName{Namespace: namespace, Name: name}) {
+			continue
+		}
 		a.runner.RemoveSchedule(id)
+		delete(a.entryids, key)
+		keys = append(keys, namespace+"."+name)
+	}
+	a.entryidMu.Unlock()
+
+	// The ticks are now handled by the new owner of the bucket.
+	if a.ticks != nil && len(keys) > 0 {
+		if err := a.ticks.release(ctx, keys); err != nil {
+			logging.FromContext(ctx).Warnw("Failed to save the ticks of the PingSources handed over", zap.String("bucket", bucket.Name()), zap.Error(err))
+		}
 	}
-	a.entryids = make(map[string]cron.EntryID)
 }
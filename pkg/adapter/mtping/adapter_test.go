@@ -18,19 +18,34 @@ package mtping
 
 import (
 	"context"
+	"fmt"
+	"sort"
 	"sync"
 	"testing"
 	"time"
 
+	cetypes "github.com/cloudevents/sdk-go/v2/types"
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes/fake"
 
 	sourcesv1 "knative.dev/eventing/pkg/apis/sources/v1"
 
 	"github.com/robfig/cron/v3"
 
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
 	_ "knative.dev/pkg/client/injection/kube/client/fake"
+	"knative.dev/pkg/hash"
 	"knative.dev/pkg/logging"
+	"knative.dev/pkg/reconciler"
 	rectesting "knative.dev/pkg/reconciler/testing"
+	_ "knative.dev/pkg/system/testing"
 
 	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
 )
@@ -92,6 +107,184 @@ func TestUpdateRemoveAdapter(t *testing.T) {
 	}
 }
 
+func TestBucketHandover(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	logger := logging.FromContext(ctx)
+	start := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	fakeClock := clock.NewFakeClock(start)
+	configMaps := fake.NewSimpleClientset().CoreV1().ConfigMaps("")
+
+	buckets := hash.NewBucketSet(sets.NewString("bucket-0", "bucket-1")).Buckets()
+	var sources []*sourcesv1.PingSource
+	for i := 0; i < 6; i++ {
+		sources = append(sources, &sourcesv1.PingSource{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprint("test-name-", i),
+				Namespace: "test-ns",
+			},
+			Spec: sourcesv1.PingSourceSpec{
+				Schedule:               "*/10 * * * * *",
+				MissedTickPolicy:       sourcesv1.MissedTickFireAll,
+				ScheduledTimeExtension: true,
+			},
+			Status: sourcesv1.PingSourceStatus{
+				SourceStatus: duckv1.SourceStatus{
+					SinkURI: &apis.URL{Path: "a sink"},
+				},
+			},
+		})
+	}
+	inBucket := func(bucket reconciler.Bucket) int {
+		n := 0
+		for _, source := range sources {
+			if bucket.Has(types.NamespacedName{Namespace: source.Namespace, Name: source.Name}) {
+				n++
+			}
+		}
+		return n
+	}
+	require.NotZero(t, inBucket(buckets[0]))
+	require.NotZero(t, inBucket(buckets[1]))
+
+	// Each replica has its own schedules and shares the ticks with the others.
+	type replica struct {
+		*mtpingAdapter
+		ce *adaptertest.TestCloudEventsClient
+	}
+	newReplica := func() replica {
+		ce := adaptertest.NewTestClient()
+		runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logger)
+		runner.ticks = newTickStore(configMaps, logger)
+		runner.clock = fakeClock
+		return replica{
+			mtpingAdapter: &mtpingAdapter{
+				logger:   logger,
+				runner:   runner,
+				ticks:    runner.ticks,
+				entryids: make(map[string]cron.EntryID),
+			},
+			ce: ce,
+		}
+	}
+	promote := func(r replica, bucket reconciler.Bucket) {
+		for _, source := range sources {
+			if bucket.Has(types.NamespacedName{Namespace: source.Namespace, Name: source.Name}) {
+				r.Update(ctx, source)
+			}
+		}
+	}
+	// jobs returns the jobs scheduled by r, run by tick.
+	jobs := func(r replica) []cron.Job {
+		var jobs []cron.Job
+		for _, entry := range r.runner.(*cronJobsRunner).cron.Entries() {
+			jobs = append(jobs, entry.Job)
+		}
+		return jobs
+	}
+	tick := func(jobs []cron.Job) {
+		var wg sync.WaitGroup
+		for _, job := range jobs {
+			wg.Add(1)
+			go func(job cron.Job) {
+				defer wg.Done()
+				job.Run()
+			}(job)
+		}
+		wg.Wait()
+	}
+	advance := func(to time.Duration) {
+		fakeClock.SetTime(start.Add(to))
+	}
+
+	a, b := newReplica(), newReplica()
+	sent := func() int {
+		return len(a.ce.Sent()) + len(b.ce.Sent())
+	}
+
+	// a leads both buckets.
+	promote(a, buckets[0])
+	promote(a, buckets[1])
+	advance(10 * time.Second)
+	tick(jobs(a))
+	require.Equal(t, len(sources), sent())
+
+	// a hands bucket-0 over to b, which reads the ticks of both buckets.
+	advance(15 * time.Second)
+	a.RemoveBucket(ctx, buckets[0])
+	promote(b, buckets[0])
+	advance(20 * time.Second)
+	tick(jobs(a))
+	tick(jobs(b))
+	require.Equal(t, 2*len(sources), sent())
+
+	// a hands bucket-1 over to b while the jobs of a tick are starting, they don't fire
+	// for the sources of bucket-1.
+	advance(30 * time.Second)
+	started := jobs(a)
+	a.RemoveBucket(ctx, buckets[1])
+	tick(started)
+	tick(jobs(b))
+	require.Equal(t, 2*len(sources)+inBucket(buckets[0]), sent())
+
+	// b catches up with the tick missed in between, from the ticks saved by a since b read
+	// them.
+	advance(35 * time.Second)
+	promote(b, buckets[1])
+	require.Eventually(t, func() bool {
+		return sent() == 3*len(sources)
+	}, 5*time.Second, 10*time.Millisecond)
+	advance(40 * time.Second)
+	tick(jobs(a))
+	tick(jobs(b))
+	require.Equal(t, 4*len(sources), sent())
+
+	// b goes away and a takes both buckets back, catching up with the tick missed in between.
+	advance(42 * time.Second)
+	b.RemoveBucket(ctx, buckets[0])
+	b.RemoveBucket(ctx, buckets[1])
+	require.Empty(t, jobs(b))
+	advance(55 * time.Second)
+	promote(a, buckets[0])
+	promote(a, buckets[1])
+	require.Eventually(t, func() bool {
+		return sent() == 5*len(sources)
+	}, 5*time.Second, 10*time.Millisecond)
+	advance(60 * time.Second)
+	tick(jobs(a))
+
+	// Each tick was fired exactly once.
+	time.Sleep(600 * time.Millisecond)
+	want := make(map[string][]time.Time)
+	for _, source := range sources {
+		for i := 1; i <= 6; i++ {
+			key := sourcesv1.PingSourceSource(source.Namespace, source.Name)
+			want[key] = append(want[key], start.Add(time.Duration(i)*10*time.Second))
+		}
+	}
+	got := make(map[string][]time.Time)
+	for _, event := range append(a.ce.Sent(), b.ce.Sent()...) {
+		scheduled, err := cetypes.ToTime(event.Extensions()[sourcesv1.PingSourceScheduledTimeExtension])
+		require.NoError(t, err)
+		got[event.Source()] = append(got[event.Source()], scheduled)
+	}
+	for _, times := range got {
+		sort.Slice(times, func(i, j int) bool {
+			return times[i].Before(times[j])
+		})
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Error("Unexpected scheduled times (-want, +got) =", diff)
+	}
+
+	// The last ticks are saved for the next owners.
+	require.NoError(t, a.ticks.flush(ctx))
+	cm, err := configMaps.Get(ctx, ticksConfigMapName, metav1.GetOptions{})
+	require.NoError(t, err)
+	for _, source := range sources {
+		require.Equal(t, "2021-06-01T12:01:00Z", cm.Data[tickKey(source)])
+	}
+}
+
 type testRunner struct {
 	CronJobRunner
 }
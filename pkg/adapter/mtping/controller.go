@@ -40,8 +40,9 @@ type MTAdapter interface {
 	// Remove is called when the source has been deleted.
 	Remove(source *sourcesv1.PingSource)
 
-	// RemoveAll is called when the adapter stopped leading
-	RemoveAll(ctx context.Context)
+	// RemoveBucket is called when the adapter stopped leading bucket. The sources of the
+	// bucket are handed over to the replica leading it next.
+	RemoveBucket(ctx context.Context, bucket reconciler.Bucket)
 }
 
 // NewController initializes the controller. This is called by the shared adapter Main
@@ -58,7 +59,7 @@ func NewController(ctx context.Context, adapter adapter.Adapter) *controller.Imp
 		return controller.Options{
 			SkipStatusUpdates: true,
 			DemoteFunc: func(b reconciler.Bucket) {
-				mtadapter.RemoveAll(ctx)
+				mtadapter.RemoveBucket(ctx, b)
 			},
 		}
 	})
@@ -22,6 +22,7 @@ import (
 
 	"testing"
 
+	"knative.dev/pkg/reconciler"
 	. "knative.dev/pkg/reconciler/testing"
 
 	"knative.dev/eventing/pkg/adapter/v2"
@@ -47,7 +48,7 @@ func (testAdapter) Remove(p *sourcesv1.PingSource) {
 	removePingsource[fmt.Sprintf("%s/%s", p.Namespace, p.Name)] = true
 }
 
-func (testAdapter) RemoveAll(context.Context) {
+func (testAdapter) RemoveBucket(context.Context, reconciler.Bucket) {
 }
 
 func TestNew(t *testing.T) {
@@ -19,15 +19,22 @@ package mtping
 import (
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"math/rand"
+	"sync/atomic"
+	"text/template"
 	"time"
 
 	cloudevents "github.com/cloudevents/sdk-go/v2"
 	cecontext "github.com/cloudevents/sdk-go/v2/context"
 	"github.com/google/uuid"
+	"github.com/rickb777/date/period"
 	"github.com/robfig/cron/v3"
 	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
 	"k8s.io/client-go/kubernetes"
 	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/tools/record"
@@ -35,6 +42,7 @@ import (
 	kncloudevents "knative.dev/eventing/pkg/adapter/v2"
 	"knative.dev/eventing/pkg/adapter/v2/util/crstatusevent"
 	sourcesv1 "knative.dev/eventing/pkg/apis/sources/v1"
+	sourcesclientv1 "knative.dev/eventing/pkg/client/clientset/versioned/typed/sources/v1"
 )
 
 type CronJobRunner interface {
@@ -56,23 +64,36 @@ type cronJobsRunner struct {
 
 	// kubeClient for sending k8s events
 	kubeClient kubernetes.Interface
+
+	// ticks keeps the last tick of the sources catching up with their missed ticks, if set.
+	ticks *tickStore
+
+	// sourcesClient reports the events sent by the one-shot sources, if set.
+	sourcesClient sourcesclientv1.PingSourcesGetter
+
+	clock clock.PassiveClock
 }
 
 const (
 	resourceGroup = "pingsources.sources.knative.dev"
+
+	// defaultMissedTickLookback is how far back the missed ticks are caught up by default.
+	defaultMissedTickLookback = time.Hour
 )
 
 func NewCronJobsRunner(ceClient cloudevents.Client, kubeClient kubernetes.Interface, logger *zap.SugaredLogger, opts ...cron.Option) *cronJobsRunner {
+	opts = append([]cron.Option{cron.WithParser(sourcesv1.PingScheduleParser)}, opts...)
 	return &cronJobsRunner{
 		cron:       *cron.New(opts...),
 		Client:     ceClient,
 		Logger:     logger,
 		kubeClient: kubeClient,
+		clock:      clock.RealClock{},
 	}
 }
 
 func (a *cronJobsRunner) AddSchedule(source *sourcesv1.PingSource) cron.EntryID {
-	event, err := makeEvent(source)
+	events, err := newTickEvents(source)
 	if err != nil {
 		a.Logger.Error("failed to makeEvent: ", zap.Error(err))
 	}
@@ -93,14 +114,148 @@ func (a *cronJobsRunner) AddSchedule(source *sourcesv1.PingSource) cron.EntryID
 		ResourceGroup: resourceGroup,
 	}
 
+	ctx = kncloudevents.ContextWithMetricTag(ctx, metricTag)
+
+	schedule, err := parseSchedule(source)
+	if err != nil {
+		a.Logger.Error("failed to parse the schedule: ", zap.Error(err))
+		return 0
+	}
+
+	var track func(time.Time) bool
+	if a.ticks != nil {
+		key := tickKey(source)
+		if catchesUp(source) {
+			a.catchUp(ctx, source, schedule, events)
+			track = func(t time.Time) bool {
+				return a.ticks.set(key, t)
+			}
+		} else {
+			a.ticks.remove(key)
+		}
+	}
+
+	var fired func(time.Time)
+	if source.Spec.At != nil && a.sourcesClient != nil {
+		fired = func(t time.Time) {
+			a.reportFired(ctx, source, t)
+		}
+	}
+
+	return a.cron.Schedule(schedule, cron.FuncJob(a.cronTick(ctx, events, track, fired)))
+}
+
+// reportFired annotates the one-shot source with the time t its event was sent at, for the
+// PingSource reconciler to mark it completed.
+func (a *cronJobsRunner) reportFired(ctx context.Context, source *sourcesv1.PingSource, t time.Time) {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{
+				sourcesv1.PingSourceFiredAtAnnotationKey: t.UTC().Format(time.RFC3339),
+			},
+		},
+	})
+	if err != nil {
+		a.Logger.Errorw("Failed to make the patch reporting the event of a one-shot source", zap.Error(err))
+		return
+	}
+	_, err = a.sourcesClient.PingSources(source.Namespace).Patch(ctx, source.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		a.Logger.Errorw("Failed to report the event of a one-shot source", zap.String("source", tickKey(source)), zap.Error(err))
+	}
+}
+
+func parseSchedule(source *sourcesv1.PingSource) (cron.Schedule, error) {
+	if source.Spec.At != nil {
+		return onceSchedule(source.Spec.At.Time), nil
+	}
+
 	schedule := source.Spec.Schedule
 	if source.Spec.Timezone != "" {
 		schedule = "CRON_TZ=" + source.Spec.Timezone + " " + schedule
 	}
+	return sourcesv1.PingScheduleParser.Parse(schedule)
+}
 
-	ctx = kncloudevents.ContextWithMetricTag(ctx, metricTag)
-	id, _ := a.cron.AddFunc(schedule, a.cronTick(ctx, event))
-	return id
+func catchesUp(source *sourcesv1.PingSource) bool {
+	return source.Spec.MissedTickPolicy == sourcesv1.MissedTickFireOnce || source.Spec.MissedTickPolicy == sourcesv1.MissedTickFireAll
+}
+
+// catchUp sends the events of the ticks of source missed since the last one handled, as told by
+// its MissedTickPolicy and no further back than its MissedTickLookback. The events carry the
+// scheduled time of their tick.
+func (a *cronJobsRunner) catchUp(ctx context.Context, source *sourcesv1.PingSource, schedule cron.Schedule, events *tickEvents) {
+	key := tickKey(source)
+	now := a.clock.Now()
+	last, ok := a.ticks.last(ctx, key)
+	// The ticks to come are handled by the schedule.
+	a.ticks.set(key, now)
+	if !ok {
+		return
+	}
+
+	lookback := defaultMissedTickLookback
+	if source.Spec.MissedTickLookback != nil {
+		if p, err := period.Parse(*source.Spec.MissedTickLookback); err == nil {
+			lookback, _ = p.Duration()
+		}
+	}
+	if lookback <= 0 || lookback > sourcesv1.PingSourceMaxMissedTickLookback {
+		lookback = sourcesv1.PingSourceMaxMissedTickLookback
+	}
+	if earliest := now.Add(-lookback); last.Before(earliest) {
+		last = earliest
+	}
+
+	missed, skipped := missedTicks(schedule, last, now)
+	if len(missed) == 0 {
+		return
+	}
+	if source.Spec.MissedTickPolicy == sourcesv1.MissedTickFireOnce {
+		missed = missed[len(missed)-1:]
+	} else if skipped > 0 {
+		a.Logger.Warnw("Skipping the oldest missed ticks", zap.String("source", key), zap.Int("ticks", skipped))
+	}
+
+	a.Logger.Infow("Catching up with missed ticks", zap.String("source", key), zap.Int("ticks", len(missed)))
+	go func() {
+		for _, t := range missed {
+			event, err := events.make(t)
+			if err != nil {
+				a.Logger.Errorw("Failed to make the event of a missed tick", zap.String("source", key), zap.Error(err))
+				continue
+			}
+			event.SetExtension(sourcesv1.PingSourceScheduledTimeExtension, t)
+			a.send(ctx, event)
+		}
+	}()
+}
+
+// missedTicks returns the ticks of schedule after last up to now, no more than the most recent
+// PingSourceMaxMissedTicks ones, along with the number of older ticks left out.
+func missedTicks(schedule cron.Schedule, last, now time.Time) ([]time.Time, int) {
+	var missed []time.Time
+	skipped := 0
+	for t := schedule.Next(last); !t.IsZero() && !t.After(now); t = schedule.Next(t) {
+		if len(missed) == sourcesv1.PingSourceMaxMissedTicks {
+			missed = missed[1:]
+			skipped++
+		}
+		missed = append(missed, t)
+	}
+	return missed, skipped
+}
+
+// onceSchedule is the schedule of a one-shot PingSource, firing at the given time if it's
+// still to come.
+type onceSchedule time.Time
+
+// Next implements cron.Schedule. The zero time is never reached, so the job doesn't run again.
+func (s onceSchedule) Next(t time.Time) time.Time {
+	if at := time.Time(s); t.Before(at) {
+		return at
+	}
+	return time.Time{}
 }
 
 func (a *cronJobsRunner) RemoveSchedule(id cron.EntryID) {
@@ -120,25 +275,99 @@ func (a *cronJobsRunner) Stop() {
 	}
 }
 
-func (a *cronJobsRunner) cronTick(ctx context.Context, event cloudevents.Event) func() {
+// cronTick returns the job sending event. track is called with the scheduled time of the tick
+// before it's handled, if set, and tells whether the tick is still handled by this replica.
+func (a *cronJobsRunner) cronTick(ctx context.Context, events *tickEvents, track func(time.Time) bool, fired func(time.Time)) func() {
 	return func() {
-		event := event.Clone()
-		event.SetID(uuid.New().String()) // provide an ID here so we can track it with logging
-		defer a.Logger.Debug("Finished sending cloudevent id: ", event.ID())
-		target := cecontext.TargetFrom(ctx).String()
-		source := event.Context.GetSource()
+		// The schedules have a resolution of one second.
+		scheduled := a.clock.Now().Truncate(time.Second)
+		if track != nil && !track(scheduled) {
+			// The source was handed over to another replica in the meantime.
+			return
+		}
+		if event, err := events.make(scheduled); err != nil {
+			a.Logger.Error("failed to make the event of the tick: ", zap.Error(err))
+		} else {
+			a.send(ctx, event)
+		}
+		if fired != nil {
+			fired(a.clock.Now())
+		}
+	}
+}
 
-		// Provide a delay so not all ping fired instantaneously distribute load on resources.
-		time.Sleep(time.Duration(rand.Intn(500)) * time.Millisecond) //nolint:gosec // Cryptographic randomness not necessary here.
+// send sends event to the target of ctx, after a random delay.
+func (a *cronJobsRunner) send(ctx context.Context, event cloudevents.Event) {
+	event.SetID(uuid.New().String()) // provide an ID here so we can track it with logging
+	defer a.Logger.Debug("Finished sending cloudevent id: ", event.ID())
+	target := cecontext.TargetFrom(ctx).String()
+	source := event.Context.GetSource()
 
-		a.Logger.Debugf("sending cloudevent id: %s, source: %s, target: %s", event.ID(), source, target)
+	// Provide a delay so not all ping fired instantaneously distribute load on resources.
+	time.Sleep(time.Duration(rand.Intn(500)) * time.Millisecond) //nolint:gosec // Cryptographic randomness not necessary here.
 
-		if result := a.Client.Send(ctx, event); !cloudevents.IsACK(result) {
-			// Exhausted number of retries. Event is lost.
-			a.Logger.Error("failed to send cloudevent result: ", zap.Any("result", result),
-				zap.String("source", source), zap.String("target", target), zap.String("id", event.ID()))
+	a.Logger.Debugf("sending cloudevent id: %s, source: %s, target: %s", event.ID(), source, target)
+
+	if result := a.Client.Send(ctx, event); !cloudevents.IsACK(result) {
+		// Exhausted number of retries. Event is lost.
+		a.Logger.Error("failed to send cloudevent result: ", zap.Any("result", result),
+			zap.String("source", source), zap.String("target", target), zap.String("id", event.ID()))
+	}
+}
+
+// tickEvents makes the events sent at the ticks of a PingSource.
+type tickEvents struct {
+	// event is sent as is unless the data is templated.
+	event       cloudevents.Event
+	contentType string
+	template    *template.Template
+	extension   bool
+	name        string
+	namespace   string
+	// sequence is the number of the last tick, accessed atomically.
+	sequence uint64
+}
+
+func newTickEvents(source *sourcesv1.PingSource) (*tickEvents, error) {
+	event, err := makeEvent(source)
+	events := &tickEvents{
+		event:       event,
+		contentType: source.Spec.ContentType,
+		extension:   source.Spec.ScheduledTimeExtension,
+		name:        source.Name,
+		namespace:   source.Namespace,
+	}
+	if err != nil {
+		return events, err
+	}
+
+	if source.Spec.DataTemplated && source.Spec.DataBase64 == "" {
+		events.template, err = sourcesv1.ParsePingSourceTemplate(source.Spec.Data)
+	}
+	return events, err
+}
+
+// make returns the event of the tick scheduled at t.
+func (e *tickEvents) make(t time.Time) (cloudevents.Event, error) {
+	event := e.event.Clone()
+	if e.template != nil {
+		data, err := sourcesv1.RenderPingSourceTemplate(e.template, sourcesv1.PingSourceTemplateInput{
+			Time:      t,
+			Name:      e.name,
+			Namespace: e.namespace,
+			Sequence:  atomic.AddUint64(&e.sequence, 1),
+		}, -1)
+		if err != nil {
+			return event, err
+		}
+		if err := event.SetData(e.contentType, data); err != nil {
+			return event, err
 		}
 	}
+	if e.extension {
+		event.SetExtension(sourcesv1.PingSourceScheduledTimeExtension, t)
+	}
+	return event, nil
 }
 
 func makeEvent(source *sourcesv1.PingSource) (cloudevents.Event, error) {
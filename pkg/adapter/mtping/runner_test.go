@@ -26,7 +26,14 @@ import (
 
 	cloudevents "github.com/cloudevents/sdk-go/v2"
 
+	"github.com/cloudevents/sdk-go/v2/types"
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/pointer"
 
 	"knative.dev/pkg/apis"
 	duckv1 "knative.dev/pkg/apis/duck/v1"
@@ -37,6 +44,7 @@ import (
 
 	adaptertesting "knative.dev/eventing/pkg/adapter/v2/test"
 	sourcesv1 "knative.dev/eventing/pkg/apis/sources/v1"
+	eventingfake "knative.dev/eventing/pkg/client/clientset/versioned/fake"
 )
 
 const (
@@ -223,6 +231,269 @@ func TestAddRunRemoveSchedules(t *testing.T) {
 	}
 }
 
+func TestScheduleNext(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	future := metav1.NewTime(now.Add(time.Hour))
+	past := metav1.NewTime(now.Add(-time.Hour))
+
+	testCases := map[string]struct {
+		spec sourcesv1.PingSourceSpec
+		want time.Time
+	}{
+		"seconds": {
+			spec: sourcesv1.PingSourceSpec{Schedule: "*/15 * * * * *"},
+			want: now.Add(15 * time.Second),
+		},
+		"@every": {
+			spec: sourcesv1.PingSourceSpec{Schedule: "@every 15s"},
+			want: now.Add(15 * time.Second),
+		},
+		"one-shot": {
+			spec: sourcesv1.PingSourceSpec{At: &future},
+			want: future.Time,
+		},
+		"past one-shot": {
+			spec: sourcesv1.PingSourceSpec{At: &past},
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			ctx, _ := rectesting.SetupFakeContext(t)
+			runner := NewCronJobsRunner(adaptertesting.NewTestClient(), kubeclient.Get(ctx), logging.FromContext(ctx))
+			entryId := runner.AddSchedule(&sourcesv1.PingSource{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-name",
+					Namespace: "test-ns",
+				},
+				Spec: tc.spec,
+				Status: sourcesv1.PingSourceStatus{
+					SourceStatus: duckv1.SourceStatus{
+						SinkURI: &apis.URL{Path: "a sink"},
+					},
+				},
+			})
+
+			entry := runner.cron.Entry(entryId)
+			if entry.ID != entryId {
+				t.Fatal("Entry has not been added")
+			}
+			if got := entry.Schedule.Next(now); !got.Equal(tc.want) {
+				t.Errorf("Next() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestOneShotFired(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	at := metav1.NewTime(now)
+	source := &sourcesv1.PingSource{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-name",
+			Namespace: "test-ns",
+		},
+		Spec: sourcesv1.PingSourceSpec{At: &at},
+		Status: sourcesv1.PingSourceStatus{
+			SourceStatus: duckv1.SourceStatus{
+				SinkURI: &apis.URL{Path: "a sink"},
+			},
+		},
+	}
+	ce := adaptertesting.NewTestClient()
+	sources := eventingfake.NewSimpleClientset(source)
+
+	runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logging.FromContext(ctx))
+	runner.sourcesClient = sources.SourcesV1()
+	runner.clock = clock.NewFakeClock(now.Add(time.Second))
+	runner.cron.Entry(runner.AddSchedule(source)).Job.Run()
+	require.Len(t, ce.Sent(), 1)
+
+	// The adapter reports when the event was sent.
+	got, err := sources.SourcesV1().PingSources("test-ns").Get(ctx, "test-name", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "2021-06-01T12:00:01Z", got.Annotations[sourcesv1.PingSourceFiredAtAnnotationKey])
+}
+
+func TestTickEvents(t *testing.T) {
+	scheduled := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := map[string]struct {
+		spec          sourcesv1.PingSourceSpec
+		wantData      []string
+		wantExtension bool
+	}{
+		"static data": {
+			spec: sourcesv1.PingSourceSpec{
+				ContentType: cloudevents.TextPlain,
+				Data:        "{{.Name}}",
+			},
+			wantData: []string{"{{.Name}}", "{{.Name}}"},
+		},
+		"templated data": {
+			spec: sourcesv1.PingSourceSpec{
+				ContentType:   cloudevents.ApplicationJSON,
+				Data:          `{"source": "{{.Namespace}}/{{.Name}}", "time": "{{.Time.Format "15:04"}}", "sequence": {{.Sequence}}}`,
+				DataTemplated: true,
+			},
+			wantData: []string{
+				`{"source": "test-ns/test-name", "time": "12:00", "sequence": 1}`,
+				`{"source": "test-ns/test-name", "time": "12:00", "sequence": 2}`,
+			},
+		},
+		"scheduled time extension": {
+			spec: sourcesv1.PingSourceSpec{
+				ContentType:            cloudevents.TextPlain,
+				Data:                   sampleData,
+				ScheduledTimeExtension: true,
+			},
+			wantData:      []string{sampleData, sampleData},
+			wantExtension: true,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			events, err := newTickEvents(&sourcesv1.PingSource{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-name",
+					Namespace: "test-ns",
+				},
+				Spec: tc.spec,
+			})
+			require.NoError(t, err)
+
+			for _, want := range tc.wantData {
+				event, err := events.make(scheduled)
+				require.NoError(t, err)
+				require.Equal(t, want, string(event.Data()))
+				require.Equal(t, tc.spec.ContentType, event.DataContentType())
+
+				ext, ok := event.Extensions()[sourcesv1.PingSourceScheduledTimeExtension]
+				require.Equal(t, tc.wantExtension, ok)
+				if ok {
+					got, err := types.ToTime(ext)
+					require.NoError(t, err)
+					require.True(t, scheduled.Equal(got))
+				}
+			}
+		})
+	}
+}
+
+func TestCatchUp(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := map[string]struct {
+		policy   sourcesv1.MissedTickPolicy
+		lookback *string
+		// last is the last tick handled, if any
+		last string
+		want []time.Time
+	}{
+		"skip": {
+			policy: sourcesv1.MissedTickSkip,
+			last:   "2021-06-01T11:59:25Z",
+		},
+		"first schedule": {
+			policy: sourcesv1.MissedTickFireAll,
+		},
+		"fire once": {
+			policy: sourcesv1.MissedTickFireOnce,
+			last:   "2021-06-01T11:59:25Z",
+			want:   []time.Time{now},
+		},
+		"fire all": {
+			policy: sourcesv1.MissedTickFireAll,
+			last:   "2021-06-01T11:59:25Z",
+			want:   []time.Time{now.Add(-30 * time.Second), now.Add(-20 * time.Second), now.Add(-10 * time.Second), now},
+		},
+		"fire all within the lookback": {
+			policy:   sourcesv1.MissedTickFireAll,
+			lookback: pointer.StringPtr("PT15S"),
+			last:     "2021-06-01T11:00:00Z",
+			want:     []time.Time{now.Add(-10 * time.Second), now},
+		},
+		"nothing missed": {
+			policy: sourcesv1.MissedTickFireAll,
+			last:   "2021-06-01T12:00:00Z",
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			ctx, _ := rectesting.SetupFakeContext(t)
+			logger := logging.FromContext(ctx)
+			ce := adaptertesting.NewTestClient()
+
+			cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: ticksConfigMapName}}
+			if tc.last != "" {
+				cm.Data = map[string]string{"test-ns.test-name": tc.last}
+			}
+			configMaps := fake.NewSimpleClientset(cm).CoreV1().ConfigMaps("")
+
+			runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logger)
+			runner.ticks = newTickStore(configMaps, logger)
+			runner.clock = clock.NewFakeClock(now)
+			runner.AddSchedule(&sourcesv1.PingSource{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-name",
+					Namespace: "test-ns",
+				},
+				Spec: sourcesv1.PingSourceSpec{
+					Schedule:           "*/10 * * * * *",
+					MissedTickPolicy:   tc.policy,
+					MissedTickLookback: tc.lookback,
+				},
+				Status: sourcesv1.PingSourceStatus{
+					SourceStatus: duckv1.SourceStatus{
+						SinkURI: &apis.URL{Path: "a sink"},
+					},
+				},
+			})
+
+			require.Eventually(t, func() bool {
+				return len(ce.Sent()) == len(tc.want)
+			}, 5*time.Second, 10*time.Millisecond)
+			time.Sleep(600 * time.Millisecond)
+
+			var got []time.Time
+			for _, e := range ce.Sent() {
+				ext, err := types.ToTime(e.Extensions()[sourcesv1.PingSourceScheduledTimeExtension])
+				require.NoError(t, err)
+				got = append(got, ext)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Error("Unexpected scheduled times (-want, +got) =", diff)
+			}
+
+			// The ticks to come are left to the schedule.
+			last, ok := runner.ticks.last(ctx, "test-ns.test-name")
+			if catchesUp(&sourcesv1.PingSource{Spec: sourcesv1.PingSourceSpec{MissedTickPolicy: tc.policy}}) {
+				require.True(t, ok)
+				require.Equal(t, now, last)
+			} else {
+				require.False(t, ok)
+			}
+		})
+	}
+}
+
+func TestMissedTicks(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	schedule, err := sourcesv1.PingScheduleParser.Parse("*/10 * * * * *")
+	require.NoError(t, err)
+
+	missed, skipped := missedTicks(schedule, now.Add(-35*time.Second), now)
+	require.Zero(t, skipped)
+	require.Equal(t, []time.Time{now.Add(-30 * time.Second), now.Add(-20 * time.Second), now.Add(-10 * time.Second), now}, missed)
+
+	// Only the most recent ticks are caught up.
+	missed, skipped = missedTicks(schedule, now.Add(-time.Hour), now)
+	require.Equal(t, 360-sourcesv1.PingSourceMaxMissedTicks, skipped)
+	require.Len(t, missed, sourcesv1.PingSourceMaxMissedTicks)
+	require.Equal(t, now.Add(-time.Duration(10*(sourcesv1.PingSourceMaxMissedTicks-1))*time.Second), missed[0])
+	require.Equal(t, now, missed[len(missed)-1])
+}
+
 func TestStartStopCron(t *testing.T) {
 	ctx, _ := rectesting.SetupFakeContext(t)
 	logger := logging.FromContext(ctx)
@@ -0,0 +1,260 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/util/retry"
+
+	sourcesv1 "knative.dev/eventing/pkg/apis/sources/v1"
+)
+
+const (
+	// ticksConfigMapName is the name of the ConfigMap holding the last tick of the PingSources
+	// catching up with their missed ticks.
+	ticksConfigMapName = "pingsource-mt-adapter-ticks"
+
+	// ticksFlushInterval is how often the ticks are written to the ConfigMap.
+	ticksFlushInterval = 10 * time.Second
+)
+
+// tickStore keeps the time up to which the ticks of the PingSources were handled. It's saved in a
+// ConfigMap shared by the replicas, read by the next owner of a PingSource to catch up with the
+// ticks missed in between.
+type tickStore struct {
+	client typedcorev1.ConfigMapInterface
+	logger *zap.SugaredLogger
+
+	mutex   sync.Mutex
+	ticks   map[string]time.Time
+	pending map[string]bool
+	// owned holds the keys whose ticks were read from the ConfigMap, which are handled by this
+	// replica until they're released.
+	owned map[string]bool
+	// released holds the keys handed over to another replica.
+	released map[string]bool
+}
+
+func newTickStore(client typedcorev1.ConfigMapInterface, logger *zap.SugaredLogger) *tickStore {
+	return &tickStore{
+		client:   client,
+		logger:   logger,
+		ticks:    make(map[string]time.Time),
+		pending:  make(map[string]bool),
+		owned:    make(map[string]bool),
+		released: make(map[string]bool),
+	}
+}
+
+// tickKey returns the key of the ticks of source. Namespaces can't contain dots.
+func tickKey(source *sourcesv1.PingSource) string {
+	return source.Namespace + "." + source.Name
+}
+
+// last returns the time up to which the ticks of key were handled. It reads the ConfigMap when
+// key isn't owned by the store yet, as another replica may have handled its ticks since. key is
+// owned by the store afterwards.
+func (s *tickStore) last(ctx context.Context, key string) (time.Time, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.owned[key] {
+		cm, err := s.client.Get(ctx, ticksConfigMapName, metav1.GetOptions{})
+		switch {
+		case apierrors.IsNotFound(err):
+		case err != nil:
+			s.logger.Warnw("Failed to read the ticks of the PingSources", zap.Error(err))
+			return time.Time{}, false
+		default:
+			if t, err := time.Parse(time.RFC3339, cm.Data[key]); err == nil {
+				// A tick removed since is gone, the most recent one wins otherwise.
+				if last, ok := s.ticks[key]; (!ok && !s.pending[key]) || (ok && t.After(last)) {
+					s.ticks[key] = t
+				}
+			}
+		}
+		s.owned[key] = true
+		delete(s.released, key)
+	}
+	t, ok := s.ticks[key]
+	return t, ok
+}
+
+// set records that the ticks of key were handled up to t. It returns false when key was handed
+// over to another replica, which handles the tick instead.
+func (s *tickStore) set(key string, t time.Time) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.released[key] {
+		return false
+	}
+	if last, ok := s.ticks[key]; ok && !t.After(last) {
+		return true
+	}
+	s.ticks[key] = t
+	s.pending[key] = true
+	return true
+}
+
+// remove forgets the ticks of key.
+func (s *tickStore) remove(key string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// The tick may have been saved by another replica.
+	delete(s.ticks, key)
+	delete(s.owned, key)
+	delete(s.released, key)
+	s.pending[key] = true
+}
+
+// release hands the ticks of keys over to another replica, saving them first so that the new
+// owner doesn't fire them again.
+func (s *tickStore) release(ctx context.Context, keys []string) error {
+	s.mutex.Lock()
+	for _, key := range keys {
+		delete(s.owned, key)
+		s.released[key] = true
+	}
+	s.mutex.Unlock()
+
+	err := s.flush(ctx)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, key := range keys {
+		// The ticks which couldn't be saved are kept for the next flush.
+		if s.released[key] && !s.pending[key] {
+			delete(s.ticks, key)
+		}
+	}
+	return err
+}
+
+// flush writes the ticks changed since the last flush to the ConfigMap. The ticks of the keys it
+// doesn't know about are left untouched.
+func (s *tickStore) flush(ctx context.Context) error {
+	s.mutex.Lock()
+	// changes holds the new value of each pending key, empty when it was removed.
+	changes := make(map[string]string, len(s.pending))
+	for key := range s.pending {
+		if t, ok := s.ticks[key]; ok {
+			changes[key] = t.UTC().Format(time.RFC3339)
+		} else {
+			changes[key] = ""
+		}
+	}
+	s.pending = make(map[string]bool)
+	s.mutex.Unlock()
+
+	if len(changes) == 0 {
+		return nil
+	}
+	if err := s.write(ctx, changes); err != nil {
+		s.mutex.Lock()
+		for key := range changes {
+			s.pending[key] = true
+		}
+		s.mutex.Unlock()
+		return err
+	}
+	return nil
+}
+
+// write applies changes to the ConfigMap, retrying when another replica updated it in between.
+// The saved ticks only move forward.
+func (s *tickStore) write(ctx context.Context, changes map[string]string) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cm, err := s.client.Get(ctx, ticksConfigMapName, metav1.GetOptions{})
+		exists := err == nil
+		if apierrors.IsNotFound(err) {
+			cm = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: ticksConfigMapName}}
+		} else if err != nil {
+			return err
+		}
+		if cm.Data == nil {
+			cm.Data = make(map[string]string)
+		}
+		changed := false
+		for key, value := range changes {
+			old, ok := cm.Data[key]
+			switch {
+			case value == "":
+				if ok {
+					delete(cm.Data, key)
+					changed = true
+				}
+			case old != value && !savedAfter(old, value):
+				cm.Data[key] = value
+				changed = true
+			}
+		}
+		if !changed {
+			return nil
+		}
+
+		if exists {
+			_, err = s.client.Update(ctx, cm, metav1.UpdateOptions{})
+		} else {
+			_, err = s.client.Create(ctx, cm, metav1.CreateOptions{})
+			if apierrors.IsAlreadyExists(err) {
+				// Created by another replica, retry as a conflict.
+				return apierrors.NewConflict(corev1.Resource("configmaps"), ticksConfigMapName, err)
+			}
+		}
+		return err
+	})
+}
+
+// savedAfter tells whether the tick saved is more recent than value.
+func savedAfter(saved, value string) bool {
+	s, err := time.Parse(time.RFC3339, saved)
+	if err != nil {
+		return false
+	}
+	v, err := time.Parse(time.RFC3339, value)
+	return err == nil && s.After(v)
+}
+
+// start flushes the ticks every interval, and a last time when stopCh is closed.
+func (s *tickStore) start(stopCh <-chan struct{}, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-stopCh:
+			if err := s.flush(context.Background()); err != nil {
+				s.logger.Warnw("Failed to save the ticks of the PingSources", zap.Error(err))
+			}
+			return
+		}
+		if err := s.flush(context.Background()); err != nil {
+			s.logger.Warnw("Failed to save the ticks of the PingSources", zap.Error(err))
+		}
+	}
+}
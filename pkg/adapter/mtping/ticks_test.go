@@ -0,0 +1,100 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestTickStore(t *testing.T) {
+	ctx := context.Background()
+	last := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	client := fake.NewSimpleClientset(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: ticksConfigMapName},
+		Data: map[string]string{
+			"ns.known":   last.Format(time.RFC3339),
+			"ns.other":   last.Format(time.RFC3339),
+			"ns.invalid": "yesterday",
+		},
+	}).CoreV1().ConfigMaps("")
+	s := newTickStore(client, zap.NewNop().Sugar())
+
+	got, ok := s.last(ctx, "ns.known")
+	require.True(t, ok)
+	require.Equal(t, last, got)
+	_, ok = s.last(ctx, "ns.invalid")
+	require.False(t, ok)
+
+	// The ticks only move forward.
+	s.set("ns.known", last.Add(-time.Minute))
+	s.set("ns.new", last.Add(time.Minute))
+	s.remove("ns.other")
+	require.NoError(t, s.flush(ctx))
+
+	cm, err := client.Get(ctx, ticksConfigMapName, metav1.GetOptions{})
+	require.NoError(t, err)
+	want := map[string]string{
+		"ns.known":   "2021-06-01T12:00:00Z",
+		"ns.new":     "2021-06-01T12:01:00Z",
+		"ns.invalid": "yesterday",
+	}
+	if diff := cmp.Diff(want, cm.Data); diff != "" {
+		t.Error("Unexpected ticks (-want, +got) =", diff)
+	}
+
+	// The ticks handed over are saved, and read by the other replica.
+	require.True(t, s.set("ns.known", last.Add(time.Minute)))
+	require.NoError(t, s.release(ctx, []string{"ns.known"}))
+	require.False(t, s.set("ns.known", last.Add(2*time.Minute)))
+	other := newTickStore(client, zap.NewNop().Sugar())
+	got, ok = other.last(ctx, "ns.known")
+	require.True(t, ok)
+	require.Equal(t, last.Add(time.Minute), got)
+
+	// The ticks come back from the other replica, which moved them forward.
+	require.True(t, other.set("ns.known", last.Add(3*time.Minute)))
+	require.NoError(t, other.release(ctx, []string{"ns.known"}))
+	got, ok = s.last(ctx, "ns.known")
+	require.True(t, ok)
+	require.Equal(t, last.Add(3*time.Minute), got)
+	require.True(t, s.set("ns.known", last.Add(4*time.Minute)))
+
+	// The saved ticks don't move backward.
+	require.NoError(t, client.Delete(ctx, ticksConfigMapName, metav1.DeleteOptions{}))
+	require.True(t, other.set("ns.other", last.Add(time.Minute)))
+	require.NoError(t, other.flush(ctx))
+	require.True(t, s.set("ns.other", last))
+	require.NoError(t, s.flush(ctx))
+	cm, err = client.Get(ctx, ticksConfigMapName, metav1.GetOptions{})
+	require.NoError(t, err)
+	want = map[string]string{
+		"ns.known": "2021-06-01T12:04:00Z",
+		"ns.other": "2021-06-01T12:01:00Z",
+	}
+	if diff := cmp.Diff(want, cm.Data); diff != "" {
+		t.Error("Unexpected ticks (-want, +got) =", diff)
+	}
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+
+	"knative.dev/pkg/apis"
+
+	"knative.dev/eventing/pkg/apis/feature"
+	"knative.dev/eventing/pkg/eventfilter/jsonpath"
+)
+
+// DataFilter is a JSONPath predicate evaluated against the data of events
+// having a JSON datacontenttype.
+type DataFilter struct {
+	// Path is the JSONPath expression selecting values from the event data,
+	// e.g. .order.region or {.items[*].sku}.
+	Path string `json:"path"`
+
+	// Value, when specified, must be equal to the string representation of at least
+	// one of the values selected by Path. When not specified, Path must select
+	// at least one value.
+	// +optional
+	Value *string `json:"value,omitempty"`
+}
+
+// Validate the DataFilter.
+func (f *DataFilter) Validate(ctx context.Context) *apis.FieldError {
+	if f.Path == "" {
+		return apis.ErrMissingField("path")
+	}
+	if err := jsonpath.ValidatePath(f.Path); err != nil {
+		fe := apis.ErrInvalidValue(f.Path, "path")
+		fe.Details = err.Error()
+		return fe
+	}
+	return nil
+}
+
+// ValidateDataFilters validates the provided list of DataFilter, returning an error
+// if the data-filter feature is disabled.
+func ValidateDataFilters(ctx context.Context, filters []DataFilter) *apis.FieldError {
+	if len(filters) == 0 {
+		return nil
+	}
+	if !feature.FromContext(ctx).IsEnabled(feature.DataFilter) {
+		return apis.ErrDisallowedFields(apis.CurrentField)
+	}
+	var errs *apis.FieldError
+	for i := range filters {
+		errs = errs.Also(filters[i].Validate(ctx).ViaIndex(i))
+	}
+	return errs
+}
+
+// DataFilterPredicates converts the provided list of DataFilter to JSONPath predicates.
+func DataFilterPredicates(filters []DataFilter) []jsonpath.Predicate {
+	predicates := make([]jsonpath.Predicate, 0, len(filters))
+	for _, f := range filters {
+		predicates = append(predicates, jsonpath.Predicate{Path: f.Path, Value: f.Value})
+	}
+	return predicates
+}
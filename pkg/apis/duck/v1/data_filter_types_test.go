@@ -0,0 +1,85 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/utils/pointer"
+	"knative.dev/pkg/apis"
+
+	"knative.dev/eventing/pkg/apis/feature"
+	"knative.dev/eventing/pkg/eventfilter/jsonpath"
+)
+
+func TestValidateDataFilters(t *testing.T) {
+	dataFilterEnabledCtx := feature.ToContext(context.TODO(), feature.Flags{
+		feature.DataFilter: feature.Enabled,
+	})
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		filters []DataFilter
+		want    *apis.FieldError
+	}{{
+		name: "nil is valid",
+		ctx:  context.TODO(),
+	}, {
+		name:    "valid filters",
+		ctx:     dataFilterEnabledCtx,
+		filters: []DataFilter{{Path: ".a.b"}, {Path: "$.a[0]", Value: pointer.StringPtr("c")}},
+	}, {
+		name:    "missing path",
+		ctx:     dataFilterEnabledCtx,
+		filters: []DataFilter{{Path: ".a.b"}, {Value: pointer.StringPtr("c")}},
+		want:    apis.ErrMissingField("[1].path"),
+	}, {
+		name:    "invalid path",
+		ctx:     dataFilterEnabledCtx,
+		filters: []DataFilter{{Path: ".a["}},
+		want: &apis.FieldError{
+			Message: "invalid value: .a[",
+			Paths:   []string{"[0].path"},
+			Details: "unterminated array",
+		},
+	}, {
+		name:    "feature disabled",
+		ctx:     context.TODO(),
+		filters: []DataFilter{{Path: ".a.b"}},
+		want:    apis.ErrDisallowedFields(apis.CurrentField),
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := ValidateDataFilters(tc.ctx, tc.filters)
+			if diff := cmp.Diff(tc.want.Error(), got.Error()); diff != "" {
+				t.Error("ValidateDataFilters (-want, +got) =", diff)
+			}
+		})
+	}
+}
+
+func TestDataFilterPredicates(t *testing.T) {
+	filters := []DataFilter{{Path: ".a.b"}, {Path: ".c", Value: pointer.StringPtr("d")}}
+	want := []jsonpath.Predicate{{Path: ".a.b"}, {Path: ".c", Value: pointer.StringPtr("d")}}
+	if diff := cmp.Diff(want, DataFilterPredicates(filters)); diff != "" {
+		t.Error("DataFilterPredicates (-want, +got) =", diff)
+	}
+}
@@ -18,6 +18,10 @@ package v1
 
 import (
 	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
 
 	"github.com/rickb777/date/period"
 	"knative.dev/eventing/pkg/apis/feature"
@@ -47,7 +51,10 @@ type DeliverySpec struct {
 	// +optional
 	Timeout *string `json:"timeout,omitempty"`
 
-	// BackoffPolicy is the retry backoff policy (linear, exponential).
+	// BackoffPolicy is the retry backoff policy (linear, exponential, exponential-jitter,
+	// exponential-equal-jitter).
+	//
+	// Note: The exponential-jitter and exponential-equal-jitter policies are EXPERIMENTAL and might break anytime.
 	// +optional
 	BackoffPolicy *BackoffPolicyType `json:"backoffPolicy,omitempty"`
 
@@ -58,10 +65,122 @@ type DeliverySpec struct {
 	//
 	// For linear policy, backoff delay is backoffDelay*<numberOfRetries>.
 	// For exponential policy, backoff delay is backoffDelay*2^<numberOfRetries>.
+	// For exponential-jitter policy, backoff delay is a random delay between 0 and
+	// backoffDelay*2^<numberOfRetries>.
+	// For exponential-equal-jitter policy, backoff delay is half of backoffDelay*2^<numberOfRetries>
+	// plus a random delay between 0 and the other half.
 	// +optional
 	BackoffDelay *string `json:"backoffDelay,omitempty"`
+
+	// BackoffMaxDelay is the maximum delay before retrying, capping the delay computed by the
+	// backoff policy before any jitter is applied.
+	// More information on Duration format:
+	//  - https://www.iso.org/iso-8601-date-and-time-format.html
+	//  - https://en.wikipedia.org/wiki/ISO_8601
+	//
+	// Note: This API is EXPERIMENTAL and might break anytime.
+	// +optional
+	BackoffMaxDelay *string `json:"backoffMaxDelay,omitempty"`
+
+	// RetryAfterMax makes the sender honor the Retry-After header of 429 and 503 responses,
+	// waiting before retrying for the duration it specifies, capped at RetryAfterMax, when
+	// longer than the backoff delay. Both the delay-seconds and HTTP-date forms are supported.
+	// More information on Duration format:
+	//  - https://www.iso.org/iso-8601-date-and-time-format.html
+	//  - https://en.wikipedia.org/wiki/ISO_8601
+	//
+	// Note: This API is EXPERIMENTAL and might break anytime.
+	// +optional
+	RetryAfterMax *string `json:"retryAfterMax,omitempty"`
+
+	// RetryOn is the list of response status codes which are retried, either single
+	// status codes, e.g. "409", or inclusive ranges, e.g. "500-599". When set, the
+	// responses with any other status code aren't retried. Requests failing without
+	// a response are always retried.
+	//
+	// Note: This API is EXPERIMENTAL and might break anytime.
+	// +optional
+	RetryOn []string `json:"retryOn,omitempty"`
+
+	// DoNotRetryOn is the list of response status codes which are never retried,
+	// even when they match RetryOn, either single status codes, e.g. "422", or
+	// inclusive ranges, e.g. "400-499".
+	//
+	// Note: This API is EXPERIMENTAL and might break anytime.
+	// +optional
+	DoNotRetryOn []string `json:"doNotRetryOn,omitempty"`
+
+	// OrderingKey is the name of the attribute, e.g. partitionkey or subject, ordering
+	// the delivery of the events: the events with the same value are delivered one after
+	// the other, in the order they were received, waiting for the retries of the previous
+	// ones. The events with different values, or without the attribute, are delivered
+	// concurrently.
+	//
+	// Note: This API is EXPERIMENTAL and might break anytime.
+	// +optional
+	OrderingKey *string `json:"orderingKey,omitempty"`
+
+	// RateLimit caps the rate of the events sent to the destination. The events beyond
+	// the limit wait for a bounded time, then are rejected with 429 Too Many Requests
+	// so that their sender retries them.
+	//
+	// Note: This API is EXPERIMENTAL and might break anytime.
+	// +optional
+	RateLimit *RateLimit `json:"rateLimit,omitempty"`
+
+	// MaxInFlight is the maximum number of events sent to the destination at the same
+	// time. An event counts as in flight until its delivery completes, including while it
+	// waits for the backoff delay of a retry. The events beyond the limit wait for a
+	// bounded time, then are rejected with 429 Too Many Requests so that their sender
+	// retries them.
+	//
+	// Note: This API is EXPERIMENTAL and might break anytime.
+	// +optional
+	MaxInFlight *int32 `json:"maxInFlight,omitempty"`
+
+	// MaxBatchSize, when set, makes the dispatcher accumulate the events and send them to
+	// the destination in batches of at most this many events, using the CloudEvents
+	// batched content mode (application/cloudevents-batch+json). The response status of
+	// a batch applies to all its events, including the retries and the dead letter sink.
+	//
+	// Note: This API is EXPERIMENTAL and might break anytime.
+	// +optional
+	MaxBatchSize *int32 `json:"maxBatchSize,omitempty"`
+
+	// MaxBatchDelay is the maximum time an event waits for its batch to be full before
+	// the batch is sent, expressed as an ISO 8601 duration. It defaults to one second.
+	//
+	// Note: This API is EXPERIMENTAL and might break anytime.
+	// +optional
+	MaxBatchDelay *string `json:"maxBatchDelay,omitempty"`
+}
+
+// RateLimit is a token bucket limiting the rate of the events.
+type RateLimit struct {
+	// EventsPerSecond is the sustained rate of the events, it must be greater than 0.
+	EventsPerSecond int32 `json:"eventsPerSecond"`
+
+	// Burst is the maximum number of events sent at once, above the sustained rate.
+	// It must be greater than 0, it defaults to EventsPerSecond.
+	// +optional
+	Burst *int32 `json:"burst,omitempty"`
+}
+
+// Validate the RateLimit.
+func (rl *RateLimit) Validate(ctx context.Context) *apis.FieldError {
+	var errs *apis.FieldError
+	if rl.EventsPerSecond < 1 {
+		errs = errs.Also(apis.ErrInvalidValue(rl.EventsPerSecond, "eventsPerSecond"))
+	}
+	if rl.Burst != nil && *rl.Burst < 1 {
+		errs = errs.Also(apis.ErrInvalidValue(*rl.Burst, "burst"))
+	}
+	return errs
 }
 
+// Only allow lowercase alphanumeric attribute names, as defined by the CloudEvents spec.
+var validOrderingKey = regexp.MustCompile(`^[a-z0-9]+$`)
+
 func (ds *DeliverySpec) Validate(ctx context.Context) *apis.FieldError {
 	if ds == nil {
 		return nil
@@ -90,6 +209,10 @@ func (ds *DeliverySpec) Validate(ctx context.Context) *apis.FieldError {
 		switch *ds.BackoffPolicy {
 		case BackoffPolicyExponential, BackoffPolicyLinear:
 			// nothing
+		case BackoffPolicyExponentialJitter, BackoffPolicyExponentialEqualJitter:
+			if !feature.FromContext(ctx).IsEnabled(feature.DeliveryJitter) {
+				errs = errs.Also(apis.ErrInvalidValue(*ds.BackoffPolicy, "backoffPolicy", "the "+feature.DeliveryJitter+" feature is disabled"))
+			}
 		default:
 			errs = errs.Also(apis.ErrInvalidValue(*ds.BackoffPolicy, "backoffPolicy"))
 		}
@@ -101,9 +224,146 @@ func (ds *DeliverySpec) Validate(ctx context.Context) *apis.FieldError {
 			errs = errs.Also(apis.ErrInvalidValue(*ds.BackoffDelay, "backoffDelay"))
 		}
 	}
+
+	if ds.BackoffMaxDelay != nil {
+		if feature.FromContext(ctx).IsEnabled(feature.DeliveryJitter) {
+			p, te := period.Parse(*ds.BackoffMaxDelay)
+			if te != nil || p.IsZero() || p.IsNegative() {
+				errs = errs.Also(apis.ErrInvalidValue(*ds.BackoffMaxDelay, "backoffMaxDelay"))
+			}
+		} else {
+			errs = errs.Also(apis.ErrDisallowedFields("backoffMaxDelay"))
+		}
+	}
+
+	if ds.RetryAfterMax != nil {
+		if feature.FromContext(ctx).IsEnabled(feature.DeliveryRetryAfter) {
+			p, te := period.Parse(*ds.RetryAfterMax)
+			if te != nil || p.IsZero() || p.IsNegative() {
+				errs = errs.Also(apis.ErrInvalidValue(*ds.RetryAfterMax, "retryAfterMax"))
+			}
+		} else {
+			errs = errs.Also(apis.ErrDisallowedFields("retryAfterMax"))
+		}
+	}
+
+	if len(ds.RetryOn) > 0 || len(ds.DoNotRetryOn) > 0 {
+		if feature.FromContext(ctx).IsEnabled(feature.DeliveryRetryOn) {
+			errs = errs.Also(validateStatusCodeRanges(ds.RetryOn, "retryOn"))
+			errs = errs.Also(validateStatusCodeRanges(ds.DoNotRetryOn, "doNotRetryOn"))
+		} else {
+			if len(ds.RetryOn) > 0 {
+				errs = errs.Also(apis.ErrDisallowedFields("retryOn"))
+			}
+			if len(ds.DoNotRetryOn) > 0 {
+				errs = errs.Also(apis.ErrDisallowedFields("doNotRetryOn"))
+			}
+		}
+	}
+
+	if ds.OrderingKey != nil {
+		if !feature.FromContext(ctx).IsEnabled(feature.DeliveryOrdering) {
+			errs = errs.Also(apis.ErrDisallowedFields("orderingKey"))
+		} else if !IsValidOrderingKey(*ds.OrderingKey) {
+			errs = errs.Also(apis.ErrInvalidValue(*ds.OrderingKey, "orderingKey"))
+		}
+	}
+
+	if ds.RateLimit != nil {
+		if feature.FromContext(ctx).IsEnabled(feature.DeliveryRateLimit) {
+			errs = errs.Also(ds.RateLimit.Validate(ctx).ViaField("rateLimit"))
+		} else {
+			errs = errs.Also(apis.ErrDisallowedFields("rateLimit"))
+		}
+	}
+
+	if ds.MaxInFlight != nil {
+		if !feature.FromContext(ctx).IsEnabled(feature.DeliveryRateLimit) {
+			errs = errs.Also(apis.ErrDisallowedFields("maxInFlight"))
+		} else if *ds.MaxInFlight < 1 {
+			errs = errs.Also(apis.ErrInvalidValue(*ds.MaxInFlight, "maxInFlight"))
+		}
+	}
+
+	if ds.MaxBatchSize != nil || ds.MaxBatchDelay != nil {
+		if feature.FromContext(ctx).IsEnabled(feature.DeliveryBatching) {
+			errs = errs.Also(ds.validateBatching())
+		} else {
+			if ds.MaxBatchSize != nil {
+				errs = errs.Also(apis.ErrDisallowedFields("maxBatchSize"))
+			}
+			if ds.MaxBatchDelay != nil {
+				errs = errs.Also(apis.ErrDisallowedFields("maxBatchDelay"))
+			}
+		}
+	}
+	return errs
+}
+
+func (ds *DeliverySpec) validateBatching() *apis.FieldError {
+	var errs *apis.FieldError
+	if ds.MaxBatchSize == nil {
+		errs = errs.Also(apis.ErrMissingField("maxBatchSize"))
+	} else if *ds.MaxBatchSize < 1 {
+		errs = errs.Also(apis.ErrInvalidValue(*ds.MaxBatchSize, "maxBatchSize"))
+	}
+	if ds.MaxBatchDelay != nil {
+		p, te := period.Parse(*ds.MaxBatchDelay)
+		if te != nil || p.IsZero() || p.IsNegative() {
+			errs = errs.Also(apis.ErrInvalidValue(*ds.MaxBatchDelay, "maxBatchDelay"))
+		}
+	}
+	if ds.MaxBatchSize != nil && ds.OrderingKey != nil {
+		// The batches are sent concurrently.
+		errs = errs.Also(apis.ErrMultipleOneOf("orderingKey", "maxBatchSize"))
+	}
+	return errs
+}
+
+// IsValidOrderingKey returns true if the ordering key is a valid attribute name.
+func IsValidOrderingKey(key string) bool {
+	return validOrderingKey.MatchString(key)
+}
+
+func validateStatusCodeRanges(ranges []string, field string) *apis.FieldError {
+	var errs *apis.FieldError
+	for i, r := range ranges {
+		if _, _, err := ParseStatusCodeRange(r); err != nil {
+			errs = errs.Also(apis.ErrInvalidArrayValue(r, field, i))
+		}
+	}
 	return errs
 }
 
+// ParseStatusCodeRange parses a status code, e.g. "409", or an inclusive range of status
+// codes, e.g. "500-599", returning its bounds.
+func ParseStatusCodeRange(s string) (int, int, error) {
+	from, to := s, s
+	if i := strings.IndexByte(s, '-'); i >= 0 {
+		from, to = s[:i], s[i+1:]
+	}
+	min, err := parseStatusCode(from)
+	if err != nil {
+		return 0, 0, err
+	}
+	max, err := parseStatusCode(to)
+	if err != nil {
+		return 0, 0, err
+	}
+	if min > max {
+		return 0, 0, fmt.Errorf("invalid status code range %q", s)
+	}
+	return min, max, nil
+}
+
+func parseStatusCode(s string) (int, error) {
+	code, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || code < 100 || code > 599 {
+		return 0, fmt.Errorf("invalid status code %q", s)
+	}
+	return code, nil
+}
+
 // BackoffPolicyType is the type for backoff policies
 type BackoffPolicyType string
 
@@ -113,6 +373,12 @@ const (
 
 	// Exponential backoff policy
 	BackoffPolicyExponential BackoffPolicyType = "exponential"
+
+	// Exponential backoff policy with full jitter
+	BackoffPolicyExponentialJitter BackoffPolicyType = "exponential-jitter"
+
+	// Exponential backoff policy with equal jitter
+	BackoffPolicyExponentialEqualJitter BackoffPolicyType = "exponential-equal-jitter"
 )
 
 // DeliveryStatus contains the Status of an object supporting delivery options. This type is intended to be embedded into a status struct.
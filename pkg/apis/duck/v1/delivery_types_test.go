@@ -30,9 +30,31 @@ func TestDeliverySpecValidation(t *testing.T) {
 	deliveryTimeoutEnabledCtx := feature.ToContext(context.TODO(), feature.Flags{
 		feature.DeliveryTimeout: feature.Enabled,
 	})
+	deliveryRetryAfterEnabledCtx := feature.ToContext(context.TODO(), feature.Flags{
+		feature.DeliveryRetryAfter: feature.Enabled,
+	})
+	deliveryJitterEnabledCtx := feature.ToContext(context.TODO(), feature.Flags{
+		feature.DeliveryJitter: feature.Enabled,
+	})
+	deliveryRetryOnEnabledCtx := feature.ToContext(context.TODO(), feature.Flags{
+		feature.DeliveryRetryOn: feature.Enabled,
+	})
+	deliveryOrderingEnabledCtx := feature.ToContext(context.TODO(), feature.Flags{
+		feature.DeliveryOrdering: feature.Enabled,
+	})
+	deliveryRateLimitEnabledCtx := feature.ToContext(context.TODO(), feature.Flags{
+		feature.DeliveryRateLimit: feature.Enabled,
+	})
+	deliveryBatchingEnabledCtx := feature.ToContext(context.TODO(), feature.Flags{
+		feature.DeliveryBatching: feature.Enabled,
+		feature.DeliveryOrdering: feature.Enabled,
+	})
 
 	invalidString := "invalid time"
 	bop := BackoffPolicyExponential
+	jitter := BackoffPolicyExponentialJitter
+	equalJitter := BackoffPolicyExponentialEqualJitter
+	invalidPolicy := BackoffPolicyType("garbage")
 	validDuration := "PT2S"
 	invalidDuration := "1985-04-12T23:20:50.52Z"
 	tests := []struct {
@@ -79,10 +101,154 @@ func TestDeliverySpecValidation(t *testing.T) {
 		name: "disabled timeout",
 		spec: &DeliverySpec{Timeout: &validDuration},
 		want: apis.ErrDisallowedFields("timeout"),
+	}, {
+		name: "valid retryAfterMax",
+		spec: &DeliverySpec{RetryAfterMax: &validDuration},
+		ctx:  deliveryRetryAfterEnabledCtx,
+	}, {
+		name: "invalid retryAfterMax",
+		spec: &DeliverySpec{RetryAfterMax: &invalidDuration},
+		ctx:  deliveryRetryAfterEnabledCtx,
+		want: apis.ErrInvalidValue(invalidDuration, "retryAfterMax"),
+	}, {
+		name: "zero retryAfterMax",
+		spec: &DeliverySpec{RetryAfterMax: pointer.StringPtr("PT0S")},
+		ctx:  deliveryRetryAfterEnabledCtx,
+		want: apis.ErrInvalidValue("PT0S", "retryAfterMax"),
+	}, {
+		name: "negative retryAfterMax",
+		spec: &DeliverySpec{RetryAfterMax: pointer.StringPtr("-PT1S")},
+		ctx:  deliveryRetryAfterEnabledCtx,
+		want: apis.ErrInvalidValue("-PT1S", "retryAfterMax"),
+	}, {
+		name: "disabled retryAfterMax",
+		spec: &DeliverySpec{RetryAfterMax: &validDuration},
+		want: apis.ErrDisallowedFields("retryAfterMax"),
+	}, {
+		name: "valid retryOn and doNotRetryOn",
+		spec: &DeliverySpec{RetryOn: []string{"409", "500-599"}, DoNotRetryOn: []string{"422"}},
+		ctx:  deliveryRetryOnEnabledCtx,
+	}, {
+		name: "invalid retryOn and doNotRetryOn",
+		spec: &DeliverySpec{RetryOn: []string{"409", "599-500", "600"}, DoNotRetryOn: []string{"4xx"}},
+		ctx:  deliveryRetryOnEnabledCtx,
+		want: apis.ErrInvalidArrayValue("599-500", "retryOn", 1).Also(
+			apis.ErrInvalidArrayValue("600", "retryOn", 2),
+			apis.ErrInvalidArrayValue("4xx", "doNotRetryOn", 0)),
+	}, {
+		name: "disabled retryOn and doNotRetryOn",
+		spec: &DeliverySpec{RetryOn: []string{"409"}, DoNotRetryOn: []string{"422"}},
+		want: apis.ErrDisallowedFields("retryOn").Also(apis.ErrDisallowedFields("doNotRetryOn")),
 	}, {
 		name: "valid backoffPolicy",
 		spec: &DeliverySpec{BackoffPolicy: &bop},
 		want: nil,
+	}, {
+		name: "valid jitter backoffPolicy",
+		spec: &DeliverySpec{BackoffPolicy: &jitter},
+		ctx:  deliveryJitterEnabledCtx,
+	}, {
+		name: "valid equal jitter backoffPolicy",
+		spec: &DeliverySpec{BackoffPolicy: &equalJitter},
+		ctx:  deliveryJitterEnabledCtx,
+	}, {
+		name: "disabled jitter backoffPolicy",
+		spec: &DeliverySpec{BackoffPolicy: &jitter},
+		want: apis.ErrInvalidValue(jitter, "backoffPolicy", "the delivery-jitter feature is disabled"),
+	}, {
+		name: "invalid backoffPolicy",
+		spec: &DeliverySpec{BackoffPolicy: &invalidPolicy},
+		want: apis.ErrInvalidValue("garbage", "backoffPolicy"),
+	}, {
+		name: "valid backoffMaxDelay",
+		spec: &DeliverySpec{BackoffMaxDelay: &validDuration},
+		ctx:  deliveryJitterEnabledCtx,
+	}, {
+		name: "invalid backoffMaxDelay",
+		spec: &DeliverySpec{BackoffMaxDelay: &invalidDuration},
+		ctx:  deliveryJitterEnabledCtx,
+		want: apis.ErrInvalidValue(invalidDuration, "backoffMaxDelay"),
+	}, {
+		name: "zero backoffMaxDelay",
+		spec: &DeliverySpec{BackoffMaxDelay: pointer.StringPtr("PT0S")},
+		ctx:  deliveryJitterEnabledCtx,
+		want: apis.ErrInvalidValue("PT0S", "backoffMaxDelay"),
+	}, {
+		name: "disabled backoffMaxDelay",
+		spec: &DeliverySpec{BackoffMaxDelay: &validDuration},
+		want: apis.ErrDisallowedFields("backoffMaxDelay"),
+	}, {
+		name: "valid orderingKey",
+		spec: &DeliverySpec{OrderingKey: pointer.StringPtr("partitionkey")},
+		ctx:  deliveryOrderingEnabledCtx,
+	}, {
+		name: "invalid orderingKey",
+		spec: &DeliverySpec{OrderingKey: pointer.StringPtr("partition-key")},
+		ctx:  deliveryOrderingEnabledCtx,
+		want: apis.ErrInvalidValue("partition-key", "orderingKey"),
+	}, {
+		name: "disabled orderingKey",
+		spec: &DeliverySpec{OrderingKey: pointer.StringPtr("partitionkey")},
+		want: apis.ErrDisallowedFields("orderingKey"),
+	}, {
+		name: "valid rateLimit and maxInFlight",
+		spec: &DeliverySpec{
+			RateLimit:   &RateLimit{EventsPerSecond: 10, Burst: pointer.Int32Ptr(20)},
+			MaxInFlight: pointer.Int32Ptr(5),
+		},
+		ctx: deliveryRateLimitEnabledCtx,
+	}, {
+		name: "invalid rateLimit",
+		spec: &DeliverySpec{RateLimit: &RateLimit{EventsPerSecond: 0, Burst: pointer.Int32Ptr(-1)}},
+		ctx:  deliveryRateLimitEnabledCtx,
+		want: apis.ErrInvalidValue(0, "rateLimit.eventsPerSecond").Also(apis.ErrInvalidValue(-1, "rateLimit.burst")),
+	}, {
+		name: "invalid maxInFlight",
+		spec: &DeliverySpec{MaxInFlight: pointer.Int32Ptr(0)},
+		ctx:  deliveryRateLimitEnabledCtx,
+		want: apis.ErrInvalidValue(0, "maxInFlight"),
+	}, {
+		name: "disabled rateLimit and maxInFlight",
+		spec: &DeliverySpec{
+			RateLimit:   &RateLimit{EventsPerSecond: 10},
+			MaxInFlight: pointer.Int32Ptr(5),
+		},
+		want: apis.ErrDisallowedFields("rateLimit").Also(apis.ErrDisallowedFields("maxInFlight")),
+	}, {
+		name: "valid maxBatchSize and maxBatchDelay",
+		spec: &DeliverySpec{
+			MaxBatchSize:  pointer.Int32Ptr(10),
+			MaxBatchDelay: pointer.StringPtr("PT0.5S"),
+		},
+		ctx: deliveryBatchingEnabledCtx,
+	}, {
+		name: "invalid maxBatchSize and maxBatchDelay",
+		spec: &DeliverySpec{
+			MaxBatchSize:  pointer.Int32Ptr(0),
+			MaxBatchDelay: pointer.StringPtr("PT0S"),
+		},
+		ctx:  deliveryBatchingEnabledCtx,
+		want: apis.ErrInvalidValue(0, "maxBatchSize").Also(apis.ErrInvalidValue("PT0S", "maxBatchDelay")),
+	}, {
+		name: "maxBatchDelay without maxBatchSize",
+		spec: &DeliverySpec{MaxBatchDelay: pointer.StringPtr("PT1S")},
+		ctx:  deliveryBatchingEnabledCtx,
+		want: apis.ErrMissingField("maxBatchSize"),
+	}, {
+		name: "maxBatchSize with orderingKey",
+		spec: &DeliverySpec{
+			MaxBatchSize: pointer.Int32Ptr(10),
+			OrderingKey:  pointer.StringPtr("partitionkey"),
+		},
+		ctx:  deliveryBatchingEnabledCtx,
+		want: apis.ErrMultipleOneOf("orderingKey", "maxBatchSize"),
+	}, {
+		name: "disabled maxBatchSize and maxBatchDelay",
+		spec: &DeliverySpec{
+			MaxBatchSize:  pointer.Int32Ptr(10),
+			MaxBatchDelay: pointer.StringPtr("PT1S"),
+		},
+		want: apis.ErrDisallowedFields("maxBatchSize").Also(apis.ErrDisallowedFields("maxBatchDelay")),
 	}, {
 		name: "valid backoffDelay",
 		spec: &DeliverySpec{BackoffDelay: &validDuration},
@@ -120,3 +286,34 @@ func TestDeliverySpecValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestParseStatusCodeRange(t *testing.T) {
+	tests := []struct {
+		in      string
+		min     int
+		max     int
+		wantErr bool
+	}{
+		{in: "409", min: 409, max: 409},
+		{in: "500-599", min: 500, max: 599},
+		{in: "100-100", min: 100, max: 100},
+		{in: "", wantErr: true},
+		{in: "99", wantErr: true},
+		{in: "600", wantErr: true},
+		{in: "5xx", wantErr: true},
+		{in: "599-500", wantErr: true},
+		{in: "500-", wantErr: true},
+		{in: "-500", wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.in, func(t *testing.T) {
+			min, max, err := ParseStatusCodeRange(tc.in)
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("ParseStatusCodeRange() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if min != tc.min || max != tc.max {
+				t.Errorf("ParseStatusCodeRange() = %d, %d, want %d, %d", min, max, tc.min, tc.max)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+
+	"knative.dev/eventing/pkg/apis/feature"
+)
+
+// Replay requests the events a channel retained before a subscriber was added
+// to be delivered to it, before the events the channel receives afterwards.
+// When neither StartTime nor EventID is specified, all the retained events are
+// replayed.
+type Replay struct {
+	// StartTime, when specified, replays the retained events the channel
+	// received from that time.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// EventID, when specified, replays the retained events from the one with
+	// that ID. Nothing is replayed when the channel didn't retain the event.
+	// +optional
+	EventID *string `json:"eventId,omitempty"`
+}
+
+// Validate the Replay, returning an error if the subscription-replay feature is disabled.
+func (r *Replay) Validate(ctx context.Context) *apis.FieldError {
+	if r == nil {
+		return nil
+	}
+	if !feature.FromContext(ctx).IsEnabled(feature.SubscriptionReplay) {
+		return apis.ErrDisallowedFields(apis.CurrentField)
+	}
+	if r.StartTime != nil && r.EventID != nil {
+		return apis.ErrMultipleOneOf("startTime", "eventId")
+	}
+	if r.EventID != nil && *r.EventID == "" {
+		return apis.ErrInvalidValue(*r.EventID, "eventId")
+	}
+	return nil
+}
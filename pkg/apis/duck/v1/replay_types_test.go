@@ -0,0 +1,82 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	"knative.dev/pkg/apis"
+
+	"knative.dev/eventing/pkg/apis/feature"
+)
+
+func TestReplayValidation(t *testing.T) {
+	replayEnabledCtx := feature.ToContext(context.TODO(), feature.Flags{
+		feature.SubscriptionReplay: feature.Enabled,
+	})
+	startTime := metav1.Now()
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		replay *Replay
+		want   *apis.FieldError
+	}{{
+		name: "nil is valid",
+		ctx:  context.TODO(),
+	}, {
+		name:   "from the start",
+		ctx:    replayEnabledCtx,
+		replay: &Replay{},
+	}, {
+		name:   "from a time",
+		ctx:    replayEnabledCtx,
+		replay: &Replay{StartTime: &startTime},
+	}, {
+		name:   "from an event",
+		ctx:    replayEnabledCtx,
+		replay: &Replay{EventID: pointer.StringPtr("1234")},
+	}, {
+		name:   "empty event ID",
+		ctx:    replayEnabledCtx,
+		replay: &Replay{EventID: pointer.StringPtr("")},
+		want:   apis.ErrInvalidValue("", "eventId"),
+	}, {
+		name:   "both a time and an event",
+		ctx:    replayEnabledCtx,
+		replay: &Replay{StartTime: &startTime, EventID: pointer.StringPtr("1234")},
+		want:   apis.ErrMultipleOneOf("startTime", "eventId"),
+	}, {
+		name:   "feature disabled",
+		ctx:    context.TODO(),
+		replay: &Replay{},
+		want:   apis.ErrDisallowedFields(apis.CurrentField),
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.replay.Validate(tc.ctx)
+			if diff := cmp.Diff(tc.want.Error(), got.Error()); diff != "" {
+				t.Error("Replay.Validate (-want, +got) =", diff)
+			}
+		})
+	}
+}
@@ -49,6 +49,17 @@ type SubscriberSpec struct {
 	// DeliverySpec contains options controlling the event delivery
 	// +optional
 	Delivery *DeliverySpec `json:"delivery,omitempty"`
+	// DataFilter is a list of JSONPath predicates evaluated against the event data.
+	// Only events whose data is JSON and matches all the predicates are sent to the subscriber.
+	// +optional
+	DataFilter []DataFilter `json:"dataFilter,omitempty"`
+	// Replay requests the events retained by the channel before the subscriber was added.
+	// +optional
+	Replay *Replay `json:"replay,omitempty"`
+	// TriggerUID is the UID of the Trigger the subscriber receives the events of, if any.
+	// It identifies the Trigger in the events sent to the dead letter sink.
+	// +optional
+	TriggerUID types.UID `json:"triggerUid,omitempty"`
 }
 
 // SubscriberStatus defines the status of a single subscriber to a Channel.
@@ -135,6 +135,27 @@ func (in *ChannelableStatus) DeepCopy() *ChannelableStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataFilter) DeepCopyInto(out *DataFilter) {
+	*out = *in
+	if in.Value != nil {
+		in, out := &in.Value, &out.Value
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataFilter.
+func (in *DataFilter) DeepCopy() *DataFilter {
+	if in == nil {
+		return nil
+	}
+	out := new(DataFilter)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DeliverySpec) DeepCopyInto(out *DeliverySpec) {
 	*out = *in
@@ -163,6 +184,51 @@ func (in *DeliverySpec) DeepCopyInto(out *DeliverySpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.BackoffMaxDelay != nil {
+		in, out := &in.BackoffMaxDelay, &out.BackoffMaxDelay
+		*out = new(string)
+		**out = **in
+	}
+	if in.RetryAfterMax != nil {
+		in, out := &in.RetryAfterMax, &out.RetryAfterMax
+		*out = new(string)
+		**out = **in
+	}
+	if in.RetryOn != nil {
+		in, out := &in.RetryOn, &out.RetryOn
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DoNotRetryOn != nil {
+		in, out := &in.DoNotRetryOn, &out.DoNotRetryOn
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.OrderingKey != nil {
+		in, out := &in.OrderingKey, &out.OrderingKey
+		*out = new(string)
+		**out = **in
+	}
+	if in.RateLimit != nil {
+		in, out := &in.RateLimit, &out.RateLimit
+		*out = new(RateLimit)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaxInFlight != nil {
+		in, out := &in.MaxInFlight, &out.MaxInFlight
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxBatchSize != nil {
+		in, out := &in.MaxBatchSize, &out.MaxBatchSize
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxBatchDelay != nil {
+		in, out := &in.MaxBatchDelay, &out.MaxBatchDelay
+		*out = new(string)
+		**out = **in
+	}
 	return
 }
 
@@ -197,6 +263,52 @@ func (in *DeliveryStatus) DeepCopy() *DeliveryStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RateLimit) DeepCopyInto(out *RateLimit) {
+	*out = *in
+	if in.Burst != nil {
+		in, out := &in.Burst, &out.Burst
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RateLimit.
+func (in *RateLimit) DeepCopy() *RateLimit {
+	if in == nil {
+		return nil
+	}
+	out := new(RateLimit)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Replay) DeepCopyInto(out *Replay) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.EventID != nil {
+		in, out := &in.EventID, &out.EventID
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Replay.
+func (in *Replay) DeepCopy() *Replay {
+	if in == nil {
+		return nil
+	}
+	out := new(Replay)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Subscribable) DeepCopyInto(out *Subscribable) {
 	*out = *in
@@ -320,6 +432,18 @@ func (in *SubscriberSpec) DeepCopyInto(out *SubscriberSpec) {
 		*out = new(DeliverySpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.DataFilter != nil {
+		in, out := &in.DataFilter, &out.DataFilter
+		*out = make([]DataFilter, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Replay != nil {
+		in, out := &in.Replay, &out.Replay
+		*out = new(Replay)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -32,6 +32,20 @@ func (source *DeliverySpec) ConvertTo(ctx context.Context, to apis.Convertible)
 		sink.Retry = source.Retry
 		sink.BackoffDelay = source.BackoffDelay
 		sink.Timeout = source.Timeout
+		sink.RetryAfterMax = source.RetryAfterMax
+		sink.BackoffMaxDelay = source.BackoffMaxDelay
+		sink.RetryOn = source.RetryOn
+		sink.DoNotRetryOn = source.DoNotRetryOn
+		sink.OrderingKey = source.OrderingKey
+		sink.MaxInFlight = source.MaxInFlight
+		sink.MaxBatchSize = source.MaxBatchSize
+		sink.MaxBatchDelay = source.MaxBatchDelay
+		if source.RateLimit != nil {
+			sink.RateLimit = &eventingduckv1.RateLimit{
+				EventsPerSecond: source.RateLimit.EventsPerSecond,
+				Burst:           source.RateLimit.Burst,
+			}
+		}
 		if source.BackoffPolicy != nil {
 			if *source.BackoffPolicy == BackoffPolicyLinear {
 				linear := eventingduckv1.BackoffPolicyLinear
@@ -39,6 +53,12 @@ func (source *DeliverySpec) ConvertTo(ctx context.Context, to apis.Convertible)
 			} else if *source.BackoffPolicy == BackoffPolicyExponential {
 				exponential := eventingduckv1.BackoffPolicyExponential
 				sink.BackoffPolicy = &exponential
+			} else if *source.BackoffPolicy == BackoffPolicyExponentialJitter {
+				exponentialJitter := eventingduckv1.BackoffPolicyExponentialJitter
+				sink.BackoffPolicy = &exponentialJitter
+			} else if *source.BackoffPolicy == BackoffPolicyExponentialEqualJitter {
+				exponentialEqualJitter := eventingduckv1.BackoffPolicyExponentialEqualJitter
+				sink.BackoffPolicy = &exponentialEqualJitter
 			} else {
 				return fmt.Errorf("unknown BackoffPolicy, got: %q", *source.BackoffPolicy)
 			}
@@ -57,6 +77,20 @@ func (sink *DeliverySpec) ConvertFrom(ctx context.Context, from apis.Convertible
 		sink.Retry = source.Retry
 		sink.BackoffDelay = source.BackoffDelay
 		sink.Timeout = source.Timeout
+		sink.RetryAfterMax = source.RetryAfterMax
+		sink.BackoffMaxDelay = source.BackoffMaxDelay
+		sink.RetryOn = source.RetryOn
+		sink.DoNotRetryOn = source.DoNotRetryOn
+		sink.OrderingKey = source.OrderingKey
+		sink.MaxInFlight = source.MaxInFlight
+		sink.MaxBatchSize = source.MaxBatchSize
+		sink.MaxBatchDelay = source.MaxBatchDelay
+		if source.RateLimit != nil {
+			sink.RateLimit = &RateLimit{
+				EventsPerSecond: source.RateLimit.EventsPerSecond,
+				Burst:           source.RateLimit.Burst,
+			}
+		}
 		if source.BackoffPolicy != nil {
 			if *source.BackoffPolicy == eventingduckv1.BackoffPolicyLinear {
 				linear := BackoffPolicyLinear
@@ -64,6 +98,12 @@ func (sink *DeliverySpec) ConvertFrom(ctx context.Context, from apis.Convertible
 			} else if *source.BackoffPolicy == eventingduckv1.BackoffPolicyExponential {
 				exponential := BackoffPolicyExponential
 				sink.BackoffPolicy = &exponential
+			} else if *source.BackoffPolicy == eventingduckv1.BackoffPolicyExponentialJitter {
+				exponentialJitter := BackoffPolicyExponentialJitter
+				sink.BackoffPolicy = &exponentialJitter
+			} else if *source.BackoffPolicy == eventingduckv1.BackoffPolicyExponentialEqualJitter {
+				exponentialEqualJitter := BackoffPolicyExponentialEqualJitter
+				sink.BackoffPolicy = &exponentialEqualJitter
 			} else {
 				return fmt.Errorf("unknown BackoffPolicy, got: %q", *source.BackoffPolicy)
 			}
@@ -21,6 +21,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"k8s.io/utils/pointer"
 	v1 "knative.dev/eventing/pkg/apis/duck/v1"
 	"knative.dev/pkg/apis"
 	pkgduck "knative.dev/pkg/apis/duck/v1"
@@ -43,6 +44,8 @@ func TestDeliverySpecConversion(t *testing.T) {
 	var retryCount int32 = 10
 	var backoffPolicy BackoffPolicyType = BackoffPolicyLinear
 	var backoffPolicyExp BackoffPolicyType = BackoffPolicyExponential
+	var backoffPolicyJitter BackoffPolicyType = BackoffPolicyExponentialJitter
+	var backoffPolicyEqualJitter BackoffPolicyType = BackoffPolicyExponentialEqualJitter
 	var backoffPolicyBad BackoffPolicyType = "garbage"
 	badPolicyString := `unknown BackoffPolicy, got: "garbage"`
 
@@ -83,6 +86,65 @@ func TestDeliverySpecConversion(t *testing.T) {
 				URI: apis.HTTP("example.com"),
 			},
 		},
+	}, {
+		name: "with retry after max",
+		in: &DeliverySpec{
+			Retry:         &retryCount,
+			RetryAfterMax: pointer.StringPtr("PT30S"),
+			DeadLetterSink: &pkgduck.Destination{
+				URI: apis.HTTP("example.com"),
+			},
+		},
+	}, {
+		name: "with retry on status codes",
+		in: &DeliverySpec{
+			Retry:        &retryCount,
+			RetryOn:      []string{"409", "500-599"},
+			DoNotRetryOn: []string{"422"},
+			DeadLetterSink: &pkgduck.Destination{
+				URI: apis.HTTP("example.com"),
+			},
+		},
+	}, {
+		name: "with exp jitter backoff",
+		in: &DeliverySpec{
+			Retry:           &retryCount,
+			BackoffPolicy:   &backoffPolicyJitter,
+			BackoffMaxDelay: pointer.StringPtr("PT1M"),
+			DeadLetterSink: &pkgduck.Destination{
+				URI: apis.HTTP("example.com"),
+			},
+		},
+	}, {
+		name: "with exp equal jitter backoff",
+		in: &DeliverySpec{
+			Retry:           &retryCount,
+			BackoffPolicy:   &backoffPolicyEqualJitter,
+			BackoffMaxDelay: pointer.StringPtr("PT1M"),
+			DeadLetterSink: &pkgduck.Destination{
+				URI: apis.HTTP("example.com"),
+			},
+		},
+	}, {
+		name: "with ordering key",
+		in: &DeliverySpec{
+			Retry:       &retryCount,
+			OrderingKey: pointer.StringPtr("partitionkey"),
+		},
+	}, {
+		name: "with rate limit and max in flight",
+		in: &DeliverySpec{
+			Retry:       &retryCount,
+			RateLimit:   &RateLimit{EventsPerSecond: 10, Burst: pointer.Int32Ptr(20)},
+			MaxInFlight: pointer.Int32Ptr(5),
+		},
+	}, {
+		name: "with batching",
+		in: &DeliverySpec{
+			Retry:         &retryCount,
+			MaxBatchSize:  pointer.Int32Ptr(10),
+			MaxBatchDelay: pointer.StringPtr("PT0.5S"),
+		},
 	}, {
 		name: "with bad backoff",
 		in: &DeliverySpec{
@@ -120,6 +182,8 @@ func TestDeliverySpecConversionV1(t *testing.T) {
 	var retryCount int32 = 10
 	var backoffPolicy v1.BackoffPolicyType = v1.BackoffPolicyLinear
 	var backoffPolicyExp v1.BackoffPolicyType = v1.BackoffPolicyExponential
+	var backoffPolicyJitter v1.BackoffPolicyType = v1.BackoffPolicyExponentialJitter
+	var backoffPolicyEqualJitter v1.BackoffPolicyType = v1.BackoffPolicyExponentialEqualJitter
 	var backoffPolicyBad v1.BackoffPolicyType = "garbage"
 	badPolicyString := `unknown BackoffPolicy, got: "garbage"`
 
@@ -160,6 +224,56 @@ func TestDeliverySpecConversionV1(t *testing.T) {
 				URI: apis.HTTP("example.com"),
 			},
 		},
+	}, {
+		name: "with retry on status codes",
+		in: &v1.DeliverySpec{
+			Retry:        &retryCount,
+			RetryOn:      []string{"409", "500-599"},
+			DoNotRetryOn: []string{"422"},
+			DeadLetterSink: &pkgduck.Destination{
+				URI: apis.HTTP("example.com"),
+			},
+		},
+	}, {
+		name: "with exp jitter backoff",
+		in: &v1.DeliverySpec{
+			Retry:           &retryCount,
+			BackoffPolicy:   &backoffPolicyJitter,
+			BackoffMaxDelay: pointer.StringPtr("PT1M"),
+			DeadLetterSink: &pkgduck.Destination{
+				URI: apis.HTTP("example.com"),
+			},
+		},
+	}, {
+		name: "with exp equal jitter backoff",
+		in: &v1.DeliverySpec{
+			Retry:           &retryCount,
+			BackoffPolicy:   &backoffPolicyEqualJitter,
+			BackoffMaxDelay: pointer.StringPtr("PT1M"),
+			DeadLetterSink: &pkgduck.Destination{
+				URI: apis.HTTP("example.com"),
+			},
+		},
+	}, {
+		name: "with ordering key",
+		in: &v1.DeliverySpec{
+			Retry:       &retryCount,
+			OrderingKey: pointer.StringPtr("partitionkey"),
+		},
+	}, {
+		name: "with rate limit and max in flight",
+		in: &v1.DeliverySpec{
+			Retry:       &retryCount,
+			RateLimit:   &v1.RateLimit{EventsPerSecond: 10, Burst: pointer.Int32Ptr(20)},
+			MaxInFlight: pointer.Int32Ptr(5),
+		},
+	}, {
+		name: "with batching",
+		in: &v1.DeliverySpec{
+			Retry:         &retryCount,
+			MaxBatchSize:  pointer.Int32Ptr(10),
+			MaxBatchDelay: pointer.StringPtr("PT0.5S"),
+		},
 	}, {
 		name: "with bad backoff",
 		in: &v1.DeliverySpec{
@@ -23,6 +23,8 @@ import (
 	duckv1 "knative.dev/pkg/apis/duck/v1"
 
 	"github.com/rickb777/date/period"
+
+	eventingduckv1 "knative.dev/eventing/pkg/apis/duck/v1"
 )
 
 // DeliverySpec contains the delivery options for event senders,
@@ -45,7 +47,8 @@ type DeliverySpec struct {
 	//
 	Timeout *string `json:"timeout,omitempty"`
 
-	// BackoffPolicy is the retry backoff policy (linear, exponential).
+	// BackoffPolicy is the retry backoff policy (linear, exponential, exponential-jitter,
+	// exponential-equal-jitter).
 	// +optional
 	BackoffPolicy *BackoffPolicyType `json:"backoffPolicy,omitempty"`
 
@@ -56,8 +59,77 @@ type DeliverySpec struct {
 	//
 	// For linear policy, backoff delay is backoffDelay*<numberOfRetries>.
 	// For exponential policy, backoff delay is backoffDelay*2^<numberOfRetries>.
+	// For exponential-jitter policy, backoff delay is a random delay between 0 and
+	// backoffDelay*2^<numberOfRetries>.
+	// For exponential-equal-jitter policy, backoff delay is half of backoffDelay*2^<numberOfRetries>
+	// plus a random delay between 0 and the other half.
 	// +optional
 	BackoffDelay *string `json:"backoffDelay,omitempty"`
+
+	// BackoffMaxDelay is the maximum delay before retrying, capping the delay computed by the
+	// backoff policy before any jitter is applied.
+	// More information on Duration format:
+	//  - https://www.iso.org/iso-8601-date-and-time-format.html
+	//  - https://en.wikipedia.org/wiki/ISO_8601
+	//
+	// +optional
+	BackoffMaxDelay *string `json:"backoffMaxDelay,omitempty"`
+
+	// RetryAfterMax makes the sender honor the Retry-After header of 429 and 503 responses,
+	// waiting before retrying for the duration it specifies, capped at RetryAfterMax.
+	// More information on Duration format:
+	//  - https://www.iso.org/iso-8601-date-and-time-format.html
+	//  - https://en.wikipedia.org/wiki/ISO_8601
+	//
+	// +optional
+	RetryAfterMax *string `json:"retryAfterMax,omitempty"`
+
+	// RetryOn is the list of response status codes which are retried, either single
+	// status codes, e.g. "409", or inclusive ranges, e.g. "500-599". When set, the
+	// responses with any other status code aren't retried.
+	// +optional
+	RetryOn []string `json:"retryOn,omitempty"`
+
+	// DoNotRetryOn is the list of response status codes which are never retried,
+	// even when they match RetryOn.
+	// +optional
+	DoNotRetryOn []string `json:"doNotRetryOn,omitempty"`
+
+	// OrderingKey is the name of the attribute, e.g. partitionkey or subject, ordering
+	// the delivery of the events: the events with the same value are delivered one after
+	// the other, in the order they were received.
+	// +optional
+	OrderingKey *string `json:"orderingKey,omitempty"`
+
+	// RateLimit caps the rate of the events sent to the destination.
+	// +optional
+	RateLimit *RateLimit `json:"rateLimit,omitempty"`
+
+	// MaxInFlight is the maximum number of events sent to the destination at the same time,
+	// the events waiting for the backoff delay of a retry included.
+	// +optional
+	MaxInFlight *int32 `json:"maxInFlight,omitempty"`
+
+	// MaxBatchSize, when set, makes the dispatcher send the events to the destination in
+	// batches of at most this many events.
+	// +optional
+	MaxBatchSize *int32 `json:"maxBatchSize,omitempty"`
+
+	// MaxBatchDelay is the maximum time an event waits for its batch to be full, expressed
+	// as an ISO 8601 duration.
+	// +optional
+	MaxBatchDelay *string `json:"maxBatchDelay,omitempty"`
+}
+
+// RateLimit is a token bucket limiting the rate of the events.
+type RateLimit struct {
+	// EventsPerSecond is the sustained rate of the events, it must be greater than 0.
+	EventsPerSecond int32 `json:"eventsPerSecond"`
+
+	// Burst is the maximum number of events sent at once, above the sustained rate.
+	// It must be greater than 0, it defaults to EventsPerSecond.
+	// +optional
+	Burst *int32 `json:"burst,omitempty"`
 }
 
 func (ds *DeliverySpec) Validate(ctx context.Context) *apis.FieldError {
@@ -82,7 +154,7 @@ func (ds *DeliverySpec) Validate(ctx context.Context) *apis.FieldError {
 
 	if ds.BackoffPolicy != nil {
 		switch *ds.BackoffPolicy {
-		case BackoffPolicyExponential, BackoffPolicyLinear:
+		case BackoffPolicyExponential, BackoffPolicyLinear, BackoffPolicyExponentialJitter, BackoffPolicyExponentialEqualJitter:
 			// nothing
 		default:
 			errs = errs.Also(apis.ErrInvalidValue(*ds.BackoffPolicy, "backoffPolicy"))
@@ -95,6 +167,65 @@ func (ds *DeliverySpec) Validate(ctx context.Context) *apis.FieldError {
 			errs = errs.Also(apis.ErrInvalidValue(*ds.BackoffDelay, "backoffDelay"))
 		}
 	}
+
+	if ds.BackoffMaxDelay != nil {
+		p, te := period.Parse(*ds.BackoffMaxDelay)
+		if te != nil || p.IsZero() || p.IsNegative() {
+			errs = errs.Also(apis.ErrInvalidValue(*ds.BackoffMaxDelay, "backoffMaxDelay"))
+		}
+	}
+
+	if ds.RetryAfterMax != nil {
+		p, te := period.Parse(*ds.RetryAfterMax)
+		if te != nil || p.IsZero() || p.IsNegative() {
+			errs = errs.Also(apis.ErrInvalidValue(*ds.RetryAfterMax, "retryAfterMax"))
+		}
+	}
+
+	for i, r := range ds.RetryOn {
+		if _, _, err := eventingduckv1.ParseStatusCodeRange(r); err != nil {
+			errs = errs.Also(apis.ErrInvalidArrayValue(r, "retryOn", i))
+		}
+	}
+	for i, r := range ds.DoNotRetryOn {
+		if _, _, err := eventingduckv1.ParseStatusCodeRange(r); err != nil {
+			errs = errs.Also(apis.ErrInvalidArrayValue(r, "doNotRetryOn", i))
+		}
+	}
+
+	if ds.OrderingKey != nil && !eventingduckv1.IsValidOrderingKey(*ds.OrderingKey) {
+		errs = errs.Also(apis.ErrInvalidValue(*ds.OrderingKey, "orderingKey"))
+	}
+
+	if ds.RateLimit != nil {
+		if ds.RateLimit.EventsPerSecond < 1 {
+			errs = errs.Also(apis.ErrInvalidValue(ds.RateLimit.EventsPerSecond, "rateLimit.eventsPerSecond"))
+		}
+		if ds.RateLimit.Burst != nil && *ds.RateLimit.Burst < 1 {
+			errs = errs.Also(apis.ErrInvalidValue(*ds.RateLimit.Burst, "rateLimit.burst"))
+		}
+	}
+
+	if ds.MaxInFlight != nil && *ds.MaxInFlight < 1 {
+		errs = errs.Also(apis.ErrInvalidValue(*ds.MaxInFlight, "maxInFlight"))
+	}
+
+	if ds.MaxBatchSize != nil || ds.MaxBatchDelay != nil {
+		if ds.MaxBatchSize == nil {
+			errs = errs.Also(apis.ErrMissingField("maxBatchSize"))
+		} else if *ds.MaxBatchSize < 1 {
+			errs = errs.Also(apis.ErrInvalidValue(*ds.MaxBatchSize, "maxBatchSize"))
+		}
+		if ds.MaxBatchDelay != nil {
+			p, te := period.Parse(*ds.MaxBatchDelay)
+			if te != nil || p.IsZero() || p.IsNegative() {
+				errs = errs.Also(apis.ErrInvalidValue(*ds.MaxBatchDelay, "maxBatchDelay"))
+			}
+		}
+		if ds.MaxBatchSize != nil && ds.OrderingKey != nil {
+			errs = errs.Also(apis.ErrMultipleOneOf("orderingKey", "maxBatchSize"))
+		}
+	}
 	return errs
 }
 
@@ -107,6 +238,12 @@ const (
 
 	// Exponential backoff policy
 	BackoffPolicyExponential BackoffPolicyType = "exponential"
+
+	// Exponential backoff policy with full jitter
+	BackoffPolicyExponentialJitter BackoffPolicyType = "exponential-jitter"
+
+	// Exponential backoff policy with equal jitter
+	BackoffPolicyExponentialEqualJitter BackoffPolicyType = "exponential-equal-jitter"
 )
 
 // DeliveryStatus contains the Status of an object supporting delivery options.
@@ -28,6 +28,7 @@ import (
 func TestDeliverySpecValidation(t *testing.T) {
 	invalidString := "invalid time"
 	bop := BackoffPolicyExponential
+	jitter := BackoffPolicyExponentialEqualJitter
 	validDuration := "PT2S"
 	invalidDuration := "1985-04-12T23:20:50.52Z"
 	tests := []struct {
@@ -60,10 +61,91 @@ func TestDeliverySpecValidation(t *testing.T) {
 		want: func() *apis.FieldError {
 			return apis.ErrInvalidValue(invalidDuration, "timeout")
 		}(),
+	}, {
+		name: "valid retryAfterMax",
+		spec: &DeliverySpec{RetryAfterMax: &validDuration},
+		want: nil,
+	}, {
+		name: "invalid retryAfterMax",
+		spec: &DeliverySpec{RetryAfterMax: &invalidDuration},
+		want: apis.ErrInvalidValue(invalidDuration, "retryAfterMax"),
+	}, {
+		name: "zero retryAfterMax",
+		spec: &DeliverySpec{RetryAfterMax: pointer.StringPtr("PT0S")},
+		want: apis.ErrInvalidValue("PT0S", "retryAfterMax"),
+	}, {
+		name: "negative retryAfterMax",
+		spec: &DeliverySpec{RetryAfterMax: pointer.StringPtr("-PT1S")},
+		want: apis.ErrInvalidValue("-PT1S", "retryAfterMax"),
+	}, {
+		name: "valid retryOn and doNotRetryOn",
+		spec: &DeliverySpec{RetryOn: []string{"409", "500-599"}, DoNotRetryOn: []string{"422"}},
+		want: nil,
+	}, {
+		name: "invalid retryOn and doNotRetryOn",
+		spec: &DeliverySpec{RetryOn: []string{"409", "599-500"}, DoNotRetryOn: []string{"4xx"}},
+		want: apis.ErrInvalidArrayValue("599-500", "retryOn", 1).Also(
+			apis.ErrInvalidArrayValue("4xx", "doNotRetryOn", 0)),
 	}, {
 		name: "valid backoffPolicy",
 		spec: &DeliverySpec{BackoffPolicy: &bop},
 		want: nil,
+	}, {
+		name: "valid jitter backoffPolicy",
+		spec: &DeliverySpec{BackoffPolicy: &jitter},
+		want: nil,
+	}, {
+		name: "valid backoffMaxDelay",
+		spec: &DeliverySpec{BackoffMaxDelay: &validDuration},
+		want: nil,
+	}, {
+		name: "invalid backoffMaxDelay",
+		spec: &DeliverySpec{BackoffMaxDelay: &invalidDuration},
+		want: apis.ErrInvalidValue(invalidDuration, "backoffMaxDelay"),
+	}, {
+		name: "zero backoffMaxDelay",
+		spec: &DeliverySpec{BackoffMaxDelay: pointer.StringPtr("PT0S")},
+		want: apis.ErrInvalidValue("PT0S", "backoffMaxDelay"),
+	}, {
+		name: "negative backoffMaxDelay",
+		spec: &DeliverySpec{BackoffMaxDelay: pointer.StringPtr("-PT1S")},
+		want: apis.ErrInvalidValue("-PT1S", "backoffMaxDelay"),
+	}, {
+		name: "valid orderingKey",
+		spec: &DeliverySpec{OrderingKey: pointer.StringPtr("subject")},
+		want: nil,
+	}, {
+		name: "invalid orderingKey",
+		spec: &DeliverySpec{OrderingKey: pointer.StringPtr("Subject")},
+		want: apis.ErrInvalidValue("Subject", "orderingKey"),
+	}, {
+		name: "valid rateLimit and maxInFlight",
+		spec: &DeliverySpec{
+			RateLimit:   &RateLimit{EventsPerSecond: 10},
+			MaxInFlight: pointer.Int32Ptr(5),
+		},
+		want: nil,
+	}, {
+		name: "invalid rateLimit and maxInFlight",
+		spec: &DeliverySpec{
+			RateLimit:   &RateLimit{EventsPerSecond: 10, Burst: pointer.Int32Ptr(0)},
+			MaxInFlight: pointer.Int32Ptr(-1),
+		},
+		want: apis.ErrInvalidValue(0, "rateLimit.burst").Also(apis.ErrInvalidValue(-1, "maxInFlight")),
+	}, {
+		name: "valid maxBatchSize and maxBatchDelay",
+		spec: &DeliverySpec{
+			MaxBatchSize:  pointer.Int32Ptr(10),
+			MaxBatchDelay: pointer.StringPtr("PT0.5S"),
+		},
+		want: nil,
+	}, {
+		name: "invalid maxBatchSize and maxBatchDelay",
+		spec: &DeliverySpec{
+			MaxBatchDelay: pointer.StringPtr("soon"),
+			OrderingKey:   pointer.StringPtr("partitionkey"),
+		},
+		want: apis.ErrMissingField("maxBatchSize").Also(apis.ErrInvalidValue("soon", "maxBatchDelay")),
 	}, {
 		name: "valid backoffDelay",
 		spec: &DeliverySpec{BackoffDelay: &validDuration},
@@ -35,6 +35,54 @@ const (
 	// pkg/reconciler/broker
 	MTChannelBrokerClassValue = "MTChannelBasedBroker"
 
+	// BrokerDispatchModeAnnotationKey is the annotation key on Brokers to
+	// indicate how events are fanned out to their Triggers.
+	// Valid values are: trigger, broker.
+	BrokerDispatchModeAnnotationKey = GroupName + "/broker.dispatchMode"
+
+	// BrokerDispatchModeTrigger indicates that every Trigger subscribes to
+	// the Broker's channel and filters the events on its own. This is the
+	// default dispatch mode.
+	BrokerDispatchModeTrigger = "trigger"
+
+	// BrokerDispatchModeBroker indicates that the Broker subscribes once to
+	// its channel, with the delivery spec of the Broker, and the filter
+	// evaluates all its Triggers in-process, sending the events only to the
+	// matching ones. The filter acknowledges an event to the channel once
+	// its deliveries are done: the deliveries failing after their retries
+	// go to the dead letter sink of their Trigger, if any. The channel
+	// redelivers the events rejected while the filter is too busy, so a
+	// Trigger may get an event more than once.
+	BrokerDispatchModeBroker = "broker"
+
+	// BrokerEventTypePolicyAnnotationKey is the annotation key on Brokers to
+	// indicate which events their ingress accepts.
+	// Valid values are: all, registered.
+	BrokerEventTypePolicyAnnotationKey = GroupName + "/broker.eventTypePolicy"
+
+	// BrokerEventTypePolicyAll indicates that the ingress accepts any event.
+	// This is the default event type policy.
+	BrokerEventTypePolicyAll = "all"
+
+	// BrokerEventTypePolicyRegistered indicates that the ingress only accepts
+	// the events whose type and source are registered as an EventType of the
+	// Broker, rejecting the others with 403 Forbidden.
+	BrokerEventTypePolicyRegistered = "registered"
+
+	// BrokerSchemaValidationAnnotationKey is the annotation key on Brokers to
+	// validate the data of the events received by their ingress against the
+	// JSON schema of their EventType.
+	// Valid values are: reject, deadLetter.
+	BrokerSchemaValidationAnnotationKey = GroupName + "/broker.schemaValidation"
+
+	// BrokerSchemaValidationReject indicates that the ingress rejects the
+	// invalid events with 400 Bad Request.
+	BrokerSchemaValidationReject = "reject"
+
+	// BrokerSchemaValidationDeadLetter indicates that the ingress sends the
+	// invalid events to the dead letter sink of the Broker.
+	BrokerSchemaValidationDeadLetter = "deadLetter"
+
 	// ScopeAnnotationKey is the annotation key to indicate
 	// the scope of the component handling a given resource.
 	// Valid values are: cluster, namespace, resource.
@@ -56,6 +104,15 @@ const (
 	// if a Source has event types defines in its CRD.
 	EventTypesAnnotationKey = "registry.knative.dev/eventTypes"
 
+	// AutoDiscoveredLabelKey is the label key on the EventTypes registered
+	// by the broker ingress from the events it received.
+	AutoDiscoveredLabelKey = GroupName + "/autoDiscovered"
+
+	// LastSeenAnnotationKey is the annotation key on auto-discovered
+	// EventTypes holding the last time the broker ingress received such an
+	// event, in RFC 3339 format.
+	LastSeenAnnotationKey = GroupName + "/lastSeen"
+
 	// BrokerChannelAddressStatusAnnotationKey is the broker status
 	// annotation key used to specify the address of its channel.
 	BrokerChannelAddressStatusAnnotationKey = "knative.dev/channelAddress"
@@ -23,6 +23,8 @@ import (
 
 	"knative.dev/pkg/apis"
 	"knative.dev/pkg/kmp"
+
+	"knative.dev/eventing/pkg/apis/eventing"
 )
 
 const (
@@ -38,6 +40,33 @@ func (b *Broker) Validate(ctx context.Context) *apis.FieldError {
 		errs = errs.Also(apis.ErrMissingField(BrokerClassAnnotationKey))
 	}
 
+	// The dispatch mode annotation is optional, but it must be a known mode when set.
+	if dm, ok := b.GetAnnotations()[eventing.BrokerDispatchModeAnnotationKey]; ok {
+		switch dm {
+		case eventing.BrokerDispatchModeTrigger, eventing.BrokerDispatchModeBroker:
+		default:
+			errs = errs.Also(apis.ErrInvalidValue(dm, eventing.BrokerDispatchModeAnnotationKey))
+		}
+	}
+
+	// The event type policy annotation is optional, but it must be a known policy when set.
+	if p, ok := b.GetAnnotations()[eventing.BrokerEventTypePolicyAnnotationKey]; ok {
+		switch p {
+		case eventing.BrokerEventTypePolicyAll, eventing.BrokerEventTypePolicyRegistered:
+		default:
+			errs = errs.Also(apis.ErrInvalidValue(p, eventing.BrokerEventTypePolicyAnnotationKey))
+		}
+	}
+
+	// The schema validation annotation is optional, but it must be a known action when set.
+	if sv, ok := b.GetAnnotations()[eventing.BrokerSchemaValidationAnnotationKey]; ok {
+		switch sv {
+		case eventing.BrokerSchemaValidationReject, eventing.BrokerSchemaValidationDeadLetter:
+		default:
+			errs = errs.Also(apis.ErrInvalidValue(sv, eventing.BrokerSchemaValidationAnnotationKey))
+		}
+	}
+
 	errs = errs.Also(b.Spec.Validate(withNS).ViaField("spec"))
 	if apis.IsInUpdate(ctx) {
 		original := apis.GetBaseline(ctx).(*Broker)
@@ -187,6 +187,69 @@ func TestValidate(t *testing.T) {
 			},
 		},
 		want: apis.ErrInvalidValue(invalidString, "spec.delivery.backoffDelay"),
+	}, {
+		name: "valid dispatch mode",
+		b: Broker{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					"eventing.knative.dev/broker.class":        "MTChannelBasedBroker",
+					"eventing.knative.dev/broker.dispatchMode": "broker",
+				},
+			},
+		},
+	}, {
+		name: "invalid dispatch mode",
+		b: Broker{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					"eventing.knative.dev/broker.class":        "MTChannelBasedBroker",
+					"eventing.knative.dev/broker.dispatchMode": "channel",
+				},
+			},
+		},
+		want: apis.ErrInvalidValue("channel", "eventing.knative.dev/broker.dispatchMode"),
+	}, {
+		name: "valid event type policy",
+		b: Broker{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					"eventing.knative.dev/broker.class":           "MTChannelBasedBroker",
+					"eventing.knative.dev/broker.eventTypePolicy": "registered",
+				},
+			},
+		},
+	}, {
+		name: "invalid event type policy",
+		b: Broker{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					"eventing.knative.dev/broker.class":           "MTChannelBasedBroker",
+					"eventing.knative.dev/broker.eventTypePolicy": "none",
+				},
+			},
+		},
+		want: apis.ErrInvalidValue("none", "eventing.knative.dev/broker.eventTypePolicy"),
+	}, {
+		name: "valid schema validation",
+		b: Broker{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					"eventing.knative.dev/broker.class":            "MTChannelBasedBroker",
+					"eventing.knative.dev/broker.schemaValidation": "deadLetter",
+				},
+			},
+		},
+	}, {
+		name: "invalid schema validation",
+		b: Broker{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					"eventing.knative.dev/broker.class":            "MTChannelBasedBroker",
+					"eventing.knative.dev/broker.schemaValidation": "drop",
+				},
+			},
+		},
+		want: apis.ErrInvalidValue("drop", "eventing.knative.dev/broker.schemaValidation"),
 	}}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
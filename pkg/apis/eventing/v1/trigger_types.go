@@ -34,6 +34,10 @@ const (
 	// InjectionAnnotation is the annotation key used to enable knative eventing
 	// injection for a namespace to automatically create a broker.
 	InjectionAnnotation = "eventing.knative.dev/injection"
+
+	// SchemaValidationAnnotation is the annotation key used to validate the data of the events
+	// delivered to the subscriber of the Trigger against the JSON schema of their EventType.
+	SchemaValidationAnnotation = "eventing.knative.dev/schemaValidation"
 )
 
 // +genclient
@@ -83,6 +87,14 @@ type TriggerSpec struct {
 	// +optional
 	Filter *TriggerFilter `json:"filter,omitempty"`
 
+	// Transform rewrites the attributes of the events that pass the Filter before they are sent
+	// to the Subscriber. If not specified, events are sent unchanged.
+	//
+	// Note: This API is EXPERIMENTAL and might break anytime.
+	//
+	// +optional
+	Transform *TriggerTransform `json:"transform,omitempty"`
+
 	// Subscriber is the addressable that receives events from the Broker that pass the Filter. It
 	// is required.
 	Subscriber duckv1.Destination `json:"subscriber"`
@@ -102,6 +114,103 @@ type TriggerFilter struct {
 	//
 	// +optional
 	Attributes TriggerFilterAttributes `json:"attributes,omitempty"`
+
+	// SQL is a CloudEvents SQL expression that will be evaluated to true or false against each CloudEvent.
+	// An event passes the filter if the expression evaluates to true. If Attributes is also specified,
+	// both must pass.
+	//
+	// Note: This API is EXPERIMENTAL and might break anytime.
+	//
+	// +optional
+	SQL string `json:"sql,omitempty"`
+
+	// Filters is a list of filter expressions using the CloudEvents Subscriptions API filter dialects.
+	// An event passes the filter if all the expressions pass. If Attributes or SQL are also specified,
+	// all of them must pass.
+	//
+	// Note: This API is EXPERIMENTAL and might break anytime.
+	//
+	// +optional
+	Filters []SubscriptionsAPIFilter `json:"filters,omitempty"`
+
+	// Data is a list of JSONPath predicates evaluated against the event data. An event
+	// passes the filter if its data is JSON and all the predicates match. If Attributes, SQL
+	// or Filters are also specified, all of them must pass.
+	//
+	// Note: This API is EXPERIMENTAL and might break anytime.
+	//
+	// +optional
+	Data []eventingduckv1.DataFilter `json:"data,omitempty"`
+}
+
+// SubscriptionsAPIFilter is a filter expression using one of the CloudEvents Subscriptions API
+// filter dialects. Exactly one of the dialects must be specified.
+type SubscriptionsAPIFilter struct {
+	// All evaluates to true if all the nested expressions evaluate to true.
+	// It must contain at least one filter expression.
+	//
+	// +optional
+	All []SubscriptionsAPIFilter `json:"all,omitempty"`
+
+	// Any evaluates to true if at least one of the nested expressions evaluates to true.
+	// It must contain at least one filter expression.
+	//
+	// +optional
+	Any []SubscriptionsAPIFilter `json:"any,omitempty"`
+
+	// Not evaluates to true if the nested expression evaluates to false.
+	//
+	// +optional
+	Not *SubscriptionsAPIFilter `json:"not,omitempty"`
+
+	// Exact evaluates to true if the value of each context attribute or extension exactly
+	// matches the specified value. Matching is case sensitive.
+	//
+	// +optional
+	Exact map[string]string `json:"exact,omitempty"`
+
+	// Prefix evaluates to true if the value of each context attribute or extension starts
+	// with the specified value. Matching is case sensitive.
+	//
+	// +optional
+	Prefix map[string]string `json:"prefix,omitempty"`
+
+	// Suffix evaluates to true if the value of each context attribute or extension ends
+	// with the specified value. Matching is case sensitive.
+	//
+	// +optional
+	Suffix map[string]string `json:"suffix,omitempty"`
+}
+
+// TriggerTransform rewrites the context attributes of an event. Type, Source and the values of
+// Extensions are templates which can reference the attributes of the original event as ${name},
+// e.g. "${source}/orders". All the templates are evaluated against the original event.
+type TriggerTransform struct {
+	// Type overrides the type attribute. It must not evaluate to an empty string.
+	//
+	// +optional
+	Type string `json:"type,omitempty"`
+
+	// Source overrides the source attribute. It must evaluate to a non-empty URI-reference.
+	//
+	// +optional
+	Source string `json:"source,omitempty"`
+
+	// Extensions sets extension attributes, overriding the existing values. An extension
+	// whose value evaluates to an empty string is removed.
+	//
+	// +optional
+	Extensions map[string]string `json:"extensions,omitempty"`
+
+	// Rename renames extension attributes, from the key to the value.
+	//
+	// +optional
+	Rename map[string]string `json:"rename,omitempty"`
+
+	// Remove removes extension attributes.
+	//
+	// +optional
+	Remove []string `json:"remove,omitempty"`
 }
 
 // TriggerFilterAttributes is a map of context attribute names to values for
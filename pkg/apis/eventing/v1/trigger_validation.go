@@ -22,10 +22,16 @@ import (
 	"fmt"
 	"regexp"
 
+	"github.com/cloudevents/sdk-go/v2/binding/spec"
 	"knative.dev/pkg/apis"
 	"knative.dev/pkg/kmp"
 
 	corev1 "k8s.io/api/core/v1"
+
+	eventingduckv1 "knative.dev/eventing/pkg/apis/duck/v1"
+	"knative.dev/eventing/pkg/apis/feature"
+	"knative.dev/eventing/pkg/eventfilter/cesql"
+	"knative.dev/eventing/pkg/eventtransform"
 )
 
 var (
@@ -38,6 +44,7 @@ func (t *Trigger) Validate(ctx context.Context) *apis.FieldError {
 	errs := t.Spec.Validate(ctx).ViaField("spec")
 	errs = t.validateAnnotation(errs, DependencyAnnotation, t.validateDependencyAnnotation)
 	errs = t.validateAnnotation(errs, InjectionAnnotation, t.validateInjectionAnnotation)
+	errs = t.validateAnnotation(errs, SchemaValidationAnnotation, t.validateSchemaValidationAnnotation)
 	if apis.IsInUpdate(ctx) {
 		original := apis.GetBaseline(ctx).(*Trigger)
 		errs = errs.Also(t.CheckImmutableFields(ctx, original))
@@ -63,6 +70,22 @@ func (ts *TriggerSpec) Validate(ctx context.Context) *apis.FieldError {
 				errs = errs.Also(fe)
 			}
 		}
+
+		if ts.Filter.SQL != "" {
+			errs = errs.Also(ts.Filter.validateSQL(ctx).ViaField("filter"))
+		}
+
+		if len(ts.Filter.Filters) != 0 {
+			errs = errs.Also(ts.Filter.validateFilters(ctx).ViaField("filter"))
+		}
+
+		if len(ts.Filter.Data) != 0 {
+			errs = errs.Also(eventingduckv1.ValidateDataFilters(ctx, ts.Filter.Data).ViaField("filter", "data"))
+		}
+	}
+
+	if ts.Transform != nil {
+		errs = errs.Also(ts.Transform.Validate(ctx).ViaField("transform"))
 	}
 
 	if fe := ts.Subscriber.Validate(ctx); fe != nil {
@@ -78,6 +101,164 @@ func (ts *TriggerSpec) Validate(ctx context.Context) *apis.FieldError {
 	return errs
 }
 
+func (tf *TriggerFilter) validateSQL(ctx context.Context) *apis.FieldError {
+	if !feature.FromContext(ctx).IsEnabled(feature.NewTriggerFilters) {
+		return apis.ErrDisallowedFields("sql")
+	}
+	if _, err := cesql.Parse(tf.SQL); err != nil {
+		fe := apis.ErrInvalidValue(tf.SQL, "sql")
+		fe.Details = err.Error()
+		return fe
+	}
+	return nil
+}
+
+func (tf *TriggerFilter) validateFilters(ctx context.Context) *apis.FieldError {
+	if !feature.FromContext(ctx).IsEnabled(feature.NewTriggerFilters) {
+		return apis.ErrDisallowedFields("filters")
+	}
+	var errs *apis.FieldError
+	for i, f := range tf.Filters {
+		errs = errs.Also(f.Validate(ctx).ViaFieldIndex("filters", i))
+	}
+	return errs
+}
+
+// Validate the SubscriptionsAPIFilter, recursing in the nested expressions.
+func (f *SubscriptionsAPIFilter) Validate(ctx context.Context) *apis.FieldError {
+	var dialects []string
+	if f.All != nil {
+		dialects = append(dialects, "all")
+	}
+	if f.Any != nil {
+		dialects = append(dialects, "any")
+	}
+	if f.Not != nil {
+		dialects = append(dialects, "not")
+	}
+	if f.Exact != nil {
+		dialects = append(dialects, "exact")
+	}
+	if f.Prefix != nil {
+		dialects = append(dialects, "prefix")
+	}
+	if f.Suffix != nil {
+		dialects = append(dialects, "suffix")
+	}
+	if len(dialects) != 1 {
+		return apis.ErrGeneric(fmt.Sprintf("expected exactly one filter dialect, got %d", len(dialects)), dialects...)
+	}
+
+	var errs *apis.FieldError
+	switch {
+	case f.All != nil:
+		errs = validateNestedFilters(ctx, "all", f.All)
+	case f.Any != nil:
+		errs = validateNestedFilters(ctx, "any", f.Any)
+	case f.Not != nil:
+		errs = f.Not.Validate(ctx).ViaField("not")
+	case f.Exact != nil:
+		errs = validateFilterAttributes("exact", f.Exact)
+	case f.Prefix != nil:
+		errs = validateFilterAttributes("prefix", f.Prefix)
+	case f.Suffix != nil:
+		errs = validateFilterAttributes("suffix", f.Suffix)
+	}
+	return errs
+}
+
+func validateNestedFilters(ctx context.Context, field string, filters []SubscriptionsAPIFilter) *apis.FieldError {
+	if len(filters) == 0 {
+		return apis.ErrInvalidValue("expected at least one filter expression", field)
+	}
+	var errs *apis.FieldError
+	for i, f := range filters {
+		errs = errs.Also(f.Validate(ctx).ViaFieldIndex(field, i))
+	}
+	return errs
+}
+
+func validateFilterAttributes(field string, attrs map[string]string) *apis.FieldError {
+	if len(attrs) == 0 {
+		return apis.ErrInvalidValue("expected at least one attribute", field)
+	}
+	var errs *apis.FieldError
+	for attr, value := range attrs {
+		if !validAttributeName.MatchString(attr) {
+			errs = errs.Also(&apis.FieldError{
+				Message: fmt.Sprintf("Invalid attribute name: %q", attr),
+				Paths:   []string{field},
+			})
+		}
+		if value == "" {
+			errs = errs.Also(apis.ErrInvalidValue(value, apis.CurrentField).ViaKey(attr).ViaField(field))
+		}
+	}
+	return errs
+}
+
+// Validate the TriggerTransform. The transform must not produce an invalid CloudEvent, so only
+// extensions can be set, renamed or removed, while the required attributes other than type and
+// source, like id, can't be changed.
+func (tt *TriggerTransform) Validate(ctx context.Context) *apis.FieldError {
+	if !feature.FromContext(ctx).IsEnabled(feature.TriggerTransform) {
+		return apis.ErrDisallowedFields(apis.CurrentField)
+	}
+	if tt.Type == "" && tt.Source == "" && len(tt.Extensions) == 0 && len(tt.Rename) == 0 && len(tt.Remove) == 0 {
+		return apis.ErrMissingOneOf("type", "source", "extensions", "rename", "remove")
+	}
+
+	var errs *apis.FieldError
+	errs = errs.Also(validateTemplate("type", tt.Type))
+	errs = errs.Also(validateTemplate("source", tt.Source))
+	if tt.Source != "" && eventtransform.IsStatic(tt.Source) {
+		if _, err := apis.ParseURL(tt.Source); err != nil {
+			fe := apis.ErrInvalidValue(tt.Source, "source")
+			fe.Details = err.Error()
+			errs = errs.Also(fe)
+		}
+	}
+	for name, value := range tt.Extensions {
+		errs = errs.Also(validateExtensionName("extensions", name))
+		errs = errs.Also(validateTemplate(apis.CurrentField, value).ViaKey(name).ViaField("extensions"))
+	}
+	for from, to := range tt.Rename {
+		errs = errs.Also(validateExtensionName("rename", from))
+		errs = errs.Also(validateExtensionName("rename", to))
+	}
+	for i, name := range tt.Remove {
+		errs = errs.Also(validateExtensionName(apis.CurrentField, name).ViaFieldIndex("remove", i))
+	}
+	return errs
+}
+
+func validateTemplate(field, template string) *apis.FieldError {
+	if err := eventtransform.ValidateTemplate(template); err != nil {
+		fe := apis.ErrInvalidValue(template, field)
+		fe.Details = err.Error()
+		return fe
+	}
+	return nil
+}
+
+// validateExtensionName checks that name is a valid extension attribute name, rejecting the names
+// of the CloudEvents context attributes.
+func validateExtensionName(field, name string) *apis.FieldError {
+	if !validAttributeName.MatchString(name) {
+		return &apis.FieldError{
+			Message: fmt.Sprintf("Invalid attribute name: %q", name),
+			Paths:   []string{field},
+		}
+	}
+	if spec.V1.Attribute(name) != nil {
+		return &apis.FieldError{
+			Message: fmt.Sprintf("Context attributes can't be changed as extensions: %q", name),
+			Paths:   []string{field},
+		}
+	}
+	return nil
+}
+
 // CheckImmutableFields checks that any immutable fields were not changed.
 func (t *Trigger) CheckImmutableFields(ctx context.Context, original *Trigger) *apis.FieldError {
 	if original == nil {
@@ -163,3 +344,13 @@ func (t *Trigger) validateInjectionAnnotation(injectionAnnotation string) *apis.
 	}
 	return nil
 }
+
+func (t *Trigger) validateSchemaValidationAnnotation(schemaValidationAnnotation string) *apis.FieldError {
+	if schemaValidationAnnotation != "enabled" && schemaValidationAnnotation != "disabled" {
+		return &apis.FieldError{
+			Message: fmt.Sprintf(`The provided schema validation annotation value can only be "enabled" or "disabled", not %q`, schemaValidationAnnotation),
+			Paths:   []string{""},
+		}
+	}
+	return nil
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	eventingduckv1 "knative.dev/eventing/pkg/apis/duck/v1"
+	"knative.dev/eventing/pkg/apis/feature"
 	"knative.dev/pkg/apis"
 	duckv1 "knative.dev/pkg/apis/duck/v1"
 )
@@ -274,6 +275,24 @@ func TestTriggerValidation(t *testing.T) {
 				Message: `The provided injection annotation is only used for default broker, but non-default broker specified here: "test-broker"`,
 			},
 		},
+		{
+			name: "invalid schema validation annotation value",
+			t: &Trigger{
+				ObjectMeta: v1.ObjectMeta{
+					Namespace: "test-ns",
+					Annotations: map[string]string{
+						SchemaValidationAnnotation: "reject",
+					}},
+				Spec: TriggerSpec{
+					Broker:     "test-broker",
+					Filter:     validEmptyFilter,
+					Subscriber: validSubscriber,
+				}},
+			want: &apis.FieldError{
+				Paths:   []string{"metadata.annotations[eventing.knative.dev/schemaValidation]"},
+				Message: `The provided schema validation annotation value can only be "enabled" or "disabled", not "reject"`,
+			},
+		},
 		{
 			name: "invalid delivery, invalid delay string",
 			t: &Trigger{
@@ -487,6 +506,334 @@ func TestTriggerSpecValidation(t *testing.T) {
 	}
 }
 
+func TestTriggerSpecValidationSQLFilter(t *testing.T) {
+	newTriggerFiltersEnabledCtx := feature.ToContext(context.TODO(), feature.Flags{
+		feature.NewTriggerFilters: feature.Enabled,
+	})
+	tests := []struct {
+		name string
+		ctx  context.Context
+		ts   *TriggerSpec
+		want *apis.FieldError
+	}{{
+		name: "valid SQL filter",
+		ctx:  newTriggerFiltersEnabledCtx,
+		ts: &TriggerSpec{
+			Broker:     "test_broker",
+			Filter:     &TriggerFilter{SQL: "type LIKE 'com.acme.%' AND priority > 3"},
+			Subscriber: validSubscriber,
+		},
+	}, {
+		name: "valid SQL filter with attributes",
+		ctx:  newTriggerFiltersEnabledCtx,
+		ts: &TriggerSpec{
+			Broker: "test_broker",
+			Filter: &TriggerFilter{
+				Attributes: validAttributesFilter.Attributes,
+				SQL:        "EXISTS subject",
+			},
+			Subscriber: validSubscriber,
+		},
+	}, {
+		name: "invalid SQL filter",
+		ctx:  newTriggerFiltersEnabledCtx,
+		ts: &TriggerSpec{
+			Broker:     "test_broker",
+			Filter:     &TriggerFilter{SQL: "type LIKE"},
+			Subscriber: validSubscriber,
+		},
+		want: &apis.FieldError{
+			Message: "invalid value: type LIKE",
+			Paths:   []string{"filter.sql"},
+			Details: "expected a string literal pattern at position 9, got end of expression",
+		},
+	}, {
+		name: "SQL filter with feature disabled",
+		ctx:  context.TODO(),
+		ts: &TriggerSpec{
+			Broker:     "test_broker",
+			Filter:     &TriggerFilter{SQL: "type = 'abc'"},
+			Subscriber: validSubscriber,
+		},
+		want: apis.ErrDisallowedFields("filter.sql"),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.ts.Validate(test.ctx)
+			if diff := cmp.Diff(test.want.Error(), got.Error()); diff != "" {
+				t.Errorf("Validate TriggerSpec (-want, +got) =\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTriggerSpecValidationDataFilter(t *testing.T) {
+	dataFilterEnabledCtx := feature.ToContext(context.TODO(), feature.Flags{
+		feature.DataFilter: feature.Enabled,
+	})
+	region := "eu-west"
+	tests := []struct {
+		name string
+		ctx  context.Context
+		data []eventingduckv1.DataFilter
+		want *apis.FieldError
+	}{{
+		name: "valid data filter",
+		ctx:  dataFilterEnabledCtx,
+		data: []eventingduckv1.DataFilter{{Path: ".order.region", Value: &region}, {Path: "{.order.id}"}},
+	}, {
+		name: "invalid path",
+		ctx:  dataFilterEnabledCtx,
+		data: []eventingduckv1.DataFilter{{Path: ".order.region"}, {Path: ".order.items["}},
+		want: &apis.FieldError{
+			Message: "invalid value: .order.items[",
+			Paths:   []string{"filter.data[1].path"},
+			Details: "unterminated array",
+		},
+	}, {
+		name: "missing path",
+		ctx:  dataFilterEnabledCtx,
+		data: []eventingduckv1.DataFilter{{Value: &region}},
+		want: apis.ErrMissingField("filter.data[0].path"),
+	}, {
+		name: "data filter with feature disabled",
+		ctx:  context.TODO(),
+		data: []eventingduckv1.DataFilter{{Path: ".order.region"}},
+		want: apis.ErrDisallowedFields("filter.data"),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ts := &TriggerSpec{
+				Broker:     "test_broker",
+				Filter:     &TriggerFilter{Data: test.data},
+				Subscriber: validSubscriber,
+			}
+			got := ts.Validate(test.ctx)
+			if diff := cmp.Diff(test.want.Error(), got.Error()); diff != "" {
+				t.Errorf("Validate TriggerSpec (-want, +got) =\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTriggerSpecValidationFilters(t *testing.T) {
+	newTriggerFiltersEnabledCtx := feature.ToContext(context.TODO(), feature.Flags{
+		feature.NewTriggerFilters: feature.Enabled,
+	})
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		filters []SubscriptionsAPIFilter
+		want    *apis.FieldError
+	}{{
+		name: "valid nested filters",
+		ctx:  newTriggerFiltersEnabledCtx,
+		filters: []SubscriptionsAPIFilter{{
+			Exact: map[string]string{"type": "com.acme.order"},
+		}, {
+			Any: []SubscriptionsAPIFilter{{
+				Prefix: map[string]string{"source": "/acme"},
+			}, {
+				All: []SubscriptionsAPIFilter{{
+					Suffix: map[string]string{"subject": ".json"},
+				}, {
+					Not: &SubscriptionsAPIFilter{
+						Exact: map[string]string{"myext": "value"},
+					},
+				}},
+			}},
+		}},
+	}, {
+		name: "filters with feature disabled",
+		ctx:  context.TODO(),
+		filters: []SubscriptionsAPIFilter{{
+			Exact: map[string]string{"type": "com.acme.order"},
+		}},
+		want: apis.ErrDisallowedFields("filter.filters"),
+	}, {
+		name:    "no dialect",
+		ctx:     newTriggerFiltersEnabledCtx,
+		filters: []SubscriptionsAPIFilter{{}},
+		want:    apis.ErrGeneric("expected exactly one filter dialect, got 0").ViaFieldIndex("filter.filters", 0),
+	}, {
+		name: "multiple dialects",
+		ctx:  newTriggerFiltersEnabledCtx,
+		filters: []SubscriptionsAPIFilter{{
+			Exact:  map[string]string{"type": "com.acme.order"},
+			Prefix: map[string]string{"type": "com.acme"},
+		}},
+		want: apis.ErrGeneric("expected exactly one filter dialect, got 2", "exact", "prefix").ViaFieldIndex("filter.filters", 0),
+	}, {
+		name: "invalid attribute name",
+		ctx:  newTriggerFiltersEnabledCtx,
+		filters: []SubscriptionsAPIFilter{{
+			Prefix: map[string]string{"invALID": "com.acme"},
+		}},
+		want: &apis.FieldError{
+			Message: `Invalid attribute name: "invALID"`,
+			Paths:   []string{"filter.filters[0].prefix"},
+		},
+	}, {
+		name: "empty attribute value",
+		ctx:  newTriggerFiltersEnabledCtx,
+		filters: []SubscriptionsAPIFilter{{
+			Suffix: map[string]string{"type": ""},
+		}},
+		want: apis.ErrInvalidValue("", "filter.filters[0].suffix[type]"),
+	}, {
+		name: "empty exact",
+		ctx:  newTriggerFiltersEnabledCtx,
+		filters: []SubscriptionsAPIFilter{{
+			Exact: map[string]string{},
+		}},
+		want: apis.ErrInvalidValue("expected at least one attribute", "filter.filters[0].exact"),
+	}, {
+		name: "empty any",
+		ctx:  newTriggerFiltersEnabledCtx,
+		filters: []SubscriptionsAPIFilter{{
+			Any: []SubscriptionsAPIFilter{},
+		}},
+		want: apis.ErrInvalidValue("expected at least one filter expression", "filter.filters[0].any"),
+	}, {
+		name: "invalid nested filter",
+		ctx:  newTriggerFiltersEnabledCtx,
+		filters: []SubscriptionsAPIFilter{{
+			All: []SubscriptionsAPIFilter{{
+				Exact: map[string]string{"type": "com.acme.order"},
+			}, {
+				Not: &SubscriptionsAPIFilter{
+					Exact: map[string]string{"0invalid": "value"},
+				},
+			}},
+		}},
+		want: &apis.FieldError{
+			Message: `Invalid attribute name: "0invalid"`,
+			Paths:   []string{"filter.filters[0].all[1].not.exact"},
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ts := &TriggerSpec{
+				Broker:     "test_broker",
+				Filter:     &TriggerFilter{Filters: test.filters},
+				Subscriber: validSubscriber,
+			}
+			got := ts.Validate(test.ctx)
+			if diff := cmp.Diff(test.want.Error(), got.Error()); diff != "" {
+				t.Errorf("Validate TriggerSpec (-want, +got) =\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTriggerSpecValidationTransform(t *testing.T) {
+	triggerTransformEnabledCtx := feature.ToContext(context.TODO(), feature.Flags{
+		feature.TriggerTransform: feature.Enabled,
+	})
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		transform *TriggerTransform
+		want      *apis.FieldError
+	}{{
+		name: "valid transform",
+		ctx:  triggerTransformEnabledCtx,
+		transform: &TriggerTransform{
+			Type:       "${type}.transformed",
+			Source:     "/transformed",
+			Extensions: map[string]string{"region": "${subject}", "priority": ""},
+			Rename:     map[string]string{"zone": "area"},
+			Remove:     []string{"traceparent"},
+		},
+	}, {
+		name:      "transform with feature disabled",
+		ctx:       context.TODO(),
+		transform: &TriggerTransform{Type: "dev.knative.transformed"},
+		want:      apis.ErrDisallowedFields("transform"),
+	}, {
+		name:      "empty transform",
+		ctx:       triggerTransformEnabledCtx,
+		transform: &TriggerTransform{},
+		want:      apis.ErrMissingOneOf("type", "source", "extensions", "rename", "remove").ViaField("transform"),
+	}, {
+		name:      "invalid type template",
+		ctx:       triggerTransformEnabledCtx,
+		transform: &TriggerTransform{Type: "${type"},
+		want: &apis.FieldError{
+			Message: "invalid value: ${type",
+			Paths:   []string{"transform.type"},
+			Details: `unterminated reference in "${type"`,
+		},
+	}, {
+		name:      "invalid static source",
+		ctx:       triggerTransformEnabledCtx,
+		transform: &TriggerTransform{Source: "http://[::1"},
+		want: &apis.FieldError{
+			Message: "invalid value: http://[::1",
+			Paths:   []string{"transform.source"},
+			Details: `parse "http://[::1": missing ']' in host`,
+		},
+	}, {
+		name:      "invalid extension template",
+		ctx:       triggerTransformEnabledCtx,
+		transform: &TriggerTransform{Extensions: map[string]string{"region": "${Region}"}},
+		want: &apis.FieldError{
+			Message: "invalid value: ${Region}",
+			Paths:   []string{"transform.extensions[region]"},
+			Details: `invalid attribute name "Region"`,
+		},
+	}, {
+		name:      "id can't be set",
+		ctx:       triggerTransformEnabledCtx,
+		transform: &TriggerTransform{Extensions: map[string]string{"id": ""}},
+		want: &apis.FieldError{
+			Message: `Context attributes can't be changed as extensions: "id"`,
+			Paths:   []string{"transform.extensions"},
+		},
+	}, {
+		name:      "context attributes can't be renamed",
+		ctx:       triggerTransformEnabledCtx,
+		transform: &TriggerTransform{Rename: map[string]string{"region": "specversion"}},
+		want: &apis.FieldError{
+			Message: `Context attributes can't be changed as extensions: "specversion"`,
+			Paths:   []string{"transform.rename"},
+		},
+	}, {
+		name:      "invalid removed extension name",
+		ctx:       triggerTransformEnabledCtx,
+		transform: &TriggerTransform{Remove: []string{"region", "Zone"}},
+		want: &apis.FieldError{
+			Message: `Invalid attribute name: "Zone"`,
+			Paths:   []string{"transform.remove[1]"},
+		},
+	}, {
+		name:      "context attributes can't be removed",
+		ctx:       triggerTransformEnabledCtx,
+		transform: &TriggerTransform{Remove: []string{"source"}},
+		want: &apis.FieldError{
+			Message: `Context attributes can't be changed as extensions: "source"`,
+			Paths:   []string{"transform.remove[0]"},
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ts := &TriggerSpec{
+				Broker:     "test_broker",
+				Transform:  test.transform,
+				Subscriber: validSubscriber,
+			}
+			got := ts.Validate(test.ctx)
+			if diff := cmp.Diff(test.want.Error(), got.Error()); diff != "" {
+				t.Errorf("Validate TriggerSpec (-want, +got) =\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestTriggerImmutableFields(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -29,6 +29,10 @@ const (
 	EventTypeConditionReady                           = apis.ConditionReady
 	EventTypeConditionBrokerExists apis.ConditionType = "BrokerExists"
 	EventTypeConditionBrokerReady  apis.ConditionType = "BrokerReady"
+
+	// EventTypeConditionSchemaValid is set when the EventType holds a JSON schema, it doesn't
+	// affect the readiness of the EventType.
+	EventTypeConditionSchemaValid apis.ConditionType = "SchemaValid"
 )
 
 // GetConditionSet retrieves the condition set for this resource. Implements the KRShaped interface.
@@ -85,6 +89,19 @@ func (et *EventTypeStatus) MarkBrokerNotConfigured() {
 		"BrokerNotConfigured", "Broker has not yet been reconciled.")
 }
 
+func (et *EventTypeStatus) MarkSchemaValid() {
+	eventTypeCondSet.Manage(et).MarkTrue(EventTypeConditionSchemaValid)
+}
+
+func (et *EventTypeStatus) MarkSchemaInvalid(reason, messageFormat string, messageA ...interface{}) {
+	eventTypeCondSet.Manage(et).MarkFalse(EventTypeConditionSchemaValid, reason, messageFormat, messageA...)
+}
+
+// MarkNoSchema removes the SchemaValid condition of an EventType without JSON schema.
+func (et *EventTypeStatus) MarkNoSchema() {
+	_ = eventTypeCondSet.Manage(et).ClearCondition(EventTypeConditionSchemaValid)
+}
+
 func (et *EventTypeStatus) PropagateBrokerStatus(bs *eventingv1.BrokerStatus) {
 	bc := bs.GetConditionSet().Manage(bs).GetTopLevelCondition()
 	if bc == nil {
@@ -265,3 +265,28 @@ func TestEventTypeConditionStatus(t *testing.T) {
 		})
 	}
 }
+
+func TestEventTypeSchemaCondition(t *testing.T) {
+	ets := &EventTypeStatus{}
+	ets.MarkBrokerExists()
+	ets.PropagateBrokerStatus(eventingv1.TestHelper.ReadyBrokerStatus())
+
+	ets.MarkSchemaInvalid("SchemaInvalid", "invalid schema")
+	if got := ets.GetCondition(EventTypeConditionSchemaValid); got == nil || got.Status != corev1.ConditionFalse {
+		t.Errorf("unexpected SchemaValid condition: %v", got)
+	}
+	// An invalid schema doesn't affect the readiness.
+	if !ets.IsReady() {
+		t.Error("the EventType with an invalid schema isn't ready")
+	}
+
+	ets.MarkSchemaValid()
+	if got := ets.GetCondition(EventTypeConditionSchemaValid); got == nil || got.Status != corev1.ConditionTrue {
+		t.Errorf("unexpected SchemaValid condition: %v", got)
+	}
+
+	ets.MarkNoSchema()
+	if got := ets.GetCondition(EventTypeConditionSchemaValid); got != nil {
+		t.Errorf("unexpected SchemaValid condition: %v", got)
+	}
+}
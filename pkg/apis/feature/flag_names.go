@@ -17,8 +17,19 @@ limitations under the License.
 package feature
 
 const (
-	KReferenceGroup   = "kreference-group"
-	DeliveryTimeout   = "delivery-timeout"
-	KReferenceMapping = "kreference-mapping"
-	StrictSubscriber  = "strict-subscriber"
+	KReferenceGroup    = "kreference-group"
+	DeliveryTimeout    = "delivery-timeout"
+	KReferenceMapping  = "kreference-mapping"
+	StrictSubscriber   = "strict-subscriber"
+	NewTriggerFilters  = "new-trigger-filters"
+	DataFilter         = "data-filter"
+	TriggerTransform   = "trigger-transform"
+	DeliveryRetryAfter = "delivery-retryafter"
+	DeliveryRetryOn    = "delivery-retryon"
+	DeliveryJitter     = "delivery-jitter"
+	IMCPersistence     = "imc-persistence"
+	DeliveryOrdering   = "delivery-ordering"
+	SubscriptionReplay = "subscription-replay"
+	DeliveryRateLimit  = "delivery-ratelimit"
+	DeliveryBatching   = "delivery-batching"
 )
@@ -87,6 +87,15 @@ type ParallelBranch struct {
 	// +optional
 	Filter *duckv1.Destination `json:"filter,omitempty"`
 
+	// DataFilter is a list of JSONPath predicates evaluated against the event data.
+	// Only events whose data is JSON and matches all the predicates are sent to the branch.
+	// If Filter is also specified, the events must pass both.
+	//
+	// Note: This API is EXPERIMENTAL and might break anytime.
+	//
+	// +optional
+	DataFilter []eventingduckv1.DataFilter `json:"dataFilter,omitempty"`
+
 	// Subscriber receiving the event when the filter passes
 	Subscriber duckv1.Destination `json:"subscriber"`
 
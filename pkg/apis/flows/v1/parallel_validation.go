@@ -20,6 +20,8 @@ import (
 	"context"
 
 	"knative.dev/pkg/apis"
+
+	eventingduckv1 "knative.dev/eventing/pkg/apis/duck/v1"
 )
 
 func (p *Parallel) Validate(ctx context.Context) *apis.FieldError {
@@ -38,6 +40,10 @@ func (ps *ParallelSpec) Validate(ctx context.Context) *apis.FieldError {
 			errs = errs.Also(apis.ErrInvalidArrayValue(s, "branches.filter", i))
 		}
 
+		if e := eventingduckv1.ValidateDataFilters(ctx, s.DataFilter); e != nil {
+			errs = errs.Also(e.ViaField("dataFilter").ViaFieldIndex("branches", i))
+		}
+
 		if e := s.Subscriber.Validate(ctx); e != nil {
 			errs = errs.Also(apis.ErrInvalidArrayValue(s, "branches.subscriber", i))
 		}
@@ -22,6 +22,8 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	eventingduckv1 "knative.dev/eventing/pkg/apis/duck/v1"
+	"knative.dev/eventing/pkg/apis/feature"
 	messagingv1 "knative.dev/eventing/pkg/apis/messaging/v1"
 	"knative.dev/pkg/apis"
 )
@@ -204,3 +206,56 @@ func TestParallelSpecValidate(t *testing.T) {
 		})
 	}
 }
+
+func TestParallelSpecValidateDataFilter(t *testing.T) {
+	dataFilterEnabledCtx := feature.ToContext(context.TODO(), feature.Flags{
+		feature.DataFilter: feature.Enabled,
+	})
+	branches := func(dataFilter ...eventingduckv1.DataFilter) []ParallelBranch {
+		b := getValidBranches()
+		b[0].DataFilter = dataFilter
+		return b
+	}
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		ps   *ParallelSpec
+		want *apis.FieldError
+	}{
+		{
+			name: "valid data filter",
+			ctx:  dataFilterEnabledCtx,
+			ps: &ParallelSpec{
+				Branches:        branches(eventingduckv1.DataFilter{Path: ".order.region"}),
+				ChannelTemplate: getValidChannelTemplate(),
+			},
+		},
+		{
+			name: "invalid data filter",
+			ctx:  dataFilterEnabledCtx,
+			ps: &ParallelSpec{
+				Branches:        branches(eventingduckv1.DataFilter{}),
+				ChannelTemplate: getValidChannelTemplate(),
+			},
+			want: apis.ErrMissingField("branches[0].dataFilter[0].path"),
+		},
+		{
+			name: "data filter with feature disabled",
+			ctx:  context.TODO(),
+			ps: &ParallelSpec{
+				Branches:        branches(eventingduckv1.DataFilter{Path: ".order.region"}),
+				ChannelTemplate: getValidChannelTemplate(),
+			},
+			want: apis.ErrDisallowedFields("branches[0].dataFilter"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.ps.Validate(tt.ctx)
+			if diff := cmp.Diff(tt.want.Error(), got.Error()); diff != "" {
+				t.Errorf("%s: ParallelSpec.Validate (-want, +got) = %v", tt.name, diff)
+			}
+		})
+	}
+}
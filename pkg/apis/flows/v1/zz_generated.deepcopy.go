@@ -64,6 +64,13 @@ func (in *ParallelBranch) DeepCopyInto(out *ParallelBranch) {
 		*out = new(duckv1.Destination)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.DataFilter != nil {
+		in, out := &in.DataFilter, &out.DataFilter
+		*out = make([]apisduckv1.DataFilter, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	in.Subscriber.DeepCopyInto(&out.Subscriber)
 	if in.Reply != nil {
 		in, out := &in.Reply, &out.Reply
@@ -67,8 +67,30 @@ var (
 type InMemoryChannelSpec struct {
 	// Channel conforms to Duck type Channelable.
 	eventingduckv1.ChannelableSpec `json:",inline"`
+
+	// Persistence is how the dispatcher keeps the events of the channel, defaults to memory.
+	// With memory, the events are only kept while they are in flight and are lost when the
+	// dispatcher restarts.
+	// With disk, each event is appended to a write-ahead log on the local disk of the dispatcher
+	// before it is acknowledged, and the events which weren't delivered to every subscriber are
+	// replayed when the dispatcher restarts.
+	// Note: This API is EXPERIMENTAL and might break anytime.
+	// +optional
+	Persistence InMemoryChannelPersistence `json:"persistence,omitempty"`
 }
 
+// InMemoryChannelPersistence is how the dispatcher keeps the events of an InMemoryChannel.
+type InMemoryChannelPersistence string
+
+const (
+	// InMemoryChannelPersistenceMemory keeps the events in memory while they are in flight.
+	InMemoryChannelPersistenceMemory InMemoryChannelPersistence = "memory"
+
+	// InMemoryChannelPersistenceDisk keeps the events in a write-ahead log on disk until they
+	// are delivered.
+	InMemoryChannelPersistenceDisk InMemoryChannelPersistence = "disk"
+)
+
 // ChannelStatus represents the current state of a Channel.
 type InMemoryChannelStatus struct {
 	// Channel conforms to Duck type ChannelableStatus.
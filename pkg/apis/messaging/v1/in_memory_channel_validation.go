@@ -23,6 +23,7 @@ import (
 	"knative.dev/pkg/apis"
 
 	"knative.dev/eventing/pkg/apis/eventing"
+	"knative.dev/eventing/pkg/apis/feature"
 )
 
 func (imc *InMemoryChannel) Validate(ctx context.Context) *apis.FieldError {
@@ -52,5 +53,13 @@ func (imcs *InMemoryChannelSpec) Validate(ctx context.Context) *apis.FieldError
 		}
 	}
 
+	if imcs.Persistence != "" {
+		if !feature.FromContext(ctx).IsEnabled(feature.IMCPersistence) {
+			errs = errs.Also(apis.ErrDisallowedFields("persistence"))
+		} else if imcs.Persistence != InMemoryChannelPersistenceMemory && imcs.Persistence != InMemoryChannelPersistenceDisk {
+			errs = errs.Also(apis.ErrInvalidValue(imcs.Persistence, "persistence"))
+		}
+	}
+
 	return errs
 }
@@ -17,13 +17,17 @@ limitations under the License.
 package v1
 
 import (
+	"context"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"knative.dev/pkg/apis"
 
 	eventingduck "knative.dev/eventing/pkg/apis/duck/v1"
 	"knative.dev/eventing/pkg/apis/eventing"
+	"knative.dev/eventing/pkg/apis/feature"
 )
 
 func TestInMemoryChannelValidation(t *testing.T) {
@@ -105,3 +109,46 @@ func TestInMemoryChannelValidation(t *testing.T) {
 
 	doValidateTest(t, tests)
 }
+
+func TestInMemoryChannelPersistenceValidation(t *testing.T) {
+	enabledCtx := feature.ToContext(context.TODO(), feature.Flags{
+		feature.IMCPersistence: feature.Enabled,
+	})
+
+	tests := []struct {
+		name        string
+		ctx         context.Context
+		persistence InMemoryChannelPersistence
+		want        *apis.FieldError
+	}{{
+		name:        "memory",
+		ctx:         enabledCtx,
+		persistence: InMemoryChannelPersistenceMemory,
+	}, {
+		name:        "disk",
+		ctx:         enabledCtx,
+		persistence: InMemoryChannelPersistenceDisk,
+	}, {
+		name:        "invalid",
+		ctx:         enabledCtx,
+		persistence: "tape",
+		want:        apis.ErrInvalidValue("tape", "spec.persistence"),
+	}, {
+		name:        "feature disabled",
+		ctx:         context.TODO(),
+		persistence: InMemoryChannelPersistenceDisk,
+		want:        apis.ErrDisallowedFields("spec.persistence"),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			imc := &InMemoryChannel{
+				Spec: InMemoryChannelSpec{Persistence: test.persistence},
+			}
+			got := imc.Validate(test.ctx)
+			if diff := cmp.Diff(test.want.Error(), got.Error()); diff != "" {
+				t.Errorf("%s: validate (-want, +got) = %v", test.name, diff)
+			}
+		})
+	}
+}
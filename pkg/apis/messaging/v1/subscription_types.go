@@ -104,6 +104,23 @@ type SubscriptionSpec struct {
 	// Delivery configuration
 	// +optional
 	Delivery *eventingduckv1.DeliverySpec `json:"delivery,omitempty"`
+
+	// DataFilter is a list of JSONPath predicates evaluated against the event data.
+	// Only events whose data is JSON and matches all the predicates are delivered to the Subscriber.
+	//
+	// Note: This API is EXPERIMENTAL and might break anytime.
+	//
+	// +optional
+	DataFilter []eventingduckv1.DataFilter `json:"dataFilter,omitempty"`
+
+	// Replay, when specified, delivers the events the Channel retained before the
+	// Subscription was added, before the events it receives afterwards. It only applies
+	// when the Subscription is added, and requires a Channel retaining events.
+	//
+	// Note: This API is EXPERIMENTAL and might break anytime.
+	//
+	// +optional
+	Replay *eventingduckv1.Replay `json:"replay,omitempty"`
 }
 
 // SubscriptionStatus (computed) for a subscription
@@ -19,6 +19,7 @@ package v1
 import (
 	"context"
 
+	eventingduckv1 "knative.dev/eventing/pkg/apis/duck/v1"
 	"knative.dev/eventing/pkg/apis/feature"
 
 	"github.com/google/go-cmp/cmp/cmpopts"
@@ -86,6 +87,14 @@ func (ss *SubscriptionSpec) Validate(ctx context.Context) *apis.FieldError {
 		}
 	}
 
+	if fe := eventingduckv1.ValidateDataFilters(ctx, ss.DataFilter); fe != nil {
+		errs = errs.Also(fe.ViaField("dataFilter"))
+	}
+
+	if fe := ss.Replay.Validate(ctx); fe != nil {
+		errs = errs.Also(fe.ViaField("replay"))
+	}
+
 	return errs
 }
 
@@ -20,6 +20,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"k8s.io/utils/pointer"
 	"knative.dev/pkg/apis"
 	duckv1 "knative.dev/pkg/apis/duck/v1"
 
@@ -707,3 +708,103 @@ func TestSubscriptionSpecValidationWithStrictSubscriber(t *testing.T) {
 		})
 	}
 }
+
+func TestSubscriptionSpecValidationWithDataFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		enabled bool
+		c       *SubscriptionSpec
+		want    *apis.FieldError
+	}{{
+		name:    "valid data filter",
+		enabled: true,
+		c: &SubscriptionSpec{
+			Channel:    getValidChannelRef(),
+			Subscriber: getValidDestination(),
+			DataFilter: []eventingduckv1.DataFilter{{Path: "$.order.region"}},
+		},
+	}, {
+		name:    "invalid data filter path",
+		enabled: true,
+		c: &SubscriptionSpec{
+			Channel:    getValidChannelRef(),
+			Subscriber: getValidDestination(),
+			DataFilter: []eventingduckv1.DataFilter{{Path: ".order.items["}},
+		},
+		want: &apis.FieldError{
+			Message: "invalid value: .order.items[",
+			Paths:   []string{"dataFilter[0].path"},
+			Details: "unterminated array",
+		},
+	}, {
+		name: "data filter with feature disabled",
+		c: &SubscriptionSpec{
+			Channel:    getValidChannelRef(),
+			Subscriber: getValidDestination(),
+			DataFilter: []eventingduckv1.DataFilter{{Path: "$.order.region"}},
+		},
+		want: apis.ErrDisallowedFields("dataFilter"),
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.TODO()
+			if test.enabled {
+				ctx = feature.ToContext(ctx, feature.Flags{
+					feature.DataFilter: feature.Enabled,
+				})
+			}
+			got := test.c.Validate(ctx)
+			if diff := cmp.Diff(test.want.Error(), got.Error()); diff != "" {
+				t.Errorf("%s: dataFilter (-want, +got) = %v", test.name, diff)
+			}
+		})
+	}
+}
+
+func TestSubscriptionSpecValidationWithReplay(t *testing.T) {
+	tests := []struct {
+		name    string
+		enabled bool
+		c       *SubscriptionSpec
+		want    *apis.FieldError
+	}{{
+		name:    "valid replay",
+		enabled: true,
+		c: &SubscriptionSpec{
+			Channel:    getValidChannelRef(),
+			Subscriber: getValidDestination(),
+			Replay:     &eventingduckv1.Replay{EventID: pointer.StringPtr("1234")},
+		},
+	}, {
+		name:    "invalid replay",
+		enabled: true,
+		c: &SubscriptionSpec{
+			Channel:    getValidChannelRef(),
+			Subscriber: getValidDestination(),
+			Replay:     &eventingduckv1.Replay{EventID: pointer.StringPtr("")},
+		},
+		want: apis.ErrInvalidValue("", "replay.eventId"),
+	}, {
+		name: "replay with feature disabled",
+		c: &SubscriptionSpec{
+			Channel:    getValidChannelRef(),
+			Subscriber: getValidDestination(),
+			Replay:     &eventingduckv1.Replay{},
+		},
+		want: apis.ErrDisallowedFields("replay"),
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.TODO()
+			if test.enabled {
+				ctx = feature.ToContext(ctx, feature.Flags{
+					feature.SubscriptionReplay: feature.Enabled,
+				})
+			}
+			got := test.c.Validate(ctx)
+			if diff := cmp.Diff(test.want.Error(), got.Error()); diff != "" {
+				t.Errorf("%s: replay (-want, +got) = %v", test.name, diff)
+			}
+		})
+	}
+}
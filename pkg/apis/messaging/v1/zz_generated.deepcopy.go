@@ -338,6 +338,18 @@ func (in *SubscriptionSpec) DeepCopyInto(out *SubscriptionSpec) {
 		*out = new(apisduckv1.DeliverySpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.DataFilter != nil {
+		in, out := &in.DataFilter, &out.DataFilter
+		*out = make([]apisduckv1.DataFilter, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Replay != nil {
+		in, out := &in.Replay, &out.Replay
+		*out = new(apisduckv1.Replay)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
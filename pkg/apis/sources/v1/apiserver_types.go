@@ -80,6 +80,20 @@ type ApiServerSourceSpec struct {
 	// source. Defaults to default if not set.
 	// +optional
 	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+
+	// NamespaceSelector tracks the resources of all the namespaces matching
+	// the label selector instead of the namespace of the source. The
+	// ServiceAccount must be allowed to get, list and watch the resources in
+	// all the namespaces, it's allowed to get, list and watch the namespaces.
+	// More info: http://kubernetes.io/docs/concepts/overview/working-with-objects/labels/#label-selectors
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
+	// Operations are the operations on the resources sending events, among
+	// `add`, `update` and `delete`.
+	// Defaults to all of them.
+	// +optional
+	Operations []string `json:"operations,omitempty"`
 }
 
 // ApiServerSourceStatus defines the observed state of ApiServerSource
@@ -118,6 +132,12 @@ type APIVersionKindSelector struct {
 	// More info: http://kubernetes.io/docs/concepts/overview/working-with-objects/labels/#label-selectors
 	// +optional
 	LabelSelector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// FieldSelector filters this source to objects to those resources pass the
+	// field selector.
+	// More info: https://kubernetes.io/docs/concepts/overview/working-with-objects/field-selectors/
+	// +optional
+	FieldSelector string `json:"fieldSelector,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -20,6 +20,8 @@ import (
 	"context"
 	"strings"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	"knative.dev/pkg/apis"
@@ -30,6 +32,13 @@ const (
 	ReferenceMode = "Reference"
 	// ResourceMode produces payloads of ResourceEvent
 	ResourceMode = "Resource"
+
+	// AddOperation sends the events of the resources added
+	AddOperation = "add"
+	// UpdateOperation sends the events of the resources updated
+	UpdateOperation = "update"
+	// DeleteOperation sends the events of the resources deleted
+	DeleteOperation = "delete"
 )
 
 func (c *ApiServerSource) Validate(ctx context.Context) *apis.FieldError {
@@ -61,6 +70,29 @@ func (cs *ApiServerSourceSpec) Validate(ctx context.Context) *apis.FieldError {
 		if strings.TrimSpace(res.Kind) == "" {
 			errs = errs.Also(apis.ErrMissingField("kind").ViaFieldIndex("resources", i))
 		}
+		if _, err := fields.ParseSelector(res.FieldSelector); err != nil {
+			fe := apis.ErrInvalidValue(res.FieldSelector, "fieldSelector")
+			fe.Details = err.Error()
+			errs = errs.Also(fe.ViaFieldIndex("resources", i))
+		}
+	}
+
+	if cs.NamespaceSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(cs.NamespaceSelector); err != nil {
+			errs = errs.Also(&apis.FieldError{
+				Message: "Invalid label selector",
+				Paths:   []string{"namespaceSelector"},
+				Details: err.Error(),
+			})
+		}
+	}
+
+	for i, op := range cs.Operations {
+		switch op {
+		case AddOperation, UpdateOperation, DeleteOperation:
+		default:
+			errs = errs.Also(apis.ErrInvalidArrayValue(op, "operations", i))
+		}
 	}
 
 	if cs.ResourceOwner != nil {
@@ -19,11 +19,14 @@ package v1
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestPingSourceSetDefaults(t *testing.T) {
+	at := metav1.NewTime(time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC))
 	testCases := map[string]struct {
 		initial  PingSource
 		expected PingSource
@@ -55,6 +58,18 @@ func TestPingSourceSetDefaults(t *testing.T) {
 				},
 			},
 		},
+		"one-shot": {
+			initial: PingSource{
+				Spec: PingSourceSpec{
+					At: &at,
+				},
+			},
+			expected: PingSource{
+				Spec: PingSourceSpec{
+					At: &at,
+				},
+			},
+		},
 	}
 	for n, tc := range testCases {
 		t.Run(n, func(t *testing.T) {
@@ -21,6 +21,7 @@ import (
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"knative.dev/pkg/apis"
 )
@@ -43,6 +44,14 @@ var PingSourceCondSet = apis.NewLivingConditionSet(
 const (
 	// PingSourceEventType is the default PingSource CloudEvent type.
 	PingSourceEventType = "dev.knative.sources.ping"
+
+	// PingSourceScheduledTimeExtension is the CloudEvent extension holding the scheduled time
+	// of the tick an event was sent for.
+	PingSourceScheduledTimeExtension = "scheduledtime"
+
+	// PingSourceFiredAtAnnotationKey is the annotation set by the adapter on a one-shot
+	// PingSource once it sent its event, holding the time it was sent at in RFC 3339 format.
+	PingSourceFiredAtAnnotationKey = "sources.knative.dev/firedAt"
 )
 
 // GetConditionSet retrieves the condition set for this resource. Implements the KRShaped interface.
@@ -100,6 +109,11 @@ func (s *PingSourceStatus) MarkNoSink(reason, messageFormat string, messageA ...
 	PingSourceCondSet.Manage(s).MarkFalse(PingSourceConditionSinkProvided, reason, messageFormat, messageA...)
 }
 
+// MarkCompleted records the time the event of a one-shot PingSource was sent at.
+func (s *PingSourceStatus) MarkCompleted(t metav1.Time) {
+	s.CompletionTime = &t
+}
+
 // PropagateDeploymentAvailability uses the availability of the provided Deployment to determine if
 // PingSourceConditionDeployed should be marked as true or false.
 func (s *PingSourceStatus) PropagateDeploymentAvailability(d *appsv1.Deployment) {
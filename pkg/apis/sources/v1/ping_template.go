@@ -0,0 +1,152 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+)
+
+const (
+	// PingSourceTemplateMaxSize is the maximum size in bytes of the data rendered by the template
+	// of a PingSource, whatever the configured dataMaxSize.
+	PingSourceTemplateMaxSize = 1 << 20
+
+	// maxPrintfWidth is the maximum width and precision of the verbs of printf in the templates,
+	// which would otherwise render arbitrarily large data.
+	maxPrintfWidth = 64
+)
+
+// PingSourceTemplateInput is the input of the template of the data of a PingSource.
+// +k8s:deepcopy-gen=false
+type PingSourceTemplateInput struct {
+	// Time is the scheduled time of the tick.
+	Time time.Time
+	// Name is the name of the PingSource.
+	Name string
+	// Namespace is the namespace of the PingSource.
+	Namespace string
+	// Sequence is the number of the tick since the PingSource was scheduled by its adapter,
+	// starting at 1.
+	Sequence uint64
+}
+
+// ParsePingSourceTemplate parses the template of the data of a PingSource. The range, with and
+// template actions aren't allowed, so that executing the template takes a time bounded by its
+// size.
+func ParsePingSourceTemplate(data string) (*template.Template, error) {
+	t, err := template.New("data").Option("missingkey=error").Funcs(template.FuncMap{"printf": printf}).Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	if t.Tree != nil {
+		if err := checkTemplateNode(t.Tree.Root); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// RenderPingSourceTemplate executes the template of the data of a PingSource with input. The
+// rendered data can't be larger than maxSize bytes, when not negative, nor than
+// PingSourceTemplateMaxSize.
+func RenderPingSourceTemplate(t *template.Template, input PingSourceTemplateInput, maxSize int64) ([]byte, error) {
+	if maxSize < 0 || maxSize > PingSourceTemplateMaxSize {
+		maxSize = PingSourceTemplateMaxSize
+	}
+	w := &limitedWriter{limit: maxSize}
+	if err := t.Execute(w, input); err != nil {
+		return nil, err
+	}
+	return w.buf.Bytes(), nil
+}
+
+// ExecutePingSourceTemplate parses and executes the template of the data of a PingSource with
+// input, rendering at most maxSize bytes as RenderPingSourceTemplate.
+func ExecutePingSourceTemplate(data string, input PingSourceTemplateInput, maxSize int64) ([]byte, error) {
+	t, err := ParsePingSourceTemplate(data)
+	if err != nil {
+		return nil, err
+	}
+	return RenderPingSourceTemplate(t, input, maxSize)
+}
+
+func checkTemplateNode(node parse.Node) error {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		for _, child := range n.Nodes {
+			if err := checkTemplateNode(child); err != nil {
+				return err
+			}
+		}
+	case *parse.IfNode:
+		if err := checkTemplateNode(n.List); err != nil {
+			return err
+		}
+		if n.ElseList != nil {
+			return checkTemplateNode(n.ElseList)
+		}
+	case *parse.RangeNode:
+		return fmt.Errorf("the range actions aren't allowed")
+	case *parse.WithNode:
+		return fmt.Errorf("the with actions aren't allowed")
+	case *parse.TemplateNode:
+		return fmt.Errorf("the template actions aren't allowed")
+	}
+	return nil
+}
+
+// printf is the printf function of the templates, limiting the width and precision of the verbs.
+func printf(format string, args ...interface{}) (string, error) {
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		// n is the number being read in the flags, width and precision of the verb.
+		n := 0
+		for i++; i < len(format) && strings.IndexByte("+-# 0123456789.*[]", format[i]) >= 0; i++ {
+			switch c := format[i]; {
+			case c == '*':
+				return "", fmt.Errorf("printf: the widths and precisions taken from the arguments aren't allowed")
+			case c >= '0' && c <= '9':
+				if n = n*10 + int(c-'0'); n > maxPrintfWidth {
+					return "", fmt.Errorf("printf: the widths and precisions are limited to %d", maxPrintfWidth)
+				}
+			default:
+				n = 0
+			}
+		}
+	}
+	return fmt.Sprintf(format, args...), nil
+}
+
+// limitedWriter buffers up to limit bytes.
+type limitedWriter struct {
+	buf   bytes.Buffer
+	limit int64
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	if int64(w.buf.Len()+len(p)) > w.limit {
+		return 0, fmt.Errorf("the rendered data exceeds %d bytes", w.limit)
+	}
+	return w.buf.Write(p)
+}
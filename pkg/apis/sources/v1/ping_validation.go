@@ -20,11 +20,12 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/rickb777/date/period"
 
 	"github.com/robfig/cron/v3"
 	"knative.dev/pkg/apis"
@@ -32,27 +33,39 @@ import (
 	"knative.dev/eventing/pkg/apis/sources/config"
 )
 
+// PingScheduleParser parses the schedules of PingSources: cron specs with an optional leading
+// seconds field, and descriptors like `@every 15s`.
+var PingScheduleParser = cron.NewParser(
+	cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
 func (c *PingSource) Validate(ctx context.Context) *apis.FieldError {
 	return c.Spec.Validate(ctx).ViaField("spec")
 }
 
 func (cs *PingSourceSpec) Validate(ctx context.Context) *apis.FieldError {
 	var errs *apis.FieldError
-	schedule := cs.Schedule
-
-	errs = validateDescriptor(schedule)
 
-	if cs.Timezone != "" {
-		schedule = "CRON_TZ=" + cs.Timezone + " " + schedule
-	}
+	if cs.At != nil {
+		if cs.Schedule != "" {
+			errs = errs.Also(apis.ErrMultipleOneOf("at", "schedule"))
+		}
+		if cs.Timezone != "" {
+			errs = errs.Also(apis.ErrMultipleOneOf("at", "timezone"))
+		}
+	} else {
+		schedule := cs.Schedule
+		if cs.Timezone != "" {
+			schedule = "CRON_TZ=" + cs.Timezone + " " + schedule
+		}
 
-	if _, err := cron.ParseStandard(schedule); err != nil {
-		if strings.HasPrefix(err.Error(), "provided bad location") {
-			fe := apis.ErrInvalidValue(err, "timezone")
-			errs = errs.Also(fe)
-		} else {
-			fe := apis.ErrInvalidValue(err, "schedule")
-			errs = errs.Also(fe)
+		if _, err := PingScheduleParser.Parse(schedule); err != nil {
+			if strings.HasPrefix(err.Error(), "provided bad location") {
+				fe := apis.ErrInvalidValue(err, "timezone")
+				errs = errs.Also(fe)
+			} else {
+				fe := apis.ErrInvalidValue(err, "schedule")
+				errs = errs.Also(fe)
+			}
 		}
 	}
 
@@ -87,13 +100,51 @@ func (cs *PingSourceSpec) Validate(ctx context.Context) *apis.FieldError {
 			fe := apis.ErrInvalidValue(fmt.Sprintf("the data length of %d bytes exceeds limit set at %d.", bsize, pingDefaults.DataMaxSize), "data")
 			errs = errs.Also(fe)
 		}
-		if cs.ContentType == cloudevents.ApplicationJSON {
+		if cs.DataTemplated {
+			// validate the template, the size of its output for a sample tick and, if data is JSON,
+			// the output
+			data, err := ExecutePingSourceTemplate(cs.Data, PingSourceTemplateInput{
+				Time:      time.Unix(0, 0),
+				Name:      "name",
+				Namespace: "namespace",
+				Sequence:  1,
+			}, pingDefaults.DataMaxSize)
+			if err != nil {
+				errs = errs.Also(apis.ErrInvalidValue(err, "data"))
+			} else if cs.ContentType == cloudevents.ApplicationJSON {
+				if err := validateJSON(string(data)); err != nil {
+					errs = errs.Also(apis.ErrInvalidValue(err, "data"))
+				}
+			}
+		} else if cs.ContentType == cloudevents.ApplicationJSON {
 			// validate if data is valid JSON
 			if err := validateJSON(cs.Data); err != nil {
 				errs = errs.Also(apis.ErrInvalidValue(err, "data"))
 			}
 		}
 	}
+	if cs.DataTemplated && cs.DataBase64 != "" {
+		errs = errs.Also(apis.ErrDisallowedFields("dataTemplated"))
+	}
+
+	switch cs.MissedTickPolicy {
+	case "", MissedTickSkip:
+		if cs.MissedTickLookback != nil {
+			errs = errs.Also(apis.ErrDisallowedFields("missedTickLookback"))
+		}
+	case MissedTickFireOnce, MissedTickFireAll:
+		if cs.MissedTickLookback != nil {
+			p, err := period.Parse(*cs.MissedTickLookback)
+			if err != nil || p.IsZero() || p.IsNegative() {
+				errs = errs.Also(apis.ErrInvalidValue(*cs.MissedTickLookback, "missedTickLookback"))
+			} else if d, _ := p.Duration(); d <= 0 || d > PingSourceMaxMissedTickLookback {
+				errs = errs.Also(apis.ErrOutOfBoundsValue(*cs.MissedTickLookback, "PT0S", "PT24H", "missedTickLookback"))
+			}
+		}
+	default:
+		errs = errs.Also(apis.ErrInvalidValue(cs.MissedTickPolicy, "missedTickPolicy"))
+	}
+
 	errs = errs.Also(cs.SourceSpec.Validate(ctx))
 	return errs
 }
@@ -102,10 +153,3 @@ func validateJSON(str string) error {
 	var objmap map[string]interface{}
 	return json.Unmarshal([]byte(str), &objmap)
 }
-
-func validateDescriptor(spec string) *apis.FieldError {
-	if strings.Contains(spec, "@every") {
-		return apis.ErrInvalidValue(errors.New("unsupported descriptor @every"), "schedule")
-	}
-	return nil
-}
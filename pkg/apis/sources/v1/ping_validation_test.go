@@ -21,9 +21,12 @@ import (
 	"encoding/base64"
 	"strings"
 	"testing"
+	"time"
 
 	cloudevents "github.com/cloudevents/sdk-go/v2"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
 	duckv1 "knative.dev/pkg/apis/duck/v1"
 
 	"github.com/google/go-cmp/cmp"
@@ -125,7 +128,7 @@ func TestPingSourceValidation(t *testing.T) {
 			},
 			want: func() *apis.FieldError {
 				var errs *apis.FieldError
-				fe := apis.ErrInvalidValue("expected exactly 5 fields, found 1: [2]", "spec.schedule")
+				fe := apis.ErrInvalidValue("expected 5 to 6 fields, found 1: [2]", "spec.schedule")
 				errs = errs.Also(fe)
 				return errs
 			}(),
@@ -363,10 +366,62 @@ func TestPingSourceValidation(t *testing.T) {
 			},
 			want: nil,
 		}, {
-			name: "unsupported @every descriptor",
+			name: "valid schedule with seconds",
+			source: PingSource{
+				Spec: PingSourceSpec{
+					Schedule: "*/15 * * * * *",
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{
+							Ref: &duckv1.KReference{
+								APIVersion: "v1",
+								Kind:       "broker",
+								Name:       "default",
+							},
+						},
+					},
+				},
+			},
+			want: nil,
+		}, {
+			name: "valid @every descriptor",
+			source: PingSource{
+				Spec: PingSourceSpec{
+					Schedule: "@every 15s",
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{
+							Ref: &duckv1.KReference{
+								APIVersion: "v1",
+								Kind:       "broker",
+								Name:       "default",
+							},
+						},
+					},
+				},
+			},
+			want: nil,
+		}, {
+			name: "valid @every descriptor with TZ",
 			source: PingSource{
 				Spec: PingSourceSpec{
 					Schedule: "@every 2h",
+					Timezone: "Europe/Paris",
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{
+							Ref: &duckv1.KReference{
+								APIVersion: "v1",
+								Kind:       "broker",
+								Name:       "default",
+							},
+						},
+					},
+				},
+			},
+			want: nil,
+		}, {
+			name: "invalid @every descriptor",
+			source: PingSource{
+				Spec: PingSourceSpec{
+					Schedule: "@every 15",
 					SourceSpec: duckv1.SourceSpec{
 						Sink: duckv1.Destination{
 							Ref: &duckv1.KReference{
@@ -380,15 +435,264 @@ func TestPingSourceValidation(t *testing.T) {
 			},
 			want: func() *apis.FieldError {
 				var errs *apis.FieldError
-				fe := apis.ErrInvalidValue("unsupported descriptor @every", "spec.schedule")
+				fe := apis.ErrInvalidValue("failed to parse duration @every 15: time: missing unit in duration \"15\"", "spec.schedule")
 				errs = errs.Also(fe)
 				return errs
 			}(),
 		}, {
-			name: "unsupported @every descriptor with TZ",
+			name: "valid data template",
 			source: PingSource{
 				Spec: PingSourceSpec{
-					Schedule: "@every 2h",
+					Schedule:      "*/2 * * * *",
+					ContentType:   cloudevents.ApplicationJSON,
+					Data:          `{"window": "{{.Time.Format "15:04"}}", "sequence": {{.Sequence}}}`,
+					DataTemplated: true,
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{
+							Ref: &duckv1.KReference{
+								APIVersion: "v1",
+								Kind:       "broker",
+								Name:       "default",
+							},
+						},
+					},
+				},
+			},
+			want: nil,
+		}, {
+			name: "invalid data template",
+			source: PingSource{
+				Spec: PingSourceSpec{
+					Schedule:      "*/2 * * * *",
+					Data:          "{{.Missing}}",
+					DataTemplated: true,
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{
+							Ref: &duckv1.KReference{
+								APIVersion: "v1",
+								Kind:       "broker",
+								Name:       "default",
+							},
+						},
+					},
+				},
+			},
+			want: apis.ErrInvalidValue(`template: data:1:2: executing "data" at <.Missing>: can't evaluate field Missing in type v1.PingSourceTemplateInput`, "spec.data"),
+		}, {
+			name: "data template making invalid JSON",
+			source: PingSource{
+				Spec: PingSourceSpec{
+					Schedule:      "*/2 * * * *",
+					ContentType:   cloudevents.ApplicationJSON,
+					Data:          `{"name": {{.Name}}}`,
+					DataTemplated: true,
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{
+							Ref: &duckv1.KReference{
+								APIVersion: "v1",
+								Kind:       "broker",
+								Name:       "default",
+							},
+						},
+					},
+				},
+			},
+			want: apis.ErrInvalidValue("invalid character 'a' in literal null (expecting 'u')", "spec.data"),
+		}, {
+			name: "data template with a range action",
+			source: PingSource{
+				Spec: PingSourceSpec{
+					Schedule:      "*/2 * * * *",
+					Data:          "{{range 3000}}{{range 3000}}{{end}}{{end}}",
+					DataTemplated: true,
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{
+							Ref: &duckv1.KReference{
+								APIVersion: "v1",
+								Kind:       "broker",
+								Name:       "default",
+							},
+						},
+					},
+				},
+			},
+			want: apis.ErrInvalidValue("the range actions aren't allowed", "spec.data"),
+		}, {
+			name: "data template with a wide printf verb",
+			source: PingSource{
+				Spec: PingSourceSpec{
+					Schedule:      "*/2 * * * *",
+					Data:          `{{printf "%0100000d" 1}}`,
+					DataTemplated: true,
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{
+							Ref: &duckv1.KReference{
+								APIVersion: "v1",
+								Kind:       "broker",
+								Name:       "default",
+							},
+						},
+					},
+				},
+			},
+			want: apis.ErrInvalidValue(`template: data:1:2: executing "data" at <printf "%0100000d" 1>: error calling printf: printf: the widths and precisions are limited to 64`, "spec.data"),
+		}, {
+			name: "data template rendering data too big",
+			source: PingSource{
+				Spec: PingSourceSpec{
+					Schedule:      "*/2 * * * *",
+					Data:          `{{printf "%064d" 1}}{{printf "%064d" 2}}`,
+					DataTemplated: true,
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{
+							Ref: &duckv1.KReference{
+								APIVersion: "v1",
+								Kind:       "broker",
+								Name:       "default",
+							},
+						},
+					},
+				},
+			},
+			ctx: func(ctx context.Context) context.Context {
+				return config.ToContext(ctx, &config.Config{PingDefaults: &config.PingDefaults{DataMaxSize: 100}})
+			},
+			want: apis.ErrInvalidValue("the rendered data exceeds 100 bytes", "spec.data"),
+		}, {
+			name: "templated dataBase64",
+			source: PingSource{
+				Spec: PingSourceSpec{
+					Schedule:      "*/2 * * * *",
+					DataBase64:    "ZGF0YQ==",
+					DataTemplated: true,
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{
+							Ref: &duckv1.KReference{
+								APIVersion: "v1",
+								Kind:       "broker",
+								Name:       "default",
+							},
+						},
+					},
+				},
+			},
+			want: apis.ErrDisallowedFields("spec.dataTemplated"),
+		}, {
+			name: "valid missed tick policy",
+			source: PingSource{
+				Spec: PingSourceSpec{
+					Schedule:           "*/2 * * * *",
+					MissedTickPolicy:   MissedTickFireAll,
+					MissedTickLookback: pointer.StringPtr("PT30M"),
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{
+							Ref: &duckv1.KReference{
+								APIVersion: "v1",
+								Kind:       "broker",
+								Name:       "default",
+							},
+						},
+					},
+				},
+			},
+			want: nil,
+		}, {
+			name: "invalid missed tick policy",
+			source: PingSource{
+				Spec: PingSourceSpec{
+					Schedule:         "*/2 * * * *",
+					MissedTickPolicy: "fireTwice",
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{
+							Ref: &duckv1.KReference{
+								APIVersion: "v1",
+								Kind:       "broker",
+								Name:       "default",
+							},
+						},
+					},
+				},
+			},
+			want: apis.ErrInvalidValue("fireTwice", "spec.missedTickPolicy"),
+		}, {
+			name: "invalid missed tick lookback",
+			source: PingSource{
+				Spec: PingSourceSpec{
+					Schedule:           "*/2 * * * *",
+					MissedTickPolicy:   MissedTickFireOnce,
+					MissedTickLookback: pointer.StringPtr("30m"),
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{
+							Ref: &duckv1.KReference{
+								APIVersion: "v1",
+								Kind:       "broker",
+								Name:       "default",
+							},
+						},
+					},
+				},
+			},
+			want: apis.ErrInvalidValue("30m", "spec.missedTickLookback"),
+		}, {
+			name: "missed tick lookback too long",
+			source: PingSource{
+				Spec: PingSourceSpec{
+					Schedule:           "*/2 * * * *",
+					MissedTickPolicy:   MissedTickFireOnce,
+					MissedTickLookback: pointer.StringPtr("P2D"),
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{
+							Ref: &duckv1.KReference{
+								APIVersion: "v1",
+								Kind:       "broker",
+								Name:       "default",
+							},
+						},
+					},
+				},
+			},
+			want: apis.ErrOutOfBoundsValue("P2D", "PT0S", "PT24H", "spec.missedTickLookback"),
+		}, {
+			name: "missed tick lookback without catching up",
+			source: PingSource{
+				Spec: PingSourceSpec{
+					Schedule:           "*/2 * * * *",
+					MissedTickLookback: pointer.StringPtr("PT30M"),
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{
+							Ref: &duckv1.KReference{
+								APIVersion: "v1",
+								Kind:       "broker",
+								Name:       "default",
+							},
+						},
+					},
+				},
+			},
+			want: apis.ErrDisallowedFields("spec.missedTickLookback"),
+		}, {
+			name: "valid one-shot",
+			source: PingSource{
+				Spec: PingSourceSpec{
+					At: &metav1.Time{Time: time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)},
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{
+							Ref: &duckv1.KReference{
+								APIVersion: "v1",
+								Kind:       "broker",
+								Name:       "default",
+							},
+						},
+					},
+				},
+			},
+			want: nil,
+		}, {
+			name: "one-shot with schedule and timezone",
+			source: PingSource{
+				Spec: PingSourceSpec{
+					At:       &metav1.Time{Time: time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)},
+					Schedule: "*/2 * * * *",
 					Timezone: "Europe/Paris",
 					SourceSpec: duckv1.SourceSpec{
 						Sink: duckv1.Destination{
@@ -403,8 +707,8 @@ func TestPingSourceValidation(t *testing.T) {
 			},
 			want: func() *apis.FieldError {
 				var errs *apis.FieldError
-				fe := apis.ErrInvalidValue("unsupported descriptor @every", "spec.schedule")
-				errs = errs.Also(fe)
+				errs = errs.Also(apis.ErrMultipleOneOf("spec.at", "spec.schedule"))
+				errs = errs.Also(apis.ErrMultipleOneOf("spec.at", "spec.timezone"))
 				return errs
 			}(),
 		}, {
@@ -31,15 +31,22 @@ func (source *PingSource) ConvertTo(ctx context.Context, obj apis.Convertible) e
 	case *v1.PingSource:
 		sink.ObjectMeta = source.ObjectMeta
 		sink.Status = v1.PingSourceStatus{
-			SourceStatus: source.Status.SourceStatus,
+			SourceStatus:   source.Status.SourceStatus,
+			CompletionTime: source.Status.CompletionTime,
 		}
 		sink.Spec = v1.PingSourceSpec{
 			SourceSpec:  source.Spec.SourceSpec,
 			Schedule:    source.Spec.Schedule,
+			At:          source.Spec.At,
 			Timezone:    source.Spec.Timezone,
 			ContentType: source.Spec.ContentType,
 			Data:        source.Spec.Data,
 			DataBase64:  source.Spec.DataBase64,
+
+			DataTemplated:          source.Spec.DataTemplated,
+			ScheduledTimeExtension: source.Spec.ScheduledTimeExtension,
+			MissedTickPolicy:       v1.MissedTickPolicy(source.Spec.MissedTickPolicy),
+			MissedTickLookback:     source.Spec.MissedTickLookback,
 		}
 
 		return nil
@@ -55,16 +62,23 @@ func (sink *PingSource) ConvertFrom(ctx context.Context, obj apis.Convertible) e
 	case *v1.PingSource:
 		sink.ObjectMeta = source.ObjectMeta
 		sink.Status = PingSourceStatus{
-			SourceStatus: source.Status.SourceStatus,
+			SourceStatus:   source.Status.SourceStatus,
+			CompletionTime: source.Status.CompletionTime,
 		}
 
 		sink.Spec = PingSourceSpec{
 			SourceSpec:  source.Spec.SourceSpec,
 			Schedule:    source.Spec.Schedule,
+			At:          source.Spec.At,
 			Timezone:    source.Spec.Timezone,
 			ContentType: source.Spec.ContentType,
 			Data:        source.Spec.Data,
 			DataBase64:  source.Spec.DataBase64,
+
+			DataTemplated:          source.Spec.DataTemplated,
+			ScheduledTimeExtension: source.Spec.ScheduledTimeExtension,
+			MissedTickPolicy:       MissedTickPolicy(source.Spec.MissedTickPolicy),
+			MissedTickLookback:     source.Spec.MissedTickLookback,
 		}
 
 		return nil
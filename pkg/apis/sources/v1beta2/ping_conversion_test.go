@@ -20,8 +20,13 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"knative.dev/pkg/apis"
+
+	v1 "knative.dev/eventing/pkg/apis/sources/v1"
 )
 
 // implement apis.Convertible
@@ -46,3 +51,37 @@ func TestPingSourceConversionBadType(t *testing.T) {
 		t.Errorf("ConvertFrom() = %#v, wanted error", good)
 	}
 }
+
+func TestPingSourceConversionOneShot(t *testing.T) {
+	at := metav1.NewTime(time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC))
+	source := &PingSource{
+		ObjectMeta: metav1.ObjectMeta{Name: "ping", Namespace: "ns"},
+		Spec: PingSourceSpec{
+			At:               &at,
+			Data:             "data",
+			MissedTickPolicy: MissedTickFireOnce,
+		},
+		Status: PingSourceStatus{
+			CompletionTime: &at,
+		},
+	}
+
+	converted := &v1.PingSource{}
+	if err := source.ConvertTo(context.Background(), converted); err != nil {
+		t.Fatal("ConvertTo() =", err)
+	}
+	if converted.Spec.At == nil || !converted.Spec.At.Equal(&at) {
+		t.Errorf("ConvertTo() at = %v, want %v", converted.Spec.At, at)
+	}
+	if converted.Spec.MissedTickPolicy != v1.MissedTickFireOnce {
+		t.Errorf("ConvertTo() missedTickPolicy = %v, want %v", converted.Spec.MissedTickPolicy, v1.MissedTickFireOnce)
+	}
+
+	got := &PingSource{}
+	if err := got.ConvertFrom(context.Background(), converted); err != nil {
+		t.Fatal("ConvertFrom() =", err)
+	}
+	if diff := cmp.Diff(source, got); diff != "" {
+		t.Error("Unexpected round trip (-want, +got) =", diff)
+	}
+}
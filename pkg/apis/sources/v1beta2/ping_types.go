@@ -58,10 +58,16 @@ type PingSourceSpec struct {
 	//   and modifications of the event sent to the sink.
 	duckv1.SourceSpec `json:",inline"`
 
-	// Schedule is the cron schedule. Defaults to `* * * * *`.
+	// Schedule is the cron schedule, with an optional leading seconds field. Descriptors
+	// like `@every 15s` are supported too. Defaults to `* * * * *` unless At is set.
 	// +optional
 	Schedule string `json:"schedule,omitempty"`
 
+	// At is the time of the single event sent by a one-shot PingSource.
+	// Mutually exclusive with Schedule and Timezone.
+	// +optional
+	At *metav1.Time `json:"at,omitempty"`
+
 	// Timezone modifies the actual time relative to the specified timezone.
 	// Defaults to the system time zone.
 	// More general information about time zones: https://www.iana.org/time-zones
@@ -81,8 +87,44 @@ type PingSourceSpec struct {
 	// Mutually exclusive with Data.
 	// +optional
 	DataBase64 string `json:"dataBase64,omitempty"`
+
+	// DataTemplated tells whether Data is a Go template, executed at each tick with the scheduled
+	// time of the tick (.Time), the name (.Name) and namespace (.Namespace) of the PingSource and
+	// the sequence number of the tick (.Sequence). The range, with and template actions aren't
+	// allowed. Not supported with DataBase64.
+	// +optional
+	DataTemplated bool `json:"dataTemplated,omitempty"`
+
+	// ScheduledTimeExtension tells whether the events carry the scheduled time of their tick in
+	// the scheduledtime extension.
+	// +optional
+	ScheduledTimeExtension bool `json:"scheduledTimeExtension,omitempty"`
+
+	// MissedTickPolicy tells what to do with the ticks missed while the adapter wasn't running:
+	// skip (default), fireOnce or fireAll.
+	// +optional
+	MissedTickPolicy MissedTickPolicy `json:"missedTickPolicy,omitempty"`
+
+	// MissedTickLookback is how far back the missed ticks are caught up, as an ISO 8601 duration.
+	// Defaults to one hour, at most one day. No more than the last 100 missed ticks are caught up.
+	// +optional
+	MissedTickLookback *string `json:"missedTickLookback,omitempty"`
 }
 
+// MissedTickPolicy tells what a PingSource does with the ticks missed while its adapter wasn't running.
+type MissedTickPolicy string
+
+const (
+	// MissedTickSkip drops the missed ticks.
+	MissedTickSkip MissedTickPolicy = "skip"
+
+	// MissedTickFireOnce sends a single event for the last missed tick.
+	MissedTickFireOnce MissedTickPolicy = "fireOnce"
+
+	// MissedTickFireAll sends an event for each missed tick.
+	MissedTickFireAll MissedTickPolicy = "fireAll"
+)
+
 // PingSourceStatus defines the observed state of PingSource.
 type PingSourceStatus struct {
 	// inherits duck/v1 SourceStatus, which currently provides:
@@ -93,6 +135,11 @@ type PingSourceStatus struct {
 	// * SinkURI - the current active sink URI that has been configured for the
 	//   Source.
 	duckv1.SourceStatus `json:",inline"`
+
+	// CompletionTime is the time the event of a one-shot PingSource was sent at, as reported
+	// by the adapter.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -20,16 +20,17 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/rickb777/date/period"
 
-	"github.com/robfig/cron/v3"
 	"knative.dev/pkg/apis"
 
 	"knative.dev/eventing/pkg/apis/sources/config"
+	v1 "knative.dev/eventing/pkg/apis/sources/v1"
 )
 
 func (c *PingSource) Validate(ctx context.Context) *apis.FieldError {
@@ -38,21 +39,28 @@ func (c *PingSource) Validate(ctx context.Context) *apis.FieldError {
 
 func (cs *PingSourceSpec) Validate(ctx context.Context) *apis.FieldError {
 	var errs *apis.FieldError
-	schedule := cs.Schedule
 
-	errs = validateDescriptor(schedule)
-
-	if cs.Timezone != "" {
-		schedule = "CRON_TZ=" + cs.Timezone + " " + schedule
-	}
+	if cs.At != nil {
+		if cs.Schedule != "" {
+			errs = errs.Also(apis.ErrMultipleOneOf("at", "schedule"))
+		}
+		if cs.Timezone != "" {
+			errs = errs.Also(apis.ErrMultipleOneOf("at", "timezone"))
+		}
+	} else {
+		schedule := cs.Schedule
+		if cs.Timezone != "" {
+			schedule = "CRON_TZ=" + cs.Timezone + " " + schedule
+		}
 
-	if _, err := cron.ParseStandard(schedule); err != nil {
-		if strings.HasPrefix(err.Error(), "provided bad location") {
-			fe := apis.ErrInvalidValue(err, "timezone")
-			errs = errs.Also(fe)
-		} else {
-			fe := apis.ErrInvalidValue(err, "schedule")
-			errs = errs.Also(fe)
+		if _, err := v1.PingScheduleParser.Parse(schedule); err != nil {
+			if strings.HasPrefix(err.Error(), "provided bad location") {
+				fe := apis.ErrInvalidValue(err, "timezone")
+				errs = errs.Also(fe)
+			} else {
+				fe := apis.ErrInvalidValue(err, "schedule")
+				errs = errs.Also(fe)
+			}
 		}
 	}
 
@@ -87,13 +95,51 @@ func (cs *PingSourceSpec) Validate(ctx context.Context) *apis.FieldError {
 			fe := apis.ErrInvalidValue(fmt.Sprintf("the data length of %d bytes exceeds limit set at %d.", bsize, pingDefaults.DataMaxSize), "data")
 			errs = errs.Also(fe)
 		}
-		if cs.ContentType == cloudevents.ApplicationJSON {
+		if cs.DataTemplated {
+			// validate the template, the size of its output for a sample tick and, if data is JSON,
+			// the output
+			data, err := v1.ExecutePingSourceTemplate(cs.Data, v1.PingSourceTemplateInput{
+				Time:      time.Unix(0, 0),
+				Name:      "name",
+				Namespace: "namespace",
+				Sequence:  1,
+			}, pingDefaults.DataMaxSize)
+			if err != nil {
+				errs = errs.Also(apis.ErrInvalidValue(err, "data"))
+			} else if cs.ContentType == cloudevents.ApplicationJSON {
+				if err := validateJSON(string(data)); err != nil {
+					errs = errs.Also(apis.ErrInvalidValue(err, "data"))
+				}
+			}
+		} else if cs.ContentType == cloudevents.ApplicationJSON {
 			// validate if data is valid JSON
 			if err := validateJSON(cs.Data); err != nil {
 				errs = errs.Also(apis.ErrInvalidValue(err, "data"))
 			}
 		}
 	}
+	if cs.DataTemplated && cs.DataBase64 != "" {
+		errs = errs.Also(apis.ErrDisallowedFields("dataTemplated"))
+	}
+
+	switch cs.MissedTickPolicy {
+	case "", MissedTickSkip:
+		if cs.MissedTickLookback != nil {
+			errs = errs.Also(apis.ErrDisallowedFields("missedTickLookback"))
+		}
+	case MissedTickFireOnce, MissedTickFireAll:
+		if cs.MissedTickLookback != nil {
+			p, err := period.Parse(*cs.MissedTickLookback)
+			if err != nil || p.IsZero() || p.IsNegative() {
+				errs = errs.Also(apis.ErrInvalidValue(*cs.MissedTickLookback, "missedTickLookback"))
+			} else if d, _ := p.Duration(); d <= 0 || d > v1.PingSourceMaxMissedTickLookback {
+				errs = errs.Also(apis.ErrOutOfBoundsValue(*cs.MissedTickLookback, "PT0S", "PT24H", "missedTickLookback"))
+			}
+		}
+	default:
+		errs = errs.Also(apis.ErrInvalidValue(cs.MissedTickPolicy, "missedTickPolicy"))
+	}
+
 	errs = errs.Also(cs.SourceSpec.Validate(ctx))
 	return errs
 }
@@ -102,10 +148,3 @@ func validateJSON(str string) error {
 	var objmap map[string]interface{}
 	return json.Unmarshal([]byte(str), &objmap)
 }
-
-func validateDescriptor(spec string) *apis.FieldError {
-	if strings.Contains(spec, "@every") {
-		return apis.ErrInvalidValue(errors.New("unsupported descriptor @every"), "schedule")
-	}
-	return nil
-}
@@ -0,0 +1,318 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filter
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"go.opencensus.io/trace"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/logging"
+
+	eventingv1 "knative.dev/eventing/pkg/apis/eventing/v1"
+	broker "knative.dev/eventing/pkg/broker"
+	"knative.dev/eventing/pkg/channel/attributes"
+	"knative.dev/eventing/pkg/eventfilter"
+	"knative.dev/eventing/pkg/kncloudevents"
+	"knative.dev/eventing/pkg/reconciler/sugar/trigger/path"
+	"knative.dev/eventing/pkg/tracing"
+	"knative.dev/eventing/pkg/utils"
+)
+
+// triggerBatcher accumulates the events sent to the subscriber of a Trigger in the broker
+// dispatch mode.
+type triggerBatcher struct {
+	*kncloudevents.Batcher
+
+	mutex sync.RWMutex
+	t     *eventingv1.Trigger
+	b     *eventingv1.Broker
+}
+
+func (tb *triggerBatcher) objects() (*eventingv1.Trigger, *eventingv1.Broker) {
+	tb.mutex.RLock()
+	defer tb.mutex.RUnlock()
+	return tb.t, tb.b
+}
+
+func (tb *triggerBatcher) setObjects(t *eventingv1.Trigger, b *eventingv1.Broker) {
+	tb.mutex.Lock()
+	defer tb.mutex.Unlock()
+	tb.t, tb.b = t, b
+}
+
+// triggerBatchers holds the batcher of each batched Trigger.
+type triggerBatchers struct {
+	mutex    sync.Mutex
+	batchers map[types.UID]*triggerBatcher
+}
+
+func newTriggerBatchers() *triggerBatchers {
+	return &triggerBatchers{batchers: make(map[types.UID]*triggerBatcher)}
+}
+
+// get returns the batcher of the Trigger, creating it with send if the Trigger doesn't have
+// one yet or its batch configuration changed.
+func (c *triggerBatchers) get(t *eventingv1.Trigger, b *eventingv1.Broker, config kncloudevents.BatchConfig, send func(tb *triggerBatcher, events []*cloudevents.Event) error) *triggerBatcher {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if tb, ok := c.batchers[t.UID]; ok {
+		if tb.Config() == config {
+			tb.setObjects(t, b)
+			return tb
+		}
+		go tb.Close()
+	}
+	tb := &triggerBatcher{t: t, b: b}
+	tb.Batcher = kncloudevents.NewBatcher(config, func(events []*cloudevents.Event) error {
+		return send(tb, events)
+	})
+	c.batchers[t.UID] = tb
+	return tb
+}
+
+// delete closes the batcher of the Trigger with the given UID, sending its pending events.
+func (c *triggerBatchers) delete(uid types.UID) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if tb, ok := c.batchers[uid]; ok {
+		go tb.Close()
+		delete(c.batchers, uid)
+	}
+}
+
+// batchConfig returns the batch configuration of the Trigger delivery spec, falling back to the
+// Broker one. It's nil when the events are sent one by one.
+func (h *Handler) batchConfig(t *eventingv1.Trigger, b *eventingv1.Broker) *kncloudevents.BatchConfig {
+	delivery := t.Spec.Delivery
+	if delivery == nil && b != nil {
+		delivery = b.Spec.Delivery
+	}
+	if delivery == nil {
+		return nil
+	}
+	config, err := kncloudevents.BatchConfigFromDeliverySpec(*delivery)
+	if err != nil {
+		// The delivery spec is validated by the webhook, so this should never happen.
+		h.logger.Warn("Failed to parse the delivery spec", zap.String("namespace", t.Namespace), zap.String("name", t.Name), zap.Error(err))
+		return nil
+	}
+	return config
+}
+
+// transformEvent returns a copy of the event with the transformers applied.
+func transformEvent(ctx context.Context, event *cloudevents.Event, transformers ...binding.Transformer) (*cloudevents.Event, error) {
+	if len(transformers) == 0 {
+		return event, nil
+	}
+	// The transformers rewrite an event message in place, while the event is shared with
+	// the other Triggers.
+	clone := event.Clone()
+	return binding.ToEvent(ctx, binding.ToMessage(&clone), transformers...)
+}
+
+// serveTriggerBatch handles a batch of events sent to a Trigger, in the CloudEvents batched
+// content mode. The events passing the filter of the Trigger are sent to its subscriber in a
+// single batch, whose response status is the one of the request. The replies to the batch
+// aren't forwarded.
+func (h *Handler) serveTriggerBatch(writer http.ResponseWriter, request *http.Request, triggerRef path.NamespacedNameUID) {
+	ctx := request.Context()
+
+	events, err := kncloudevents.ReadBatch(request.Body)
+	if err != nil {
+		h.logger.Warn("failed to extract the events from the request", zap.Error(err))
+		writer.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	ctx, span := trace.StartSpan(ctx, tracing.TriggerMessagingDestination(triggerRef.NamespacedName))
+	defer span.End()
+
+	if span.IsRecordingEvents() {
+		span.AddAttributes(
+			tracing.MessagingSystemAttribute,
+			tracing.MessagingProtocolHTTP,
+			tracing.TriggerMessagingDestinationAttribute(triggerRef.NamespacedName),
+		)
+	}
+
+	h.logger.Debug("Received batch", zap.Any("triggerRef", triggerRef), zap.Int("size", len(events)))
+
+	t, reportArgs, entry, ok := h.lookupTrigger(writer, triggerRef)
+	if !ok {
+		return
+	}
+	subscriberURI := t.Status.SubscriberURI
+	ctx = logging.WithLogger(ctx, h.logger.Sugar())
+
+	matching := make([]*cloudevents.Event, 0, len(events))
+	for _, event := range events {
+		// Remove the TTL attribute that is used by the Broker, the events without it weren't
+		// sent by the Broker and are dropped.
+		if _, err := broker.GetTTL(event.Context); err != nil {
+			h.logger.Warn("No TTL seen, dropping", zap.Any("triggerRef", triggerRef), zap.Any("event", event))
+			continue
+		}
+		if err := broker.DeleteTTL(event.Context); err != nil {
+			h.logger.Warn("Failed to delete TTL.", zap.Error(err))
+		}
+		if entry.filter.Filter(ctx, *event) == eventfilter.FailFilter {
+			continue
+		}
+		h.reportArrivalTime(event, reportArgs)
+		transformed, err := transformEvent(ctx, event, entry.transformers...)
+		if err != nil {
+			h.logger.Warn("Failed to transform the event, dropping", zap.Any("triggerRef", triggerRef), zap.Error(err))
+			continue
+		}
+		matching = append(matching, transformed)
+	}
+	if len(matching) == 0 {
+		// No event was meant for this Trigger.
+		return
+	}
+
+	release, err := h.sender.Limiters.Acquire(ctx, subscriberURI.String(), entry.limits)
+	if err != nil {
+		h.logger.Warn("Rejecting the batch", zap.Any("triggerRef", triggerRef), zap.Error(err))
+		writer.WriteHeader(http.StatusTooManyRequests)
+		h.reportBatchCount(reportArgs, len(matching), http.StatusTooManyRequests)
+		return
+	}
+	defer release()
+
+	response, err := h.sendBatch(ctx, request.Header, subscriberURI.String(), matching, reportArgs, nil, nil)
+	if err != nil {
+		h.logger.Error("failed to send batch", zap.Error(err))
+		writer.WriteHeader(http.StatusInternalServerError)
+		h.reportBatchCount(reportArgs, len(matching), http.StatusInternalServerError)
+		return
+	}
+	_, _ = io.Copy(io.Discard, response.Body)
+	response.Body.Close()
+	writer.WriteHeader(response.StatusCode)
+	h.reportBatchCount(reportArgs, len(matching), response.StatusCode)
+}
+
+// addToBatch adds the event, with the transformers applied, to the batch of the Trigger in the
+// broker dispatch mode.
+func (h *Handler) addToBatch(ctx context.Context, t *eventingv1.Trigger, b *eventingv1.Broker, config kncloudevents.BatchConfig, event *cloudevents.Event, reportArgs *ReportArgs, transformers ...binding.Transformer) {
+	transformed, err := transformEvent(ctx, event, transformers...)
+	if err != nil {
+		h.logger.Warn("Failed to transform the event", zap.String("namespace", t.Namespace), zap.String("name", t.Name), zap.Error(err))
+		_ = h.reporter.ReportEventCount(reportArgs, http.StatusInternalServerError)
+		return
+	}
+	h.batchers.get(t, b, config, h.dispatchBatchToTrigger).Add(transformed, nil)
+}
+
+// dispatchBatchToTrigger sends the events to the subscriber of the Trigger in a single batch,
+// retrying as configured by the Trigger or Broker delivery spec. When the batch can't be
+// delivered, each event goes to the Trigger's dead letter sink.
+func (h *Handler) dispatchBatchToTrigger(tb *triggerBatcher, events []*cloudevents.Event) error {
+	t, b := tb.objects()
+	// The batch outlives the requests of its events, which were already accepted.
+	ctx := kncloudevents.ContextWithUnboundedLimiterWait(context.Background())
+	reportArgs := &ReportArgs{
+		ns:         t.Namespace,
+		trigger:    t.Name,
+		broker:     t.Spec.Broker,
+		filterType: triggerFilterAttribute(t.Spec.Filter, "type"),
+	}
+
+	target := t.Status.SubscriberURI.String()
+	retryConfig := h.retryConfig(t, b)
+	release, err := h.sender.Limiters.Acquire(ctx, target, retryConfig)
+	if err != nil {
+		h.logger.Error("failed to send batch", zap.Error(err), zap.Any("target", target))
+		h.reportBatchCount(reportArgs, len(events), http.StatusTooManyRequests)
+		for _, event := range events {
+			h.sendToDeadLetterSink(ctx, nil, t, event, knativeErrorTransformers(t, http.StatusTooManyRequests, err.Error(), kncloudevents.DeliveryAttempts{})...)
+		}
+		return err
+	}
+	var attempts kncloudevents.DeliveryAttempts
+	response, err := h.sendBatch(ctx, nil, target, events, reportArgs, retryConfig, &attempts)
+	release()
+	if err == nil && (response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices) {
+		err = fmt.Errorf("unexpected HTTP response, expected 2xx, got %d", response.StatusCode)
+	}
+	if err != nil {
+		h.logger.Error("failed to send batch", zap.Error(err), zap.Any("target", target))
+		statusCode := http.StatusInternalServerError
+		data := fmt.Sprintf("dispatch error: %s", err.Error())
+		if response != nil {
+			statusCode = response.StatusCode
+			body := make([]byte, attributes.KnativeErrorDataExtensionMaxLength)
+			n, _ := io.ReadFull(response.Body, body)
+			data = string(body[:n])
+			response.Body.Close()
+		}
+		h.reportBatchCount(reportArgs, len(events), statusCode)
+		for _, event := range events {
+			h.sendToDeadLetterSink(ctx, nil, t, event, knativeErrorTransformers(t, statusCode, data, attempts)...)
+		}
+		return err
+	}
+	// The replies to a batch aren't forwarded.
+	_, _ = io.Copy(io.Discard, response.Body)
+	response.Body.Close()
+	h.reportBatchCount(reportArgs, len(events), response.StatusCode)
+	return nil
+}
+
+// sendBatch sends the events to the target in a single request, retrying as configured by
+// retryConfig and recording the requests sent in attempts when not nil.
+func (h *Handler) sendBatch(ctx context.Context, headers http.Header, target string, events []*cloudevents.Event, reporterArgs *ReportArgs, retryConfig *kncloudevents.RetryConfig, attempts *kncloudevents.DeliveryAttempts) (*http.Response, error) {
+	req, err := h.sender.NewCloudEventRequestWithTarget(ctx, target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the request: %w", err)
+	}
+	if err := kncloudevents.WriteHTTPRequestWithBatch(ctx, events, req, utils.PassThroughHeaders(headers)); err != nil {
+		return nil, fmt.Errorf("failed to write request: %w", err)
+	}
+
+	start := time.Now()
+	resp, err := h.sender.SendWithRetriesAndAttempts(req, retryConfig, attempts)
+	dispatchTime := time.Since(start)
+	if err != nil {
+		err = fmt.Errorf("failed to dispatch batch: %w", err)
+	}
+
+	sc := 0
+	if resp != nil {
+		sc = resp.StatusCode
+	}
+	_ = h.reporter.ReportEventDispatchTime(reporterArgs, sc, dispatchTime)
+
+	return resp, err
+}
+
+// reportBatchCount counts each event of a batch with the status code of the batch.
+func (h *Handler) reportBatchCount(reportArgs *ReportArgs, size int, statusCode int) {
+	for i := 0; i < size; i++ {
+		_ = h.reporter.ReportEventCount(reportArgs, statusCode)
+	}
+}
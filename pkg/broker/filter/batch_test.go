@@ -0,0 +1,204 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filter
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+	"knative.dev/pkg/apis"
+
+	eventingduckv1 "knative.dev/eventing/pkg/apis/duck/v1"
+	eventingv1 "knative.dev/eventing/pkg/apis/eventing/v1"
+	broker "knative.dev/eventing/pkg/broker"
+	"knative.dev/eventing/pkg/kncloudevents"
+	reconcilertesting "knative.dev/eventing/pkg/reconciler/testing/v1"
+)
+
+// batchRecorder plays a subscriber receiving batches, recording the IDs of the events of each
+// batch.
+type batchRecorder struct {
+	t      *testing.T
+	status int
+
+	mutex   sync.Mutex
+	batches [][]string
+}
+
+func (r *batchRecorder) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	if !kncloudevents.IsBatch(request.Header) {
+		r.t.Error("Expected a batch, got Content-Type", request.Header.Get("Content-Type"))
+	}
+	events, err := kncloudevents.ReadBatch(request.Body)
+	if err != nil {
+		r.t.Error("Unable to read the batch:", err)
+	}
+	ids := make([]string, 0, len(events))
+	for _, e := range events {
+		if _, err := broker.GetTTL(e.Context); err == nil {
+			r.t.Error("Expected the TTL to be removed from", e.ID())
+		}
+		ids = append(ids, e.ID())
+	}
+	r.mutex.Lock()
+	r.batches = append(r.batches, ids)
+	r.mutex.Unlock()
+	writer.WriteHeader(r.status)
+}
+
+func (r *batchRecorder) received() [][]string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([][]string(nil), r.batches...)
+}
+
+func makeEventWithID(e *cloudevents.Event, id string) *cloudevents.Event {
+	e.SetID(id)
+	return e
+}
+
+func TestReceiverBatch(t *testing.T) {
+	testCases := map[string]struct {
+		filter           *eventingv1.TriggerFilter
+		subscriberStatus int
+		expectedStatus   int
+		expectedBatches  [][]string
+	}{
+		"Accepted": {
+			filter:           makeTriggerFilterWithAttributes(eventType, eventSource),
+			subscriberStatus: http.StatusAccepted,
+			expectedStatus:   http.StatusAccepted,
+			expectedBatches:  [][]string{{"1", "4"}},
+		},
+		"Subscriber failure": {
+			filter:           makeTriggerFilterWithAttributes(eventType, eventSource),
+			subscriberStatus: http.StatusServiceUnavailable,
+			expectedStatus:   http.StatusServiceUnavailable,
+			expectedBatches:  [][]string{{"1", "4"}},
+		},
+		"Invalid filter": {
+			filter:           makeTriggerFilterWithData(eventingduckv1.DataFilter{Path: ".order["}),
+			subscriberStatus: http.StatusAccepted,
+			expectedStatus:   http.StatusInternalServerError,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			recorder := &batchRecorder{t: t, status: tc.subscriberStatus}
+			s := httptest.NewServer(recorder)
+			defer s.Close()
+
+			trigger := makeTrigger(tc.filter)
+			trigger.Status.SubscriberURI = apis.HTTP(s.Listener.Addr().String())
+			listers := reconcilertesting.NewListers([]runtime.Object{trigger})
+			h, err := NewHandler(
+				zaptest.NewLogger(t, zaptest.WrapOptions(zap.AddCaller())),
+				listers.GetTriggerLister(),
+				listers.GetBrokerLister(),
+				listers.GetEventTypeIndexer(broker.EventTypeIndexers),
+				&mockReporter{},
+				8080)
+			if err != nil {
+				t.Fatal("Unable to create receiver:", err)
+			}
+
+			// The events without TTL and the ones not passing the filter are dropped.
+			b, err := json.Marshal([]*cloudevents.Event{
+				makeEventWithID(makeEvent(), "1"),
+				makeEventWithID(makeDifferentEvent(), "2"),
+				makeEventWithID(makeEventWithoutTTL(), "3"),
+				makeEventWithID(makeEvent(), "4"),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			request := httptest.NewRequest(http.MethodPost, validPath, bytes.NewBuffer(b))
+			request.Header.Set("Content-Type", cloudevents.ApplicationCloudEventsBatchJSON)
+
+			responseWriter := httptest.NewRecorder()
+			h.ServeHTTP(responseWriter, request)
+
+			if got := responseWriter.Result().StatusCode; got != tc.expectedStatus {
+				t.Errorf("Unexpected status. Expected %v. Actual %v.", tc.expectedStatus, got)
+			}
+			if diff := cmp.Diff(tc.expectedBatches, recorder.received()); diff != "" {
+				t.Error("Unexpected batches (-want, +got) =", diff)
+			}
+		})
+	}
+}
+
+func TestBrokerReceiverBatch(t *testing.T) {
+	subscriber := &batchRecorder{t: t, status: http.StatusAccepted}
+	s := httptest.NewServer(subscriber)
+	defer s.Close()
+
+	trigger := makeIndexedTrigger("batched", nil)
+	trigger.Status.SubscriberURI = apis.HTTP(s.Listener.Addr().String())
+	trigger.Spec.Delivery = &eventingduckv1.DeliverySpec{
+		MaxBatchSize:  pointer.Int32Ptr(2),
+		MaxBatchDelay: pointer.StringPtr("PT0.1S"),
+	}
+	listers := reconcilertesting.NewListers([]runtime.Object{
+		trigger,
+		reconcilertesting.NewBroker(indexBrokerName, testNS),
+	})
+	h, err := NewHandler(
+		zaptest.NewLogger(t, zaptest.WrapOptions(zap.AddCaller())),
+		listers.GetTriggerLister(),
+		listers.GetBrokerLister(),
+		listers.GetEventTypeIndexer(broker.EventTypeIndexers),
+		&mockReporter{},
+		8080)
+	if err != nil {
+		t.Fatal("Unable to create receiver:", err)
+	}
+
+	for _, id := range []string{"1", "2", "3"} {
+		b, err := makeEventWithID(makeEvent(), id).MarshalJSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+		request := httptest.NewRequest(http.MethodPost, validBrokerPath, bytes.NewBuffer(b))
+		request.Header.Set("Content-Type", cloudevents.ApplicationCloudEventsJSON)
+		responseWriter := httptest.NewRecorder()
+		h.ServeHTTP(responseWriter, request)
+		if got := responseWriter.Result().StatusCode; got != http.StatusAccepted {
+			t.Errorf("Unexpected status. Expected %v. Actual %v.", http.StatusAccepted, got)
+		}
+	}
+
+	// The first batch is sent once full, the second one after the maximum delay.
+	deadline := time.Now().Add(5 * time.Second)
+	for len(subscriber.received()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if diff := cmp.Diff([][]string{{"1", "2"}, {"3"}}, subscriber.received()); diff != "" {
+		t.Error("Unexpected batches (-want, +got) =", diff)
+	}
+}
@@ -0,0 +1,358 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	opencensusclient "github.com/cloudevents/sdk-go/observability/opencensus/v2/client"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/binding"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+	"go.opencensus.io/trace"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/logging"
+
+	eventingv1 "knative.dev/eventing/pkg/apis/eventing/v1"
+	broker "knative.dev/eventing/pkg/broker"
+	"knative.dev/eventing/pkg/channel/attributes"
+	"knative.dev/eventing/pkg/eventfilter"
+	"knative.dev/eventing/pkg/kncloudevents"
+	"knative.dev/eventing/pkg/tracing"
+	"knative.dev/eventing/pkg/utils"
+)
+
+// serveBroker handles the events sent to a Broker running in the broker dispatch mode.
+// 1. extract event from request
+// 2. get the candidate Triggers from the Broker's Trigger index
+// 3. filter event with every candidate Trigger
+// 4. deliver the event to the subscribers of the matching Triggers on the dispatch queue
+// 5. write the response
+//
+// The deliveries send the event to the subscribers honoring their delivery spec, and send the
+// replies back to the Broker. The event is acknowledged once all its deliveries are done,
+// either delivered or, after their retries, sent to the dead letter sink of their Trigger, or
+// dropped when it doesn't have one. The deliveries of the batched Triggers are done once the event
+// is added to their batch. When the queue is full, the channel is asked to redeliver
+// the event later, so the Triggers whose delivery was already done may get it again.
+func (h *Handler) serveBroker(writer http.ResponseWriter, request *http.Request, brokerRef types.NamespacedName) {
+	ctx := request.Context()
+
+	message := cehttp.NewMessageFromHttpRequest(request)
+	defer message.Finish(nil)
+
+	event, err := binding.ToEvent(ctx, message)
+	if err != nil {
+		h.logger.Warn("failed to extract event from request", zap.Error(err))
+		writer.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	ctx, span := trace.StartSpan(ctx, tracing.BrokerMessagingDestination(brokerRef))
+	defer span.End()
+
+	if span.IsRecordingEvents() {
+		span.AddAttributes(
+			tracing.MessagingSystemAttribute,
+			tracing.MessagingProtocolHTTP,
+			tracing.BrokerMessagingDestinationAttribute(brokerRef),
+			tracing.MessagingMessageIDAttribute(event.ID()),
+		)
+		span.AddAttributes(opencensusclient.EventTraceAttributes(event)...)
+	}
+
+	// Remove the TTL attribute that is used by the Broker.
+	ttl, err := broker.GetTTL(event.Context)
+	if err != nil {
+		// Only messages sent by the Broker should be here. If the attribute isn't here, then the
+		// event wasn't sent by the Broker, so we can drop it.
+		h.logger.Warn("No TTL seen, dropping", zap.Any("brokerRef", brokerRef), zap.Any("event", event))
+		writer.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if err := broker.DeleteTTL(event.Context); err != nil {
+		h.logger.Warn("Failed to delete TTL.", zap.Error(err))
+	}
+
+	h.logger.Debug("Received message", zap.Any("brokerRef", brokerRef))
+
+	b, err := h.brokerLister.Brokers(brokerRef.Namespace).Get(brokerRef.Name)
+	if err != nil {
+		h.logger.Info("Unable to get the Broker", zap.Error(err), zap.Any("brokerRef", brokerRef))
+		writer.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	index, err := h.triggerIndexes.get(brokerRef)
+	if err != nil {
+		h.logger.Error("Unable to list the Triggers", zap.Error(err), zap.Any("brokerRef", brokerRef))
+		writer.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	ctx = logging.WithLogger(ctx, h.logger.Sugar())
+
+	// The deliveries started aren't canceled with the request, so that they reach the retries and
+	// dead letter sink of their Trigger, keeping its span and headers.
+	dispatchCtx := trace.NewContext(logging.WithLogger(context.Background(), h.logger.Sugar()), span)
+	headers := request.Header.Clone()
+	var wg sync.WaitGroup
+	var deliveries []func()
+	for _, t := range index.candidates(event) {
+		if !h.triggerMatches(ctx, t, event) {
+			continue
+		}
+		t := t
+		deliveries = append(deliveries, func() {
+			defer wg.Done()
+			h.dispatchToTrigger(dispatchCtx, headers, b, t, event, ttl)
+		})
+	}
+	// The deliveries of an event matching more Triggers than the queue size are queued in
+	// parts, each one once the previous one is done.
+	for len(deliveries) > 0 {
+		part := deliveries
+		if len(part) > h.deliveries.size {
+			part = part[:h.deliveries.size]
+		}
+		wg.Add(len(part))
+		if !h.deliveries.submit(part) {
+			h.logger.Warn("Too many deliveries in progress, rejecting the event", zap.Any("brokerRef", brokerRef))
+			writer.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		wg.Wait()
+		deliveries = deliveries[len(part):]
+	}
+
+	writer.WriteHeader(http.StatusAccepted)
+}
+
+// triggerMatches returns true if the Trigger is ready to receive events and its filter
+// passes the event.
+func (h *Handler) triggerMatches(ctx context.Context, t *eventingv1.Trigger, event *cloudevents.Event) bool {
+	if t.Status.SubscriberURI == nil {
+		h.logger.Debug("Trigger has no subscriber URI, skipping", zap.String("namespace", t.Namespace), zap.String("name", t.Name))
+		return false
+	}
+	entry := h.filters.entry(t)
+	if entry.err != nil {
+		// The filter and transform are validated by the webhook, so this should never happen.
+		h.logger.Warn("Failed to build the Trigger filter or transform", zap.String("namespace", t.Namespace), zap.String("name", t.Name), zap.Error(entry.err))
+		return false
+	}
+	return entry.filter.Filter(ctx, *event) != eventfilter.FailFilter
+}
+
+// dispatchToTrigger sends the event to the subscriber of the Trigger, retrying as configured by the
+// Trigger or Broker delivery spec. Events that can't be delivered go to the Trigger's dead letter sink,
+// while the replies of the subscriber are sent back to the Broker.
+func (h *Handler) dispatchToTrigger(ctx context.Context, headers http.Header, b *eventingv1.Broker, t *eventingv1.Trigger, event *cloudevents.Event, ttl int32) {
+	reportArgs := &ReportArgs{
+		ns:         t.Namespace,
+		trigger:    t.Name,
+		broker:     t.Spec.Broker,
+		filterType: triggerFilterAttribute(t.Spec.Filter, "type"),
+	}
+
+	h.reportArrivalTime(event, reportArgs)
+
+	if err := h.validateSchema(t, event); err != nil {
+		h.logger.Debug("The event data doesn't match its schema", zap.String("namespace", t.Namespace), zap.String("name", t.Name), zap.Error(err))
+		_ = h.reporter.ReportEventCount(reportArgs, http.StatusBadRequest)
+		invalid := event.Clone()
+		invalid.SetExtension(broker.SchemaErrorAttribute, err.Error())
+		h.sendToDeadLetterSink(ctx, headers, t, &invalid, knativeErrorTransformers(t, http.StatusBadRequest, err.Error(), kncloudevents.DeliveryAttempts{})...)
+		return
+	}
+
+	entry := h.filters.entry(t)
+	if entry.err != nil {
+		// The filter and transform are validated by the webhook, so this should never happen.
+		h.logger.Warn("Failed to build the Trigger filter or transform", zap.String("namespace", t.Namespace), zap.String("name", t.Name), zap.Error(entry.err))
+		_ = h.reporter.ReportEventCount(reportArgs, http.StatusInternalServerError)
+		return
+	}
+	transformers := entry.transformers
+
+	if batch := h.batchConfig(t, b); batch != nil {
+		// The event is delivered with the batch of the Trigger, once it's full or waited for
+		// the maximum delay.
+		h.addToBatch(ctx, t, b, *batch, event, reportArgs, transformers...)
+		return
+	}
+
+	target := t.Status.SubscriberURI.String()
+	retryConfig := h.retryConfig(t, b)
+	// The event was already delivered to the other Triggers, so an event rejected by the limiter
+	// of the subscriber is handled like a failed delivery.
+	release, err := h.sender.Limiters.Acquire(ctx, target, retryConfig)
+	if err != nil {
+		h.logger.Error("failed to send event", zap.Error(err), zap.Any("target", target))
+		_ = h.reporter.ReportEventCount(reportArgs, http.StatusTooManyRequests)
+		h.sendToDeadLetterSink(ctx, headers, t, event, knativeErrorTransformers(t, http.StatusTooManyRequests, err.Error(), kncloudevents.DeliveryAttempts{})...)
+		return
+	}
+	var attempts kncloudevents.DeliveryAttempts
+	response, err := h.sendEvent(ctx, headers, target, event, reportArgs, retryConfig, &attempts, transformers...)
+	release()
+	if err == nil && (response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices) {
+		err = fmt.Errorf("unexpected HTTP response, expected 2xx, got %d", response.StatusCode)
+	}
+	if err != nil {
+		h.logger.Error("failed to send event", zap.Error(err), zap.Any("target", target))
+		statusCode := http.StatusInternalServerError
+		data := fmt.Sprintf("dispatch error: %s", err.Error())
+		if response != nil {
+			statusCode = response.StatusCode
+			body := make([]byte, attributes.KnativeErrorDataExtensionMaxLength)
+			n, _ := io.ReadFull(response.Body, body)
+			data = string(body[:n])
+			response.Body.Close()
+		}
+		_ = h.reporter.ReportEventCount(reportArgs, statusCode)
+		h.sendToDeadLetterSink(ctx, headers, t, event, knativeErrorTransformers(t, statusCode, data, attempts)...)
+		return
+	}
+
+	h.logger.Debug("Successfully dispatched message", zap.Any("target", target))
+
+	statusCode, err := h.replyToBroker(ctx, headers, response, ttl, b)
+	if err != nil {
+		h.logger.Error("failed to send the reply to the Broker", zap.Error(err), zap.Any("target", target))
+	}
+	_ = h.reporter.ReportEventCount(reportArgs, statusCode)
+}
+
+// retryConfig returns the retry configuration of the Trigger delivery spec, falling back to the
+// Broker one.
+func (h *Handler) retryConfig(t *eventingv1.Trigger, b *eventingv1.Broker) *kncloudevents.RetryConfig {
+	delivery := t.Spec.Delivery
+	if delivery == nil && b != nil {
+		delivery = b.Spec.Delivery
+	}
+	if delivery == nil {
+		return nil
+	}
+	retryConfig, err := kncloudevents.RetryConfigFromDeliverySpec(*delivery)
+	if err != nil {
+		// The delivery spec is validated by the webhook, so this should never happen.
+		h.logger.Warn("Failed to parse the delivery spec", zap.String("namespace", t.Namespace), zap.String("name", t.Name), zap.Error(err))
+		return nil
+	}
+	return &retryConfig
+}
+
+// sendToDeadLetterSink sends the event, with the transformers applied, to the dead letter sink of the Trigger.
+func (h *Handler) sendToDeadLetterSink(ctx context.Context, headers http.Header, t *eventingv1.Trigger, event *cloudevents.Event, transformers ...binding.Transformer) {
+	if t.Status.DeadLetterSinkURI == nil {
+		return
+	}
+	// The transformers rewrite an event message in place, while the event is shared with
+	// the other Triggers.
+	clone := event.Clone()
+	target := t.Status.DeadLetterSinkURI.String()
+	if err := h.sendMessage(ctx, headers, target, binding.ToMessage(&clone), transformers...); err != nil {
+		h.logger.Error("failed to send event to the dead letter sink", zap.Error(err), zap.Any("target", target))
+	}
+}
+
+// knativeErrorTransformers returns the Transformers adding the knative error extensions to the events
+// the Trigger failed to deliver.
+func knativeErrorTransformers(t *eventingv1.Trigger, statusCode int, data string, attempts kncloudevents.DeliveryAttempts) binding.Transformers {
+	// Unprintable control characters are not allowed in header values.
+	data = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, data)
+	firstFailure := attempts.FirstFailure
+	if firstFailure.IsZero() {
+		// The request failed before being sent.
+		firstFailure = time.Now()
+	}
+	transformers := attributes.KnativeErrorTransformers(*t.Status.SubscriberURI.URL(), statusCode, data)
+	return append(transformers, attributes.KnativeErrorDeliveryTransformers(attempts.Count, firstFailure, attributes.KnativeErrorKindTrigger, string(t.UID))...)
+}
+
+// replyToBroker sends the event in the response of a subscriber, if any, back to the Broker.
+// The returned status code is the one reported for the delivery.
+func (h *Handler) replyToBroker(ctx context.Context, headers http.Header, resp *http.Response, ttl int32, b *eventingv1.Broker) (int, error) {
+	response := cehttp.NewMessageFromHttpResponse(resp)
+	defer response.Finish(nil)
+
+	if response.ReadEncoding() == binding.EncodingUnknown {
+		// Like in writeResponse, a non-empty response that isn't a CloudEvent is a delivery failure.
+		body := make([]byte, 1)
+		n, _ := response.BodyReader.Read(body)
+		response.BodyReader.Close()
+		if n != 0 {
+			return http.StatusBadGateway, errors.New("received a non-empty response not recognized as CloudEvent. The response MUST be either empty or a valid CloudEvent")
+		}
+		return resp.StatusCode, nil
+	}
+
+	event, err := binding.ToEvent(ctx, response)
+	if err != nil {
+		return http.StatusBadGateway, err
+	}
+
+	// Reattach the TTL (with the same value) to the response event before sending it to the Broker.
+	if err := broker.SetTTL(event.Context, ttl); err != nil {
+		return http.StatusInternalServerError, fmt.Errorf("failed to reset TTL: %w", err)
+	}
+
+	if b.Status.Address.URL == nil {
+		return http.StatusInternalServerError, fmt.Errorf("broker %s/%s has no address", b.Namespace, b.Name)
+	}
+	if err := h.sendMessage(ctx, headers, b.Status.Address.URL.String(), binding.ToMessage(event)); err != nil {
+		return http.StatusInternalServerError, err
+	}
+	return resp.StatusCode, nil
+}
+
+// sendMessage sends the message to the target without retries, failing on non 2xx responses.
+func (h *Handler) sendMessage(ctx context.Context, headers http.Header, target string, message binding.Message, transformers ...binding.Transformer) error {
+	defer message.Finish(nil)
+
+	req, err := h.sender.NewCloudEventRequestWithTarget(ctx, target)
+	if err != nil {
+		return fmt.Errorf("failed to create the request: %w", err)
+	}
+	if err := kncloudevents.WriteHTTPRequestWithAdditionalHeaders(ctx, message, req, utils.PassThroughHeaders(headers), transformers...); err != nil {
+		return fmt.Errorf("failed to write request: %w", err)
+	}
+	resp, err := h.sender.Send(req)
+	if err != nil {
+		return fmt.Errorf("failed to dispatch message: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("unexpected HTTP response, expected 2xx, got %d", resp.StatusCode)
+	}
+	return nil
+}
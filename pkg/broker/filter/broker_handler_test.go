@@ -0,0 +1,496 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filter
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/event"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+	"knative.dev/pkg/apis"
+
+	eventingduckv1 "knative.dev/eventing/pkg/apis/duck/v1"
+	eventingv1 "knative.dev/eventing/pkg/apis/eventing/v1"
+	eventingv1beta1 "knative.dev/eventing/pkg/apis/eventing/v1beta1"
+	broker "knative.dev/eventing/pkg/broker"
+	reconcilertesting "knative.dev/eventing/pkg/reconciler/testing/v1"
+)
+
+var validBrokerPath = fmt.Sprintf("/brokers/%s/%s", testNS, indexBrokerName)
+
+func TestBrokerReceiver(t *testing.T) {
+	testCases := map[string]struct {
+		triggers []*eventingv1.Trigger
+		// subscribers are the behaviour of the subscriber of each Trigger, keyed by Trigger name
+		subscribers    map[string]subscriberBehaviour
+		brokerDelivery *eventingduckv1.DeliverySpec
+		noBroker       bool
+		eventTypes     []*eventingv1beta1.EventType
+		event          *cloudevents.Event
+		expectedStatus int
+		// expectedDispatches is the number of requests received by the subscriber of each Trigger
+		expectedDispatches map[string]int
+		expectedDLS        []string
+		expectedReplies    int
+		// expectedTypes is the type of the event received by the subscriber of each Trigger
+		expectedTypes map[string]string
+		// expectedDLSErrors is the knative error code, attempts, kind and UID of each dead letter
+		expectedDLSErrors map[string]string
+	}{
+		"Broker not found": {
+			triggers:       []*eventingv1.Trigger{makeIndexedTrigger("first", nil)},
+			noBroker:       true,
+			expectedStatus: http.StatusBadRequest,
+		},
+		"No TTL": {
+			triggers:       []*eventingv1.Trigger{makeIndexedTrigger("first", nil)},
+			event:          makeEventWithoutTTL(),
+			expectedStatus: http.StatusBadRequest,
+		},
+		"No Triggers": {
+			expectedStatus: http.StatusAccepted,
+		},
+		"Dispatch to the matching Triggers only": {
+			triggers: []*eventingv1.Trigger{
+				makeIndexedTrigger("no-filter", nil),
+				makeIndexedTrigger("type", makeTriggerFilterWithAttributes(eventType, "")),
+				makeIndexedTrigger("other-type", makeTriggerFilterWithAttributes("other-type", "")),
+				makeIndexedTrigger("sql", makeTriggerFilterWithSQL("source = 'other-source'")),
+				makeIndexedTrigger("extension", makeTriggerFilterWithAttributesAndExtension(eventType, eventSource, extensionValue)),
+			},
+			expectedStatus: http.StatusAccepted,
+			expectedDispatches: map[string]int{
+				"no-filter": 1,
+				"type":      1,
+			},
+		},
+		"Trigger without subscriber URI is skipped": {
+			triggers: []*eventingv1.Trigger{
+				makeIndexedTrigger("ready", nil),
+				withoutSubscriberURI(makeIndexedTrigger("not-ready", nil)),
+			},
+			expectedStatus: http.StatusAccepted,
+			expectedDispatches: map[string]int{
+				"ready": 1,
+			},
+		},
+		"Replies are sent to the Broker": {
+			triggers: []*eventingv1.Trigger{
+				makeIndexedTrigger("first", nil),
+				makeIndexedTrigger("second", nil),
+			},
+			subscribers: map[string]subscriberBehaviour{
+				"first": {reply: true},
+			},
+			expectedStatus: http.StatusAccepted,
+			expectedDispatches: map[string]int{
+				"first":  1,
+				"second": 1,
+			},
+			expectedReplies: 1,
+		},
+		"Failed deliveries are retried with the Trigger delivery spec": {
+			triggers: []*eventingv1.Trigger{
+				withDelivery(makeIndexedTrigger("first", nil), &eventingduckv1.DeliverySpec{Retry: pointer.Int32Ptr(2)}),
+			},
+			brokerDelivery: &eventingduckv1.DeliverySpec{Retry: pointer.Int32Ptr(1)},
+			subscribers: map[string]subscriberBehaviour{
+				"first": {failures: 2},
+			},
+			expectedStatus: http.StatusAccepted,
+			expectedDispatches: map[string]int{
+				"first": 3,
+			},
+		},
+		"Failed deliveries are retried with the Broker delivery spec": {
+			triggers: []*eventingv1.Trigger{
+				makeIndexedTrigger("first", nil),
+			},
+			brokerDelivery: &eventingduckv1.DeliverySpec{Retry: pointer.Int32Ptr(1)},
+			subscribers: map[string]subscriberBehaviour{
+				"first": {failures: 1},
+			},
+			expectedStatus: http.StatusAccepted,
+			expectedDispatches: map[string]int{
+				"first": 2,
+			},
+		},
+		"Events are transformed per Trigger": {
+			triggers: []*eventingv1.Trigger{
+				withTransform(makeIndexedTrigger("transformed", nil), &eventingv1.TriggerTransform{Type: "${type}.transformed"}),
+				makeIndexedTrigger("unchanged", nil),
+			},
+			expectedStatus: http.StatusAccepted,
+			expectedDispatches: map[string]int{
+				"transformed": 1,
+				"unchanged":   1,
+			},
+			expectedTypes: map[string]string{
+				"transformed": eventType + ".transformed",
+				"unchanged":   eventType,
+			},
+		},
+		"Events failing the transform go to the dead letter sink": {
+			triggers: []*eventingv1.Trigger{
+				withTransform(withDeadLetterSink(makeIndexedTrigger("first", nil)), &eventingv1.TriggerTransform{Type: "${missing}"}),
+			},
+			expectedStatus: http.StatusAccepted,
+			expectedDLS:    []string{"first"},
+			expectedDLSErrors: map[string]string{
+				"first": "500 0 Trigger/first",
+			},
+		},
+		"Events failing the schema validation go to the dead letter sink": {
+			triggers: []*eventingv1.Trigger{
+				withDeadLetterSink(withIndexedSchemaValidation(makeIndexedTrigger("first", nil))),
+				makeIndexedTrigger("second", nil),
+			},
+			eventTypes: []*eventingv1beta1.EventType{
+				reconcilertesting.NewEventType("test-eventtype", testNS,
+					reconcilertesting.WithEventTypeType(eventType),
+					reconcilertesting.WithEventTypeBroker(indexBrokerName),
+					reconcilertesting.WithEventTypeSchemaData(`{"required": ["order"]}`)),
+			},
+			event:          makeEventWithData(`{"region": "eu-west"}`),
+			expectedStatus: http.StatusAccepted,
+			expectedDispatches: map[string]int{
+				"second": 1,
+			},
+			expectedDLS: []string{"first"},
+			expectedDLSErrors: map[string]string{
+				"first": "400 0 Trigger/first",
+			},
+		},
+		"Undeliverable events go to the dead letter sink": {
+			triggers: []*eventingv1.Trigger{
+				withDeadLetterSink(makeIndexedTrigger("first", nil)),
+				makeIndexedTrigger("second", nil),
+			},
+			subscribers: map[string]subscriberBehaviour{
+				"first":  {failures: 1},
+				"second": {failures: 1},
+			},
+			expectedStatus: http.StatusAccepted,
+			expectedDispatches: map[string]int{
+				"first":  1,
+				"second": 1,
+			},
+			expectedDLS: []string{"first"},
+			expectedDLSErrors: map[string]string{
+				"first": "503 1 Trigger/first",
+			},
+		},
+		"Dead letters carry the delivery attempts": {
+			triggers: []*eventingv1.Trigger{
+				withDeadLetterSink(withDelivery(makeIndexedTrigger("first", nil), &eventingduckv1.DeliverySpec{Retry: pointer.Int32Ptr(2)})),
+			},
+			subscribers: map[string]subscriberBehaviour{
+				"first": {failures: 3},
+			},
+			expectedStatus: http.StatusAccepted,
+			expectedDispatches: map[string]int{
+				"first": 3,
+			},
+			expectedDLS: []string{"first"},
+			expectedDLSErrors: map[string]string{
+				"first": "503 3 Trigger/first",
+			},
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			recorder := newRequestRecorder(t, tc.subscribers)
+			s := httptest.NewServer(recorder)
+			defer s.Close()
+
+			objs := make([]runtime.Object, 0, len(tc.triggers)+len(tc.eventTypes)+1)
+			for _, et := range tc.eventTypes {
+				objs = append(objs, et)
+			}
+			for _, trig := range tc.triggers {
+				if trig.Status.SubscriberURI != nil {
+					trig.Status.SubscriberURI = apis.HTTP(s.Listener.Addr().String())
+					trig.Status.SubscriberURI.Path = "/subscribers/" + trig.Name
+				}
+				if trig.Status.DeadLetterSinkURI != nil {
+					trig.Status.DeadLetterSinkURI = apis.HTTP(s.Listener.Addr().String())
+					trig.Status.DeadLetterSinkURI.Path = "/dls/" + trig.Name
+				}
+				objs = append(objs, trig)
+			}
+			if !tc.noBroker {
+				brokerAddress := apis.HTTP(s.Listener.Addr().String())
+				brokerAddress.Path = "/broker"
+				objs = append(objs, reconcilertesting.NewBroker(indexBrokerName, testNS,
+					reconcilertesting.WithBrokerAddressURI(brokerAddress),
+					func(b *eventingv1.Broker) { b.Spec.Delivery = tc.brokerDelivery }))
+			}
+
+			listers := reconcilertesting.NewListers(objs)
+			h, err := NewHandler(
+				zaptest.NewLogger(t, zaptest.WrapOptions(zap.AddCaller())),
+				listers.GetTriggerLister(),
+				listers.GetBrokerLister(),
+				listers.GetEventTypeIndexer(broker.EventTypeIndexers),
+				&mockReporter{},
+				8080)
+			if err != nil {
+				t.Fatal("Unable to create receiver:", err)
+			}
+
+			e := tc.event
+			if e == nil {
+				e = makeEvent()
+			}
+			b, err := e.MarshalJSON()
+			if err != nil {
+				t.Fatal(err)
+			}
+			request := httptest.NewRequest(http.MethodPost, validBrokerPath, bytes.NewBuffer(b))
+			request.Header.Set(cehttp.ContentType, event.ApplicationCloudEventsJSON)
+
+			responseWriter := httptest.NewRecorder()
+			h.ServeHTTP(responseWriter, request)
+
+			if got := responseWriter.Result().StatusCode; got != tc.expectedStatus {
+				t.Errorf("Unexpected status. Expected %v. Actual %v.", tc.expectedStatus, got)
+			}
+			if diff := cmp.Diff(tc.expectedDispatches, recorder.dispatches); diff != "" {
+				t.Error("Unexpected dispatches (-want, +got) =", diff)
+			}
+			if diff := cmp.Diff(tc.expectedDLS, recorder.deadLetters); diff != "" {
+				t.Error("Unexpected dead letters (-want, +got) =", diff)
+			}
+			if tc.expectedDLSErrors != nil {
+				if diff := cmp.Diff(tc.expectedDLSErrors, recorder.deadLetterErrors); diff != "" {
+					t.Error("Unexpected dead letter errors (-want, +got) =", diff)
+				}
+			}
+			if tc.expectedTypes != nil {
+				if diff := cmp.Diff(tc.expectedTypes, recorder.types); diff != "" {
+					t.Error("Unexpected event types (-want, +got) =", diff)
+				}
+			}
+			if got := len(recorder.replies); got != tc.expectedReplies {
+				t.Errorf("Unexpected replies. Expected %v. Actual %v.", tc.expectedReplies, got)
+			}
+			for _, reply := range recorder.replies {
+				if ttl, err := broker.GetTTL(reply.Context); err != nil || ttl != 1 {
+					t.Errorf("Expected the reply to have the TTL reattached, got %v, %v", ttl, err)
+				}
+			}
+		})
+	}
+}
+
+func TestBrokerReceiverQueue(t *testing.T) {
+	// The subscriber blocks until released.
+	release := make(chan struct{})
+	var received int32
+	s := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&received, 1)
+		<-release
+		writer.WriteHeader(http.StatusAccepted)
+	}))
+	defer s.Close()
+
+	first := makeIndexedTrigger("first", nil)
+	first.Status.SubscriberURI = apis.HTTP(s.Listener.Addr().String())
+	second := makeIndexedTrigger("second", nil)
+	second.Status.SubscriberURI = apis.HTTP(s.Listener.Addr().String())
+	listers := reconcilertesting.NewListers([]runtime.Object{
+		first,
+		second,
+		reconcilertesting.NewBroker(indexBrokerName, testNS, reconcilertesting.WithBrokerAddressURI(apis.HTTP("broker.example.com"))),
+	})
+	h, err := NewHandler(
+		zaptest.NewLogger(t, zaptest.WrapOptions(zap.AddCaller())),
+		listers.GetTriggerLister(),
+		listers.GetBrokerLister(),
+		listers.GetEventTypeIndexer(broker.EventTypeIndexers),
+		&mockReporter{},
+		8080)
+	if err != nil {
+		t.Fatal("Unable to create receiver:", err)
+	}
+	// The event matches more Triggers than the queue size.
+	h.deliveries = newDispatchQueue(1, 1)
+
+	serve := func() int {
+		b, err := makeEvent().MarshalJSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+		request := httptest.NewRequest(http.MethodPost, validBrokerPath, bytes.NewBuffer(b))
+		request.Header.Set(cehttp.ContentType, event.ApplicationCloudEventsJSON)
+		responseWriter := httptest.NewRecorder()
+		h.ServeHTTP(responseWriter, request)
+		return responseWriter.Result().StatusCode
+	}
+
+	statusCh := make(chan int)
+	go func() {
+		statusCh <- serve()
+	}()
+	for atomic.LoadInt32(&received) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	// The event isn't acknowledged before being delivered.
+	select {
+	case got := <-statusCh:
+		t.Fatalf("Unexpected status %v before the deliveries are done.", got)
+	case <-time.After(10 * time.Millisecond):
+	}
+	// The queue is full, the channel redelivers the event later.
+	if got := serve(); got != http.StatusTooManyRequests {
+		t.Errorf("Unexpected status. Expected %v. Actual %v.", http.StatusTooManyRequests, got)
+	}
+
+	// The deliveries are queued in parts, the event is acknowledged once all of them are done.
+	close(release)
+	if got := <-statusCh; got != http.StatusAccepted {
+		t.Errorf("Unexpected status. Expected %v. Actual %v.", http.StatusAccepted, got)
+	}
+	if got := atomic.LoadInt32(&received); got != 2 {
+		t.Errorf("Unexpected dispatches. Expected 2. Actual %v.", got)
+	}
+}
+
+type subscriberBehaviour struct {
+	// failures is the number of requests failing before the subscriber accepts the event
+	failures int
+	// reply makes the subscriber reply with an event
+	reply bool
+}
+
+// requestRecorder plays the subscribers, the dead letter sinks and the Broker ingress, recording
+// the requests they receive.
+type requestRecorder struct {
+	t           *testing.T
+	subscribers map[string]subscriberBehaviour
+
+	mutex       sync.Mutex
+	dispatches  map[string]int
+	types       map[string]string
+	deadLetters []string
+	// deadLetterErrors holds the knative error code, attempts, kind and UID of the dead letters.
+	deadLetterErrors map[string]string
+	replies          []*cloudevents.Event
+}
+
+func newRequestRecorder(t *testing.T, subscribers map[string]subscriberBehaviour) *requestRecorder {
+	return &requestRecorder{t: t, subscribers: subscribers}
+}
+
+func (r *requestRecorder) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	parts := strings.SplitN(strings.TrimPrefix(request.URL.Path, "/"), "/", 2)
+	kind, name := parts[0], ""
+	if len(parts) == 2 {
+		name = parts[1]
+	}
+
+	switch kind {
+	case "subscribers":
+		if r.dispatches == nil {
+			r.dispatches = make(map[string]int)
+		}
+		r.dispatches[name]++
+		if r.types == nil {
+			r.types = make(map[string]string)
+		}
+		r.types[name] = request.Header.Get("Ce-Type")
+		behaviour := r.subscribers[name]
+		if r.dispatches[name] <= behaviour.failures {
+			writer.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if behaviour.reply {
+			reply := makeDifferentEvent()
+			_ = broker.DeleteTTL(reply.Context)
+			message := binding.ToMessage(reply)
+			defer message.Finish(nil)
+			if err := cehttp.WriteResponseWriter(context.Background(), message, http.StatusOK, writer); err != nil {
+				r.t.Error("Unable to write the reply:", err)
+			}
+			return
+		}
+		writer.WriteHeader(http.StatusAccepted)
+	case "dls":
+		r.deadLetters = append(r.deadLetters, name)
+		if r.deadLetterErrors == nil {
+			r.deadLetterErrors = make(map[string]string)
+		}
+		r.deadLetterErrors[name] = fmt.Sprintf("%s %s %s/%s",
+			request.Header.Get("Ce-Knativeerrorcode"),
+			request.Header.Get("Ce-Knativeerrorattempts"),
+			request.Header.Get("Ce-Knativeerrorkind"),
+			request.Header.Get("Ce-Knativeerroruid"))
+		if _, err := time.Parse(time.RFC3339Nano, request.Header.Get("Ce-Knativeerrortime")); err != nil {
+			r.t.Error("Unexpected knativeerrortime:", err)
+		}
+		writer.WriteHeader(http.StatusAccepted)
+	case "broker":
+		e, err := binding.ToEvent(context.Background(), cehttp.NewMessageFromHttpRequest(request))
+		if err != nil {
+			r.t.Error("Unable to read the reply:", err)
+		}
+		r.replies = append(r.replies, e)
+		writer.WriteHeader(http.StatusAccepted)
+	default:
+		r.t.Error("Unexpected request to", request.URL.Path)
+		writer.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func withoutSubscriberURI(t *eventingv1.Trigger) *eventingv1.Trigger {
+	t.Status.SubscriberURI = nil
+	return t
+}
+
+func withDeadLetterSink(t *eventingv1.Trigger) *eventingv1.Trigger {
+	t.Status.DeadLetterSinkURI = apis.HTTP("dls.example.com")
+	return t
+}
+
+func withIndexedSchemaValidation(t *eventingv1.Trigger) *eventingv1.Trigger {
+	t.Annotations = map[string]string{eventingv1.SchemaValidationAnnotation: "enabled"}
+	return t
+}
+
+func withDelivery(t *eventingv1.Trigger, delivery *eventingduckv1.DeliverySpec) *eventingv1.Trigger {
+	t.Spec.Delivery = delivery
+	return t
+}
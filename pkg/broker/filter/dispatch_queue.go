@@ -0,0 +1,96 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filter
+
+import (
+	"sync"
+)
+
+const (
+	// dispatchWorkers is the maximum number of Trigger deliveries running at once in the broker
+	// dispatch mode.
+	dispatchWorkers = 1000
+	// dispatchQueueSize is the maximum number of Trigger deliveries accepted in the broker
+	// dispatch mode, pending or running.
+	dispatchQueueSize = 10000
+)
+
+// dispatchQueue runs the Trigger deliveries of the events accepted by the Brokers in the broker
+// dispatch mode, with a bounded number of workers.
+type dispatchQueue struct {
+	workers int
+	size    int
+
+	mutex sync.Mutex
+	tasks []func()
+	// pending is the number of tasks not done yet.
+	pending int
+	running int
+	wg      sync.WaitGroup
+}
+
+func newDispatchQueue(workers, size int) *dispatchQueue {
+	return &dispatchQueue{workers: workers, size: size}
+}
+
+// submit queues all the tasks, or none of them when the queue doesn't have room for all of them.
+// More tasks than the queue size are never accepted, they must be submitted in parts.
+func (q *dispatchQueue) submit(tasks []func()) bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if q.pending+len(tasks) > q.size {
+		return false
+	}
+	q.pending += len(tasks)
+	q.wg.Add(len(tasks))
+	q.tasks = append(q.tasks, tasks...)
+	// The workers busy with other tasks don't take the new ones.
+	for i := 0; i < len(tasks) && q.running < q.workers; i++ {
+		q.running++
+		go q.work()
+	}
+	return true
+}
+
+// work runs the queued tasks until the queue is empty.
+func (q *dispatchQueue) work() {
+	for {
+		q.mutex.Lock()
+		if len(q.tasks) == 0 {
+			q.running--
+			q.mutex.Unlock()
+			return
+		}
+		task := q.tasks[0]
+		q.tasks[0] = nil
+		q.tasks = q.tasks[1:]
+		q.mutex.Unlock()
+
+		task()
+
+		q.mutex.Lock()
+		q.pending--
+		q.mutex.Unlock()
+		q.wg.Done()
+	}
+}
+
+// wait blocks until the tasks submitted are done.
+func (q *dispatchQueue) wait() {
+	q.wg.Wait()
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filter
+
+import (
+	"sync/atomic"
+	"testing"
+)
+
+func TestDispatchQueue(t *testing.T) {
+	q := newDispatchQueue(2, 4)
+
+	release := make(chan struct{})
+	var running, maxRunning, done int32
+	task := func() {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			max := atomic.LoadInt32(&maxRunning)
+			if n <= max || atomic.CompareAndSwapInt32(&maxRunning, max, n) {
+				break
+			}
+		}
+		<-release
+		atomic.AddInt32(&running, -1)
+		atomic.AddInt32(&done, 1)
+	}
+	tasks := func(n int) []func() {
+		l := make([]func(), n)
+		for i := range l {
+			l[i] = task
+		}
+		return l
+	}
+
+	if !q.submit(tasks(3)) {
+		t.Fatal("Expected the tasks to be queued")
+	}
+	// The tasks are queued all or none.
+	if q.submit(tasks(2)) {
+		t.Fatal("Expected the tasks to be rejected by the full queue")
+	}
+	if !q.submit(tasks(1)) {
+		t.Fatal("Expected the tasks to be queued")
+	}
+	close(release)
+	q.wait()
+	if got := atomic.LoadInt32(&done); got != 4 {
+		t.Errorf("Unexpected tasks done. Expected 4. Actual %v.", got)
+	}
+	if got := atomic.LoadInt32(&maxRunning); got > 2 {
+		t.Errorf("Unexpected tasks running at once. Expected at most 2. Actual %v.", got)
+	}
+
+	// Even an empty queue doesn't accept more tasks than its size.
+	if q.submit(tasks(5)) {
+		t.Fatal("Expected more tasks than the queue size to be rejected")
+	}
+	if !q.submit(tasks(4)) {
+		t.Fatal("Expected the tasks to be queued by the empty queue")
+	}
+	q.wait()
+	if got := atomic.LoadInt32(&done); got != 8 {
+		t.Errorf("Unexpected tasks done. Expected 8. Actual %v.", got)
+	}
+
+	// A slow task doesn't hold up the next ones while workers are available.
+	slow := make(chan struct{})
+	fast := make(chan struct{})
+	q.submit([]func(){func() { <-slow }})
+	q.submit([]func(){func() { close(fast) }})
+	<-fast
+	close(slow)
+	q.wait()
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filter
+
+import (
+	"sync"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"k8s.io/apimachinery/pkg/types"
+
+	eventingv1 "knative.dev/eventing/pkg/apis/eventing/v1"
+	"knative.dev/eventing/pkg/eventfilter"
+	"knative.dev/eventing/pkg/kncloudevents"
+)
+
+type filterCacheEntry struct {
+	generation   int64
+	broker       types.NamespacedName
+	filter       eventfilter.Filter
+	transformers []binding.Transformer
+	limits       *kncloudevents.RetryConfig
+	err          error
+}
+
+// filterCache holds the materialized filter, transformers and limits of each Trigger, so that SQL
+// expressions, JSONPath predicates and transform templates are parsed, and the Broker looked up,
+// once per Trigger generation instead of once per event.
+type filterCache struct {
+	mutex   sync.RWMutex
+	entries map[types.UID]filterCacheEntry
+	// limits resolves the limits of a Trigger, there are none when it's nil.
+	limits func(t *eventingv1.Trigger) *kncloudevents.RetryConfig
+}
+
+func newFilterCache(limits func(t *eventingv1.Trigger) *kncloudevents.RetryConfig) *filterCache {
+	return &filterCache{entries: make(map[types.UID]filterCacheEntry), limits: limits}
+}
+
+// get returns the materialized filter of the Trigger, building it if the Trigger
+// isn't in the cache yet or its generation changed.
+func (c *filterCache) get(t *eventingv1.Trigger) (eventfilter.Filter, error) {
+	entry := c.entry(t)
+	return entry.filter, entry.err
+}
+
+// entry returns the cache entry of the Trigger, building its filter, transformers and limits if the
+// Trigger isn't in the cache yet or its generation changed. The entry holds the error when
+// either of them can't be built.
+func (c *filterCache) entry(t *eventingv1.Trigger) filterCacheEntry {
+	c.mutex.RLock()
+	entry, ok := c.entries[t.UID]
+	c.mutex.RUnlock()
+	if ok && entry.generation == t.Generation {
+		return entry
+	}
+
+	entry = filterCacheEntry{
+		generation: t.Generation,
+		broker:     types.NamespacedName{Namespace: t.Namespace, Name: t.Spec.Broker},
+	}
+	if entry.filter, entry.err = materializeTriggerFilter(t.Spec.Filter); entry.err == nil {
+		entry.transformers, entry.err = triggerTransformers(t)
+	}
+	if c.limits != nil {
+		entry.limits = c.limits(t)
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	// Don't replace an entry built by a concurrent call for a newer generation.
+	if current, ok := c.entries[t.UID]; !ok || current.generation <= t.Generation {
+		c.entries[t.UID] = entry
+	}
+	return entry
+}
+
+// delete removes the materialized filter of the Trigger with the given UID.
+func (c *filterCache) delete(uid types.UID) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	delete(c.entries, uid)
+}
+
+// invalidateBroker removes the entries of the Triggers of the given Broker, whose limits may
+// come from the Broker.
+func (c *filterCache) invalidateBroker(ref types.NamespacedName) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for uid, entry := range c.entries {
+		if entry.broker == ref {
+			delete(c.entries, uid)
+		}
+	}
+}
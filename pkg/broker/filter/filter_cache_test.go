@@ -0,0 +1,155 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filter
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+
+	eventingv1 "knative.dev/eventing/pkg/apis/eventing/v1"
+	"knative.dev/eventing/pkg/eventfilter"
+	"knative.dev/eventing/pkg/kncloudevents"
+)
+
+func TestFilterCache(t *testing.T) {
+	c := newFilterCache(nil)
+	trigger := makeTrigger(makeTriggerFilterWithSQL("type = 'com.example.someevent'"))
+	trigger.Generation = 1
+	event := makeEvent()
+
+	first, err := c.get(trigger)
+	if err != nil {
+		t.Fatal("get() =", err)
+	}
+	if got := first.Filter(context.TODO(), *event); got != eventfilter.PassFilter {
+		t.Errorf("Filter() = %v, want %v", got, eventfilter.PassFilter)
+	}
+
+	// Changing the spec without bumping the generation keeps the cached filter.
+	trigger.Spec.Filter = makeTriggerFilterWithSQL("type = 'some-other-type'")
+	cached, err := c.get(trigger)
+	if err != nil {
+		t.Fatal("get() =", err)
+	}
+	if got := cached.Filter(context.TODO(), *event); got != eventfilter.PassFilter {
+		t.Errorf("Filter() = %v, want %v", got, eventfilter.PassFilter)
+	}
+
+	trigger.Generation = 2
+	updated, err := c.get(trigger)
+	if err != nil {
+		t.Fatal("get() =", err)
+	}
+	if got := updated.Filter(context.TODO(), *event); got != eventfilter.FailFilter {
+		t.Errorf("Filter() = %v, want %v", got, eventfilter.FailFilter)
+	}
+
+	trigger.Generation = 3
+	trigger.Spec.Filter = makeTriggerFilterWithSQL("type LIKE")
+	if _, err := c.get(trigger); err == nil {
+		t.Error("Expected an error for an invalid SQL expression")
+	}
+
+	c.delete(trigger.UID)
+	if len(c.entries) != 0 {
+		t.Errorf("Expected the cache to be empty, got %d entries", len(c.entries))
+	}
+}
+
+func TestFilterCacheTransformers(t *testing.T) {
+	c := newFilterCache(nil)
+	trigger := withTransform(makeTrigger(nil), &eventingv1.TriggerTransform{Type: "${type}.v2"})
+	trigger.Generation = 1
+
+	first := c.entry(trigger)
+	if first.err != nil {
+		t.Fatal("entry() =", first.err)
+	}
+	if len(first.transformers) != 1 {
+		t.Fatalf("Expected 1 transformer, got %d", len(first.transformers))
+	}
+	if cached := c.entry(trigger); cached.transformers[0] != first.transformers[0] {
+		t.Error("Expected the transformers to be built once per generation")
+	}
+
+	// The transform which can't be built fails the entry, until the next generation.
+	trigger.Generation = 2
+	trigger.Spec.Transform = &eventingv1.TriggerTransform{Type: "${type"}
+	failed := c.entry(trigger)
+	if failed.err == nil {
+		t.Fatal("Expected an error for an unterminated reference")
+	}
+	if _, err := c.get(trigger); err != failed.err {
+		t.Errorf("Expected the cached error, got %v", err)
+	}
+}
+
+func TestHandlerDeleteTriggerFilter(t *testing.T) {
+	h := &Handler{filters: newFilterCache(nil), triggerIndexes: newTriggerIndexCache(nil), batchers: newTriggerBatchers()}
+	trigger := makeTrigger(makeTriggerFilterWithAttributes(eventType, eventSource))
+	if _, err := h.filters.get(trigger); err != nil {
+		t.Fatal("get() =", err)
+	}
+	other := makeTriggerWithDifferentUID(nil)
+	if _, err := h.filters.get(other); err != nil {
+		t.Fatal("get() =", err)
+	}
+
+	h.DeleteTriggerFilter(cache.DeletedFinalStateUnknown{Key: "ns/name", Obj: trigger})
+	h.DeleteTriggerFilter(&eventingv1.Trigger{})
+	h.DeleteTriggerFilter(nil)
+
+	if _, ok := h.filters.entries[trigger.UID]; ok {
+		t.Error("Expected the filter of the deleted Trigger to be evicted")
+	}
+	if _, ok := h.filters.entries[types.UID("wrongone")]; !ok {
+		t.Error("Expected the filter of the other Trigger to be kept")
+	}
+}
+
+func TestHandlerBrokerLimits(t *testing.T) {
+	resolved := 0
+	h := &Handler{filters: newFilterCache(func(*eventingv1.Trigger) *kncloudevents.RetryConfig {
+		resolved++
+		return &kncloudevents.RetryConfig{MaxInFlight: resolved}
+	})}
+	trigger := makeTrigger(nil)
+	trigger.Spec.Broker = "default"
+
+	if limits := h.filters.entry(trigger).limits; limits == nil || limits.MaxInFlight != 1 {
+		t.Fatalf("Expected the resolved limits, got %+v", limits)
+	}
+	h.filters.entry(trigger)
+	if resolved != 1 {
+		t.Errorf("Expected the limits to be resolved once, got %d", resolved)
+	}
+
+	// Only the changes of the Broker of the Trigger resolve its limits again.
+	h.BrokerEventHandler().OnUpdate(nil, &eventingv1.Broker{ObjectMeta: metav1.ObjectMeta{Namespace: testNS, Name: "other"}})
+	h.filters.entry(trigger)
+	if resolved != 1 {
+		t.Errorf("Expected the limits to be kept, resolved %d times", resolved)
+	}
+	h.BrokerEventHandler().OnUpdate(nil, &eventingv1.Broker{ObjectMeta: metav1.ObjectMeta{Namespace: testNS, Name: "default"}})
+	if limits := h.filters.entry(trigger).limits; limits == nil || limits.MaxInFlight != 2 {
+		t.Errorf("Expected the limits to be resolved again, got %+v", limits)
+	}
+}
@@ -30,13 +30,21 @@ import (
 	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
 	"go.opencensus.io/trace"
 	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/pkg/kmeta"
 	"knative.dev/pkg/logging"
 
+	eventingduckv1 "knative.dev/eventing/pkg/apis/duck/v1"
 	eventingv1 "knative.dev/eventing/pkg/apis/eventing/v1"
 	broker "knative.dev/eventing/pkg/broker"
 	eventinglisters "knative.dev/eventing/pkg/client/listers/eventing/v1"
 	"knative.dev/eventing/pkg/eventfilter"
 	"knative.dev/eventing/pkg/eventfilter/attributes"
+	"knative.dev/eventing/pkg/eventfilter/cesql"
+	"knative.dev/eventing/pkg/eventfilter/jsonpath"
+	"knative.dev/eventing/pkg/eventfilter/subscriptionsapi"
+	"knative.dev/eventing/pkg/eventtransform"
 	"knative.dev/eventing/pkg/kncloudevents"
 	"knative.dev/eventing/pkg/reconciler/sugar/trigger/path"
 	"knative.dev/eventing/pkg/tracing"
@@ -64,12 +72,25 @@ type Handler struct {
 	reporter StatsReporter
 
 	triggerLister eventinglisters.TriggerLister
-	logger        *zap.Logger
+	brokerLister  eventinglisters.BrokerLister
+	// eventTypeIndexer looks up the EventTypes of the events by Broker and type
+	eventTypeIndexer cache.Indexer
+	// filters caches the materialized filter of each Trigger
+	filters *filterCache
+	// triggerIndexes caches the Triggers of each Broker indexed by type and source
+	triggerIndexes *triggerIndexCache
+	// batchers accumulates the events of the batched Triggers in the broker dispatch mode
+	batchers *triggerBatchers
+	// deliveries runs the Trigger deliveries in the broker dispatch mode
+	deliveries *dispatchQueue
+	// schemas caches the compiled JSON schema of each EventType
+	schemas *broker.SchemaCache
+	logger  *zap.Logger
 }
 
 // NewHandler creates a new Handler and its associated MessageReceiver. The caller is responsible for
 // Start()ing the returned Handler.
-func NewHandler(logger *zap.Logger, triggerLister eventinglisters.TriggerLister, reporter StatsReporter, port int) (*Handler, error) {
+func NewHandler(logger *zap.Logger, triggerLister eventinglisters.TriggerLister, brokerLister eventinglisters.BrokerLister, eventTypeIndexer cache.Indexer, reporter StatsReporter, port int) (*Handler, error) {
 	kncloudevents.ConfigureConnectionArgs(&kncloudevents.ConnectionArgs{
 		MaxIdleConns:        defaultMaxIdleConnections,
 		MaxIdleConnsPerHost: defaultMaxIdleConnectionsPerHost,
@@ -80,18 +101,88 @@ func NewHandler(logger *zap.Logger, triggerLister eventinglisters.TriggerLister,
 		return nil, fmt.Errorf("failed to create message sender: %w", err)
 	}
 
-	return &Handler{
-		receiver:      kncloudevents.NewHTTPMessageReceiver(port),
-		sender:        sender,
-		reporter:      reporter,
-		triggerLister: triggerLister,
-		logger:        logger,
-	}, nil
+	h := &Handler{
+		receiver:         kncloudevents.NewHTTPMessageReceiver(port),
+		sender:           sender,
+		reporter:         reporter,
+		triggerLister:    triggerLister,
+		brokerLister:     brokerLister,
+		eventTypeIndexer: eventTypeIndexer,
+		triggerIndexes:   newTriggerIndexCache(triggerLister),
+		batchers:         newTriggerBatchers(),
+		deliveries:       newDispatchQueue(dispatchWorkers, dispatchQueueSize),
+		schemas:          broker.NewSchemaCache(),
+		logger:           logger,
+	}
+	h.filters = newFilterCache(h.limits)
+	return h, nil
+}
+
+// BrokerEventHandler returns the event handler evicting the cached limits of the Triggers of the
+// changed Brokers, to be added to a Broker informer.
+func (h *Handler) BrokerEventHandler() cache.ResourceEventHandler {
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: h.invalidateBrokerLimits,
+		UpdateFunc: func(_, obj interface{}) {
+			h.invalidateBrokerLimits(obj)
+		},
+		DeleteFunc: h.invalidateBrokerLimits,
+	}
+}
+
+// invalidateBrokerLimits drops the cached entries of the Triggers of the Broker.
+func (h *Handler) invalidateBrokerLimits(obj interface{}) {
+	acc, err := kmeta.DeletionHandlingAccessor(obj)
+	if err != nil {
+		return
+	}
+	h.filters.invalidateBroker(types.NamespacedName{Namespace: acc.GetNamespace(), Name: acc.GetName()})
+}
+
+// EventTypeEventHandler returns the event handler evicting the compiled JSON schemas of the
+// deleted EventTypes, to be added to an EventType informer.
+func (h *Handler) EventTypeEventHandler() cache.ResourceEventHandler {
+	return h.schemas.EventTypeEventHandler()
+}
+
+// TriggerEventHandler returns the event handler keeping the cached filters and
+// Trigger indexes in sync with a Trigger informer.
+func (h *Handler) TriggerEventHandler() cache.ResourceEventHandler {
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: h.invalidateTriggerIndex,
+		UpdateFunc: func(_, obj interface{}) {
+			h.invalidateTriggerIndex(obj)
+		},
+		DeleteFunc: h.DeleteTriggerFilter,
+	}
+}
+
+// DeleteTriggerFilter evicts the cached filter of a deleted Trigger, and sends its pending batch.
+// It is meant to be used as the DeleteFunc of a Trigger informer event handler.
+func (h *Handler) DeleteTriggerFilter(obj interface{}) {
+	h.invalidateTriggerIndex(obj)
+	acc, err := kmeta.DeletionHandlingAccessor(obj)
+	if err != nil {
+		return
+	}
+	h.filters.delete(acc.GetUID())
+	h.batchers.delete(acc.GetUID())
+}
+
+// invalidateTriggerIndex drops the Trigger index of the Broker the Trigger belongs to.
+func (h *Handler) invalidateTriggerIndex(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	if t, ok := obj.(*eventingv1.Trigger); ok {
+		h.triggerIndexes.invalidate(types.NamespacedName{Namespace: t.Namespace, Name: t.Spec.Broker})
+	}
 }
 
 // Start begins to receive messages for the handler.
 //
-// HTTP POST requests to the root path (/) are accepted.
+// HTTP POST requests to the Trigger paths (/triggers/namespace/name/uid) and to the
+// Broker paths (/brokers/namespace/name) are accepted.
 //
 // This method will block until ctx is done.
 func (h *Handler) Start(ctx context.Context) error {
@@ -112,6 +203,11 @@ func (h *Handler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
 		return
 	}
 
+	if brokerRef, err := path.ParseBroker(request.RequestURI); err == nil {
+		h.serveBroker(writer, request, brokerRef)
+		return
+	}
+
 	triggerRef, err := path.Parse(request.RequestURI)
 	if err != nil {
 		h.logger.Info("Unable to parse path as trigger", zap.Error(err), zap.String("path", request.RequestURI))
@@ -119,6 +215,11 @@ func (h *Handler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
 		return
 	}
 
+	if kncloudevents.IsBatch(request.Header) {
+		h.serveTriggerBatch(writer, request, triggerRef)
+		return
+	}
+
 	ctx := request.Context()
 
 	message := cehttp.NewMessageFromHttpRequest(request)
@@ -161,31 +262,15 @@ func (h *Handler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
 
 	h.logger.Debug("Received message", zap.Any("triggerRef", triggerRef))
 
-	t, err := h.getTrigger(triggerRef)
-	if err != nil {
-		h.logger.Info("Unable to get the Trigger", zap.Error(err), zap.Any("triggerRef", triggerRef))
-		writer.WriteHeader(http.StatusBadRequest)
+	t, reportArgs, entry, ok := h.lookupTrigger(writer, triggerRef)
+	if !ok {
 		return
 	}
-
-	reportArgs := &ReportArgs{
-		ns:         t.Namespace,
-		trigger:    t.Name,
-		broker:     t.Spec.Broker,
-		filterType: triggerFilterAttribute(t.Spec.Filter, "type"),
-	}
-
 	subscriberURI := t.Status.SubscriberURI
-	if subscriberURI == nil {
-		// Record the event count.
-		writer.WriteHeader(http.StatusBadRequest)
-		_ = h.reporter.ReportEventCount(reportArgs, http.StatusBadRequest)
-		return
-	}
 
 	// Check if the event should be sent.
 	ctx = logging.WithLogger(ctx, h.logger.Sugar())
-	filterResult := filterEvent(ctx, t.Spec.Filter, *event)
+	filterResult := entry.filter.Filter(ctx, *event)
 
 	if filterResult == eventfilter.FailFilter {
 		// We do not count the event. The event will be counted in the broker ingress.
@@ -195,12 +280,85 @@ func (h *Handler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
 
 	h.reportArrivalTime(event, reportArgs)
 
-	h.send(ctx, writer, request.Header, subscriberURI.String(), reportArgs, event, ttl)
+	if err := h.validateSchema(t, event); err != nil {
+		// Like for the failed deliveries, the channel sends the event to the dead letter sink
+		// of the Trigger, with the error in the knativeerrordata extension.
+		h.logger.Debug("The event data doesn't match its schema", zap.Any("triggerRef", triggerRef), zap.Error(err))
+		writer.WriteHeader(http.StatusBadRequest)
+		_, _ = writer.Write([]byte(err.Error()))
+		_ = h.reporter.ReportEventCount(reportArgs, http.StatusBadRequest)
+		return
+	}
+
+	// Wait for the limiter of the subscriber, the events beyond its limits are rejected so that
+	// the channel retries them later.
+	release, err := h.sender.Limiters.Acquire(ctx, subscriberURI.String(), entry.limits)
+	if err != nil {
+		h.logger.Warn("Rejecting the event", zap.Any("triggerRef", triggerRef), zap.Error(err))
+		writer.WriteHeader(http.StatusTooManyRequests)
+		_ = h.reporter.ReportEventCount(reportArgs, http.StatusTooManyRequests)
+		return
+	}
+	defer release()
+
+	h.send(ctx, writer, request.Header, subscriberURI.String(), reportArgs, event, ttl, entry.transformers...)
 }
 
-func (h *Handler) send(ctx context.Context, writer http.ResponseWriter, headers http.Header, target string, reportArgs *ReportArgs, event *cloudevents.Event, ttl int32) {
+// limits returns the delivery configuration holding the rate limit and the maximum number of
+// events in flight of the Trigger, falling back to the ones of its Broker. It's resolved once per
+// Trigger generation by the filter cache, which is invalidated when the Broker changes.
+func (h *Handler) limits(t *eventingv1.Trigger) *kncloudevents.RetryConfig {
+	var b *eventingv1.Broker
+	if t.Spec.Delivery == nil {
+		var err error
+		if b, err = h.brokerLister.Brokers(t.Namespace).Get(t.Spec.Broker); err != nil {
+			// Without its Broker, the Trigger has no limits until the Broker is added.
+			h.logger.Warn("Failed to get the Broker of the Trigger, its limits are not applied",
+				zap.String("namespace", t.Namespace), zap.String("name", t.Name), zap.Error(err))
+		}
+	}
+	return h.retryConfig(t, b)
+}
+
+// validateSchema validates the data of the event against the JSON schema of its EventType, when
+// the Trigger validates the events delivered to its subscriber.
+func (h *Handler) validateSchema(t *eventingv1.Trigger, event *cloudevents.Event) error {
+	if h.eventTypeIndexer == nil || t.GetAnnotations()[eventingv1.SchemaValidationAnnotation] != "enabled" {
+		return nil
+	}
+	et, err := broker.LookupEventType(h.eventTypeIndexer, t.Namespace, t.Spec.Broker, event)
+	if err != nil {
+		h.logger.Warn("Failed to look up the EventType", zap.Error(err))
+		return nil
+	}
+	if et == nil {
+		return nil
+	}
+	return h.schemas.Validate(et, event)
+}
+
+// triggerTransformers returns the transformers to apply to the events sent to the subscriber of the Trigger.
+func triggerTransformers(t *eventingv1.Trigger) ([]binding.Transformer, error) {
+	tt := t.Spec.Transform
+	if tt == nil {
+		return nil, nil
+	}
+	transformer, err := eventtransform.NewTransformer(eventtransform.Transform{
+		Type:       tt.Type,
+		Source:     tt.Source,
+		Extensions: tt.Extensions,
+		Rename:     tt.Rename,
+		Remove:     tt.Remove,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return []binding.Transformer{transformer}, nil
+}
+
+func (h *Handler) send(ctx context.Context, writer http.ResponseWriter, headers http.Header, target string, reportArgs *ReportArgs, event *cloudevents.Event, ttl int32, transformers ...binding.Transformer) {
 	// send the event to trigger's subscriber
-	response, err := h.sendEvent(ctx, headers, target, event, reportArgs)
+	response, err := h.sendEvent(ctx, headers, target, event, reportArgs, nil, nil, transformers...)
 	if err != nil {
 		h.logger.Error("failed to send event", zap.Error(err))
 		writer.WriteHeader(http.StatusInternalServerError)
@@ -218,13 +376,21 @@ func (h *Handler) send(ctx context.Context, writer http.ResponseWriter, headers
 	_ = h.reporter.ReportEventCount(reportArgs, statusCode)
 }
 
-func (h *Handler) sendEvent(ctx context.Context, headers http.Header, target string, event *cloudevents.Event, reporterArgs *ReportArgs) (*http.Response, error) {
+// sendEvent sends the event to the target, retrying as configured by retryConfig and recording the
+// requests sent in attempts when not nil.
+func (h *Handler) sendEvent(ctx context.Context, headers http.Header, target string, event *cloudevents.Event, reporterArgs *ReportArgs, retryConfig *kncloudevents.RetryConfig, attempts *kncloudevents.DeliveryAttempts, transformers ...binding.Transformer) (*http.Response, error) {
 	// Send the event to the subscriber
 	req, err := h.sender.NewCloudEventRequestWithTarget(ctx, target)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create the request: %w", err)
 	}
 
+	if len(transformers) > 0 {
+		// The transformers rewrite an event message in place, while the event is shared with
+		// the other Triggers.
+		clone := event.Clone()
+		event = &clone
+	}
 	message := binding.ToMessage(event)
 	defer message.Finish(nil)
 
@@ -233,13 +399,13 @@ func (h *Handler) sendEvent(ctx context.Context, headers http.Header, target str
 	// Following the spec https://github.com/knative/specs/blob/main/specs/eventing/data-plane.md#derived-reply-events
 	additionalHeaders.Set("prefer", "reply")
 
-	err = kncloudevents.WriteHTTPRequestWithAdditionalHeaders(ctx, message, req, additionalHeaders)
+	err = kncloudevents.WriteHTTPRequestWithAdditionalHeaders(ctx, message, req, additionalHeaders, transformers...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to write request: %w", err)
 	}
 
 	start := time.Now()
-	resp, err := h.sender.Send(req)
+	resp, err := h.sender.SendWithRetriesAndAttempts(req, retryConfig, attempts)
 	dispatchTime := time.Since(start)
 	if err != nil {
 		err = fmt.Errorf("failed to dispatch message: %w", err)
@@ -318,6 +484,42 @@ func (h *Handler) reportArrivalTime(event *event.Event, reportArgs *ReportArgs)
 	}
 }
 
+// lookupTrigger returns the Trigger referenced by triggerRef along with its report arguments and
+// its cached filter and transformers. It replies to the request and returns false when the Trigger
+// can't receive events.
+func (h *Handler) lookupTrigger(writer http.ResponseWriter, triggerRef path.NamespacedNameUID) (*eventingv1.Trigger, *ReportArgs, filterCacheEntry, bool) {
+	t, err := h.getTrigger(triggerRef)
+	if err != nil {
+		h.logger.Info("Unable to get the Trigger", zap.Error(err), zap.Any("triggerRef", triggerRef))
+		writer.WriteHeader(http.StatusBadRequest)
+		return nil, nil, filterCacheEntry{}, false
+	}
+
+	reportArgs := &ReportArgs{
+		ns:         t.Namespace,
+		trigger:    t.Name,
+		broker:     t.Spec.Broker,
+		filterType: triggerFilterAttribute(t.Spec.Filter, "type"),
+	}
+
+	if t.Status.SubscriberURI == nil {
+		// Record the event count.
+		writer.WriteHeader(http.StatusBadRequest)
+		_ = h.reporter.ReportEventCount(reportArgs, http.StatusBadRequest)
+		return nil, nil, filterCacheEntry{}, false
+	}
+
+	entry := h.filters.entry(t)
+	if entry.err != nil {
+		// The filter and transform are validated by the webhook, so this should never happen.
+		h.logger.Warn("Failed to build the Trigger filter or transform", zap.Any("triggerRef", triggerRef), zap.Error(entry.err))
+		writer.WriteHeader(http.StatusInternalServerError)
+		_ = h.reporter.ReportEventCount(reportArgs, http.StatusInternalServerError)
+		return nil, nil, filterCacheEntry{}, false
+	}
+	return t, reportArgs, entry, true
+}
+
 func (h *Handler) getTrigger(ref path.NamespacedNameUID) (*eventingv1.Trigger, error) {
 	t, err := h.triggerLister.Triggers(ref.Namespace).Get(ref.Name)
 	if err != nil {
@@ -329,15 +531,60 @@ func (h *Handler) getTrigger(ref path.NamespacedNameUID) (*eventingv1.Trigger, e
 	return t, nil
 }
 
-func filterEvent(ctx context.Context, filter *eventingv1.TriggerFilter, event cloudevents.Event) eventfilter.FilterResult {
+// materializeTriggerFilter builds the event filter combining all the expressions of the Trigger filter.
+func materializeTriggerFilter(filter *eventingv1.TriggerFilter) (eventfilter.Filter, error) {
+	var filters eventfilter.Filters
 	if filter == nil {
-		return eventfilter.NoFilter
+		return filters, nil
 	}
-	var filters eventfilter.Filters
 	if filter.Attributes != nil && len(filter.Attributes) != 0 {
 		filters = append(filters, attributes.NewAttributesFilter(filter.Attributes))
 	}
-	return filters.Filter(ctx, event)
+	if filter.SQL != "" {
+		f, err := cesql.NewCESQLFilter(filter.SQL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the SQL filter: %w", err)
+		}
+		filters = append(filters, f)
+	}
+	for _, f := range filter.Filters {
+		filters = append(filters, materializeSubscriptionsAPIFilter(f))
+	}
+	if len(filter.Data) != 0 {
+		f, err := jsonpath.NewDataFilter(eventingduckv1.DataFilterPredicates(filter.Data)...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the data filter: %w", err)
+		}
+		filters = append(filters, f)
+	}
+	return filters, nil
+}
+
+// materializeSubscriptionsAPIFilter builds the event filter for the dialect set in the filter expression.
+func materializeSubscriptionsAPIFilter(filter eventingv1.SubscriptionsAPIFilter) eventfilter.Filter {
+	switch {
+	case filter.Exact != nil:
+		return subscriptionsapi.NewExactFilter(filter.Exact)
+	case filter.Prefix != nil:
+		return subscriptionsapi.NewPrefixFilter(filter.Prefix)
+	case filter.Suffix != nil:
+		return subscriptionsapi.NewSuffixFilter(filter.Suffix)
+	case filter.All != nil:
+		return subscriptionsapi.NewAllFilter(materializeSubscriptionsAPIFilters(filter.All)...)
+	case filter.Any != nil:
+		return subscriptionsapi.NewAnyFilter(materializeSubscriptionsAPIFilters(filter.Any)...)
+	case filter.Not != nil:
+		return subscriptionsapi.NewNotFilter(materializeSubscriptionsAPIFilter(*filter.Not))
+	}
+	return eventfilter.Filters{}
+}
+
+func materializeSubscriptionsAPIFilters(filters []eventingv1.SubscriptionsAPIFilter) []eventfilter.Filter {
+	materialized := make([]eventfilter.Filter, 0, len(filters))
+	for _, f := range filters {
+		materialized = append(materialized, materializeSubscriptionsAPIFilter(f))
+	}
+	return materialized
 }
 
 // triggerFilterAttribute returns the filter attribute value for a given `attributeName`. If it doesn't not exist,
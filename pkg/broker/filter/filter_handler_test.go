@@ -24,6 +24,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -37,10 +38,14 @@ import (
 	"go.uber.org/zap/zaptest"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
 	"knative.dev/pkg/apis"
 
+	eventingduckv1 "knative.dev/eventing/pkg/apis/duck/v1"
 	eventingv1 "knative.dev/eventing/pkg/apis/eventing/v1"
+	eventingv1beta1 "knative.dev/eventing/pkg/apis/eventing/v1beta1"
 	broker "knative.dev/eventing/pkg/broker"
+	"knative.dev/eventing/pkg/kncloudevents"
 	reconcilertesting "knative.dev/eventing/pkg/reconciler/testing/v1"
 )
 
@@ -66,6 +71,7 @@ var (
 func TestReceiver(t *testing.T) {
 	testCases := map[string]struct {
 		triggers                    []*eventingv1.Trigger
+		eventTypes                  []*eventingv1beta1.EventType
 		request                     *http.Request
 		event                       *cloudevents.Event
 		requestFails                bool
@@ -220,6 +226,125 @@ func TestReceiver(t *testing.T) {
 			expectedEventDispatchTime:   true,
 			expectedEventProcessingTime: true,
 		},
+		"Dispatch succeeded - SQL filter": {
+			triggers: []*eventingv1.Trigger{
+				makeTrigger(makeTriggerFilterWithSQL(fmt.Sprintf("type = '%s' AND %s LIKE 'my-%%'", eventType, extensionName))),
+			},
+			event:                     makeEventWithExtension(extensionName, extensionValue),
+			expectedDispatch:          true,
+			expectedEventCount:        true,
+			expectedEventDispatchTime: true,
+		},
+		"Wrong SQL filter": {
+			triggers: []*eventingv1.Trigger{
+				makeTrigger(makeTriggerFilterWithSQL(fmt.Sprintf("type = '%s' AND %s = 'some-other-extension-value'", eventType, extensionName))),
+			},
+			event:              makeEventWithExtension(extensionName, extensionValue),
+			expectedEventCount: false,
+		},
+		"Dispatch succeeded - Subscriptions API filters": {
+			triggers: []*eventingv1.Trigger{
+				makeTrigger(makeTriggerFilterWithFilters(
+					eventingv1.SubscriptionsAPIFilter{Prefix: map[string]string{"type": "com.example."}},
+					eventingv1.SubscriptionsAPIFilter{Any: []eventingv1.SubscriptionsAPIFilter{
+						{Suffix: map[string]string{extensionName: "-value"}},
+						{Exact: map[string]string{"source": "some-other-source"}},
+					}},
+				)),
+			},
+			event:                     makeEventWithExtension(extensionName, extensionValue),
+			expectedDispatch:          true,
+			expectedEventCount:        true,
+			expectedEventDispatchTime: true,
+		},
+		"Wrong Subscriptions API filters": {
+			triggers: []*eventingv1.Trigger{
+				makeTrigger(makeTriggerFilterWithFilters(
+					eventingv1.SubscriptionsAPIFilter{Not: &eventingv1.SubscriptionsAPIFilter{
+						Exact: map[string]string{"type": eventType},
+					}},
+				)),
+			},
+			expectedEventCount: false,
+		},
+		"Invalid data filter": {
+			triggers: []*eventingv1.Trigger{
+				makeTrigger(makeTriggerFilterWithData(eventingduckv1.DataFilter{Path: ".order["})),
+			},
+			expectedStatus:     http.StatusInternalServerError,
+			expectedEventCount: true,
+		},
+		"Dispatch succeeded - Data filter": {
+			triggers: []*eventingv1.Trigger{
+				makeTrigger(makeTriggerFilterWithData(
+					eventingduckv1.DataFilter{Path: ".order.region", Value: pointer.StringPtr("eu-west")},
+					eventingduckv1.DataFilter{Path: ".order.items[*].sku"},
+				)),
+			},
+			event:                     makeEventWithData(`{"order": {"region": "eu-west", "items": [{"sku": "a1"}]}}`),
+			expectedDispatch:          true,
+			expectedEventCount:        true,
+			expectedEventDispatchTime: true,
+		},
+		"Schema validation - valid data": {
+			triggers:                  []*eventingv1.Trigger{withSchemaValidation(makeTrigger(nil))},
+			eventTypes:                []*eventingv1beta1.EventType{makeEventTypeWithSchema(`{"required": ["order"]}`)},
+			event:                     makeEventWithData(`{"order": {"region": "eu-west"}}`),
+			expectedDispatch:          true,
+			expectedEventCount:        true,
+			expectedEventDispatchTime: true,
+		},
+		"Schema validation - invalid data": {
+			triggers:           []*eventingv1.Trigger{withSchemaValidation(makeTrigger(nil))},
+			eventTypes:         []*eventingv1beta1.EventType{makeEventTypeWithSchema(`{"required": ["order"]}`)},
+			event:              makeEventWithData(`{"region": "eu-west"}`),
+			expectedStatus:     http.StatusBadRequest,
+			expectedEventCount: true,
+		},
+		"Wrong Data filter": {
+			triggers: []*eventingv1.Trigger{
+				makeTrigger(makeTriggerFilterWithData(
+					eventingduckv1.DataFilter{Path: ".order.region", Value: pointer.StringPtr("us-east")},
+				)),
+			},
+			event:              makeEventWithData(`{"order": {"region": "eu-west"}}`),
+			expectedEventCount: false,
+		},
+		"Dispatch succeeded - Transform": {
+			triggers: []*eventingv1.Trigger{
+				withTransform(makeTrigger(nil), &eventingv1.TriggerTransform{
+					Type:       "${type}.transformed",
+					Source:     "/transformed",
+					Extensions: map[string]string{"origin": "${source}"},
+					Rename:     map[string]string{extensionName: "renamed"},
+				}),
+			},
+			event: makeEventWithExtension(extensionName, extensionValue),
+			expectedHeaders: http.Header{
+				"Ce-Type":        []string{eventType + ".transformed"},
+				"Ce-Source":      []string{"/transformed"},
+				"Ce-Origin":      []string{eventSource},
+				"Ce-Renamed":     []string{extensionValue},
+				"Ce-Myextension": nil,
+			},
+			expectedDispatch:          true,
+			expectedEventCount:        true,
+			expectedEventDispatchTime: true,
+		},
+		"Transform can't be built": {
+			triggers: []*eventingv1.Trigger{
+				withTransform(makeTrigger(nil), &eventingv1.TriggerTransform{Type: "${type"}),
+			},
+			expectedStatus:     http.StatusInternalServerError,
+			expectedEventCount: true,
+		},
+		"Transform failed": {
+			triggers: []*eventingv1.Trigger{
+				withTransform(makeTrigger(nil), &eventingv1.TriggerTransform{Type: "${missing}"}),
+			},
+			expectedStatus:     http.StatusInternalServerError,
+			expectedEventCount: true,
+		},
 		"Wrong Extension with attribs": {
 			triggers: []*eventingv1.Trigger{
 				makeTrigger(makeTriggerFilterWithAttributesAndExtension(eventType, eventSource, "some-other-extension-value")),
@@ -407,11 +532,16 @@ func TestReceiver(t *testing.T) {
 				}
 				correctURI = append(correctURI, trig)
 			}
+			for _, et := range tc.eventTypes {
+				correctURI = append(correctURI, et)
+			}
 			listers := reconcilertesting.NewListers(correctURI)
 			reporter := &mockReporter{}
 			r, err := NewHandler(
 				zaptest.NewLogger(t, zaptest.WrapOptions(zap.AddCaller())),
 				listers.GetTriggerLister(),
+				listers.GetBrokerLister(),
+				listers.GetEventTypeIndexer(broker.EventTypeIndexers),
 				reporter,
 				8080)
 			if tc.expectNewToFail {
@@ -515,26 +645,42 @@ func (r *responseWriterWithInvocationsCheck) WriteHeader(statusCode int) {
 }
 
 type mockReporter struct {
+	// mutex guards the fields, as the Broker path reports from a goroutine per Trigger.
+	mutex                       sync.Mutex
 	eventCountReported          bool
 	eventDispatchTimeReported   bool
 	eventProcessingTimeReported bool
 }
 
 func (r *mockReporter) ReportEventCount(args *ReportArgs, responseCode int) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
 	r.eventCountReported = true
 	return nil
 }
 
 func (r *mockReporter) ReportEventDispatchTime(args *ReportArgs, responseCode int, d time.Duration) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
 	r.eventDispatchTimeReported = true
 	return nil
 }
 
 func (r *mockReporter) ReportEventProcessingTime(args *ReportArgs, d time.Duration) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
 	r.eventProcessingTimeReported = true
 	return nil
 }
 
+func (r *mockReporter) ReportLimiterState(destination string, state kncloudevents.LimiterState) error {
+	return nil
+}
+
+func (r *mockReporter) ReportLimiterRejection(destination string) error {
+	return nil
+}
+
 type fakeHandler struct {
 	failRequest     bool
 	failStatus      int
@@ -616,6 +762,24 @@ func makeTriggerFilterWithAttributesAndExtension(t, s, e string) *eventingv1.Tri
 	}
 }
 
+func makeTriggerFilterWithSQL(sql string) *eventingv1.TriggerFilter {
+	return &eventingv1.TriggerFilter{
+		SQL: sql,
+	}
+}
+
+func makeTriggerFilterWithFilters(filters ...eventingv1.SubscriptionsAPIFilter) *eventingv1.TriggerFilter {
+	return &eventingv1.TriggerFilter{
+		Filters: filters,
+	}
+}
+
+func makeTriggerFilterWithData(data ...eventingduckv1.DataFilter) *eventingv1.TriggerFilter {
+	return &eventingv1.TriggerFilter{
+		Data: data,
+	}
+}
+
 func makeTriggerWithDifferentUID(filter *eventingv1.TriggerFilter) *eventingv1.Trigger {
 	t := makeTrigger(filter)
 	t.ObjectMeta.UID = "wrongone"
@@ -642,6 +806,23 @@ func makeTrigger(filter *eventingv1.TriggerFilter) *eventingv1.Trigger {
 	}
 }
 
+func withTransform(t *eventingv1.Trigger, transform *eventingv1.TriggerTransform) *eventingv1.Trigger {
+	t.Spec.Transform = transform
+	return t
+}
+
+func withSchemaValidation(t *eventingv1.Trigger) *eventingv1.Trigger {
+	t.Annotations = map[string]string{eventingv1.SchemaValidationAnnotation: "enabled"}
+	t.Spec.Broker = "default"
+	return t
+}
+
+func makeEventTypeWithSchema(schema string) *eventingv1beta1.EventType {
+	return reconcilertesting.NewEventType("test-eventtype", testNS,
+		reconcilertesting.WithEventTypeType(eventType),
+		reconcilertesting.WithEventTypeSchemaData(schema))
+}
+
 func makeTriggerWithoutFilter() *eventingv1.Trigger {
 	t := makeTrigger(makeTriggerFilterWithAttributes("", ""))
 	t.Spec.Filter = nil
@@ -687,6 +868,12 @@ func makeEventWithExtension(extName, extValue string) *cloudevents.Event {
 	return &e
 }
 
+func makeEventWithData(data string) *cloudevents.Event {
+	e := makeEvent()
+	_ = e.SetData(cloudevents.ApplicationJSON, []byte(data))
+	return e
+}
+
 func makeNonEmptyResponse() *http.Response {
 	r := &http.Response{
 		Status:     "200 OK",
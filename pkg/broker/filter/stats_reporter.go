@@ -27,6 +27,7 @@ import (
 	"go.opencensus.io/stats/view"
 	"go.opencensus.io/tag"
 	broker "knative.dev/eventing/pkg/broker"
+	"knative.dev/eventing/pkg/kncloudevents"
 	eventingmetrics "knative.dev/eventing/pkg/metrics"
 	"knative.dev/pkg/metrics"
 	"knative.dev/pkg/metrics/metricskey"
@@ -62,6 +63,29 @@ var (
 		stats.UnitMilliseconds,
 	)
 
+	// limiterInFlightM records the number of events being sent to a Trigger subscriber with a
+	// limiter.
+	limiterInFlightM = stats.Int64(
+		"limiter_in_flight",
+		"Number of events being sent to a Trigger subscriber with a limiter",
+		stats.UnitDimensionless,
+	)
+
+	// limiterWaitingM records the number of events waiting for the limiter of a Trigger subscriber.
+	limiterWaitingM = stats.Int64(
+		"limiter_waiting",
+		"Number of events waiting for the limiter of a Trigger subscriber",
+		stats.UnitDimensionless,
+	)
+
+	// limiterRejectionCountM is a counter which records the events rejected by the limiter of a
+	// Trigger subscriber.
+	limiterRejectionCountM = stats.Int64(
+		"limiter_rejection_count",
+		"Number of events rejected by the limiter of a Trigger subscriber",
+		stats.UnitDimensionless,
+	)
+
 	// Create the tag keys that will be used to add tags to our measurements.
 	// Tag keys must conform to the restrictions described in
 	// go.opencensus.io/tag/validate.go. Currently those restrictions are:
@@ -88,6 +112,8 @@ type StatsReporter interface {
 	ReportEventCount(args *ReportArgs, responseCode int) error
 	ReportEventDispatchTime(args *ReportArgs, responseCode int, d time.Duration) error
 	ReportEventProcessingTime(args *ReportArgs, d time.Duration) error
+	ReportLimiterState(destination string, state kncloudevents.LimiterState) error
+	ReportLimiterRejection(destination string) error
 }
 
 var _ StatsReporter = (*reporter)(nil)
@@ -128,6 +154,24 @@ func register() {
 			Aggregation: view.Distribution(metrics.Buckets125(1, 10000)...), // 1, 2, 5, 10, 20, 50, 100, 1000, 5000, 10000
 			TagKeys:     []tag.Key{triggerFilterTypeKey, broker.UniqueTagKey, broker.ContainerTagKey},
 		},
+		&view.View{
+			Description: limiterInFlightM.Description(),
+			Measure:     limiterInFlightM,
+			Aggregation: view.LastValue(),
+			TagKeys:     []tag.Key{broker.DestinationTagKey, broker.UniqueTagKey, broker.ContainerTagKey},
+		},
+		&view.View{
+			Description: limiterWaitingM.Description(),
+			Measure:     limiterWaitingM,
+			Aggregation: view.LastValue(),
+			TagKeys:     []tag.Key{broker.DestinationTagKey, broker.UniqueTagKey, broker.ContainerTagKey},
+		},
+		&view.View{
+			Description: limiterRejectionCountM.Description(),
+			Measure:     limiterRejectionCountM,
+			Aggregation: view.Count(),
+			TagKeys:     []tag.Key{broker.DestinationTagKey, broker.UniqueTagKey, broker.ContainerTagKey},
+		},
 	)
 	if err != nil {
 		log.Printf("failed to register opencensus views, %s", err)
@@ -171,6 +215,35 @@ func (r *reporter) ReportEventProcessingTime(args *ReportArgs, d time.Duration)
 	return nil
 }
 
+// ReportLimiterState captures the number of events in flight and waiting for the limiter of a
+// Trigger subscriber.
+func (r *reporter) ReportLimiterState(destination string, state kncloudevents.LimiterState) error {
+	ctx, err := r.generateDestinationTag(destination)
+	if err != nil {
+		return err
+	}
+	metrics.RecordBatch(ctx, limiterInFlightM.M(int64(state.InFlight)), limiterWaitingM.M(int64(state.Waiting)))
+	return nil
+}
+
+// ReportLimiterRejection captures an event rejected by the limiter of a Trigger subscriber.
+func (r *reporter) ReportLimiterRejection(destination string) error {
+	ctx, err := r.generateDestinationTag(destination)
+	if err != nil {
+		return err
+	}
+	metrics.Record(ctx, limiterRejectionCountM.M(1))
+	return nil
+}
+
+func (r *reporter) generateDestinationTag(destination string) (context.Context, error) {
+	return tag.New(
+		emptyContext,
+		tag.Insert(broker.DestinationTagKey, destination),
+		tag.Insert(broker.ContainerTagKey, r.container),
+		tag.Insert(broker.UniqueTagKey, r.uniqueName))
+}
+
 func (r *reporter) generateTag(args *ReportArgs, tags ...tag.Mutator) (context.Context, error) {
 	ctx := metricskey.WithResource(emptyContext, resource.Resource{
 		Type: eventingmetrics.ResourceTypeKnativeTrigger,
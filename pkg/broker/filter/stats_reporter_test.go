@@ -23,6 +23,7 @@ import (
 
 	"go.opencensus.io/resource"
 	broker "knative.dev/eventing/pkg/broker"
+	"knative.dev/eventing/pkg/kncloudevents"
 	"knative.dev/eventing/pkg/metrics"
 	"knative.dev/pkg/metrics/metricstest"
 	_ "knative.dev/pkg/metrics/testing"
@@ -90,6 +91,22 @@ func TestStatsReporter(t *testing.T) {
 	})
 	metricstest.AssertMetric(t, metricstest.DistributionCountOnlyMetric("event_processing_latencies", 2, wantTags))
 	metricstest.CheckDistributionData(t, "event_processing_latencies", wantTags, 2, 1000.0, 8000.0)
+
+	// test ReportLimiterState and ReportLimiterRejection
+	limiterTags := map[string]string{
+		broker.LabelDestination:   "http://subscriber.example.com/",
+		broker.LabelContainerName: "testcontainer",
+		broker.LabelUniqueName:    "testpod",
+	}
+	expectSuccess(t, func() error {
+		return r.ReportLimiterState("http://subscriber.example.com/", kncloudevents.LimiterState{InFlight: 2, Waiting: 1})
+	})
+	metricstest.CheckLastValueData(t, "limiter_in_flight", limiterTags, 2)
+	metricstest.CheckLastValueData(t, "limiter_waiting", limiterTags, 1)
+	expectSuccess(t, func() error {
+		return r.ReportLimiterRejection("http://subscriber.example.com/")
+	})
+	metricstest.CheckCountData(t, "limiter_rejection_count", limiterTags, 1)
 }
 
 func TestReporterEmptySourceAndTypeFilter(t *testing.T) {
@@ -153,6 +170,9 @@ func resetMetrics() {
 	metricstest.Unregister(
 		"event_count",
 		"event_dispatch_latencies",
-		"event_processing_latencies")
+		"event_processing_latencies",
+		"limiter_in_flight",
+		"limiter_waiting",
+		"limiter_rejection_count")
 	register()
 }
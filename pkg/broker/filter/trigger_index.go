@@ -0,0 +1,152 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filter
+
+import (
+	"sync"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+
+	eventingv1 "knative.dev/eventing/pkg/apis/eventing/v1"
+	eventinglisters "knative.dev/eventing/pkg/client/listers/eventing/v1"
+)
+
+// triggerIndexKey holds the exact type and source a Trigger filters on.
+// An empty value matches any value of the attribute.
+type triggerIndexKey struct {
+	eventType string
+	source    string
+}
+
+// triggerIndex groups the Triggers of a Broker by the exact type and source
+// they filter on, so that an event is only evaluated against the Triggers
+// that could possibly match it.
+type triggerIndex struct {
+	buckets map[triggerIndexKey][]*eventingv1.Trigger
+}
+
+func newTriggerIndex(triggers []*eventingv1.Trigger) *triggerIndex {
+	i := &triggerIndex{buckets: make(map[triggerIndexKey][]*eventingv1.Trigger)}
+	for _, t := range triggers {
+		key := indexKeyOf(t.Spec.Filter)
+		i.buckets[key] = append(i.buckets[key], t)
+	}
+	return i
+}
+
+// candidates returns the Triggers whose exact type and source are either
+// unset or equal to the event ones. The full filter of the returned Triggers
+// still has to be evaluated.
+func (i *triggerIndex) candidates(event *cloudevents.Event) []*eventingv1.Trigger {
+	keys := []triggerIndexKey{{}}
+	if event.Type() != "" {
+		keys = append(keys, triggerIndexKey{eventType: event.Type()})
+	}
+	if event.Source() != "" {
+		keys = append(keys, triggerIndexKey{source: event.Source()})
+	}
+	if event.Type() != "" && event.Source() != "" {
+		keys = append(keys, triggerIndexKey{eventType: event.Type(), source: event.Source()})
+	}
+
+	var candidates []*eventingv1.Trigger
+	for _, key := range keys {
+		candidates = append(candidates, i.buckets[key]...)
+	}
+	return candidates
+}
+
+// indexKeyOf extracts the exact type and source a Trigger filters on, looking at
+// the attributes filter first and then at the top level exact filter expressions.
+func indexKeyOf(filter *eventingv1.TriggerFilter) triggerIndexKey {
+	var key triggerIndexKey
+	if filter == nil {
+		return key
+	}
+	key.eventType = filter.Attributes["type"]
+	key.source = filter.Attributes["source"]
+	for _, f := range filter.Filters {
+		if key.eventType == "" {
+			key.eventType = f.Exact["type"]
+		}
+		if key.source == "" {
+			key.source = f.Exact["source"]
+		}
+	}
+	return key
+}
+
+// triggerIndexCache holds the Trigger index of each Broker. The index of a Broker
+// is built lazily from the TriggerLister and dropped whenever one of its Triggers
+// changes.
+type triggerIndexCache struct {
+	triggerLister eventinglisters.TriggerLister
+
+	mutex   sync.RWMutex
+	indexes map[types.NamespacedName]*triggerIndex
+	// versions is bumped on every invalidation, so that an index built
+	// concurrently with a Trigger change isn't cached.
+	versions map[types.NamespacedName]uint64
+}
+
+func newTriggerIndexCache(triggerLister eventinglisters.TriggerLister) *triggerIndexCache {
+	return &triggerIndexCache{
+		triggerLister: triggerLister,
+		indexes:       make(map[types.NamespacedName]*triggerIndex),
+		versions:      make(map[types.NamespacedName]uint64),
+	}
+}
+
+// get returns the Trigger index of the given Broker, building it if needed.
+func (c *triggerIndexCache) get(broker types.NamespacedName) (*triggerIndex, error) {
+	c.mutex.RLock()
+	index, ok := c.indexes[broker]
+	version := c.versions[broker]
+	c.mutex.RUnlock()
+	if ok {
+		return index, nil
+	}
+
+	triggers, err := c.triggerLister.Triggers(broker.Namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	owned := make([]*eventingv1.Trigger, 0, len(triggers))
+	for _, t := range triggers {
+		if t.Spec.Broker == broker.Name {
+			owned = append(owned, t)
+		}
+	}
+	index = newTriggerIndex(owned)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.versions[broker] == version {
+		c.indexes[broker] = index
+	}
+	return index, nil
+}
+
+// invalidate drops the Trigger index of the given Broker.
+func (c *triggerIndexCache) invalidate(broker types.NamespacedName) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	delete(c.indexes, broker)
+	c.versions[broker]++
+}
@@ -0,0 +1,207 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filter
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/apis"
+
+	eventingv1 "knative.dev/eventing/pkg/apis/eventing/v1"
+	"knative.dev/eventing/pkg/eventfilter"
+	reconcilertesting "knative.dev/eventing/pkg/reconciler/testing/v1"
+)
+
+const indexBrokerName = "test-broker"
+
+func TestTriggerIndexCandidates(t *testing.T) {
+	triggers := []*eventingv1.Trigger{
+		makeIndexedTrigger("no-filter", nil),
+		makeIndexedTrigger("any", makeTriggerFilterWithAttributes("", "")),
+		makeIndexedTrigger("type", makeTriggerFilterWithAttributes(eventType, "")),
+		makeIndexedTrigger("source", makeTriggerFilterWithAttributes("", eventSource)),
+		makeIndexedTrigger("type-and-source", makeTriggerFilterWithAttributes(eventType, eventSource)),
+		makeIndexedTrigger("other-type", makeTriggerFilterWithAttributes("other-type", "")),
+		makeIndexedTrigger("other-source", makeTriggerFilterWithAttributes(eventType, "other-source")),
+		makeIndexedTrigger("exact-type", makeTriggerFilterWithFilters(eventingv1.SubscriptionsAPIFilter{
+			Exact: map[string]string{"type": eventType},
+		})),
+		makeIndexedTrigger("exact-other-type", makeTriggerFilterWithFilters(eventingv1.SubscriptionsAPIFilter{
+			Exact: map[string]string{"type": "other-type"},
+		})),
+		makeIndexedTrigger("sql", makeTriggerFilterWithSQL("type = 'other-type'")),
+	}
+	index := newTriggerIndex(triggers)
+
+	var got []string
+	for _, trigger := range index.candidates(makeEvent()) {
+		got = append(got, trigger.Name)
+	}
+	sort.Strings(got)
+
+	want := []string{"any", "exact-type", "no-filter", "source", "sql", "type", "type-and-source"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Error("Unexpected candidates (-want, +got) =", diff)
+	}
+}
+
+func TestTriggerIndexCache(t *testing.T) {
+	otherBroker := makeIndexedTrigger("other-broker", nil)
+	otherBroker.Spec.Broker = "other-broker"
+	otherNamespace := makeIndexedTrigger("other-namespace", nil)
+	otherNamespace.Namespace = "other-namespace"
+
+	listers := reconcilertesting.NewListers([]runtime.Object{
+		makeIndexedTrigger("first", nil),
+		makeIndexedTrigger("second", makeTriggerFilterWithAttributes(eventType, "")),
+		otherBroker,
+		otherNamespace,
+	})
+	c := newTriggerIndexCache(listers.GetTriggerLister())
+	broker := types.NamespacedName{Namespace: testNS, Name: indexBrokerName}
+
+	index, err := c.get(broker)
+	if err != nil {
+		t.Fatal("get() =", err)
+	}
+	var got []string
+	for _, trigger := range index.candidates(makeEvent()) {
+		got = append(got, trigger.Name)
+	}
+	sort.Strings(got)
+	if diff := cmp.Diff([]string{"first", "second"}, got); diff != "" {
+		t.Error("Unexpected candidates (-want, +got) =", diff)
+	}
+
+	cached, err := c.get(broker)
+	if err != nil {
+		t.Fatal("get() =", err)
+	}
+	if cached != index {
+		t.Error("Expected the cached index to be returned")
+	}
+
+	c.invalidate(broker)
+	rebuilt, err := c.get(broker)
+	if err != nil {
+		t.Fatal("get() =", err)
+	}
+	if rebuilt == index {
+		t.Error("Expected the index to be rebuilt after the invalidation")
+	}
+}
+
+func TestHandlerTriggerEventHandler(t *testing.T) {
+	listers := reconcilertesting.NewListers([]runtime.Object{makeIndexedTrigger("first", nil)})
+	h := &Handler{
+		filters:        newFilterCache(nil),
+		triggerIndexes: newTriggerIndexCache(listers.GetTriggerLister()),
+		batchers:       newTriggerBatchers(),
+	}
+	broker := types.NamespacedName{Namespace: testNS, Name: indexBrokerName}
+	trigger := makeIndexedTrigger("first", nil)
+
+	handler := h.TriggerEventHandler()
+	for n, notify := range map[string]func(){
+		"add":    func() { handler.OnAdd(trigger) },
+		"update": func() { handler.OnUpdate(trigger, trigger) },
+		"delete": func() { handler.OnDelete(trigger) },
+	} {
+		t.Run(n, func(t *testing.T) {
+			if _, err := h.triggerIndexes.get(broker); err != nil {
+				t.Fatal("get() =", err)
+			}
+			notify()
+			if _, ok := h.triggerIndexes.indexes[broker]; ok {
+				t.Error("Expected the Trigger index of the Broker to be invalidated")
+			}
+		})
+	}
+}
+
+// BenchmarkBrokerFanout compares matching an event against the Triggers of a Broker using the
+// Trigger index with evaluating the filter of every Trigger, as it happens when every Trigger
+// subscribes to the Broker's channel. Every Trigger filters on one of 100 event types.
+func BenchmarkBrokerFanout(b *testing.B) {
+	for _, n := range []int{10, 1000, 10000} {
+		objs := make([]runtime.Object, 0, n)
+		triggers := make([]*eventingv1.Trigger, 0, n)
+		for i := 0; i < n; i++ {
+			t := makeIndexedTrigger(fmt.Sprintf("trigger-%d", i), makeTriggerFilterWithAttributes(fmt.Sprintf("%s.%d", eventType, i%100), eventSource))
+			objs = append(objs, t)
+			triggers = append(triggers, t)
+		}
+		listers := reconcilertesting.NewListers(objs)
+		h := &Handler{
+			filters:        newFilterCache(nil),
+			triggerIndexes: newTriggerIndexCache(listers.GetTriggerLister()),
+			logger:         zap.NewNop(),
+		}
+		broker := types.NamespacedName{Namespace: testNS, Name: indexBrokerName}
+		event := makeEvent()
+		event.SetType(eventType + ".42")
+		ctx := context.Background()
+
+		b.Run(fmt.Sprintf("triggers=%d/indexed", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				index, err := h.triggerIndexes.get(broker)
+				if err != nil {
+					b.Fatal("get() =", err)
+				}
+				for _, t := range index.candidates(event) {
+					_ = h.triggerMatches(ctx, t, event)
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("triggers=%d/per-trigger", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for _, t := range triggers {
+					filter, err := h.filters.get(t)
+					if err != nil {
+						b.Fatal("get() =", err)
+					}
+					_ = filter.Filter(ctx, *event) == eventfilter.PassFilter
+				}
+			}
+		})
+	}
+}
+
+func makeIndexedTrigger(name string, filter *eventingv1.TriggerFilter) *eventingv1.Trigger {
+	return &eventingv1.Trigger{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNS,
+			Name:      name,
+			UID:       types.UID(name),
+		},
+		Spec: eventingv1.TriggerSpec{
+			Broker: indexBrokerName,
+			Filter: filter,
+		},
+		Status: eventingv1.TriggerStatus{
+			SubscriberURI: apis.HTTP("subscriber.example.com"),
+		},
+	}
+}
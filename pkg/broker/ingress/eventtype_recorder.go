@@ -0,0 +1,251 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"context"
+	"crypto/md5" //nolint:gosec // No strong cryptography needed.
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"go.uber.org/zap"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"knative.dev/eventing/pkg/apis/eventing"
+	"knative.dev/eventing/pkg/apis/eventing/v1beta1"
+	"knative.dev/eventing/pkg/client/clientset/versioned"
+	eventinglisters "knative.dev/eventing/pkg/client/listers/eventing/v1"
+	eventinglistersv1beta1 "knative.dev/eventing/pkg/client/listers/eventing/v1beta1"
+	"knative.dev/pkg/apis"
+	"knative.dev/pkg/kmeta"
+)
+
+// maxAutoDiscoveredEventTypes is the maximum number of auto-discovered EventTypes registered for
+// a Broker, bounding the EventTypes created by the senders of arbitrary events.
+const maxAutoDiscoveredEventTypes = 100
+
+// observedEventType is a distinct event type received by a Broker.
+type observedEventType struct {
+	namespace string
+	broker    string
+	eventType string
+	source    string
+	schema    string
+}
+
+// name returns the fixed name of the EventType registered for the observed event type, so that
+// the ingress replicas observing it don't register it more than once. The fields are quoted so
+// that distinct event types don't share a name.
+func (o observedEventType) name() string {
+	key := fmt.Sprintf("%q %q %q %q", o.broker, o.eventType, o.source, o.schema)
+	return fmt.Sprintf("%x", md5.Sum([]byte(key))) //nolint:gosec // No strong cryptography needed.
+}
+
+// brokerKey returns the namespace and name of the Broker of the observed event type.
+func (o observedEventType) brokerKey() string {
+	return o.namespace + "/" + o.broker
+}
+
+func (o observedEventType) matches(et *v1beta1.EventType) bool {
+	return et.Spec.Broker == o.broker &&
+		et.Spec.Type == o.eventType &&
+		et.Spec.Source.String() == o.source &&
+		et.Spec.Schema.String() == o.schema
+}
+
+// EventTypeRecorder records the distinct (type, source, dataschema) tuples of a sample of the
+// events received by each Broker, and periodically registers them as auto-discovered EventTypes.
+type EventTypeRecorder struct {
+	client          versioned.Interface
+	brokerLister    eventinglisters.BrokerLister
+	eventTypeLister eventinglistersv1beta1.EventTypeLister
+	// sampleRate is the fraction of the events which are recorded, between 0 and 1.
+	sampleRate float64
+	// interval is the period between two flushes of the recorded event types.
+	interval time.Duration
+	// maxEventTypes is the maximum number of auto-discovered EventTypes of a Broker.
+	maxEventTypes int
+	logger        *zap.Logger
+
+	mutex    sync.Mutex
+	observed map[observedEventType]struct{}
+	// observedPerBroker is the number of event types observed for each Broker since the last
+	// flush, up to maxEventTypes.
+	observedPerBroker map[string]int
+}
+
+// NewEventTypeRecorder returns an EventTypeRecorder recording the given fraction of the events,
+// and registering them every interval once started.
+func NewEventTypeRecorder(client versioned.Interface, brokerLister eventinglisters.BrokerLister, eventTypeLister eventinglistersv1beta1.EventTypeLister, sampleRate float64, interval time.Duration, logger *zap.Logger) *EventTypeRecorder {
+	return &EventTypeRecorder{
+		client:            client,
+		brokerLister:      brokerLister,
+		eventTypeLister:   eventTypeLister,
+		sampleRate:        sampleRate,
+		interval:          interval,
+		maxEventTypes:     maxAutoDiscoveredEventTypes,
+		logger:            logger,
+		observed:          make(map[observedEventType]struct{}),
+		observedPerBroker: make(map[string]int),
+	}
+}
+
+// Record records the type, source and dataschema of the event received by the Broker, if the
+// event is part of the sample.
+func (r *EventTypeRecorder) Record(brokerNamespace, brokerName string, event *cloudevents.Event) {
+	if r.sampleRate < 1 && rand.Float64() >= r.sampleRate { //nolint:gosec // No strong cryptography needed.
+		return
+	}
+	key := observedEventType{
+		namespace: brokerNamespace,
+		broker:    brokerName,
+		eventType: event.Type(),
+		source:    event.Source(),
+		schema:    event.DataSchema(),
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if _, ok := r.observed[key]; ok || r.observedPerBroker[key.brokerKey()] >= r.maxEventTypes {
+		return
+	}
+	r.observed[key] = struct{}{}
+	r.observedPerBroker[key.brokerKey()]++
+}
+
+// Start flushes the recorded event types every interval, until the context is done.
+func (r *EventTypeRecorder) Start(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.Flush(ctx)
+		}
+	}
+}
+
+// Flush registers an EventType for each recorded event type which doesn't have one yet, and
+// refreshes the last seen time of the auto-discovered EventTypes recorded again.
+func (r *EventTypeRecorder) Flush(ctx context.Context) {
+	r.mutex.Lock()
+	observed := r.observed
+	r.observed = make(map[observedEventType]struct{})
+	r.observedPerBroker = make(map[string]int)
+	r.mutex.Unlock()
+
+	now := time.Now()
+	// created counts the EventTypes created for each Broker, which aren't listed yet.
+	created := make(map[string]int)
+	for o := range observed {
+		if err := r.register(ctx, o, now, created); err != nil {
+			r.logger.Warn("Failed to register an observed event type",
+				zap.String("namespace", o.namespace),
+				zap.String("broker", o.broker),
+				zap.String("event.type", o.eventType),
+				zap.Error(err))
+		}
+	}
+}
+
+func (r *EventTypeRecorder) register(ctx context.Context, o observedEventType, now time.Time, created map[string]int) error {
+	eventTypes, err := r.eventTypeLister.EventTypes(o.namespace).List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	autoDiscovered := created[o.brokerKey()]
+	for _, et := range eventTypes {
+		if o.matches(et) {
+			return r.refresh(ctx, et, now)
+		}
+		if et.Spec.Broker == o.broker && et.Labels[eventing.AutoDiscoveredLabelKey] == "true" {
+			autoDiscovered++
+		}
+	}
+	if autoDiscovered >= r.maxEventTypes {
+		return fmt.Errorf("the Broker already has %d auto-discovered EventTypes", autoDiscovered)
+	}
+
+	b, err := r.brokerLister.Brokers(o.namespace).Get(o.broker)
+	if err != nil {
+		return err
+	}
+	et := &v1beta1.EventType{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      o.name(),
+			Namespace: o.namespace,
+			Labels: map[string]string{
+				eventing.AutoDiscoveredLabelKey: "true",
+			},
+			Annotations: map[string]string{
+				eventing.LastSeenAnnotationKey: now.UTC().Format(time.RFC3339),
+			},
+			// The EventType is deleted along with its Broker.
+			OwnerReferences: []metav1.OwnerReference{*kmeta.NewControllerRef(b)},
+		},
+		Spec: v1beta1.EventTypeSpec{
+			Type:   o.eventType,
+			Source: parseURL(o.source),
+			Schema: parseURL(o.schema),
+			Broker: o.broker,
+		},
+	}
+	_, err = r.client.EventingV1beta1().EventTypes(o.namespace).Create(ctx, et, metav1.CreateOptions{})
+	if apierrs.IsAlreadyExists(err) {
+		// Another replica registered it first.
+		return nil
+	}
+	if err == nil {
+		created[o.brokerKey()]++
+	}
+	return err
+}
+
+// refresh updates the last seen time of an auto-discovered EventType, at most once per interval.
+// The EventTypes registered by hand are left untouched.
+func (r *EventTypeRecorder) refresh(ctx context.Context, et *v1beta1.EventType, now time.Time) error {
+	if et.Labels[eventing.AutoDiscoveredLabelKey] != "true" {
+		return nil
+	}
+	if lastSeen, err := time.Parse(time.RFC3339, et.Annotations[eventing.LastSeenAnnotationKey]); err == nil && now.Sub(lastSeen) < r.interval {
+		return nil
+	}
+	et = et.DeepCopy()
+	if et.Annotations == nil {
+		et.Annotations = make(map[string]string, 1)
+	}
+	et.Annotations[eventing.LastSeenAnnotationKey] = now.UTC().Format(time.RFC3339)
+	_, err := r.client.EventingV1beta1().EventTypes(et.Namespace).Update(ctx, et, metav1.UpdateOptions{})
+	return err
+}
+
+func parseURL(s string) *apis.URL {
+	if s == "" {
+		return nil
+	}
+	u, err := apis.ParseURL(s)
+	if err != nil {
+		return nil
+	}
+	return u
+}
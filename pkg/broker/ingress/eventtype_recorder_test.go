@@ -0,0 +1,181 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgotesting "k8s.io/client-go/testing"
+
+	"knative.dev/eventing/pkg/apis/eventing"
+	"knative.dev/eventing/pkg/apis/eventing/v1beta1"
+	"knative.dev/eventing/pkg/client/clientset/versioned/fake"
+	reconcilertestingv1 "knative.dev/eventing/pkg/reconciler/testing/v1"
+	"knative.dev/pkg/apis"
+)
+
+func TestEventTypeRecorder(t *testing.T) {
+	newEvent := func(eventType, schema string) *cloudevents.Event {
+		event := cloudevents.NewEvent()
+		event.SetID("id")
+		event.SetType(eventType)
+		event.SetSource("source")
+		event.SetDataSchema(schema)
+		return &event
+	}
+	stale := observedEventType{namespace: "ns", broker: "name", eventType: "stale", source: "source"}
+	recent := observedEventType{namespace: "ns", broker: "name", eventType: "recent", source: "source"}
+	objects := []runtime.Object{
+		makeBroker("name", "ns"),
+		reconcilertestingv1.NewEventType("manual", "ns",
+			reconcilertestingv1.WithEventTypeType("manual"),
+			reconcilertestingv1.WithEventTypeSource(&apis.URL{Path: "source"}),
+			reconcilertestingv1.WithEventTypeBroker("name")),
+		reconcilertestingv1.NewEventType(stale.name(), "ns",
+			reconcilertestingv1.WithEventTypeType("stale"),
+			reconcilertestingv1.WithEventTypeSource(&apis.URL{Path: "source"}),
+			reconcilertestingv1.WithEventTypeBroker("name"),
+			reconcilertestingv1.WithEventTypeAutoDiscovered(time.Now().Add(-time.Hour))),
+		reconcilertestingv1.NewEventType(recent.name(), "ns",
+			reconcilertestingv1.WithEventTypeType("recent"),
+			reconcilertestingv1.WithEventTypeSource(&apis.URL{Path: "source"}),
+			reconcilertestingv1.WithEventTypeBroker("name"),
+			reconcilertestingv1.WithEventTypeAutoDiscovered(time.Now())),
+	}
+	listers := reconcilertestingv1.NewListers(objects)
+	client := fake.NewSimpleClientset(objects...)
+	client.ClearActions()
+
+	r := NewEventTypeRecorder(client, listers.GetBrokerLister(), listers.GetEventTypeLister(), 1, time.Minute, zap.NewNop())
+	r.Record("ns", "name", newEvent("new", "http://schema"))
+	r.Record("ns", "name", newEvent("new", "http://schema"))
+	r.Record("ns", "name", newEvent("manual", ""))
+	r.Record("ns", "name", newEvent("stale", ""))
+	r.Record("ns", "name", newEvent("recent", ""))
+	r.Record("ns", "missing", newEvent("new", ""))
+	r.Flush(context.Background())
+
+	var created []string
+	var updated []string
+	for _, action := range client.Actions() {
+		switch action.GetVerb() {
+		case "create":
+			et := action.(clientgotesting.CreateAction).GetObject().(*v1beta1.EventType)
+			created = append(created, et.Spec.Type)
+			want := observedEventType{namespace: "ns", broker: "name", eventType: "new", source: "source", schema: "http://schema"}
+			if et.Name != want.name() || !want.matches(et) {
+				t.Errorf("Unexpected EventType created: %+v", et)
+			}
+			if et.Labels[eventing.AutoDiscoveredLabelKey] != "true" || et.Annotations[eventing.LastSeenAnnotationKey] == "" {
+				t.Errorf("The created EventType isn't auto-discovered: %+v", et.ObjectMeta)
+			}
+			if len(et.OwnerReferences) != 1 || et.OwnerReferences[0].Name != "name" {
+				t.Errorf("The created EventType isn't owned by its Broker: %+v", et.OwnerReferences)
+			}
+		case "update":
+			et := action.(clientgotesting.UpdateAction).GetObject().(*v1beta1.EventType)
+			updated = append(updated, et.Spec.Type)
+			lastSeen, err := time.Parse(time.RFC3339, et.Annotations[eventing.LastSeenAnnotationKey])
+			if err != nil || time.Since(lastSeen) > time.Minute {
+				t.Errorf("The last seen time wasn't refreshed: %v", et.Annotations)
+			}
+		}
+	}
+	if diff := cmp.Diff([]string{"new"}, created); diff != "" {
+		t.Error("Unexpected created EventTypes (-want, +got) =", diff)
+	}
+	if diff := cmp.Diff([]string{"stale"}, updated); diff != "" {
+		t.Error("Unexpected updated EventTypes (-want, +got) =", diff)
+	}
+
+	// The recorded event types are only registered once.
+	client.ClearActions()
+	r.Flush(context.Background())
+	if actions := client.Actions(); len(actions) != 0 {
+		t.Error("Unexpected actions after a flush without events:", actions)
+	}
+
+	_, err := client.EventingV1beta1().EventTypes("ns").Get(context.Background(), "manual", metav1.GetOptions{})
+	if err != nil {
+		t.Error("The EventType registered by hand is gone:", err)
+	}
+}
+
+func TestEventTypeRecorderSampling(t *testing.T) {
+	r := NewEventTypeRecorder(nil, nil, nil, 0, time.Minute, zap.NewNop())
+	event := cloudevents.NewEvent()
+	event.SetType("type")
+	for i := 0; i < 10; i++ {
+		r.Record("ns", "name", &event)
+	}
+	if len(r.observed) != 0 {
+		t.Error("Unexpected recorded event types without sampling:", r.observed)
+	}
+}
+
+func TestEventTypeRecorderName(t *testing.T) {
+	a := observedEventType{broker: "name", eventType: "type", source: "source"}
+	b := observedEventType{broker: "name", eventType: "types", source: "ource"}
+	if a.name() == b.name() {
+		t.Errorf("Distinct event types share the name %s: %+v, %+v", a.name(), a, b)
+	}
+}
+
+func TestEventTypeRecorderMaxEventTypes(t *testing.T) {
+	objects := []runtime.Object{
+		makeBroker("name", "ns"),
+		reconcilertestingv1.NewEventType("auto", "ns",
+			reconcilertestingv1.WithEventTypeType("auto"),
+			reconcilertestingv1.WithEventTypeSource(&apis.URL{Path: "source"}),
+			reconcilertestingv1.WithEventTypeBroker("name"),
+			reconcilertestingv1.WithEventTypeAutoDiscovered(time.Now())),
+	}
+	listers := reconcilertestingv1.NewListers(objects)
+	client := fake.NewSimpleClientset(objects...)
+	client.ClearActions()
+
+	r := NewEventTypeRecorder(client, listers.GetBrokerLister(), listers.GetEventTypeLister(), 1, time.Minute, zap.NewNop())
+	r.maxEventTypes = 3
+	for _, eventType := range []string{"a", "b", "c", "d"} {
+		event := cloudevents.NewEvent()
+		event.SetType(eventType)
+		event.SetSource("source")
+		r.Record("ns", "name", &event)
+	}
+	if len(r.observed) != 3 {
+		t.Error("Unexpected number of recorded event types:", len(r.observed))
+	}
+	r.Flush(context.Background())
+
+	// The Broker already has an auto-discovered EventType.
+	created := 0
+	for _, action := range client.Actions() {
+		if action.GetVerb() == "create" {
+			created++
+		}
+	}
+	if created != 2 {
+		t.Error("Unexpected number of created EventTypes:", created)
+	}
+}
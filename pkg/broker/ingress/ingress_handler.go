@@ -32,6 +32,7 @@ import (
 	"go.opencensus.io/trace"
 	"go.uber.org/zap"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
 
 	"knative.dev/eventing/pkg/apis/eventing"
 	eventingv1 "knative.dev/eventing/pkg/apis/eventing/v1"
@@ -59,6 +60,15 @@ type Handler struct {
 	Reporter StatsReporter
 	// BrokerLister gets broker objects
 	BrokerLister eventinglisters.BrokerLister
+	// EventTypeIndexer looks up the EventTypes of the events by Broker and type, holding the
+	// broker.EventTypeIndex. The event types aren't checked when it's nil
+	EventTypeIndexer cache.Indexer
+	// EventTypeRecorder records the event types received by the Brokers, nil when they aren't
+	// auto-discovered
+	EventTypeRecorder *EventTypeRecorder
+	// SchemaCache holds the compiled JSON schemas of the EventTypes, nil when the events aren't
+	// validated
+	SchemaCache *broker.SchemaCache
 
 	Logger *zap.Logger
 }
@@ -81,15 +91,14 @@ func guessChannelAddress(name, namespace, domain string) string {
 	return url.String()
 }
 
-func (h *Handler) getChannelAddress(name, namespace string) (string, error) {
-	broker, err := h.getBroker(name, namespace)
-	if err != nil {
-		return "", err
+func getChannelAddress(b *eventingv1.Broker) (string, error) {
+	if b == nil {
+		return "", fmt.Errorf("Broker not found")
 	}
-	if broker.Status.Annotations == nil {
+	if b.Status.Annotations == nil {
 		return "", fmt.Errorf("Broker status annotations uninitialized")
 	}
-	address, present := broker.Status.Annotations[eventing.BrokerChannelAddressStatusAnnotationKey]
+	address, present := b.Status.Annotations[eventing.BrokerChannelAddressStatusAnnotationKey]
 	if !present {
 		return "", fmt.Errorf("Channel address not found in broker status annotations")
 	}
@@ -127,6 +136,11 @@ func (h *Handler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
 		return
 	}
 
+	if kncloudevents.IsBatch(request.Header) {
+		h.serveBatch(writer, request, nsBrokerName[1], nsBrokerName[2])
+		return
+	}
+
 	ctx := request.Context()
 
 	message := cehttp.NewMessageFromHttpRequest(request)
@@ -173,7 +187,8 @@ func (h *Handler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
 		eventType: event.Type(),
 	}
 
-	statusCode, dispatchTime := h.receive(ctx, request.Header, event, brokerNamespace, brokerName)
+	b, _ := h.getBroker(brokerName, brokerNamespace)
+	statusCode, dispatchTime := h.receive(ctx, request.Header, event, b, brokerNamespace, brokerName)
 	if dispatchTime > noDuration {
 		_ = h.Reporter.ReportEventDispatchTime(reporterArgs, statusCode, dispatchTime)
 	}
@@ -182,7 +197,65 @@ func (h *Handler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
 	writer.WriteHeader(statusCode)
 }
 
-func (h *Handler) receive(ctx context.Context, headers http.Header, event *cloudevents.Event, brokerNamespace, brokerName string) (int, time.Duration) {
+// serveBatch handles a request in the CloudEvents batched content mode. Each event of the batch
+// is sent to the Broker on its own, the response reporting the status of each event.
+func (h *Handler) serveBatch(writer http.ResponseWriter, request *http.Request, brokerNamespace, brokerName string) {
+	events, errs, err := kncloudevents.ReadBatchEntries(request.Body)
+	if err != nil {
+		h.Logger.Warn("failed to extract the events from the request", zap.Error(err))
+		writer.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	brokerNamespacedName := types.NamespacedName{
+		Name:      brokerName,
+		Namespace: brokerNamespace,
+	}
+
+	ctx, span := trace.StartSpan(request.Context(), tracing.BrokerMessagingDestination(brokerNamespacedName))
+	defer span.End()
+
+	if span.IsRecordingEvents() {
+		span.AddAttributes(
+			tracing.MessagingSystemAttribute,
+			tracing.MessagingProtocolHTTP,
+			tracing.BrokerMessagingDestinationAttribute(brokerNamespacedName),
+		)
+	}
+
+	b, _ := h.getBroker(brokerName, brokerNamespace)
+	results := make([]kncloudevents.BatchResult, len(events))
+	for i, event := range events {
+		results[i].Index = i
+		if errs[i] != nil {
+			h.Logger.Warn("failed to extract event from batch", zap.Error(errs[i]))
+			results[i].Status = http.StatusBadRequest
+			results[i].Error = errs[i].Error()
+			continue
+		}
+		results[i].ID = event.ID()
+
+		reporterArgs := &ReportArgs{
+			ns:        brokerNamespace,
+			broker:    brokerName,
+			eventType: event.Type(),
+		}
+		statusCode, dispatchTime := h.receive(ctx, request.Header, event, b, brokerNamespace, brokerName)
+		if dispatchTime > noDuration {
+			_ = h.Reporter.ReportEventDispatchTime(reporterArgs, statusCode, dispatchTime)
+		}
+		_ = h.Reporter.ReportEventCount(reporterArgs, statusCode)
+		results[i].Status = statusCode
+	}
+
+	if err := kncloudevents.WriteBatchResponse(writer, results); err != nil {
+		h.Logger.Warn("failed to write the batch response", zap.Error(err))
+	}
+}
+
+// receive sends the event to the Broker, b being nil when it can't be found. Like for its channel
+// address, the event isn't rejected then.
+func (h *Handler) receive(ctx context.Context, headers http.Header, event *cloudevents.Event, b *eventingv1.Broker, brokerNamespace, brokerName string) (int, time.Duration) {
 
 	// Setting the extension as a string as the CloudEvents sdk does not support non-string extensions.
 	event.SetExtension(broker.EventArrivalTime, cloudevents.Timestamp{Time: time.Now()})
@@ -196,7 +269,31 @@ func (h *Handler) receive(ctx context.Context, headers http.Header, event *cloud
 		return http.StatusBadRequest, noDuration
 	}
 
-	channelAddress, err := h.getChannelAddress(brokerName, brokerNamespace)
+	if !h.eventTypeAllowed(b, event) {
+		h.Logger.Debug("rejecting event with an unregistered type", zap.String("event.type", event.Type()), zap.String("event.source", event.Source()))
+		_ = h.Reporter.ReportEventTypeRejection(&ReportArgs{
+			ns:        brokerNamespace,
+			broker:    brokerName,
+			eventType: event.Type(),
+		})
+		return http.StatusForbidden, noDuration
+	}
+
+	if err := h.validateSchema(b, event); err != nil {
+		h.Logger.Debug("the event data doesn't match its schema", zap.String("event.type", event.Type()), zap.String("event.id", event.ID()), zap.Error(err))
+		dls := b.Status.DeadLetterSinkURI
+		if b.GetAnnotations()[eventing.BrokerSchemaValidationAnnotationKey] != eventing.BrokerSchemaValidationDeadLetter || dls == nil {
+			return http.StatusBadRequest, noDuration
+		}
+		event.SetExtension(broker.SchemaErrorAttribute, err.Error())
+		return h.send(ctx, headers, event, dls.String())
+	}
+
+	if h.EventTypeRecorder != nil {
+		h.EventTypeRecorder.Record(brokerNamespace, brokerName, event)
+	}
+
+	channelAddress, err := getChannelAddress(b)
 	if err != nil {
 		h.Logger.Warn("Failed to get channel address, falling back on guess", zap.Error(err))
 		channelAddress = guessChannelAddress(brokerName, brokerNamespace, network.GetClusterDomainName())
@@ -205,6 +302,43 @@ func (h *Handler) receive(ctx context.Context, headers http.Header, event *cloud
 	return h.send(ctx, headers, event, channelAddress)
 }
 
+// eventTypeAllowed returns false when the Broker only accepts the registered event types and
+// none of its EventTypes matches the type and source of the event.
+func (h *Handler) eventTypeAllowed(b *eventingv1.Broker, event *cloudevents.Event) bool {
+	if h.EventTypeIndexer == nil || b == nil {
+		return true
+	}
+	if b.GetAnnotations()[eventing.BrokerEventTypePolicyAnnotationKey] != eventing.BrokerEventTypePolicyRegistered {
+		return true
+	}
+	et, err := broker.LookupEventType(h.EventTypeIndexer, b.Namespace, b.Name, event)
+	if err != nil {
+		h.Logger.Warn("Failed to look up the EventType", zap.Error(err))
+		return false
+	}
+	return et != nil
+}
+
+// validateSchema validates the data of the event against the JSON schema of its EventType when
+// the Broker validates the events.
+func (h *Handler) validateSchema(b *eventingv1.Broker, event *cloudevents.Event) error {
+	if h.SchemaCache == nil || h.EventTypeIndexer == nil || b == nil {
+		return nil
+	}
+	if _, ok := b.GetAnnotations()[eventing.BrokerSchemaValidationAnnotationKey]; !ok {
+		return nil
+	}
+	et, err := broker.LookupEventType(h.EventTypeIndexer, b.Namespace, b.Name, event)
+	if err != nil {
+		h.Logger.Warn("Failed to look up the EventType", zap.Error(err))
+		return nil
+	}
+	if et == nil {
+		return nil
+	}
+	return h.SchemaCache.Validate(et, event)
+}
+
 func (h *Handler) send(ctx context.Context, headers http.Header, event *cloudevents.Event, target string) (int, time.Duration) {
 
 	request, err := h.Sender.NewCloudEventRequestWithTarget(ctx, target)
@@ -18,26 +18,33 @@ package ingress
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"io"
 	nethttp "net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/cloudevents/sdk-go/v2/binding"
 	"github.com/cloudevents/sdk-go/v2/client"
 	"github.com/cloudevents/sdk-go/v2/event"
 	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"go.uber.org/zap"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 
 	"knative.dev/eventing/pkg/apis/eventing"
 	eventingv1 "knative.dev/eventing/pkg/apis/eventing/v1"
+	eventingv1beta1 "knative.dev/eventing/pkg/apis/eventing/v1beta1"
 	broker "knative.dev/eventing/pkg/broker"
 	"knative.dev/eventing/pkg/kncloudevents"
 	reconcilertestingv1 "knative.dev/eventing/pkg/reconciler/testing/v1"
+	"knative.dev/pkg/apis"
 	duckv1 "knative.dev/pkg/apis/duck/v1"
 )
 
@@ -62,6 +69,7 @@ func TestHandler_ServeHTTP(t *testing.T) {
 		reporter        StatsReporter
 		defaulter       client.EventDefaulter
 		brokers         []*eventingv1.Broker
+		eventTypes      []*eventingv1beta1.EventType
 	}{
 		{
 			name:       "invalid method PATCH",
@@ -208,6 +216,71 @@ func TestHandler_ServeHTTP(t *testing.T) {
 				makeBroker("name", "ns"),
 			},
 		},
+		{
+			name:       "unregistered event type",
+			method:     nethttp.MethodPost,
+			uri:        "/ns/name",
+			body:       getValidEvent(),
+			statusCode: nethttp.StatusForbidden,
+			handler:    handler(),
+			reporter:   &mockReporter{StatusCode: nethttp.StatusForbidden, EventTypeRejected: true},
+			defaulter:  broker.TTLDefaulter(logger, 100),
+			brokers: []*eventingv1.Broker{
+				withEventTypePolicy(makeBroker("name", "ns"), eventing.BrokerEventTypePolicyRegistered),
+			},
+			eventTypes: []*eventingv1beta1.EventType{
+				reconcilertestingv1.NewEventType("other-type", "ns",
+					reconcilertestingv1.WithEventTypeType("other"),
+					reconcilertestingv1.WithEventTypeBroker("name")),
+				reconcilertestingv1.NewEventType("other-source", "ns",
+					reconcilertestingv1.WithEventTypeType("type"),
+					reconcilertestingv1.WithEventTypeSource(apis.HTTP("other-source")),
+					reconcilertestingv1.WithEventTypeBroker("name")),
+				reconcilertestingv1.NewEventType("other-broker", "ns",
+					reconcilertestingv1.WithEventTypeType("type"),
+					reconcilertestingv1.WithEventTypeBroker("other")),
+				reconcilertestingv1.NewEventType("other-namespace", "other-ns",
+					reconcilertestingv1.WithEventTypeType("type"),
+					reconcilertestingv1.WithEventTypeBroker("name")),
+			},
+		},
+		{
+			name:       "registered event type",
+			method:     nethttp.MethodPost,
+			uri:        "/ns/name",
+			body:       getValidEvent(),
+			statusCode: senderResponseStatusCode,
+			handler:    handler(),
+			reporter:   &mockReporter{StatusCode: senderResponseStatusCode, EventDispatchTimeReported: true},
+			defaulter:  broker.TTLDefaulter(logger, 100),
+			brokers: []*eventingv1.Broker{
+				withEventTypePolicy(makeBroker("name", "ns"), eventing.BrokerEventTypePolicyRegistered),
+			},
+			eventTypes: []*eventingv1beta1.EventType{
+				reconcilertestingv1.NewEventType("type", "ns",
+					reconcilertestingv1.WithEventTypeType("type"),
+					reconcilertestingv1.WithEventTypeSource(&apis.URL{Path: "source"}),
+					reconcilertestingv1.WithEventTypeBroker("name")),
+			},
+		},
+		{
+			name:       "registered event type without source",
+			method:     nethttp.MethodPost,
+			uri:        "/ns/name",
+			body:       getValidEvent(),
+			statusCode: senderResponseStatusCode,
+			handler:    handler(),
+			reporter:   &mockReporter{StatusCode: senderResponseStatusCode, EventDispatchTimeReported: true},
+			defaulter:  broker.TTLDefaulter(logger, 100),
+			brokers: []*eventingv1.Broker{
+				withEventTypePolicy(makeBroker("name", "ns"), eventing.BrokerEventTypePolicyRegistered),
+			},
+			eventTypes: []*eventingv1beta1.EventType{
+				reconcilertestingv1.NewEventType("type", "ns",
+					reconcilertestingv1.WithEventTypeType("type"),
+					reconcilertestingv1.WithEventTypeBroker("name")),
+			},
+		},
 		{
 			name:       "pass headers to handler",
 			method:     nethttp.MethodPost,
@@ -251,7 +324,7 @@ func TestHandler_ServeHTTP(t *testing.T) {
 				request.Header.Add(cehttp.ContentType, event.ApplicationCloudEventsJSON)
 			}
 
-			brokers := make([]runtime.Object, 0, len(tc.brokers))
+			brokers := make([]runtime.Object, 0, len(tc.brokers)+len(tc.eventTypes))
 			for _, b := range tc.brokers {
 				// Write the channel address in the broker status annotation unless explicitly set to nil
 				if b.Status.Annotations != nil {
@@ -263,14 +336,18 @@ func TestHandler_ServeHTTP(t *testing.T) {
 				}
 				brokers = append(brokers, b)
 			}
+			for _, et := range tc.eventTypes {
+				brokers = append(brokers, et)
+			}
 			listers := reconcilertestingv1.NewListers(brokers)
 			sender, _ := kncloudevents.NewHTTPMessageSenderWithTarget("")
 			h := &Handler{
-				Sender:       sender,
-				Defaulter:    tc.defaulter,
-				Reporter:     &mockReporter{},
-				Logger:       logger,
-				BrokerLister: listers.GetBrokerLister(),
+				Sender:           sender,
+				Defaulter:        tc.defaulter,
+				Reporter:         &mockReporter{},
+				Logger:           logger,
+				BrokerLister:     listers.GetBrokerLister(),
+				EventTypeIndexer: listers.GetEventTypeIndexer(broker.EventTypeIndexers),
 			}
 
 			h.ServeHTTP(recorder, request)
@@ -297,6 +374,85 @@ func TestHandler_ServeHTTP(t *testing.T) {
 	}
 }
 
+func TestHandler_ServeHTTPBatch(t *testing.T) {
+	logger := zap.NewNop()
+
+	var mutex sync.Mutex
+	var received []*event.Event
+	s := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		e, err := binding.ToEvent(context.Background(), cehttp.NewMessageFromHttpRequest(r))
+		if err != nil {
+			t.Error("Unable to read the event:", err)
+		}
+		mutex.Lock()
+		received = append(received, e)
+		mutex.Unlock()
+		w.WriteHeader(senderResponseStatusCode)
+	}))
+	defer s.Close()
+
+	b := makeBroker("name", "ns")
+	b.Status.Annotations[eventing.BrokerChannelAddressStatusAnnotationKey] = s.URL
+	listers := reconcilertestingv1.NewListers([]runtime.Object{b})
+	sender, _ := kncloudevents.NewHTTPMessageSenderWithTarget("")
+	h := &Handler{
+		Sender:       sender,
+		Defaulter:    broker.TTLDefaulter(logger, 100),
+		Reporter:     &mockReporter{},
+		Logger:       logger,
+		BrokerLister: listers.GetBrokerLister(),
+	}
+
+	valid := event.New()
+	valid.SetType("type")
+	valid.SetSource("source")
+	valid.SetID("1")
+	expired := valid.Clone()
+	expired.SetID("3")
+	_ = broker.SetTTL(expired.Context, 0)
+	body, _ := json.Marshal([]interface{}{
+		valid,
+		map[string]string{"specversion": "1.0", "id": "2", "type": "type"},
+		expired,
+	})
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(nethttp.MethodPost, "/ns/name", bytes.NewBuffer(body))
+	request.Header.Set(cehttp.ContentType, event.ApplicationCloudEventsBatchJSON)
+	h.ServeHTTP(recorder, request)
+
+	result := recorder.Result()
+	if result.StatusCode != nethttp.StatusMultiStatus {
+		t.Errorf("expected status code %d got %d", nethttp.StatusMultiStatus, result.StatusCode)
+	}
+	var response kncloudevents.BatchResponse
+	if err := json.NewDecoder(result.Body).Decode(&response); err != nil {
+		t.Fatal("Unable to read the response:", err)
+	}
+	want := []kncloudevents.BatchResult{
+		{Index: 0, ID: "1", Status: senderResponseStatusCode},
+		{Index: 1, Status: nethttp.StatusBadRequest},
+		{Index: 2, ID: "3", Status: nethttp.StatusBadRequest},
+	}
+	if diff := cmp.Diff(want, response.Results, cmpopts.IgnoreFields(kncloudevents.BatchResult{}, "Error")); diff != "" {
+		t.Error("Unexpected results (-want, +got) =", diff)
+	}
+	if response.Results[1].Error == "" {
+		t.Error("Expected the error of the invalid event")
+	}
+
+	// The TTL and the arrival time are set on each event.
+	if len(received) != 1 {
+		t.Fatalf("expected 1 event sent to the channel, got %d", len(received))
+	}
+	if ttl, err := broker.GetTTL(received[0].Context); err != nil || ttl != 100 {
+		t.Errorf("expected the default TTL, got %d, %v", ttl, err)
+	}
+	if _, ok := received[0].Extensions()[broker.EventArrivalTime]; !ok {
+		t.Error("expected the arrival time to be set")
+	}
+}
+
 type svc struct {
 	receivedHeaders nethttp.Header
 }
@@ -306,6 +462,113 @@ func (s *svc) ServeHTTP(w nethttp.ResponseWriter, req *nethttp.Request) {
 	w.WriteHeader(senderResponseStatusCode)
 }
 
+func TestHandler_ServeHTTPSchemaValidation(t *testing.T) {
+	const schema = `{"type": "object", "required": ["id"]}`
+	testCases := map[string]struct {
+		validation     string
+		data           string
+		wantStatusCode int
+		wantChannel    bool
+		wantDeadLetter bool
+	}{
+		"valid": {
+			validation:     eventing.BrokerSchemaValidationReject,
+			data:           `{"id": 1}`,
+			wantStatusCode: senderResponseStatusCode,
+			wantChannel:    true,
+		},
+		"invalid, rejected": {
+			validation:     eventing.BrokerSchemaValidationReject,
+			data:           `{"name": "a"}`,
+			wantStatusCode: nethttp.StatusBadRequest,
+		},
+		"invalid, dead lettered": {
+			validation:     eventing.BrokerSchemaValidationDeadLetter,
+			data:           `{"name": "a"}`,
+			wantStatusCode: nethttp.StatusAccepted,
+			wantDeadLetter: true,
+		},
+		"invalid, not validated": {
+			data:           `{"name": "a"}`,
+			wantStatusCode: senderResponseStatusCode,
+			wantChannel:    true,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			logger := zap.NewNop()
+			var channelReceived, deadLetterReceived []*event.Event
+			var mutex sync.Mutex
+			receiver := func(received *[]*event.Event, statusCode int) *httptest.Server {
+				return httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+					e, err := binding.ToEvent(context.Background(), cehttp.NewMessageFromHttpRequest(r))
+					if err != nil {
+						t.Error("Unable to read the event:", err)
+					}
+					mutex.Lock()
+					*received = append(*received, e)
+					mutex.Unlock()
+					w.WriteHeader(statusCode)
+				}))
+			}
+			channel := receiver(&channelReceived, senderResponseStatusCode)
+			defer channel.Close()
+			deadLetter := receiver(&deadLetterReceived, nethttp.StatusAccepted)
+			defer deadLetter.Close()
+
+			b := makeBroker("name", "ns")
+			b.Status.Annotations[eventing.BrokerChannelAddressStatusAnnotationKey] = channel.URL
+			b.Status.DeadLetterSinkURI, _ = apis.ParseURL(deadLetter.URL)
+			if tc.validation != "" {
+				b.Annotations = map[string]string{eventing.BrokerSchemaValidationAnnotationKey: tc.validation}
+			}
+			et := reconcilertestingv1.NewEventType("type", "ns",
+				reconcilertestingv1.WithEventTypeType("type"),
+				reconcilertestingv1.WithEventTypeBroker("name"),
+				reconcilertestingv1.WithEventTypeSchemaData(schema))
+			listers := reconcilertestingv1.NewListers([]runtime.Object{b, et})
+			sender, _ := kncloudevents.NewHTTPMessageSenderWithTarget("")
+			h := &Handler{
+				Sender:           sender,
+				Defaulter:        broker.TTLDefaulter(logger, 100),
+				Reporter:         &mockReporter{},
+				Logger:           logger,
+				BrokerLister:     listers.GetBrokerLister(),
+				EventTypeIndexer: listers.GetEventTypeIndexer(broker.EventTypeIndexers),
+				SchemaCache:      broker.NewSchemaCache(),
+			}
+
+			e := event.New()
+			e.SetType("type")
+			e.SetSource("source")
+			e.SetID("1234")
+			_ = e.SetData(event.ApplicationJSON, []byte(tc.data))
+			body, _ := e.MarshalJSON()
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(nethttp.MethodPost, "/ns/name", bytes.NewBuffer(body))
+			request.Header.Set(cehttp.ContentType, event.ApplicationCloudEventsJSON)
+			h.ServeHTTP(recorder, request)
+
+			if got := recorder.Result().StatusCode; got != tc.wantStatusCode {
+				t.Errorf("expected status code %d got %d", tc.wantStatusCode, got)
+			}
+			mutex.Lock()
+			defer mutex.Unlock()
+			if got := len(channelReceived) == 1; got != tc.wantChannel {
+				t.Errorf("expected the channel to receive the event: %v, got %d events", tc.wantChannel, len(channelReceived))
+			}
+			if got := len(deadLetterReceived) == 1; got != tc.wantDeadLetter {
+				t.Fatalf("expected the dead letter sink to receive the event: %v, got %d events", tc.wantDeadLetter, len(deadLetterReceived))
+			}
+			if tc.wantDeadLetter {
+				if _, ok := deadLetterReceived[0].Extensions()[broker.SchemaErrorAttribute]; !ok {
+					t.Errorf("expected the %s extension, got %v", broker.SchemaErrorAttribute, deadLetterReceived[0].Extensions())
+				}
+			}
+		})
+	}
+}
+
 func handler() nethttp.Handler {
 	return nethttp.HandlerFunc(func(writer nethttp.ResponseWriter, request *nethttp.Request) {
 		writer.WriteHeader(senderResponseStatusCode)
@@ -315,6 +578,7 @@ func handler() nethttp.Handler {
 type mockReporter struct {
 	StatusCode                int
 	EventDispatchTimeReported bool
+	EventTypeRejected         bool
 }
 
 func (r *mockReporter) ReportEventCount(_ *ReportArgs, responseCode int) error {
@@ -327,6 +591,11 @@ func (r *mockReporter) ReportEventDispatchTime(_ *ReportArgs, _ int, _ time.Dura
 	return nil
 }
 
+func (r *mockReporter) ReportEventTypeRejection(_ *ReportArgs) error {
+	r.EventTypeRejected = true
+	return nil
+}
+
 func getValidEvent() io.Reader {
 	e := event.New()
 	e.SetType("type")
@@ -363,6 +632,11 @@ func makeBroker(name, namespace string) *eventingv1.Broker {
 	}
 }
 
+func withEventTypePolicy(b *eventingv1.Broker, policy string) *eventingv1.Broker {
+	b.Annotations = map[string]string{eventing.BrokerEventTypePolicyAnnotationKey: policy}
+	return b
+}
+
 func withUninitializedAnnotations(b *eventingv1.Broker) *eventingv1.Broker {
 	b.Status.Annotations = nil
 	return b
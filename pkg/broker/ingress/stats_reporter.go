@@ -49,6 +49,14 @@ var (
 		stats.UnitMilliseconds,
 	)
 
+	// rejectedEventCountM is a counter which records the number of events
+	// rejected by the event type policy of the Broker.
+	rejectedEventCountM = stats.Int64(
+		"rejected_event_count",
+		"Number of events rejected because their type isn't registered for the Broker",
+		stats.UnitDimensionless,
+	)
+
 	// Create the tag keys that will be used to add tags to our measurements.
 	// Tag keys must conform to the restrictions described in
 	// go.opencensus.io/tag/validate.go. Currently those restrictions are:
@@ -73,6 +81,7 @@ func init() {
 type StatsReporter interface {
 	ReportEventCount(args *ReportArgs, responseCode int) error
 	ReportEventDispatchTime(args *ReportArgs, responseCode int, d time.Duration) error
+	ReportEventTypeRejection(args *ReportArgs) error
 }
 
 var _ StatsReporter = (*reporter)(nil)
@@ -114,6 +123,12 @@ func register() {
 			Aggregation: view.Distribution(metrics.Buckets125(1, 10000)...), // 1, 2, 5, 10, 20, 50, 100, 500, 1000, 5000, 10000
 			TagKeys:     tagKeys,
 		},
+		&view.View{
+			Description: rejectedEventCountM.Description(),
+			Measure:     rejectedEventCountM,
+			Aggregation: view.Count(),
+			TagKeys:     []tag.Key{eventTypeKey, broker.ContainerTagKey, broker.UniqueTagKey},
+		},
 	)
 	if err != nil {
 		log.Printf("failed to register opencensus views, %s", err)
@@ -141,16 +156,33 @@ func (r *reporter) ReportEventDispatchTime(args *ReportArgs, responseCode int, d
 	return nil
 }
 
-func (r *reporter) generateTag(args *ReportArgs, responseCode int) (context.Context, error) {
-	ctx := metricskey.WithResource(emptyContext, resource.Resource{
+// ReportEventTypeRejection captures the events rejected by the event type policy.
+func (r *reporter) ReportEventTypeRejection(args *ReportArgs) error {
+	ctx, err := tag.New(
+		r.resourceContext(args),
+		tag.Insert(broker.ContainerTagKey, r.container),
+		tag.Insert(broker.UniqueTagKey, r.uniqueName),
+		tag.Insert(eventTypeKey, args.eventType))
+	if err != nil {
+		return err
+	}
+	metrics.Record(ctx, rejectedEventCountM.M(1))
+	return nil
+}
+
+func (r *reporter) resourceContext(args *ReportArgs) context.Context {
+	return metricskey.WithResource(emptyContext, resource.Resource{
 		Type: eventingmetrics.ResourceTypeKnativeBroker,
 		Labels: map[string]string{
 			eventingmetrics.LabelNamespaceName: args.ns,
 			eventingmetrics.LabelBrokerName:    args.broker,
 		},
 	})
+}
+
+func (r *reporter) generateTag(args *ReportArgs, responseCode int) (context.Context, error) {
 	return tag.New(
-		ctx,
+		r.resourceContext(args),
 		tag.Insert(broker.ContainerTagKey, r.container),
 		tag.Insert(broker.UniqueTagKey, r.uniqueName),
 		tag.Insert(eventTypeKey, args.eventType),
@@ -73,6 +73,16 @@ func TestStatsReporter(t *testing.T) {
 	})
 	metricstest.AssertMetric(t, metricstest.DistributionCountOnlyMetric("event_dispatch_latencies", 2, wantTags))
 	metricstest.CheckDistributionData(t, "event_dispatch_latencies", wantTags, 2, 1100.0, 9100.0)
+
+	// test ReportEventTypeRejection
+	expectSuccess(t, func() error {
+		return r.ReportEventTypeRejection(args)
+	})
+	metricstest.AssertMetric(t, metricstest.IntMetric("rejected_event_count", 1, map[string]string{
+		metrics.LabelEventType:    "testeventtype",
+		broker.LabelUniqueName:    "testpod",
+		broker.LabelContainerName: "testcontainer",
+	}).WithResource(&resource))
 }
 
 func expectSuccess(t *testing.T, f func() error) {
@@ -90,6 +100,7 @@ func resetMetrics() {
 	// OpenCensus metrics carry global state that need to be reset between unit tests.
 	metricstest.Unregister(
 		"event_count",
-		"event_dispatch_latencies")
+		"event_dispatch_latencies",
+		"rejected_event_count")
 	register()
 }
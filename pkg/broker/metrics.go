@@ -33,9 +33,13 @@ const (
 
 	// LabelContainerName is the label for the immutable name of the container.
 	LabelContainerName = metrics.LabelContainerName
+
+	// LabelDestination is the label for the URL of the destination of a limiter.
+	LabelDestination = "destination"
 )
 
 var (
-	ContainerTagKey = tag.MustNewKey(LabelContainerName)
-	UniqueTagKey    = tag.MustNewKey(LabelUniqueName)
+	ContainerTagKey   = tag.MustNewKey(LabelContainerName)
+	UniqueTagKey      = tag.MustNewKey(LabelUniqueName)
+	DestinationTagKey = tag.MustNewKey(LabelDestination)
 )
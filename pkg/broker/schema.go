@@ -0,0 +1,187 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package broker
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/pkg/kmeta"
+
+	"knative.dev/eventing/pkg/apis/eventing/v1beta1"
+	"knative.dev/eventing/pkg/jsonschema"
+)
+
+const (
+	// SchemaErrorAttribute is the name of the CloudEvents extension attribute holding the reason
+	// why the data of an event doesn't match the JSON schema of its EventType, set on the events
+	// sent to a dead letter sink because of it.
+	SchemaErrorAttribute = "knativeschemaerror"
+
+	// EventTypeIndex is the name of the index of the EventTypes by namespace, Broker and type.
+	EventTypeIndex = "broker-type"
+)
+
+// EventTypeIndexers holds the EventTypeIndex, to be added to the EventType informer of the
+// components looking up the EventType of each event.
+var EventTypeIndexers = cache.Indexers{EventTypeIndex: eventTypeIndexFunc}
+
+func eventTypeIndexFunc(obj interface{}) ([]string, error) {
+	et, ok := obj.(*v1beta1.EventType)
+	if !ok {
+		return nil, nil
+	}
+	return []string{eventTypeIndexKey(et.Namespace, et.Spec.Broker, et.Spec.Type)}, nil
+}
+
+// eventTypeIndexKey returns the key of the EventTypes in the EventTypeIndex. Namespaces and
+// Broker names can't contain slashes.
+func eventTypeIndexKey(namespace, brokerName, eventType string) string {
+	return namespace + "/" + brokerName + "/" + eventType
+}
+
+// MatchEventType returns the EventType of the Broker matching the type and source of the event,
+// or nil. An EventType without source matches any source, the EventTypes with the source of the
+// event are preferred.
+func MatchEventType(eventTypes []*v1beta1.EventType, brokerName string, event *cloudevents.Event) *v1beta1.EventType {
+	var match *v1beta1.EventType
+	for _, et := range eventTypes {
+		if et.Spec.Broker != brokerName || et.Spec.Type != event.Type() {
+			continue
+		}
+		if et.Spec.Source.String() == event.Source() {
+			return et
+		}
+		if et.Spec.Source == nil {
+			match = et
+		}
+	}
+	return match
+}
+
+// LookupEventType returns the EventType of the Broker matching the type and source of the event
+// like MatchEventType, looking it up in an indexer holding the EventTypeIndex.
+func LookupEventType(indexer cache.Indexer, namespace, brokerName string, event *cloudevents.Event) (*v1beta1.EventType, error) {
+	objs, err := indexer.ByIndex(EventTypeIndex, eventTypeIndexKey(namespace, brokerName, event.Type()))
+	if err != nil {
+		return nil, err
+	}
+	eventTypes := make([]*v1beta1.EventType, 0, len(objs))
+	for _, obj := range objs {
+		if et, ok := obj.(*v1beta1.EventType); ok {
+			eventTypes = append(eventTypes, et)
+		}
+	}
+	return MatchEventType(eventTypes, brokerName, event), nil
+}
+
+// CompileEventTypeSchema compiles the JSON schema of the EventType, returning nil when its
+// SchemaData doesn't hold a JSON schema, an object.
+func CompileEventTypeSchema(et *v1beta1.EventType) (*jsonschema.Schema, error) {
+	if !strings.HasPrefix(strings.TrimSpace(et.Spec.SchemaData), "{") {
+		return nil, nil
+	}
+	return jsonschema.Compile([]byte(et.Spec.SchemaData))
+}
+
+// ValidateEventData validates the data of the event against the JSON schema. An event without
+// data is validated as null.
+func ValidateEventData(schema *jsonschema.Schema, event *cloudevents.Event) error {
+	data := event.Data()
+	if len(data) == 0 {
+		return schema.ValidateValue(nil)
+	}
+	if ct := event.DataContentType(); ct != "" && !isJSONContentType(ct) {
+		return fmt.Errorf("the data content type %q isn't JSON", ct)
+	}
+	return schema.Validate(data)
+}
+
+func isJSONContentType(ct string) bool {
+	ct = strings.TrimSpace(strings.SplitN(ct, ";", 2)[0])
+	return ct == cloudevents.ApplicationJSON || ct == "text/json" || strings.HasSuffix(ct, "+json")
+}
+
+type schemaCacheEntry struct {
+	generation int64
+	schema     *jsonschema.Schema
+	err        error
+}
+
+// SchemaCache holds the compiled JSON schema of each EventType, so that the schemas are compiled
+// once per EventType generation instead of once per event.
+type SchemaCache struct {
+	mutex   sync.RWMutex
+	entries map[types.UID]schemaCacheEntry
+}
+
+// NewSchemaCache returns an empty SchemaCache.
+func NewSchemaCache() *SchemaCache {
+	return &SchemaCache{entries: make(map[types.UID]schemaCacheEntry)}
+}
+
+// Get returns the compiled JSON schema of the EventType, compiling it if the EventType isn't in
+// the cache yet or its generation changed. It returns nil when the EventType has no JSON schema.
+func (c *SchemaCache) Get(et *v1beta1.EventType) (*jsonschema.Schema, error) {
+	c.mutex.RLock()
+	entry, ok := c.entries[et.UID]
+	c.mutex.RUnlock()
+	if ok && entry.generation == et.Generation {
+		return entry.schema, entry.err
+	}
+
+	schema, err := CompileEventTypeSchema(et)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	// Don't replace an entry compiled by a concurrent call for a newer generation.
+	if current, ok := c.entries[et.UID]; !ok || current.generation <= et.Generation {
+		c.entries[et.UID] = schemaCacheEntry{generation: et.Generation, schema: schema, err: err}
+	}
+	return schema, err
+}
+
+// Validate validates the data of the event against the JSON schema of the EventType. The events
+// of the EventTypes without JSON schema, or with an invalid one, are valid.
+func (c *SchemaCache) Validate(et *v1beta1.EventType, event *cloudevents.Event) error {
+	schema, err := c.Get(et)
+	if err != nil || schema == nil {
+		// The EventType reconciler surfaces the invalid schemas in the status of the EventType.
+		return nil
+	}
+	return ValidateEventData(schema, event)
+}
+
+// EventTypeEventHandler returns the event handler evicting the deleted EventTypes from the
+// cache, to be added to an EventType informer.
+func (c *SchemaCache) EventTypeEventHandler() cache.ResourceEventHandler {
+	return cache.ResourceEventHandlerFuncs{
+		DeleteFunc: func(obj interface{}) {
+			acc, err := kmeta.DeletionHandlingAccessor(obj)
+			if err != nil {
+				return
+			}
+			c.mutex.Lock()
+			defer c.mutex.Unlock()
+			delete(c.entries, acc.GetUID())
+		},
+	}
+}
@@ -18,6 +18,7 @@ package attributes
 
 import (
 	"net/url"
+	"time"
 
 	"github.com/cloudevents/sdk-go/v2/binding"
 	"github.com/cloudevents/sdk-go/v2/binding/transformer"
@@ -28,6 +29,18 @@ const (
 	KnativeErrorCodeExtensionKey       = "knativeerrorcode"
 	KnativeErrorDataExtensionKey       = "knativeerrordata"
 	KnativeErrorDataExtensionMaxLength = 1024
+
+	// The attempts, time, kind and UID extensions are added to the events dead-lettered by
+	// the channel subscriptions and the Broker Triggers. Sources have no dead letter sink.
+	KnativeErrorAttemptsExtensionKey = "knativeerrorattempts"
+	KnativeErrorTimeExtensionKey     = "knativeerrortime"
+	KnativeErrorKindExtensionKey     = "knativeerrorkind"
+	KnativeErrorUIDExtensionKey      = "knativeerroruid"
+
+	// KnativeErrorKindSubscription and KnativeErrorKindTrigger are the values of the
+	// knativeerrorkind extension.
+	KnativeErrorKindSubscription = "Subscription"
+	KnativeErrorKindTrigger      = "Trigger"
 )
 
 // KnativeErrorTransformers returns Transformers which add the specified destination and error code/data extensions.
@@ -40,3 +53,20 @@ func KnativeErrorTransformers(destination url.URL, code int, data string) bindin
 	dataTransformer := transformer.AddExtension(KnativeErrorDataExtensionKey, data)
 	return binding.Transformers{destTransformer, codeTransformer, dataTransformer}
 }
+
+// KnativeErrorDeliveryTransformers returns Transformers which add the number of delivery attempts and the time
+// of the first failed attempt extensions, as well as the kind and UID of the Subscription or Trigger whose
+// delivery failed when uid isn't empty.
+func KnativeErrorDeliveryTransformers(attempts int, firstFailure time.Time, kind string, uid string) binding.Transformers {
+	transformers := binding.Transformers{
+		transformer.AddExtension(KnativeErrorAttemptsExtensionKey, attempts),
+		transformer.AddExtension(KnativeErrorTimeExtensionKey, firstFailure),
+	}
+	if uid != "" {
+		transformers = append(transformers,
+			transformer.AddExtension(KnativeErrorKindExtensionKey, kind),
+			transformer.AddExtension(KnativeErrorUIDExtensionKey, uid),
+		)
+	}
+	return transformers
+}
@@ -21,6 +21,7 @@ import (
 	"math/rand"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/cloudevents/sdk-go/v2/binding"
 	cebindingtest "github.com/cloudevents/sdk-go/v2/binding/test"
@@ -116,6 +117,51 @@ func TestKnativeErrorTransformers(t *testing.T) {
 	}
 }
 
+// Test the KnativeErrorDeliveryTransformers() functionality
+func TestKnativeErrorDeliveryTransformers(t *testing.T) {
+	firstFailure := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name string
+		kind string
+		uid  string
+		want map[string]interface{}
+	}{{
+		name: "Without Ref",
+		want: map[string]interface{}{
+			KnativeErrorAttemptsExtensionKey: 3,
+			KnativeErrorTimeExtensionKey:     firstFailure,
+		},
+	}, {
+		name: "With Ref",
+		kind: KnativeErrorKindTrigger,
+		uid:  "abc-123",
+		want: map[string]interface{}{
+			KnativeErrorAttemptsExtensionKey: 3,
+			KnativeErrorTimeExtensionKey:     firstFailure,
+			KnativeErrorKindExtensionKey:     KnativeErrorKindTrigger,
+			KnativeErrorUIDExtensionKey:      "abc-123",
+		},
+	}}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			inputEvent := cetest.MinEvent()
+			wantEvent := inputEvent.Clone()
+			for k, v := range testCase.want {
+				wantEvent.SetExtension(k, v)
+			}
+
+			cebindingtest.RunTransformerTests(t, context.Background(), []cebindingtest.TransformerTestArgs{{
+				Name:         "Add Extensions To Event",
+				InputEvent:   inputEvent,
+				WantEvent:    wantEvent,
+				Transformers: KnativeErrorDeliveryTransformers(3, firstFailure, testCase.kind, testCase.uid),
+			}})
+		})
+	}
+}
+
 // randomString returns a randomly generated string of the specified length
 func randomString(t *testing.T, length int) string {
 	bytes := make([]byte, length)
@@ -0,0 +1,130 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fanout
+
+import (
+	"context"
+	nethttp "net/http"
+	"sync"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"go.uber.org/zap"
+
+	"knative.dev/eventing/pkg/channel"
+	"knative.dev/eventing/pkg/kncloudevents"
+)
+
+// subscriptionBatcher accumulates the events of a Subscription with a BatchConfig.
+type subscriptionBatcher struct {
+	*kncloudevents.Batcher
+
+	mutex sync.RWMutex
+	sub   Subscription
+}
+
+func (b *subscriptionBatcher) subscription() Subscription {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	return b.sub
+}
+
+func (b *subscriptionBatcher) setSubscription(sub Subscription) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.sub = sub
+}
+
+// setBatchers creates the batchers of the new batched subscriptions, updates the existing ones
+// and closes the batchers of the removed subscriptions, sending their pending events.
+// The dispatcher must implement channel.BatchMessageDispatcher for the events to be batched.
+func (f *FanoutMessageHandler) setBatchers(subs []Subscription) {
+	dispatcher, ok := f.dispatcher.(channel.BatchMessageDispatcher)
+	if !ok {
+		return
+	}
+	f.batchersMutex.Lock()
+	defer f.batchersMutex.Unlock()
+	batchers := make(map[string]*subscriptionBatcher, len(subs))
+	for _, sub := range subs {
+		if sub.Batch == nil {
+			continue
+		}
+		key := subscriptionKey(sub)
+		if b, ok := f.batchers[key]; ok && b.Config() == *sub.Batch {
+			b.setSubscription(sub)
+			batchers[key] = b
+			continue
+		}
+		b := &subscriptionBatcher{sub: sub}
+		b.Batcher = kncloudevents.NewBatcher(*sub.Batch, func(events []*cloudevents.Event) error {
+			return f.sendBatch(dispatcher, b.subscription(), events)
+		})
+		batchers[key] = b
+	}
+	for key, b := range f.batchers {
+		if batchers[key] != b {
+			go b.Close()
+		}
+	}
+	f.batchers = batchers
+}
+
+func (f *FanoutMessageHandler) batcher(sub Subscription) *subscriptionBatcher {
+	f.batchersMutex.RLock()
+	defer f.batchersMutex.RUnlock()
+	return f.batchers[subscriptionKey(sub)]
+}
+
+// sendBatch sends the events to the subscriber of sub in a single request. The batch outlives
+// the requests of its events, which were already accepted or are waiting for the result.
+func (f *FanoutMessageHandler) sendBatch(dispatcher channel.BatchMessageDispatcher, sub Subscription, events []*cloudevents.Event) error {
+	ctx := contextWithDeliveryRef(kncloudevents.ContextWithUnboundedLimiterWait(context.Background()), sub)
+	_, err := dispatcher.DispatchBatchWithRetries(ctx, events, nil, sub.Subscriber, sub.DeadLetter, sub.RetryConfig)
+	if err != nil {
+		f.logger.Error("Failed to send a batch", zap.String("subscription", subscriptionKey(sub)), zap.Int("size", len(events)), zap.Error(err))
+	}
+	return err
+}
+
+// addToBatch adds the message to the batch of sub, calling done with the result of the batch
+// once it's sent. It returns false when sub isn't batched.
+func (f *FanoutMessageHandler) addToBatch(ctx context.Context, message binding.Message, sub Subscription, done func(*channel.DispatchExecutionInfo, error)) bool {
+	if sub.Batch == nil {
+		return false
+	}
+	b := f.batcher(sub)
+	if b == nil {
+		return false
+	}
+	event, err := binding.ToEvent(ctx, message)
+	_ = message.Finish(nil)
+	if err != nil {
+		done(&channel.DispatchExecutionInfo{Time: channel.NoDuration, ResponseCode: channel.NoResponse}, err)
+		return true
+	}
+	start := time.Now()
+	b.Add(event, func(err error) {
+		info := &channel.DispatchExecutionInfo{Time: time.Since(start), ResponseCode: nethttp.StatusAccepted}
+		if err != nil {
+			info.ResponseCode = channel.NoResponse
+		}
+		done(info, err)
+	})
+	return true
+}
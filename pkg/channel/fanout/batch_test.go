@@ -0,0 +1,100 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fanout
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	bindingshttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"knative.dev/pkg/apis"
+	"knative.dev/pkg/ptr"
+
+	eventingduckv1 "knative.dev/eventing/pkg/apis/duck/v1"
+	"knative.dev/eventing/pkg/channel"
+	"knative.dev/eventing/pkg/kncloudevents"
+)
+
+func TestFanoutMessageHandlerBatch(t *testing.T) {
+	batches := make(chan int, 2)
+	subscriber := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		events, err := kncloudevents.ReadBatch(r.Body)
+		if err != nil {
+			t.Error("Unable to read the batch:", err)
+		}
+		batches <- len(events)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer subscriber.Close()
+
+	sub, err := SubscriberSpecToFanoutConfig(eventingduckv1.SubscriberSpec{
+		UID:           "sub-uid",
+		SubscriberURI: apis.HTTP(subscriber.URL[7:]), // strip the leading 'http://'
+		Delivery: &eventingduckv1.DeliverySpec{
+			MaxBatchSize:  ptr.Int32(2),
+			MaxBatchDelay: ptr.String("PT1H"),
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, &kncloudevents.BatchConfig{MaxSize: 2, MaxDelay: time.Hour}, sub.Batch)
+
+	logger := zap.NewNop()
+	h, err := NewFanoutMessageHandler(logger, channel.NewMessageDispatcher(logger), Config{
+		Subscriptions: []Subscription{*sub},
+	}, channel.NewStatsReporter("testcontainer", "testpod"))
+	require.NoError(t, err)
+
+	// Each request waits for its batch to be sent.
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			event := makeCloudEvent()
+			req := httptest.NewRequest(http.MethodPost, "http://channelname.channelnamespace/", nil)
+			if err := bindingshttp.WriteRequest(context.Background(), binding.ToMessage(&event), req); err != nil {
+				t.Error("Unable to write the request:", err)
+			}
+			resp := httptest.NewRecorder()
+			h.ServeHTTP(resp, req)
+			if resp.Code != http.StatusAccepted {
+				t.Errorf("Unexpected status. Expected %d. Actual %d.", http.StatusAccepted, resp.Code)
+			}
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, 2, <-batches)
+
+	// The pending batch of a removed subscription is sent.
+	event := makeCloudEvent()
+	message := binding.ToMessage(&event)
+	require.True(t, h.addToBatch(context.Background(), message, *sub, func(*channel.DispatchExecutionInfo, error) {}))
+	h.SetSubscriptions(context.Background(), nil)
+	select {
+	case size := <-batches:
+		require.Equal(t, 1, size)
+	case <-time.After(5 * time.Second):
+		t.Fatal("The pending batch wasn't sent")
+	}
+}
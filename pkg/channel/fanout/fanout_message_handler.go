@@ -29,12 +29,18 @@ import (
 	"sync"
 	"time"
 
+	cloudevents "github.com/cloudevents/sdk-go/v2"
 	"github.com/cloudevents/sdk-go/v2/binding"
 	"github.com/cloudevents/sdk-go/v2/binding/buffering"
 	"go.opencensus.io/trace"
 	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/types"
 	eventingduckv1 "knative.dev/eventing/pkg/apis/duck/v1"
 	"knative.dev/eventing/pkg/channel"
+	"knative.dev/eventing/pkg/channel/attributes"
+	"knative.dev/eventing/pkg/channel/wal"
+	"knative.dev/eventing/pkg/eventfilter"
+	"knative.dev/eventing/pkg/eventfilter/jsonpath"
 	"knative.dev/eventing/pkg/kncloudevents"
 )
 
@@ -43,10 +49,42 @@ const (
 )
 
 type Subscription struct {
+	// UID is the UID of the Subscription, added to the messages sent to the DeadLetter.
+	UID types.UID
+	// TriggerUID, when not empty, is the UID of the Trigger of the Subscription, added to the
+	// messages sent to the DeadLetter instead of UID.
+	TriggerUID  types.UID
 	Subscriber  *url.URL
 	Reply       *url.URL
 	DeadLetter  *url.URL
 	RetryConfig *kncloudevents.RetryConfig
+	// DataFilter is stashed to tell if the Filter changed.
+	DataFilter []eventingduckv1.DataFilter
+	// Filter, when not nil, must pass for the message to be sent to the Subscriber.
+	Filter eventfilter.Filter
+	// OrderingKey, when not empty, is the attribute of the events whose value is the key of
+	// their ordering: the events with the same key are sent one after the other, in the order
+	// they were received. The messages of all the Subscriptions are then queued.
+	OrderingKey string
+	// Replay, when not nil, asks for the events retained by the handler before the Subscription
+	// was added. They're sent before the events the handler receives afterwards.
+	Replay *eventingduckv1.Replay
+	// Batch, when not nil, sends the events to the Subscriber in batches. The replies to the
+	// batches aren't forwarded.
+	Batch *kncloudevents.BatchConfig
+}
+
+// subscriptionKey identifies the subscription, e.g. to name its queue or its cursor in the log.
+func subscriptionKey(sub Subscription) string {
+	switch {
+	case sub.UID != "":
+		return string(sub.UID)
+	case sub.Subscriber != nil:
+		return sub.Subscriber.String()
+	case sub.Reply != nil:
+		return sub.Reply.String()
+	}
+	return ""
 }
 
 // Config for a fanout.MessageHandler.
@@ -55,6 +93,18 @@ type Config struct {
 	// AsyncHandler controls whether the Subscriptions are called synchronous or asynchronously.
 	// It is expected to be false when used as a sidecar.
 	AsyncHandler bool `json:"asyncHandler,omitempty"`
+	// Log, when not nil, persists each message before acknowledging it and tracks its delivery
+	// to each Subscription, so that a handler using the same Log replays the messages which
+	// weren't delivered. The Subscriptions are called asynchronously.
+	Log *wal.Log `json:"-"`
+	// Queue, when not nil, dispatches the messages of each Subscription from a bounded queue.
+	// A message is acknowledged once it's added to the queues, so that a slow Subscription
+	// doesn't delay the others. When nil, the messages are queued only while a Subscription
+	// has an OrderingKey.
+	Queue *QueueConfig `json:"queue,omitempty"`
+	// Buffer, when not nil, retains the events received by the handler for the Subscriptions
+	// asking for a Replay.
+	Buffer *ReplayBuffer `json:"-"`
 }
 
 // MessageHandler is an http.Handler but has methods for managing
@@ -72,6 +122,20 @@ type FanoutMessageHandler struct {
 	// AsyncHandler controls whether the Subscriptions are called synchronous or asynchronously.
 	// It is expected to be false when used as a sidecar.
 	asyncHandler bool
+	// log persists the messages when not nil.
+	log *wal.Log
+	// buffer retains the events for the replays when not nil.
+	buffer *ReplayBuffer
+	// queueConfig configures the queues of the subscriptions when not nil.
+	queueConfig *QueueConfig
+
+	queuesMutex sync.RWMutex
+	queues      map[string]*subscriptionQueue
+	// activeQueueConfig configures the queues, it's nil when the messages aren't queued.
+	activeQueueConfig *QueueConfig
+
+	batchersMutex sync.RWMutex
+	batchers      map[string]*subscriptionBatcher
 
 	subscriptionsMutex sync.RWMutex
 	subscriptions      []Subscription
@@ -96,11 +160,30 @@ func NewFanoutMessageHandler(logger *zap.Logger, messageDispatcher channel.Messa
 		timeout:      defaultTimeout,
 		reporter:     reporter,
 		asyncHandler: config.AsyncHandler,
+		log:          config.Log,
+		queueConfig:  config.Queue,
+		buffer:       config.Buffer,
 	}
 	handler.subscriptions = make([]Subscription, len(config.Subscriptions))
 	for i := range config.Subscriptions {
 		handler.subscriptions[i] = config.Subscriptions[i]
 	}
+	if handler.queueConfig != nil {
+		if err := handler.queueConfig.validate(); err != nil {
+			return nil, err
+		}
+	}
+	handler.setQueues(handler.subscriptions)
+	handler.setBatchers(handler.subscriptions)
+	if handler.log != nil {
+		if err := handler.setCursors(handler.subscriptions); err != nil {
+			return nil, err
+		}
+		go handler.replay(handler.GetSubscriptions(context.Background()), handler.log.NextOffset())
+	}
+	if handler.buffer != nil {
+		handler.startReplays(handler.subscriptions)
+	}
 	// The receiver function needs to point back at the handler itself, so set it up after
 	// initialization.
 	receiver, err := channel.NewMessageReceiver(createMessageReceiverFunction(handler), logger, reporter)
@@ -130,15 +213,47 @@ func SubscriberSpecToFanoutConfig(sub eventingduckv1.SubscriberSpec) (*Subscript
 	}
 
 	var retryConfig *kncloudevents.RetryConfig
+	var batch *kncloudevents.BatchConfig
 	if sub.Delivery != nil {
 		if rc, err := kncloudevents.RetryConfigFromDeliverySpec(*sub.Delivery); err != nil {
 			return nil, err
 		} else {
 			retryConfig = &rc
 		}
+		b, err := kncloudevents.BatchConfigFromDeliverySpec(*sub.Delivery)
+		if err != nil {
+			return nil, err
+		}
+		batch = b
+	}
+
+	var filter eventfilter.Filter
+	if len(sub.DataFilter) != 0 {
+		f, err := jsonpath.NewDataFilter(eventingduckv1.DataFilterPredicates(sub.DataFilter)...)
+		if err != nil {
+			return nil, err
+		}
+		filter = f
 	}
 
-	return &Subscription{Subscriber: destination, Reply: reply, DeadLetter: deadLetter, RetryConfig: retryConfig}, nil
+	var orderingKey string
+	if sub.Delivery != nil && sub.Delivery.OrderingKey != nil {
+		orderingKey = *sub.Delivery.OrderingKey
+	}
+
+	return &Subscription{
+		UID:         sub.UID,
+		TriggerUID:  sub.TriggerUID,
+		Subscriber:  destination,
+		Reply:       reply,
+		DeadLetter:  deadLetter,
+		RetryConfig: retryConfig,
+		DataFilter:  sub.DataFilter,
+		Filter:      filter,
+		OrderingKey: orderingKey,
+		Replay:      sub.Replay,
+		Batch:       batch,
+	}, nil
 }
 
 func (f *FanoutMessageHandler) SetSubscriptions(ctx context.Context, subs []Subscription) {
@@ -147,6 +262,16 @@ func (f *FanoutMessageHandler) SetSubscriptions(ctx context.Context, subs []Subs
 	s := make([]Subscription, len(subs))
 	copy(s, subs)
 	f.subscriptions = s
+	f.setQueues(s)
+	f.setBatchers(s)
+	if f.log != nil {
+		if err := f.setCursors(s); err != nil {
+			f.logger.Error("Failed to update the cursors of the log", zap.Error(err))
+		}
+	}
+	if f.buffer != nil {
+		f.startReplays(s)
+	}
 }
 
 func (f *FanoutMessageHandler) GetSubscriptions(ctx context.Context) []Subscription {
@@ -158,9 +283,151 @@ func (f *FanoutMessageHandler) GetSubscriptions(ctx context.Context) []Subscript
 }
 
 func createMessageReceiverFunction(f *FanoutMessageHandler) func(context.Context, channel.ChannelReference, binding.Message, []binding.Transformer, nethttp.Header) error {
+	receiver := createDispatchingMessageReceiverFunction(f)
+	if f.buffer == nil {
+		return receiver
+	}
+	return func(ctx context.Context, ref channel.ChannelReference, message binding.Message, transformers []binding.Transformer, additionalHeaders nethttp.Header) error {
+		bufferedMessage, err := buffering.CopyMessage(ctx, message, transformers...)
+		if err != nil {
+			return err
+		}
+		// We don't need the original message anymore
+		_ = message.Finish(nil)
+
+		event, err := binding.ToEvent(ctx, bufferedMessage)
+		if err != nil {
+			_ = bufferedMessage.Finish(err)
+			return err
+		}
+		retained := retainedEvent{
+			namespace:         ref.Namespace,
+			event:             event,
+			additionalHeaders: additionalHeaders,
+		}
+		var ackOthers func(map[string]bool)
+		if f.log != nil {
+			// The retained event is persisted first, so that its replays are acknowledged.
+			retained.offset, ackOthers, err = f.persist(ref.Namespace, event, additionalHeaders)
+			if err != nil {
+				_ = bufferedMessage.Finish(err)
+				return err
+			}
+			retained.persisted = true
+		}
+		// The replaying subscriptions get the event from the buffer.
+		replaying := f.buffer.append(retained)
+		ctx = context.WithValue(ctx, replayingSubscriptionsKey{}, replaying)
+		if f.log == nil {
+			return receiver(ctx, ref, bufferedMessage, nil, additionalHeaders)
+		}
+		acked := false
+		persisted := persistedOffset{
+			offset: retained.offset,
+			ackOthers: func(dispatched map[string]bool) {
+				acked = true
+				// The replays acknowledge the event for the replaying subscriptions.
+				for key := range replaying {
+					dispatched[key] = true
+				}
+				ackOthers(dispatched)
+			},
+		}
+		err = receiver(context.WithValue(ctx, persistedMessageKey{}, persisted), ref, bufferedMessage, nil, additionalHeaders)
+		if !acked {
+			// The event isn't dispatched to the live subscriptions.
+			persisted.ackOthers(make(map[string]bool))
+		}
+		return err
+	}
+}
+
+func createDispatchingMessageReceiverFunction(f *FanoutMessageHandler) func(context.Context, channel.ChannelReference, binding.Message, []binding.Transformer, nethttp.Header) error {
+	queued := createQueuedMessageReceiverFunction(f)
+	if f.queueConfig != nil {
+		return queued
+	}
+	unqueued := createUnqueuedMessageReceiverFunction(f)
+	return func(ctx context.Context, ref channel.ChannelReference, message binding.Message, transformers []binding.Transformer, additionalHeaders nethttp.Header) error {
+		if f.queueing() != nil {
+			return queued(ctx, ref, message, transformers, additionalHeaders)
+		}
+		return unqueued(ctx, ref, message, transformers, additionalHeaders)
+	}
+}
+
+func createQueuedMessageReceiverFunction(f *FanoutMessageHandler) func(context.Context, channel.ChannelReference, binding.Message, []binding.Transformer, nethttp.Header) error {
+	return func(ctx context.Context, ref channel.ChannelReference, message binding.Message, transformers []binding.Transformer, additionalHeaders nethttp.Header) error {
+		subs := f.liveSubscriptions(ctx)
+
+		if len(subs) == 0 {
+			// Nothing to do here, finish the message and return
+			_ = message.Finish(nil)
+			return nil
+		}
+
+		te := kncloudevents.TypeExtractorTransformer("")
+		transformers = append(transformers, &te)
+		bufferedMessage, err := buffering.CopyMessage(ctx, message, transformers...)
+		if err != nil {
+			return err
+		}
+		// We don't need the original message anymore
+		_ = message.Finish(nil)
+
+		reportArgs := channel.ReportArgs{}
+		reportArgs.EventType = string(te)
+		reportArgs.Ns = ref.Namespace
+		return f.enqueue(ctx, subs, bufferedMessage, additionalHeaders, reportArgs)
+	}
+}
+
+func createUnqueuedMessageReceiverFunction(f *FanoutMessageHandler) func(context.Context, channel.ChannelReference, binding.Message, []binding.Transformer, nethttp.Header) error {
+	if f.log != nil {
+		return func(ctx context.Context, ref channel.ChannelReference, message binding.Message, transformers []binding.Transformer, additionalHeaders nethttp.Header) error {
+			subs := f.liveSubscriptions(ctx)
+
+			if len(subs) == 0 {
+				// Nothing to do here, finish the message and return
+				_ = message.Finish(nil)
+				return nil
+			}
+
+			parentSpan := trace.FromContext(ctx)
+			bufferedMessage, err := buffering.CopyMessage(ctx, message, transformers...)
+			if err != nil {
+				return err
+			}
+			// We don't need the original message anymore
+			_ = message.Finish(nil)
+
+			// The message is acknowledged only once it's persisted.
+			event, err := binding.ToEvent(ctx, bufferedMessage)
+			if err != nil {
+				_ = bufferedMessage.Finish(err)
+				return err
+			}
+			offset, ackOthers, err := f.persistOnce(ctx, ref.Namespace, event, additionalHeaders)
+			if err != nil {
+				_ = bufferedMessage.Finish(err)
+				return err
+			}
+			ackOthers(subscriptionKeys(subs))
+
+			reportArgs := channel.ReportArgs{}
+			reportArgs.EventType = event.Type()
+			reportArgs.Ns = ref.Namespace
+			go func() {
+				// Run async dispatch with background context, the message was already accepted.
+				ctx := kncloudevents.ContextWithUnboundedLimiterWait(trace.NewContext(context.Background(), parentSpan))
+				f.dispatchPersisted(ctx, offset, subs, bufferedMessage, additionalHeaders, reportArgs)
+			}()
+			return nil
+		}
+	}
 	if f.asyncHandler {
 		return func(ctx context.Context, ref channel.ChannelReference, message binding.Message, transformers []binding.Transformer, additionalHeaders nethttp.Header) error {
-			subs := f.GetSubscriptions(ctx)
+			subs := f.liveSubscriptions(ctx)
 
 			if len(subs) == 0 {
 				// Nothing to do here, finish the message and return
@@ -185,17 +452,17 @@ func createMessageReceiverFunction(f *FanoutMessageHandler) func(context.Context
 			// We don't need the original message anymore
 			_ = message.Finish(nil)
 			go func(m binding.Message, h nethttp.Header, s *trace.Span, r *channel.StatsReporter, args *channel.ReportArgs) {
-				// Run async dispatch with background context.
-				ctx = trace.NewContext(context.Background(), s)
+				// Run async dispatch with background context, the message was already accepted.
+				ctx = kncloudevents.ContextWithUnboundedLimiterWait(trace.NewContext(context.Background(), s))
 				// Any returned error is already logged in f.dispatch().
-				dispatchResultForFanout := f.dispatch(ctx, subs, m, h)
+				dispatchResultForFanout := f.dispatch(ctx, subs, m, h, nil)
 				_ = ParseDispatchResultAndReportMetrics(dispatchResultForFanout, *r, *args)
 			}(bufferedMessage, additionalHeaders, parentSpan, &f.reporter, &reportArgs)
 			return nil
 		}
 	}
 	return func(ctx context.Context, ref channel.ChannelReference, message binding.Message, transformers []binding.Transformer, additionalHeaders nethttp.Header) error {
-		subs := f.GetSubscriptions(ctx)
+		subs := f.liveSubscriptions(ctx)
 		if len(subs) == 0 {
 			// Nothing to do here, finish the message and return
 			_ = message.Finish(nil)
@@ -215,7 +482,7 @@ func createMessageReceiverFunction(f *FanoutMessageHandler) func(context.Context
 		reportArgs := channel.ReportArgs{}
 		reportArgs.EventType = string(te)
 		reportArgs.Ns = ref.Namespace
-		dispatchResultForFanout := f.dispatch(ctx, subs, bufferedMessage, additionalHeaders)
+		dispatchResultForFanout := f.dispatch(ctx, subs, bufferedMessage, additionalHeaders, nil)
 		return ParseDispatchResultAndReportMetrics(dispatchResultForFanout, f.reporter, reportArgs)
 	}
 }
@@ -244,7 +511,21 @@ func ParseDispatchResultAndReportMetrics(result DispatchResult, reporter channel
 
 // dispatch takes the event, fans it out to each subscription in subs. If all the fanned out
 // events return successfully, then return nil. Else, return an error.
-func (f *FanoutMessageHandler) dispatch(ctx context.Context, subs []Subscription, bufferedMessage binding.Message, additionalHeaders nethttp.Header) DispatchResult {
+// When done is not nil, it's called for each subscription once it's done with the event, even
+// after dispatch returned.
+func (f *FanoutMessageHandler) dispatch(ctx context.Context, subs []Subscription, bufferedMessage binding.Message, additionalHeaders nethttp.Header, done func(Subscription)) DispatchResult {
+	subs, filteredOut := f.filterSubscriptions(ctx, subs, bufferedMessage)
+	if done != nil {
+		for _, sub := range filteredOut {
+			done(sub)
+		}
+	}
+	if len(subs) == 0 {
+		// No subscription is interested in this message
+		_ = bufferedMessage.Finish(nil)
+		return DispatchResult{}
+	}
+
 	// Bind the lifecycle of the buffered message to the number of subs
 	bufferedMessage = buffering.WithAcksBeforeFinish(bufferedMessage, len(subs))
 
@@ -252,6 +533,9 @@ func (f *FanoutMessageHandler) dispatch(ctx context.Context, subs []Subscription
 	for _, sub := range subs {
 		go func(s Subscription) {
 			dispatchedResultPerSub, err := f.makeFanoutRequest(ctx, bufferedMessage, additionalHeaders, s)
+			if done != nil {
+				done(s)
+			}
 			errorCh <- DispatchResult{err: err, info: dispatchedResultPerSub}
 		}(sub)
 	}
@@ -292,9 +576,62 @@ func (f *FanoutMessageHandler) dispatch(ctx context.Context, subs []Subscription
 	return dispatchResultForFanout
 }
 
+// filterSubscriptions returns the subscriptions whose filter passes for the message, and the
+// ones filtering it out.
+// The message is converted to an event only if at least one subscription has a filter.
+func (f *FanoutMessageHandler) filterSubscriptions(ctx context.Context, subs []Subscription, bufferedMessage binding.Message) ([]Subscription, []Subscription) {
+	var event *cloudevents.Event
+	for _, sub := range subs {
+		if sub.Filter != nil {
+			e, err := binding.ToEvent(ctx, bufferedMessage)
+			if err != nil {
+				f.logger.Warn("Failed to convert the message to an event, it won't pass any filter", zap.Error(err))
+			}
+			event = e
+			break
+		}
+	}
+
+	filtered := make([]Subscription, 0, len(subs))
+	var filteredOut []Subscription
+	for _, sub := range subs {
+		if sub.Filter != nil && (event == nil || sub.Filter.Filter(ctx, *event) == eventfilter.FailFilter) {
+			filteredOut = append(filteredOut, sub)
+			continue
+		}
+		filtered = append(filtered, sub)
+	}
+	return filtered, filteredOut
+}
+
+// contextWithDeliveryRef returns a copy of ctx carrying the kind and UID of the Trigger or the
+// Subscription sub delivers the messages for.
+func contextWithDeliveryRef(ctx context.Context, sub Subscription) context.Context {
+	switch {
+	case sub.TriggerUID != "":
+		return channel.ContextWithDeliveryRef(ctx, attributes.KnativeErrorKindTrigger, sub.TriggerUID)
+	case sub.UID != "":
+		return channel.ContextWithDeliveryRef(ctx, attributes.KnativeErrorKindSubscription, sub.UID)
+	}
+	return ctx
+}
+
 // makeFanoutRequest sends the request to exactly one subscription. It handles both the `call` and
-// the `sink` portions of the subscription.
+// the `sink` portions of the subscription. The message of a batched subscription is sent once
+// its batch is.
 func (f *FanoutMessageHandler) makeFanoutRequest(ctx context.Context, message binding.Message, additionalHeaders nethttp.Header, sub Subscription) (*channel.DispatchExecutionInfo, error) {
+	type batchResult struct {
+		info *channel.DispatchExecutionInfo
+		err  error
+	}
+	ctx = contextWithDeliveryRef(ctx, sub)
+	result := make(chan batchResult, 1)
+	if f.addToBatch(ctx, message, sub, func(info *channel.DispatchExecutionInfo, err error) {
+		result <- batchResult{info: info, err: err}
+	}) {
+		r := <-result
+		return r.info, r.err
+	}
 	return f.dispatcher.DispatchMessageWithRetries(
 		ctx,
 		message,
@@ -28,6 +28,8 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	eventingduckv1 "knative.dev/eventing/pkg/apis/duck/v1"
+	"knative.dev/eventing/pkg/eventfilter"
+	"knative.dev/eventing/pkg/eventfilter/attributes"
 	"knative.dev/eventing/pkg/kncloudevents"
 	pkgduckv1 "knative.dev/pkg/apis/duck/v1"
 
@@ -53,6 +55,7 @@ func TestSubscriberSpecToFanoutConfig(t *testing.T) {
 	three := int32(3)
 	linear := eventingduckv1.BackoffPolicyLinear
 	delay := "PT1S"
+	orderingKey := "partitionkey"
 	spec := &eventingduckv1.SubscriberSpec{
 		SubscriberURI: apis.HTTP("subscriber.example.com"),
 		ReplyURI:      apis.HTTP("reply.example.com"),
@@ -69,9 +72,13 @@ func TestSubscriberSpecToFanoutConfig(t *testing.T) {
 			Retry:         &three,
 			BackoffPolicy: &linear,
 			BackoffDelay:  &delay,
+			OrderingKey:   &orderingKey,
 		},
+		Replay:     &eventingduckv1.Replay{},
+		TriggerUID: "trigger-uid",
 	}
 	want := Subscription{
+		TriggerUID: "trigger-uid",
 		Subscriber: apis.HTTP("subscriber.example.com").URL(),
 		Reply:      apis.HTTP("reply.example.com").URL(),
 		DeadLetter: apis.HTTP("dls.example.com").URL(),
@@ -80,6 +87,8 @@ func TestSubscriberSpecToFanoutConfig(t *testing.T) {
 			BackoffPolicy: &linear,
 			BackoffDelay:  &delay,
 		},
+		OrderingKey: "partitionkey",
+		Replay:      &eventingduckv1.Replay{},
 	}
 	got, err := SubscriberSpecToFanoutConfig(*spec)
 	if err != nil {
@@ -90,6 +99,92 @@ func TestSubscriberSpecToFanoutConfig(t *testing.T) {
 	}
 }
 
+func TestSubscriberSpecToFanoutConfigDataFilter(t *testing.T) {
+	region := "eu-west"
+	spec := eventingduckv1.SubscriberSpec{
+		SubscriberURI: apis.HTTP("subscriber.example.com"),
+		DataFilter:    []eventingduckv1.DataFilter{{Path: ".region", Value: &region}},
+	}
+	got, err := SubscriberSpecToFanoutConfig(spec)
+	if err != nil {
+		t.Fatal("Failed to convert using SubscriberSpecToFanoutConfig:", err)
+	}
+	if diff := cmp.Diff(spec.DataFilter, got.DataFilter); diff != "" {
+		t.Error("Unexpected diff", diff)
+	}
+
+	event := makeCloudEvent()
+	if err := event.SetData(cloudevents.ApplicationJSON, []byte(`{"region": "eu-west"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if res := got.Filter.Filter(context.TODO(), event); res != eventfilter.PassFilter {
+		t.Errorf("Filter() = %v, want %v", res, eventfilter.PassFilter)
+	}
+
+	spec.DataFilter = []eventingduckv1.DataFilter{{Path: ".region["}}
+	if _, err := SubscriberSpecToFanoutConfig(spec); err == nil {
+		t.Error("Expected an error converting an invalid data filter")
+	}
+}
+
+func TestFanoutMessageHandlerDeadLetterRef(t *testing.T) {
+	testCases := map[string]struct {
+		sub      Subscription
+		wantKind string
+		wantUID  string
+	}{
+		"subscription": {
+			sub:      Subscription{UID: "sub-uid"},
+			wantKind: "Subscription",
+			wantUID:  "sub-uid",
+		},
+		"trigger": {
+			sub:      Subscription{UID: "sub-uid", TriggerUID: "trigger-uid"},
+			wantKind: "Trigger",
+			wantUID:  "trigger-uid",
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			subscriber := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			}))
+			defer subscriber.Close()
+			headers := make(chan http.Header, 1)
+			deadLetter := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				headers <- r.Header
+				w.WriteHeader(http.StatusAccepted)
+			}))
+			defer deadLetter.Close()
+
+			sub := tc.sub
+			sub.Subscriber = apis.HTTP(subscriber.URL[7:]).URL()
+			sub.DeadLetter = apis.HTTP(deadLetter.URL[7:]).URL()
+
+			logger := zap.NewNop()
+			h, err := NewFanoutMessageHandler(logger, channel.NewMessageDispatcher(logger), Config{Subscriptions: []Subscription{sub}}, channel.NewStatsReporter("testcontainer", "testpod"))
+			if err != nil {
+				t.Fatal("NewHandler failed =", err)
+			}
+
+			event := makeCloudEvent()
+			req := httptest.NewRequest(http.MethodPost, "http://channelname.channelnamespace/", nil)
+			if err := bindingshttp.WriteRequest(context.Background(), binding.ToMessage(&event), req); err != nil {
+				t.Fatal("WriteRequest =", err)
+			}
+			h.ServeHTTP(httptest.NewRecorder(), req)
+
+			got := <-headers
+			if kind := got.Get("Ce-Knativeerrorkind"); kind != tc.wantKind {
+				t.Errorf("Unexpected knativeerrorkind %q, want %q", kind, tc.wantKind)
+			}
+			if uid := got.Get("Ce-Knativeerroruid"); uid != tc.wantUID {
+				t.Errorf("Unexpected knativeerroruid %q, want %q", uid, tc.wantUID)
+			}
+		})
+	}
+}
+
 func TestGetSetSubscriptions(t *testing.T) {
 	h := &FanoutMessageHandler{subscriptions: make([]Subscription, 0)}
 	subs := h.GetSubscriptions(context.TODO())
@@ -273,6 +368,39 @@ func TestFanoutMessageHandler_ServeHTTP(t *testing.T) {
 			expectedStatus:      http.StatusAccepted,
 			asyncExpectedStatus: http.StatusAccepted,
 		},
+		"filtered out subs are skipped": {
+			subs: []Subscription{
+				{
+					Subscriber: replaceSubscriber,
+					Reply:      replaceReplier,
+					Filter:     attributes.NewAttributesFilter(map[string]string{"type": "com.example.someevent"}),
+				},
+				{
+					Subscriber: replaceSubscriber,
+					Reply:      replaceReplier,
+					Filter:     attributes.NewAttributesFilter(map[string]string{"type": "com.example.otherevent"}),
+				},
+			},
+			subscriber: callableSucceed,
+			replier: func(writer http.ResponseWriter, _ *http.Request) {
+				writer.WriteHeader(http.StatusAccepted)
+			},
+			subscriberReqs:      1,
+			replierReqs:         1,
+			expectedStatus:      http.StatusAccepted,
+			asyncExpectedStatus: http.StatusAccepted,
+		},
+		"all subs filtered out": {
+			subs: []Subscription{
+				{
+					Subscriber: replaceSubscriber,
+					Filter:     attributes.NewAttributesFilter(map[string]string{"type": "com.example.otherevent"}),
+				},
+			},
+			subscriber:          callableSucceed,
+			expectedStatus:      http.StatusAccepted,
+			asyncExpectedStatus: http.StatusAccepted,
+		},
 	}
 	for n, tc := range testCases {
 		t.Run("sync - "+n, func(t *testing.T) {
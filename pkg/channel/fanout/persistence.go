@@ -0,0 +1,172 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fanout
+
+import (
+	"context"
+	"encoding/json"
+	nethttp "net/http"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/binding/buffering"
+	"go.uber.org/zap"
+
+	"knative.dev/eventing/pkg/channel"
+	"knative.dev/eventing/pkg/kncloudevents"
+)
+
+// persistedMessage is a message stored in the log.
+type persistedMessage struct {
+	Namespace string             `json:"namespace,omitempty"`
+	Headers   nethttp.Header     `json:"headers,omitempty"`
+	Event     *cloudevents.Event `json:"event"`
+}
+
+// setCursors sets the cursors of the log to the ones of subs. The cursors of the new
+// subscriptions start at the next message.
+func (f *FanoutMessageHandler) setCursors(subs []Subscription) error {
+	names := make([]string, 0, len(subs))
+	for _, sub := range subs {
+		names = append(names, subscriptionKey(sub))
+	}
+	return f.log.SetCursors(names)
+}
+
+// persist appends the event to the log, returning its offset and a function acknowledging it
+// for the subscriptions which had a cursor when it was appended, but which it isn't dispatched
+// to, like the ones added meanwhile. The function takes the keys of the subscriptions it's
+// dispatched to.
+func (f *FanoutMessageHandler) persist(namespace string, event *cloudevents.Event, additionalHeaders nethttp.Header) (uint64, func(dispatched map[string]bool), error) {
+	data, err := json.Marshal(persistedMessage{
+		Namespace: namespace,
+		Headers:   additionalHeaders,
+		Event:     event,
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+	offset, cursors, err := f.log.Append(data)
+	if err != nil {
+		return 0, nil, err
+	}
+	ackOthers := func(dispatched map[string]bool) {
+		for _, name := range cursors {
+			if dispatched[name] {
+				continue
+			}
+			if err := f.log.Ack(name, offset); err != nil {
+				f.logger.Warn("Failed to acknowledge a message of the log", zap.Uint64("offset", offset), zap.Error(err))
+			}
+		}
+	}
+	return offset, ackOthers, nil
+}
+
+type persistedMessageKey struct{}
+
+// persistedOffset is the result of persist for a message persisted before being dispatched.
+type persistedOffset struct {
+	offset    uint64
+	ackOthers func(dispatched map[string]bool)
+}
+
+// persistOnce persists the message received with ctx like persist, unless it was already
+// persisted by the replay buffer.
+func (f *FanoutMessageHandler) persistOnce(ctx context.Context, namespace string, event *cloudevents.Event, additionalHeaders nethttp.Header) (uint64, func(dispatched map[string]bool), error) {
+	if p, ok := ctx.Value(persistedMessageKey{}).(persistedOffset); ok {
+		return p.offset, p.ackOthers, nil
+	}
+	return f.persist(namespace, event, additionalHeaders)
+}
+
+// subscriptionKeys returns the keys of the subscriptions of subs.
+func subscriptionKeys(subs ...[]Subscription) map[string]bool {
+	keys := make(map[string]bool)
+	for _, s := range subs {
+		for _, sub := range s {
+			keys[subscriptionKey(sub)] = true
+		}
+	}
+	return keys
+}
+
+// ack acknowledges the message at offset for the subscription, once it's done with it.
+func (f *FanoutMessageHandler) ack(sub Subscription, offset uint64) {
+	if err := f.log.Ack(subscriptionKey(sub), offset); err != nil {
+		f.logger.Warn("Failed to acknowledge a message of the log", zap.Uint64("offset", offset), zap.Error(err))
+	}
+}
+
+// dispatchPersisted dispatches the message stored at offset to subs, acknowledging it for each
+// subscription once its delivery, including the retries and the dead letter sink, is over.
+func (f *FanoutMessageHandler) dispatchPersisted(ctx context.Context, offset uint64, subs []Subscription, bufferedMessage binding.Message, additionalHeaders nethttp.Header, reportArgs channel.ReportArgs) {
+	// Any returned error is already logged in f.dispatch().
+	dispatchResultForFanout := f.dispatch(ctx, subs, bufferedMessage, additionalHeaders, func(sub Subscription) {
+		f.ack(sub, offset)
+	})
+	_ = ParseDispatchResultAndReportMetrics(dispatchResultForFanout, f.reporter, reportArgs)
+}
+
+// replay dispatches the messages of the log before the offset end which weren't acknowledged
+// by subs, one after the other.
+func (f *FanoutMessageHandler) replay(subs []Subscription, end uint64) {
+	from := end
+	cursors := make([]uint64, len(subs))
+	for i, sub := range subs {
+		cursors[i], _ = f.log.Cursor(subscriptionKey(sub))
+		if cursors[i] < from {
+			from = cursors[i]
+		}
+	}
+	if from >= end {
+		return
+	}
+
+	f.logger.Info("Replaying the messages of the log", zap.Uint64("from", from), zap.Uint64("to", end))
+	err := f.log.Read(from, end, func(offset uint64, data []byte) error {
+		var pending []Subscription
+		for i, sub := range subs {
+			if cursors[i] <= offset {
+				pending = append(pending, sub)
+			}
+		}
+
+		var record persistedMessage
+		if err := json.Unmarshal(data, &record); err != nil || record.Event == nil {
+			f.logger.Error("Failed to decode a message of the log, skipping it", zap.Uint64("offset", offset), zap.Error(err))
+			for _, sub := range pending {
+				f.ack(sub, offset)
+			}
+			return nil
+		}
+		ctx := kncloudevents.ContextWithUnboundedLimiterWait(context.Background())
+		bufferedMessage, err := buffering.CopyMessage(ctx, binding.ToMessage(record.Event))
+		if err != nil {
+			return err
+		}
+
+		reportArgs := channel.ReportArgs{}
+		reportArgs.EventType = record.Event.Type()
+		reportArgs.Ns = record.Namespace
+		f.dispatchPersisted(ctx, offset, pending, bufferedMessage, record.Headers, reportArgs)
+		return nil
+	})
+	if err != nil {
+		f.logger.Error("Failed to replay the messages of the log", zap.Error(err))
+	}
+}
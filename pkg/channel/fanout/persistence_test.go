@@ -0,0 +1,138 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fanout
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	bindingshttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"knative.dev/pkg/apis"
+
+	"knative.dev/eventing/pkg/channel"
+	"knative.dev/eventing/pkg/channel/wal"
+)
+
+func TestFanoutMessageHandlerPersistence(t *testing.T) {
+	var mutex sync.Mutex
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		received = append(received, r.Header.Get("ce-id"))
+		mutex.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+	receivedIDs := func() []string {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return append([]string(nil), received...)
+	}
+
+	logger := zap.NewNop()
+	reporter := channel.NewStatsReporter("testcontainer", "testpod")
+	subs := []Subscription{{
+		UID:        "sub-uid",
+		Subscriber: apis.HTTP(server.URL[7:]).URL(), // strip the leading 'http://'
+	}}
+	dir := t.TempDir()
+
+	log, err := wal.Open(dir, wal.Options{})
+	require.NoError(t, err)
+	h, err := NewFanoutMessageHandler(logger, channel.NewMessageDispatcher(logger), Config{Subscriptions: subs, Log: log}, reporter)
+	require.NoError(t, err)
+
+	// The message is persisted, acknowledged, then delivered.
+	event := makeCloudEvent()
+	event.SetID("delivered")
+	req := httptest.NewRequest(http.MethodPost, "http://channelname.channelnamespace/", nil)
+	require.NoError(t, bindingshttp.WriteRequest(context.Background(), binding.ToMessage(&event), req))
+	resp := httptest.NewRecorder()
+	h.ServeHTTP(resp, req)
+	require.Equal(t, http.StatusAccepted, resp.Code)
+	require.Eventually(t, func() bool {
+		offset, _ := log.Cursor("sub-uid")
+		return offset == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Simulate a restart of the dispatcher before a message is delivered.
+	event.SetID("pending")
+	_, _, err = h.persist("channelnamespace", &event, nil)
+	require.NoError(t, err)
+	require.NoError(t, log.Close())
+
+	log, err = wal.Open(dir, wal.Options{})
+	require.NoError(t, err)
+	defer log.Close()
+	_, err = NewFanoutMessageHandler(logger, channel.NewMessageDispatcher(logger), Config{Subscriptions: subs, Log: log}, reporter)
+	require.NoError(t, err)
+
+	// Only the pending message is replayed.
+	require.Eventually(t, func() bool {
+		offset, _ := log.Cursor("sub-uid")
+		return offset == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	if diff := cmp.Diff([]string{"delivered", "pending"}, receivedIDs()); diff != "" {
+		t.Error("Unexpected delivered events (-want, +got) =", diff)
+	}
+}
+
+func TestFanoutMessageHandlerPersistenceAcksUndispatched(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	logger := zap.NewNop()
+	reporter := channel.NewStatsReporter("testcontainer", "testpod")
+	subs := []Subscription{{
+		UID:        "sub-uid",
+		Subscriber: apis.HTTP(server.URL[7:]).URL(), // strip the leading 'http://'
+	}}
+
+	log, err := wal.Open(t.TempDir(), wal.Options{})
+	require.NoError(t, err)
+	defer log.Close()
+	h, err := NewFanoutMessageHandler(logger, channel.NewMessageDispatcher(logger), Config{Subscriptions: subs, Log: log}, reporter)
+	require.NoError(t, err)
+
+	// A subscription added after the handler read its subscriptions doesn't get the message, its
+	// cursor moves past it anyway.
+	require.NoError(t, log.SetCursors([]string{"sub-uid", "added-uid"}))
+
+	event := makeCloudEvent()
+	req := httptest.NewRequest(http.MethodPost, "http://channelname.channelnamespace/", nil)
+	require.NoError(t, bindingshttp.WriteRequest(context.Background(), binding.ToMessage(&event), req))
+	resp := httptest.NewRecorder()
+	h.ServeHTTP(resp, req)
+	require.Equal(t, http.StatusAccepted, resp.Code)
+	require.Eventually(t, func() bool {
+		offset, _ := log.Cursor("sub-uid")
+		return offset == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	offset, ok := log.Cursor("added-uid")
+	require.True(t, ok)
+	require.Equal(t, uint64(1), offset)
+}
@@ -0,0 +1,428 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fanout
+
+import (
+	"context"
+	"fmt"
+	nethttp "net/http"
+	"sync"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/binding/buffering"
+	"go.opencensus.io/trace"
+	"go.uber.org/zap"
+
+	"knative.dev/eventing/pkg/channel"
+	"knative.dev/eventing/pkg/eventfilter/attributes"
+	"knative.dev/eventing/pkg/kncloudevents"
+)
+
+// OverflowPolicy is what happens to a message when the queue of a Subscription is full.
+type OverflowPolicy string
+
+const (
+	// OverflowReject rejects the message with 429 Too Many Requests, so that the sender retries it.
+	OverflowReject OverflowPolicy = "reject"
+	// OverflowDeadLetter sends the message to the dead letter sink of the Subscription. The message
+	// is rejected when the Subscription has no dead letter sink.
+	OverflowDeadLetter OverflowPolicy = "deadLetter"
+	// OverflowBlock waits for room in the queue.
+	OverflowBlock OverflowPolicy = "block"
+)
+
+// QueueConfig configures the queue of each Subscription.
+type QueueConfig struct {
+	// Size is the maximum number of messages waiting in a queue.
+	Size int `json:"size"`
+	// Workers is the number of messages of a queue dispatched concurrently.
+	Workers int `json:"workers"`
+	// Overflow is what happens to a message when a queue is full.
+	Overflow OverflowPolicy `json:"overflow"`
+}
+
+func (c *QueueConfig) validate() error {
+	if c.Size < 1 {
+		return fmt.Errorf("invalid queue size %d, it must be at least 1", c.Size)
+	}
+	if c.Workers < 1 {
+		return fmt.Errorf("invalid number of queue workers %d, it must be at least 1", c.Workers)
+	}
+	switch c.Overflow {
+	case OverflowReject, OverflowDeadLetter, OverflowBlock:
+		return nil
+	default:
+		return fmt.Errorf("invalid queue overflow policy %q", c.Overflow)
+	}
+}
+
+// defaultOrderedQueueConfig configures the queues of the subscriptions of a handler without
+// a QueueConfig when a Subscription has an ordering key.
+var defaultOrderedQueueConfig = QueueConfig{
+	Size:     100,
+	Workers:  10,
+	Overflow: OverflowBlock,
+}
+
+type queuedMessage struct {
+	ctx               context.Context
+	message           binding.Message
+	additionalHeaders nethttp.Header
+	reportArgs        channel.ReportArgs
+	// done, when not nil, is called once the Subscription is done with the message.
+	done func(Subscription)
+}
+
+// orderedMessages are the messages of a queue sharing an ordering key, dispatched one after
+// the other.
+type orderedMessages struct {
+	// key is empty for a message without ordering key.
+	key      string
+	messages []queuedMessage
+}
+
+// subscriptionQueue is the bounded queue of the messages of a Subscription.
+//
+// The messages with the same ordering key are dispatched one at a time, in the order they were
+// pushed, while the workers of the queue dispatch the messages of other keys. A message being
+// retried thus only delays the messages with its key.
+type subscriptionQueue struct {
+	key string
+	// slots holds a value for each message reserved in the queue. As a message is pushed
+	// only once it holds a slot, ready always has room for its key.
+	slots chan struct{}
+	// ready holds the keys with messages waiting to be dispatched and no message being
+	// dispatched.
+	ready   chan *orderedMessages
+	stopped chan struct{}
+
+	mutex sync.RWMutex
+	sub   Subscription
+	// keys holds the ordering keys with messages waiting or being dispatched.
+	keys map[string]*orderedMessages
+}
+
+func newSubscriptionQueue(key string, sub Subscription, size int) *subscriptionQueue {
+	return &subscriptionQueue{
+		key:     key,
+		slots:   make(chan struct{}, size),
+		ready:   make(chan *orderedMessages, size),
+		stopped: make(chan struct{}),
+		sub:     sub,
+		keys:    make(map[string]*orderedMessages),
+	}
+}
+func (q *subscriptionQueue) subscription() Subscription {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+	return q.sub
+}
+
+func (q *subscriptionQueue) setSubscription(sub Subscription) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	q.sub = sub
+}
+
+// reserve reserves a slot for a message, waiting for one to be free when block is true.
+// It returns false when the queue is full or stopped.
+func (q *subscriptionQueue) reserve(ctx context.Context, block bool) bool {
+	if !block {
+		select {
+		case <-q.stopped:
+			return false
+		case q.slots <- struct{}{}:
+			return true
+		default:
+			return false
+		}
+	}
+	select {
+	case <-q.stopped:
+		return false
+	case q.slots <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (q *subscriptionQueue) release() {
+	<-q.slots
+}
+
+func (q *subscriptionQueue) stop() {
+	close(q.stopped)
+}
+
+// push adds a message holding a slot to the queue. The messages without ordering key are
+// dispatched independently.
+func (q *subscriptionQueue) push(orderingKey string, m queuedMessage) {
+	if orderingKey == "" {
+		q.ready <- &orderedMessages{messages: []queuedMessage{m}}
+		return
+	}
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if om, ok := q.keys[orderingKey]; ok {
+		om.messages = append(om.messages, m)
+		return
+	}
+	om := &orderedMessages{key: orderingKey, messages: []queuedMessage{m}}
+	q.keys[orderingKey] = om
+	q.ready <- om
+}
+
+// pop takes the next message of a ready key, the key isn't ready until done is called.
+func (q *subscriptionQueue) pop(om *orderedMessages) queuedMessage {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	m := om.messages[0]
+	om.messages = om.messages[1:]
+	return m
+}
+
+// requeue marks the key of the message returned by pop ready again, if it has other messages.
+func (q *subscriptionQueue) requeue(om *orderedMessages) {
+	if om.key == "" {
+		return
+	}
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if len(om.messages) == 0 {
+		delete(q.keys, om.key)
+		return
+	}
+	q.ready <- om
+}
+
+// queueConfigFor returns the configuration of the queues of subs, nil when their messages
+// aren't queued.
+func (f *FanoutMessageHandler) queueConfigFor(subs []Subscription) *QueueConfig {
+	if f.queueConfig != nil {
+		return f.queueConfig
+	}
+	for _, sub := range subs {
+		if sub.OrderingKey != "" {
+			return &defaultOrderedQueueConfig
+		}
+	}
+	return nil
+}
+
+// queueing returns the configuration of the queues, nil when the messages aren't queued.
+func (f *FanoutMessageHandler) queueing() *QueueConfig {
+	f.queuesMutex.RLock()
+	defer f.queuesMutex.RUnlock()
+	return f.activeQueueConfig
+}
+
+// setQueues creates the queues of the new subscriptions, updates the existing ones and stops
+// the queues of the removed subscriptions. The messages waiting in a stopped queue are dropped.
+func (f *FanoutMessageHandler) setQueues(subs []Subscription) {
+	f.queuesMutex.Lock()
+	defer f.queuesMutex.Unlock()
+	config := f.queueConfigFor(subs)
+	if config == nil {
+		subs = nil
+	}
+	queues := make(map[string]*subscriptionQueue, len(subs))
+	for _, sub := range subs {
+		key := subscriptionKey(sub)
+		if q, ok := f.queues[key]; ok {
+			q.setSubscription(sub)
+			queues[key] = q
+			continue
+		}
+		q := newSubscriptionQueue(key, sub, config.Size)
+		for i := 0; i < config.Workers; i++ {
+			go f.queueWorker(q)
+		}
+		queues[key] = q
+	}
+	for key, q := range f.queues {
+		if _, ok := queues[key]; !ok {
+			q.stop()
+		}
+	}
+	f.queues = queues
+	f.activeQueueConfig = config
+}
+
+func (f *FanoutMessageHandler) queueWorker(q *subscriptionQueue) {
+	for {
+		select {
+		case <-q.stopped:
+			return
+		case om := <-q.ready:
+			m := q.pop(om)
+			q.release()
+			_ = f.reporter.ReportQueueDepth(&m.reportArgs, q.key, len(q.slots))
+
+			sub := q.subscription()
+			// The worker doesn't wait for the batch to be sent, so that it's filled by the
+			// next messages.
+			if f.addToBatch(m.ctx, m.message, sub, func(info *channel.DispatchExecutionInfo, err error) {
+				if m.done != nil {
+					m.done(sub)
+				}
+				_ = ParseDispatchResultAndReportMetrics(NewDispatchResult(err, info), f.reporter, m.reportArgs)
+			}) {
+				q.requeue(om)
+				continue
+			}
+			info, err := f.makeFanoutRequest(m.ctx, m.message, m.additionalHeaders, sub)
+			if err != nil {
+				f.logger.Error("Fanout had an error", zap.Error(err))
+			}
+			if m.done != nil {
+				m.done(sub)
+			}
+			q.requeue(om)
+			_ = ParseDispatchResultAndReportMetrics(NewDispatchResult(err, info), f.reporter, m.reportArgs)
+		}
+	}
+}
+
+// enqueue adds the message to the queues of the subscriptions whose filter passes, persisting
+// it first when the handler has a log. When a queue is full, the overflow policy either rejects
+// the message with channel.ErrOverloaded, sends it to the dead letter sink of the subscription
+// or waits for room in the queue, as long as ctx isn't done.
+func (f *FanoutMessageHandler) enqueue(ctx context.Context, subs []Subscription, bufferedMessage binding.Message, additionalHeaders nethttp.Header, reportArgs channel.ReportArgs) error {
+	// The subscriptions filtering the message out are done with it once it's persisted.
+	subs, _ = f.filterSubscriptions(ctx, subs, bufferedMessage)
+	if len(subs) == 0 {
+		// No subscription is interested in this message
+		_ = bufferedMessage.Finish(nil)
+		return nil
+	}
+
+	f.queuesMutex.RLock()
+	config := f.activeQueueConfig
+	queues := make([]*subscriptionQueue, len(subs))
+	for i, sub := range subs {
+		queues[i] = f.queues[subscriptionKey(sub)]
+	}
+	f.queuesMutex.RUnlock()
+	if config == nil {
+		// The subscriptions were updated and don't need queues anymore.
+		config = &QueueConfig{}
+	}
+
+	var reserved []*subscriptionQueue
+	var reservedSubs []Subscription
+	var deadLetter []Subscription
+	for i, q := range queues {
+		if q == nil {
+			// The subscription was removed.
+			continue
+		}
+		if q.reserve(ctx, config.Overflow == OverflowBlock) {
+			reserved = append(reserved, q)
+			reservedSubs = append(reservedSubs, subs[i])
+			continue
+		}
+		select {
+		case <-q.stopped:
+			continue
+		default:
+		}
+		if config.Overflow == OverflowDeadLetter && subs[i].DeadLetter != nil {
+			deadLetter = append(deadLetter, subs[i])
+			continue
+		}
+		for _, r := range reserved {
+			r.release()
+		}
+		_ = bufferedMessage.Finish(channel.ErrOverloaded)
+		return fmt.Errorf("the queue of the subscription %s is full: %w", q.key, channel.ErrOverloaded)
+	}
+
+	// The event is needed to persist the message and to get its ordering keys.
+	needEvent := f.log != nil
+	for _, sub := range reservedSubs {
+		needEvent = needEvent || sub.OrderingKey != ""
+	}
+	var event *cloudevents.Event
+	if needEvent {
+		var err error
+		if event, err = binding.ToEvent(ctx, bufferedMessage); err != nil {
+			for _, r := range reserved {
+				r.release()
+			}
+			_ = bufferedMessage.Finish(err)
+			return err
+		}
+	}
+
+	var done func(Subscription)
+	if f.log != nil {
+		// The message is acknowledged only once it's persisted.
+		offset, ackOthers, err := f.persistOnce(ctx, reportArgs.Ns, event, additionalHeaders)
+		if err != nil {
+			for _, r := range reserved {
+				r.release()
+			}
+			_ = bufferedMessage.Finish(err)
+			return err
+		}
+		done = func(sub Subscription) {
+			f.ack(sub, offset)
+		}
+		ackOthers(subscriptionKeys(reservedSubs, deadLetter))
+	}
+
+	// Bind the lifecycle of the buffered message to the number of subs
+	bufferedMessage = buffering.WithAcksBeforeFinish(bufferedMessage, len(reserved)+len(deadLetter))
+	// The message outlives the request, and was already accepted.
+	dispatchCtx := kncloudevents.ContextWithUnboundedLimiterWait(trace.NewContext(context.Background(), trace.FromContext(ctx)))
+	for _, sub := range deadLetter {
+		f.logger.Warn("The queue of the subscription is full, sending the message to its dead letter sink", zap.String("subscription", subscriptionKey(sub)))
+		_, err := f.dispatcher.DispatchMessageWithRetries(dispatchCtx, bufferedMessage, additionalHeaders, sub.DeadLetter, nil, nil, sub.RetryConfig)
+		if err != nil {
+			f.logger.Error("Failed to send the message to the dead letter sink", zap.Error(err))
+		}
+		if done != nil {
+			done(sub)
+		}
+	}
+	for i, q := range reserved {
+		q.push(orderingKey(event, reservedSubs[i]), queuedMessage{
+			ctx:               dispatchCtx,
+			message:           bufferedMessage,
+			additionalHeaders: additionalHeaders,
+			reportArgs:        reportArgs,
+			done:              done,
+		})
+		_ = f.reporter.ReportQueueDepth(&reportArgs, q.key, len(q.slots))
+	}
+	return nil
+}
+
+// orderingKey returns the value of the ordering key of sub in event, empty when sub isn't
+// ordered or event doesn't have the attribute.
+func orderingKey(event *cloudevents.Event, sub Subscription) string {
+	if sub.OrderingKey == "" || event == nil {
+		return ""
+	}
+	value, ok := attributes.LookupAttribute(*event, sub.OrderingKey)
+	if !ok || value == nil {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
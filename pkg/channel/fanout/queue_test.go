@@ -0,0 +1,204 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fanout
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	bindingshttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+	"go.uber.org/zap"
+	"knative.dev/pkg/apis"
+
+	"knative.dev/eventing/pkg/channel"
+)
+
+func TestFanoutMessageHandlerQueue(t *testing.T) {
+	testCases := map[string]struct {
+		overflow           OverflowPolicy
+		wantOverflowStatus int
+		wantDelivered      int32
+		wantDeadLettered   int32
+	}{
+		"reject": {
+			overflow:           OverflowReject,
+			wantOverflowStatus: http.StatusTooManyRequests,
+			wantDelivered:      2,
+		},
+		"dead letter": {
+			overflow:           OverflowDeadLetter,
+			wantOverflowStatus: http.StatusAccepted,
+			wantDelivered:      2,
+			wantDeadLettered:   1,
+		},
+		"block": {
+			overflow:           OverflowBlock,
+			wantOverflowStatus: http.StatusAccepted,
+			wantDelivered:      3,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			var delivered, deadLettered atomic.Int32
+			received := make(chan struct{}, 3)
+			unblock := make(chan struct{})
+			subscriber := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				delivered.Inc()
+				received <- struct{}{}
+				<-unblock
+				w.WriteHeader(http.StatusAccepted)
+			}))
+			defer subscriber.Close()
+			deadLetter := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				deadLettered.Inc()
+				w.WriteHeader(http.StatusAccepted)
+			}))
+			defer deadLetter.Close()
+
+			logger := zap.NewNop()
+			h, err := NewFanoutMessageHandler(logger, channel.NewMessageDispatcher(logger), Config{
+				Subscriptions: []Subscription{{
+					UID:        "sub-uid",
+					Subscriber: apis.HTTP(subscriber.URL[7:]).URL(), // strip the leading 'http://'
+					DeadLetter: apis.HTTP(deadLetter.URL[7:]).URL(),
+				}},
+				Queue: &QueueConfig{Size: 1, Workers: 1, Overflow: tc.overflow},
+			}, channel.NewStatsReporter("testcontainer", "testpod"))
+			require.NoError(t, err)
+
+			send := func() int {
+				event := makeCloudEvent()
+				req := httptest.NewRequest(http.MethodPost, "http://channelname.channelnamespace/", nil)
+				require.NoError(t, bindingshttp.WriteRequest(context.Background(), binding.ToMessage(&event), req))
+				resp := httptest.NewRecorder()
+				h.ServeHTTP(resp, req)
+				return resp.Code
+			}
+
+			// The worker is busy with the first message, the second one waits in the queue.
+			require.Equal(t, http.StatusAccepted, send())
+			<-received
+			require.Equal(t, http.StatusAccepted, send())
+
+			status := make(chan int)
+			go func() {
+				status <- send()
+			}()
+			if tc.overflow == OverflowBlock {
+				select {
+				case <-status:
+					t.Fatal("The message didn't wait for room in the queue")
+				case <-time.After(100 * time.Millisecond):
+				}
+				unblock <- struct{}{}
+			}
+			require.Equal(t, tc.wantOverflowStatus, <-status)
+			close(unblock)
+
+			require.Eventually(t, func() bool {
+				return delivered.Load() == tc.wantDelivered
+			}, 5*time.Second, 10*time.Millisecond, "delivered %d", delivered.Load())
+			require.Equal(t, tc.wantDeadLettered, deadLettered.Load())
+		})
+	}
+}
+
+func TestFanoutMessageHandlerOrdered(t *testing.T) {
+	var mutex sync.Mutex
+	var received []string
+	receivedIDs := func() []string {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return append([]string(nil), received...)
+	}
+	unblock := make(chan struct{})
+	subscriber := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get("ce-id")
+		mutex.Lock()
+		received = append(received, id)
+		mutex.Unlock()
+		if id == "a1" {
+			<-unblock
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer subscriber.Close()
+
+	logger := zap.NewNop()
+	h, err := NewFanoutMessageHandler(logger, channel.NewMessageDispatcher(logger), Config{
+		Subscriptions: []Subscription{{
+			UID:         "sub-uid",
+			Subscriber:  apis.HTTP(subscriber.URL[7:]).URL(), // strip the leading 'http://'
+			OrderingKey: "partitionkey",
+		}},
+	}, channel.NewStatsReporter("testcontainer", "testpod"))
+	require.NoError(t, err)
+
+	for _, id := range []string{"a1", "a2", "b1", "unordered"} {
+		event := makeCloudEvent()
+		event.SetID(id)
+		if id != "unordered" {
+			event.SetExtension("partitionkey", id[:1])
+		}
+		req := httptest.NewRequest(http.MethodPost, "http://channelname.channelnamespace/", nil)
+		require.NoError(t, bindingshttp.WriteRequest(context.Background(), binding.ToMessage(&event), req))
+		resp := httptest.NewRecorder()
+		h.ServeHTTP(resp, req)
+		require.Equal(t, http.StatusAccepted, resp.Code)
+	}
+
+	// a2 waits for a1 to be delivered, the other keys don't.
+	require.Eventually(t, func() bool {
+		return len(receivedIDs()) == 3
+	}, 5*time.Second, 10*time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+	require.ElementsMatch(t, []string{"a1", "b1", "unordered"}, receivedIDs())
+
+	close(unblock)
+	require.Eventually(t, func() bool {
+		return len(receivedIDs()) == 4
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, "a2", receivedIDs()[3])
+
+	// The messages aren't queued anymore without ordered subscriptions.
+	h.SetSubscriptions(context.Background(), []Subscription{{
+		UID:        "sub-uid",
+		Subscriber: apis.HTTP(subscriber.URL[7:]).URL(),
+	}})
+	require.Nil(t, h.queueing())
+}
+
+func TestQueueConfigValidation(t *testing.T) {
+	logger := zap.NewNop()
+	for _, config := range []QueueConfig{
+		{Size: 0, Workers: 1, Overflow: OverflowReject},
+		{Size: 1, Workers: 0, Overflow: OverflowReject},
+		{Size: 1, Workers: 1, Overflow: "drop"},
+	} {
+		config := config
+		if _, err := NewFanoutMessageHandler(logger, channel.NewMessageDispatcher(logger), Config{Queue: &config}, nil); err == nil {
+			t.Errorf("Expected an error for %+v", config)
+		}
+	}
+}
@@ -0,0 +1,282 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fanout
+
+import (
+	"context"
+	nethttp "net/http"
+	"sync"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/binding/buffering"
+	"go.uber.org/zap"
+
+	"knative.dev/eventing/pkg/channel"
+	"knative.dev/eventing/pkg/kncloudevents"
+)
+
+// ReplayBuffer retains the latest events received by a channel, bounded by count and age, so
+// that the Subscriptions added later can ask for them. A ReplayBuffer outlives the handlers
+// using it, like a channel outlives the handlers serving it.
+type ReplayBuffer struct {
+	maxAge time.Duration
+
+	mutex sync.Mutex
+	// entries is a ring holding the event with the sequence number seq at seq % len(entries).
+	entries []retainedEvent
+	// first is the sequence number of the oldest retained event, next the one of the next event.
+	first uint64
+	next  uint64
+	// subscriptions are the Subscriptions known to the buffer, only the new ones are replayed.
+	subscriptions map[string]bool
+	// replaying holds the sequence number of the next event read by the Subscriptions streaming
+	// the retained events, the events received meanwhile reach them through the buffer.
+	replaying map[string]uint64
+	// lost holds the log offsets of the events dropped before the replaying Subscriptions read
+	// them, which they're done with.
+	lost map[string][]uint64
+}
+
+type retainedEvent struct {
+	received          time.Time
+	namespace         string
+	event             *cloudevents.Event
+	additionalHeaders nethttp.Header
+	// offset is the offset of the event in the log of the handler when persisted is true.
+	offset    uint64
+	persisted bool
+}
+
+// NewReplayBuffer creates a ReplayBuffer retaining at most size events, which must be positive,
+// during at most maxAge when it's not 0.
+func NewReplayBuffer(size int, maxAge time.Duration) *ReplayBuffer {
+	return &ReplayBuffer{
+		maxAge:        maxAge,
+		entries:       make([]retainedEvent, size),
+		subscriptions: make(map[string]bool),
+		replaying:     make(map[string]uint64),
+		lost:          make(map[string][]uint64),
+	}
+}
+
+// expire drops the events older than maxAge, b.mutex must be held.
+func (b *ReplayBuffer) expire(now time.Time) {
+	if b.maxAge == 0 {
+		return
+	}
+	for b.first < b.next && now.Sub(b.entry(b.first).received) > b.maxAge {
+		b.drop()
+	}
+}
+
+// drop removes the oldest retained event, b.mutex must be held.
+func (b *ReplayBuffer) drop() {
+	e := b.entry(b.first)
+	if e.persisted {
+		for key, next := range b.replaying {
+			if next <= b.first {
+				b.lost[key] = append(b.lost[key], e.offset)
+			}
+		}
+	}
+	*e = retainedEvent{}
+	b.first++
+}
+
+func (b *ReplayBuffer) entry(seq uint64) *retainedEvent {
+	return &b.entries[seq%uint64(len(b.entries))]
+}
+
+// append retains the event e, returning the Subscriptions it must not be dispatched to as they
+// are replaying.
+func (b *ReplayBuffer) append(e retainedEvent) map[string]bool {
+	e.received = time.Now()
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.expire(e.received)
+	if b.next-b.first == uint64(len(b.entries)) {
+		b.drop()
+	}
+	*b.entry(b.next) = e
+	b.next++
+	if len(b.replaying) == 0 {
+		return nil
+	}
+	replaying := make(map[string]bool, len(b.replaying))
+	for key := range b.replaying {
+		replaying[key] = true
+	}
+	return replaying
+}
+
+// setSubscriptions updates the Subscriptions known to the buffer, returning the sequence
+// number the new Subscriptions asking for a replay start from.
+func (b *ReplayBuffer) setSubscriptions(subs []Subscription) map[string]uint64 {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.expire(time.Now())
+	known := make(map[string]bool, len(subs))
+	starts := make(map[string]uint64)
+	for _, sub := range subs {
+		key := subscriptionKey(sub)
+		known[key] = true
+		if b.subscriptions[key] || sub.Replay == nil {
+			continue
+		}
+		starts[key] = b.start(sub)
+		b.replaying[key] = starts[key]
+	}
+	for key := range b.replaying {
+		if !known[key] {
+			delete(b.replaying, key)
+			delete(b.lost, key)
+		}
+	}
+	b.subscriptions = known
+	return starts
+}
+
+// start returns the sequence number of the first event replayed to sub, b.mutex must be held.
+func (b *ReplayBuffer) start(sub Subscription) uint64 {
+	switch {
+	case sub.Replay.StartTime != nil:
+		for seq := b.first; seq < b.next; seq++ {
+			if !b.entry(seq).received.Before(sub.Replay.StartTime.Time) {
+				return seq
+			}
+		}
+		return b.next
+	case sub.Replay.EventID != nil:
+		for seq := b.first; seq < b.next; seq++ {
+			if b.entry(seq).event.ID() == *sub.Replay.EventID {
+				return seq
+			}
+		}
+		return b.next
+	}
+	return b.first
+}
+
+// read returns the events retained since the last read of the replaying Subscription key,
+// along with the number of events dropped before it could read them and the log offsets of the
+// persisted ones. Once the Subscription read all the events, it doesn't replay anymore and read
+// returns false.
+func (b *ReplayBuffer) read(key string) ([]retainedEvent, uint64, []uint64, bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.expire(time.Now())
+	from, ok := b.replaying[key]
+	if !ok {
+		// The Subscription was removed.
+		return nil, 0, nil, false
+	}
+	var dropped uint64
+	if from < b.first {
+		dropped = b.first - from
+		from = b.first
+	}
+	lost := b.lost[key]
+	delete(b.lost, key)
+	if from == b.next {
+		delete(b.replaying, key)
+		return nil, dropped, lost, false
+	}
+	events := make([]retainedEvent, 0, b.next-from)
+	for seq := from; seq < b.next; seq++ {
+		events = append(events, *b.entry(seq))
+	}
+	b.replaying[key] = b.next
+	return events, dropped, lost, true
+}
+
+type replayingSubscriptionsKey struct{}
+
+// liveSubscriptions returns the Subscriptions of the handler the message received with ctx
+// is dispatched to, leaving out the replaying ones.
+func (f *FanoutMessageHandler) liveSubscriptions(ctx context.Context) []Subscription {
+	subs := f.GetSubscriptions(ctx)
+	replaying, _ := ctx.Value(replayingSubscriptionsKey{}).(map[string]bool)
+	if len(replaying) == 0 {
+		return subs
+	}
+	live := make([]Subscription, 0, len(subs))
+	for _, sub := range subs {
+		if !replaying[subscriptionKey(sub)] {
+			live = append(live, sub)
+		}
+	}
+	return live
+}
+
+// startReplays streams the retained events to the new Subscriptions asking for them.
+func (f *FanoutMessageHandler) startReplays(subs []Subscription) {
+	starts := f.buffer.setSubscriptions(subs)
+	for _, sub := range subs {
+		if _, ok := starts[subscriptionKey(sub)]; ok {
+			go f.replayRetained(sub)
+		}
+	}
+}
+
+// replayRetained dispatches the retained events to sub one after the other, until it caught up
+// with the events received by the handler. The persisted events are acknowledged in the log for
+// sub once dispatched, or dropped before sub could read them.
+func (f *FanoutMessageHandler) replayRetained(sub Subscription) {
+	key := subscriptionKey(sub)
+	f.logger.Info("Replaying the retained events", zap.String("subscription", key))
+	for {
+		events, dropped, lost, ok := f.buffer.read(key)
+		if dropped > 0 {
+			f.logger.Warn("Retained events were dropped before being replayed", zap.String("subscription", key), zap.Uint64("dropped", dropped))
+		}
+		if f.log != nil {
+			for _, offset := range lost {
+				f.ack(sub, offset)
+			}
+		}
+		if !ok {
+			f.logger.Info("Replayed the retained events", zap.String("subscription", key))
+			return
+		}
+		for _, e := range events {
+			var done func(Subscription)
+			if f.log != nil && e.persisted {
+				offset := e.offset
+				done = func(sub Subscription) {
+					f.ack(sub, offset)
+				}
+			}
+			ctx := kncloudevents.ContextWithUnboundedLimiterWait(context.Background())
+			bufferedMessage, err := buffering.CopyMessage(ctx, binding.ToMessage(e.event))
+			if err != nil {
+				f.logger.Error("Failed to replay a retained event", zap.String("subscription", key), zap.Error(err))
+				if done != nil {
+					done(sub)
+				}
+				continue
+			}
+			reportArgs := channel.ReportArgs{}
+			reportArgs.EventType = e.event.Type()
+			reportArgs.Ns = e.namespace
+			// Any returned error is already logged in f.dispatch().
+			dispatchResultForFanout := f.dispatch(ctx, []Subscription{sub}, bufferedMessage, e.additionalHeaders, done)
+			_ = ParseDispatchResultAndReportMetrics(dispatchResultForFanout, f.reporter, reportArgs)
+		}
+	}
+}
@@ -0,0 +1,250 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fanout
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	bindingshttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	"knative.dev/pkg/apis"
+
+	eventingduckv1 "knative.dev/eventing/pkg/apis/duck/v1"
+	"knative.dev/eventing/pkg/channel"
+	"knative.dev/eventing/pkg/channel/wal"
+)
+
+func TestFanoutMessageHandlerReplay(t *testing.T) {
+	var mutex sync.Mutex
+	received := make(map[string][]string)
+	receivedIDs := func(path string) []string {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return append([]string(nil), received[path]...)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		received[r.URL.Path] = append(received[r.URL.Path], r.Header.Get("ce-id"))
+		mutex.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+	subscriber := func(path string) *apis.URL {
+		u := apis.HTTP(server.URL[7:]) // strip the leading 'http://'
+		u.Path = path
+		return u
+	}
+
+	logger := zap.NewNop()
+	h, err := NewFanoutMessageHandler(logger, channel.NewMessageDispatcher(logger), Config{
+		Subscriptions: []Subscription{{UID: "live", Subscriber: subscriber("/live").URL()}},
+		Buffer:        NewReplayBuffer(3, time.Hour),
+	}, channel.NewStatsReporter("testcontainer", "testpod"))
+	require.NoError(t, err)
+
+	send := func(id string) {
+		event := makeCloudEvent()
+		event.SetID(id)
+		req := httptest.NewRequest(http.MethodPost, "http://channelname.channelnamespace/", nil)
+		require.NoError(t, bindingshttp.WriteRequest(context.Background(), binding.ToMessage(&event), req))
+		resp := httptest.NewRecorder()
+		h.ServeHTTP(resp, req)
+		require.Equal(t, http.StatusAccepted, resp.Code)
+	}
+	for i := 0; i < 4; i++ {
+		send(fmt.Sprint("e", i))
+	}
+
+	// The new subscriptions asking for a replay receive the retained events, then the live ones.
+	h.SetSubscriptions(context.Background(), []Subscription{
+		{UID: "live", Subscriber: subscriber("/live").URL()},
+		{UID: "start", Subscriber: subscriber("/start").URL(), Replay: &eventingduckv1.Replay{}},
+		{UID: "event", Subscriber: subscriber("/event").URL(), Replay: &eventingduckv1.Replay{EventID: pointer.StringPtr("e2")}},
+		{UID: "unknown", Subscriber: subscriber("/unknown").URL(), Replay: &eventingduckv1.Replay{EventID: pointer.StringPtr("e0")}},
+		{UID: "none", Subscriber: subscriber("/none").URL()},
+	})
+	wantReceived := func(want map[string][]string) {
+		t.Helper()
+		for path, ids := range want {
+			require.Eventually(t, func() bool {
+				return len(receivedIDs(path)) == len(ids)
+			}, 5*time.Second, 10*time.Millisecond, path)
+			if diff := cmp.Diff(ids, receivedIDs(path)); diff != "" {
+				t.Errorf("Unexpected events for %s (-want, +got) = %s", path, diff)
+			}
+		}
+	}
+	wantReceived(map[string][]string{
+		"/start": {"e1", "e2", "e3"},
+		"/event": {"e2", "e3"},
+	})
+
+	send("e4")
+	wantReceived(map[string][]string{
+		"/live":    {"e0", "e1", "e2", "e3", "e4"},
+		"/start":   {"e1", "e2", "e3", "e4"},
+		"/event":   {"e2", "e3", "e4"},
+		"/unknown": {"e4"},
+		"/none":    {"e4"},
+	})
+
+	// The subscriptions are only replayed when they're added.
+	h.SetSubscriptions(context.Background(), []Subscription{
+		{UID: "start", Subscriber: subscriber("/start").URL(), Replay: &eventingduckv1.Replay{}},
+	})
+	send("e5")
+	require.Eventually(t, func() bool {
+		return len(receivedIDs("/start")) == 5
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, "e5", receivedIDs("/start")[4])
+}
+
+func TestFanoutMessageHandlerReplayWithLog(t *testing.T) {
+	var mutex sync.Mutex
+	received := make(map[string][]string)
+	receivedIDs := func(path string) []string {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return append([]string(nil), received[path]...)
+	}
+	// The replay is held until the subscription gets an event while replaying.
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/start" {
+			<-release
+		}
+		mutex.Lock()
+		received[r.URL.Path] = append(received[r.URL.Path], r.Header.Get("ce-id"))
+		mutex.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+	subscriber := func(path string) *apis.URL {
+		u := apis.HTTP(server.URL[7:]) // strip the leading 'http://'
+		u.Path = path
+		return u
+	}
+
+	log, err := wal.Open(t.TempDir(), wal.Options{})
+	require.NoError(t, err)
+	defer log.Close()
+	logger := zap.NewNop()
+	h, err := NewFanoutMessageHandler(logger, channel.NewMessageDispatcher(logger), Config{
+		Subscriptions: []Subscription{{UID: "live", Subscriber: subscriber("/live").URL()}},
+		Buffer:        NewReplayBuffer(3, time.Hour),
+		Log:           log,
+	}, channel.NewStatsReporter("testcontainer", "testpod"))
+	require.NoError(t, err)
+
+	send := func(id string) {
+		event := makeCloudEvent()
+		event.SetID(id)
+		req := httptest.NewRequest(http.MethodPost, "http://channelname.channelnamespace/", nil)
+		require.NoError(t, bindingshttp.WriteRequest(context.Background(), binding.ToMessage(&event), req))
+		resp := httptest.NewRecorder()
+		h.ServeHTTP(resp, req)
+		require.Equal(t, http.StatusAccepted, resp.Code)
+	}
+	wantCursor := func(name string, offset uint64) {
+		t.Helper()
+		require.Eventually(t, func() bool {
+			got, _ := log.Cursor(name)
+			return got == offset
+		}, 5*time.Second, 10*time.Millisecond, name)
+	}
+	send("e0")
+	send("e1")
+	wantCursor("live", 2)
+
+	// The replayed events are acknowledged for the replaying subscription, like the live ones.
+	h.SetSubscriptions(context.Background(), []Subscription{
+		{UID: "live", Subscriber: subscriber("/live").URL()},
+		{UID: "start", Subscriber: subscriber("/start").URL(), Replay: &eventingduckv1.Replay{}},
+	})
+	send("e2")
+	close(release)
+	wantCursor("live", 3)
+	wantCursor("start", 3)
+	require.Equal(t, uint64(3), log.NextOffset())
+	if diff := cmp.Diff([]string{"e0", "e1", "e2"}, receivedIDs("/start")); diff != "" {
+		t.Error("Unexpected replayed events (-want, +got) =", diff)
+	}
+}
+
+func TestReplayBuffer(t *testing.T) {
+	b := NewReplayBuffer(3, time.Minute)
+	now := time.Now()
+	for i := 0; i < 4; i++ {
+		event := makeCloudEvent()
+		event.SetID(fmt.Sprint("e", i))
+		b.append(retainedEvent{namespace: "ns", event: &event})
+	}
+	// Backdate the retained events.
+	for seq := b.first; seq < b.next; seq++ {
+		b.entry(seq).received = now.Add(time.Duration(int(seq)-4) * 20 * time.Second)
+	}
+	startTime := metav1.NewTime(now.Add(-50 * time.Second))
+
+	starts := b.setSubscriptions([]Subscription{
+		{UID: "start", Replay: &eventingduckv1.Replay{}},
+		{UID: "time", Replay: &eventingduckv1.Replay{StartTime: &startTime}},
+		{UID: "event", Replay: &eventingduckv1.Replay{EventID: pointer.StringPtr("e3")}},
+		{UID: "none"},
+	})
+	// The first event was dropped as the buffer is full, the second one expired.
+	if diff := cmp.Diff(map[string]uint64{"start": 2, "time": 2, "event": 3}, starts); diff != "" {
+		t.Error("Unexpected starts (-want, +got) =", diff)
+	}
+
+	events, dropped, _, ok := b.read("event")
+	require.True(t, ok)
+	require.Zero(t, dropped)
+	require.Len(t, events, 1)
+	require.Equal(t, "e3", events[0].event.ID())
+	_, _, _, ok = b.read("event")
+	require.False(t, ok)
+	_, _, _, ok = b.read("none")
+	require.False(t, ok)
+
+	// The persisted events dropped before a replaying subscription read them are lost for it.
+	for i := 4; i < 8; i++ {
+		event := makeCloudEvent()
+		event.SetID(fmt.Sprint("e", i))
+		b.append(retainedEvent{namespace: "ns", event: &event, offset: uint64(i), persisted: i != 5})
+	}
+	events, dropped, lost, ok := b.read("start")
+	require.True(t, ok)
+	require.Equal(t, uint64(3), dropped)
+	require.Equal(t, []uint64{4}, lost)
+	require.Len(t, events, 3)
+
+	// The subscriptions which were removed stop replaying.
+	b.setSubscriptions(nil)
+	_, _, _, ok = b.read("time")
+	require.False(t, ok)
+}
@@ -29,6 +29,7 @@ import (
 	"github.com/cloudevents/sdk-go/v2/protocol/http"
 	"go.opencensus.io/trace"
 	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 
 	"knative.dev/eventing/pkg/channel/attributes"
@@ -55,8 +56,17 @@ type MessageDispatcher interface {
 	DispatchMessageWithRetries(ctx context.Context, message cloudevents.Message, additionalHeaders nethttp.Header, destination *url.URL, reply *url.URL, deadLetter *url.URL, config *kncloudevents.RetryConfig, transformers ...binding.Transformer) (*DispatchExecutionInfo, error)
 }
 
+// BatchMessageDispatcher dispatches batches of events.
+type BatchMessageDispatcher interface {
+	// DispatchBatchWithRetries dispatches the events to a destination over HTTP, in a single
+	// request in the CloudEvents batched content mode. The result of the request applies to
+	// all the events: when it fails, each event is sent to the dead letter sink.
+	DispatchBatchWithRetries(ctx context.Context, events []*cloudevents.Event, additionalHeaders nethttp.Header, destination *url.URL, deadLetter *url.URL, config *kncloudevents.RetryConfig) (*DispatchExecutionInfo, error)
+}
+
 // MessageDispatcherImpl is the 'real' MessageDispatcher used everywhere except unit tests.
 var _ MessageDispatcher = &MessageDispatcherImpl{}
+var _ BatchMessageDispatcher = &MessageDispatcherImpl{}
 
 // MessageDispatcherImpl dispatches events to a destination over HTTP.
 type MessageDispatcherImpl struct {
@@ -70,6 +80,23 @@ type DispatchExecutionInfo struct {
 	Time         time.Duration
 	ResponseCode int
 	ResponseBody []byte
+	// Attempts is the number of requests sent, including the retries.
+	Attempts int
+	// FirstFailureTime is the time of the first failed request, zero if none failed.
+	FirstFailureTime time.Time
+}
+
+type deliveryRefKey struct{}
+
+type deliveryRef struct {
+	kind string
+	uid  types.UID
+}
+
+// ContextWithDeliveryRef returns a copy of ctx carrying the kind and UID of the Subscription or
+// Trigger a message is dispatched for, which are added to the messages sent to the dead letter sink.
+func ContextWithDeliveryRef(ctx context.Context, kind string, uid types.UID) context.Context {
+	return context.WithValue(ctx, deliveryRefKey{}, deliveryRef{kind: kind, uid: uid})
 }
 
 // NewMessageDispatcher creates a new Message dispatcher based on config.
@@ -127,11 +154,22 @@ func (d *MessageDispatcherImpl) DispatchMessageWithRetries(ctx context.Context,
 		}
 		additionalHeadersForDestination.Set("Prefer", "reply")
 
+		// Wait for the limiter of the destination, the events beyond its limits are rejected
+		// so that the sender retries them later. The slot is held through the retries and
+		// their backoff delays, which count as in flight.
+		release, err := d.sender.Limiters.Acquire(ctx, destination.String(), retriesConfig)
+		if err != nil {
+			return &DispatchExecutionInfo{
+				Time:         NoDuration,
+				ResponseCode: nethttp.StatusTooManyRequests,
+			}, fmt.Errorf("unable to complete request to %s: %v: %w", destination, err, ErrOverloaded)
+		}
 		ctx, responseMessage, responseAdditionalHeaders, dispatchExecutionInfo, err = d.executeRequest(ctx, destination, message, additionalHeadersForDestination, retriesConfig, transformers...)
+		release()
 		if err != nil {
 			// If DeadLetter is configured, then send original message with knative error extensions
 			if deadLetter != nil {
-				dispatchTransformers := d.dispatchExecutionInfoTransformers(destination, dispatchExecutionInfo)
+				dispatchTransformers := d.dispatchExecutionInfoTransformers(ctx, destination, dispatchExecutionInfo)
 				_, deadLetterResponse, _, dispatchExecutionInfo, deadLetterErr := d.executeRequest(ctx, deadLetter, message, additionalHeaders, retriesConfig, append(transformers, dispatchTransformers)...)
 				if deadLetterErr != nil {
 					return dispatchExecutionInfo, fmt.Errorf("unable to complete request to either %s (%v) or %s (%v)", destination, err, deadLetter, deadLetterErr)
@@ -167,7 +205,7 @@ func (d *MessageDispatcherImpl) DispatchMessageWithRetries(ctx context.Context,
 	if err != nil {
 		// If DeadLetter is configured, then send original message with knative error extensions
 		if deadLetter != nil {
-			dispatchTransformers := d.dispatchExecutionInfoTransformers(reply, dispatchExecutionInfo)
+			dispatchTransformers := d.dispatchExecutionInfoTransformers(ctx, reply, dispatchExecutionInfo)
 			_, deadLetterResponse, _, dispatchExecutionInfo, deadLetterErr := d.executeRequest(ctx, deadLetter, message, responseAdditionalHeaders, retriesConfig, append(transformers, dispatchTransformers)...)
 			if deadLetterErr != nil {
 				return dispatchExecutionInfo, fmt.Errorf("failed to forward reply to %s (%v) and failed to send it to the dead letter sink %s (%v)", reply, err, deadLetter, deadLetterErr)
@@ -188,6 +226,53 @@ func (d *MessageDispatcherImpl) DispatchMessageWithRetries(ctx context.Context,
 	return dispatchExecutionInfo, nil
 }
 
+func (d *MessageDispatcherImpl) DispatchBatchWithRetries(ctx context.Context, events []*cloudevents.Event, additionalHeaders nethttp.Header, destination *url.URL, deadLetter *url.URL, retriesConfig *kncloudevents.RetryConfig) (*DispatchExecutionInfo, error) {
+	// sanitize eventual host-only URLs
+	destination = d.sanitizeURL(destination)
+	deadLetter = d.sanitizeURL(deadLetter)
+
+	release, err := d.sender.Limiters.Acquire(ctx, destination.String(), retriesConfig)
+	if err != nil {
+		return &DispatchExecutionInfo{
+			Time:         NoDuration,
+			ResponseCode: nethttp.StatusTooManyRequests,
+		}, fmt.Errorf("unable to complete request to %s: %v: %w", destination, err, ErrOverloaded)
+	}
+	ctx, responseMessage, _, dispatchExecutionInfo, err := d.executeRequestWith(ctx, destination, retriesConfig, func(ctx context.Context, _ *trace.Span, req *nethttp.Request) error {
+		return kncloudevents.WriteHTTPRequestWithBatch(ctx, events, req, additionalHeaders)
+	})
+	release()
+	if err == nil {
+		// The replies to a batch aren't forwarded.
+		if responseMessage != nil {
+			_ = responseMessage.Finish(nil)
+		}
+		return dispatchExecutionInfo, nil
+	}
+	if deadLetter == nil {
+		return dispatchExecutionInfo, fmt.Errorf("unable to complete request to %s: %v", destination, err)
+	}
+
+	// Send each event of the batch to the dead letter sink, with the knative error extensions
+	// of the batch.
+	dispatchTransformers := d.dispatchExecutionInfoTransformers(ctx, destination, dispatchExecutionInfo)
+	var deadLetterErrs []error
+	for _, event := range events {
+		_, deadLetterResponse, _, deadLetterInfo, deadLetterErr := d.executeRequest(ctx, deadLetter, binding.ToMessage(event), additionalHeaders, retriesConfig, dispatchTransformers...)
+		dispatchExecutionInfo = deadLetterInfo
+		if deadLetterErr != nil {
+			deadLetterErrs = append(deadLetterErrs, deadLetterErr)
+		}
+		if deadLetterResponse != nil {
+			_ = deadLetterResponse.Finish(nil)
+		}
+	}
+	if len(deadLetterErrs) > 0 {
+		return dispatchExecutionInfo, fmt.Errorf("unable to complete request to either %s (%v) or %s (%d events failed, first error: %v)", destination, err, deadLetter, len(deadLetterErrs), deadLetterErrs[0])
+	}
+	return dispatchExecutionInfo, nil
+}
+
 func (d *MessageDispatcherImpl) executeRequest(ctx context.Context,
 	url *url.URL,
 	message cloudevents.Message,
@@ -195,6 +280,20 @@ func (d *MessageDispatcherImpl) executeRequest(ctx context.Context,
 	configs *kncloudevents.RetryConfig,
 	transformers ...binding.Transformer) (context.Context, cloudevents.Message, nethttp.Header, *DispatchExecutionInfo, error) {
 
+	return d.executeRequestWith(ctx, url, configs, func(ctx context.Context, span *trace.Span, req *nethttp.Request) error {
+		if span.IsRecordingEvents() {
+			transformers = append(transformers, tracing.PopulateSpan(span, url.String()))
+		}
+		return kncloudevents.WriteHTTPRequestWithAdditionalHeaders(ctx, message, req, additionalHeaders, transformers...)
+	})
+}
+
+// executeRequestWith sends the request written by write to url.
+func (d *MessageDispatcherImpl) executeRequestWith(ctx context.Context,
+	url *url.URL,
+	configs *kncloudevents.RetryConfig,
+	write func(ctx context.Context, span *trace.Span, req *nethttp.Request) error) (context.Context, cloudevents.Message, nethttp.Header, *DispatchExecutionInfo, error) {
+
 	d.logger.Debug("Dispatching event", zap.String("url", url.String()))
 
 	execInfo := DispatchExecutionInfo{
@@ -209,18 +308,17 @@ func (d *MessageDispatcherImpl) executeRequest(ctx context.Context,
 		return ctx, nil, nil, &execInfo, err
 	}
 
-	if span.IsRecordingEvents() {
-		transformers = append(transformers, tracing.PopulateSpan(span, url.String()))
-	}
-
-	err = kncloudevents.WriteHTTPRequestWithAdditionalHeaders(ctx, message, req, additionalHeaders, transformers...)
+	err = write(ctx, span, req)
 	if err != nil {
 		return ctx, nil, nil, &execInfo, err
 	}
 
+	var attempts kncloudevents.DeliveryAttempts
 	start := time.Now()
-	response, err := d.sender.SendWithRetries(req, configs)
+	response, err := d.sender.SendWithRetriesAndAttempts(req, configs, &attempts)
 	dispatchTime := time.Since(start)
+	execInfo.Attempts = attempts.Count
+	execInfo.FirstFailureTime = attempts.FirstFailure
 	if err != nil {
 		execInfo.Time = dispatchTime
 		execInfo.ResponseCode = nethttp.StatusInternalServerError
@@ -271,8 +369,9 @@ func (d *MessageDispatcherImpl) sanitizeURL(u *url.URL) *url.URL {
 	}
 }
 
-// dispatchExecutionTransformer returns Transformers based on the specified destination and DispatchExecutionInfo
-func (d *MessageDispatcherImpl) dispatchExecutionInfoTransformers(destination *url.URL, dispatchExecutionInfo *DispatchExecutionInfo) binding.Transformers {
+// dispatchExecutionTransformer returns Transformers based on the specified destination and DispatchExecutionInfo,
+// and on the Subscription or Trigger in ctx.
+func (d *MessageDispatcherImpl) dispatchExecutionInfoTransformers(ctx context.Context, destination *url.URL, dispatchExecutionInfo *DispatchExecutionInfo) binding.Transformers {
 	if destination == nil {
 		destination = &url.URL{}
 	}
@@ -281,7 +380,15 @@ func (d *MessageDispatcherImpl) dispatchExecutionInfoTransformers(destination *u
 	// and cause HTTP requests to fail if not removed.
 	// https://pkg.go.dev/golang.org/x/net/http/httpguts#ValidHeaderFieldValue
 	httpBody := sanitizeHTTPBody(dispatchExecutionInfo.ResponseBody)
-	return attributes.KnativeErrorTransformers(*destination, dispatchExecutionInfo.ResponseCode, httpBody)
+	transformers := attributes.KnativeErrorTransformers(*destination, dispatchExecutionInfo.ResponseCode, httpBody)
+
+	firstFailure := dispatchExecutionInfo.FirstFailureTime
+	if firstFailure.IsZero() {
+		// The request failed before being sent.
+		firstFailure = time.Now()
+	}
+	ref, _ := ctx.Value(deliveryRefKey{}).(deliveryRef)
+	return append(transformers, attributes.KnativeErrorDeliveryTransformers(dispatchExecutionInfo.Attempts, firstFailure, ref.kind, string(ref.uid))...)
 }
 
 func sanitizeHTTPBody(body []byte) string {
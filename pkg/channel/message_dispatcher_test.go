@@ -19,6 +19,7 @@ package channel
 import (
 	"bytes"
 	"context"
+	"errors"
 	"io"
 	"io/ioutil"
 	"net/http"
@@ -26,7 +27,9 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	cloudevents "github.com/cloudevents/sdk-go/v2"
 	"github.com/cloudevents/sdk-go/v2/binding"
@@ -37,6 +40,8 @@ import (
 	"go.uber.org/zap/zaptest"
 	"k8s.io/apimachinery/pkg/util/sets"
 
+	"knative.dev/eventing/pkg/channel/attributes"
+	"knative.dev/eventing/pkg/kncloudevents"
 	"knative.dev/eventing/pkg/utils"
 )
 
@@ -409,18 +414,19 @@ func TestDispatchMessage(t *testing.T) {
 			},
 			expectedDeadLetterRequest: &requestValidation{
 				Headers: map[string][]string{
-					"x-request-id":        {"id123"},
-					"knative-1":           {"knative-1-value"},
-					"knative-2":           {"knative-2-value"},
-					"traceparent":         {"ignored-value-header"},
-					"ce-abc":              {`"ce-abc-value"`},
-					"ce-knativeerrorcode": {strconv.Itoa(http.StatusBadRequest)},
-					"ce-knativeerrordata": {"destination-response"},
-					"ce-id":               {"ignored-value-header"},
-					"ce-time":             {"2002-10-02T15:00:00Z"},
-					"ce-source":           {testCeSource},
-					"ce-type":             {testCeType},
-					"ce-specversion":      {cloudevents.VersionV1},
+					"x-request-id":            {"id123"},
+					"knative-1":               {"knative-1-value"},
+					"knative-2":               {"knative-2-value"},
+					"traceparent":             {"ignored-value-header"},
+					"ce-abc":                  {`"ce-abc-value"`},
+					"ce-knativeerrorcode":     {strconv.Itoa(http.StatusBadRequest)},
+					"ce-knativeerrorattempts": {"1"},
+					"ce-knativeerrordata":     {"destination-response"},
+					"ce-id":                   {"ignored-value-header"},
+					"ce-time":                 {"2002-10-02T15:00:00Z"},
+					"ce-source":               {testCeSource},
+					"ce-type":                 {testCeType},
+					"ce-specversion":          {cloudevents.VersionV1},
 				},
 				Body: `"destination"`,
 			},
@@ -477,18 +483,19 @@ func TestDispatchMessage(t *testing.T) {
 			},
 			expectedDeadLetterRequest: &requestValidation{
 				Headers: map[string][]string{
-					"x-request-id":        {"id123"},
-					"knative-1":           {"knative-1-value"},
-					"knative-2":           {"knative-2-value"},
-					"traceparent":         {"ignored-value-header"},
-					"ce-abc":              {`"ce-abc-value"`},
-					"ce-id":               {"ignored-value-header"},
-					"ce-knativeerrorcode": {strconv.Itoa(http.StatusBadRequest)},
-					"ce-knativeerrordata": {"destination-response"},
-					"ce-time":             {"2002-10-02T15:00:00Z"},
-					"ce-source":           {testCeSource},
-					"ce-type":             {testCeType},
-					"ce-specversion":      {cloudevents.VersionV1},
+					"x-request-id":            {"id123"},
+					"knative-1":               {"knative-1-value"},
+					"knative-2":               {"knative-2-value"},
+					"traceparent":             {"ignored-value-header"},
+					"ce-abc":                  {`"ce-abc-value"`},
+					"ce-id":                   {"ignored-value-header"},
+					"ce-knativeerrorcode":     {strconv.Itoa(http.StatusBadRequest)},
+					"ce-knativeerrorattempts": {"1"},
+					"ce-knativeerrordata":     {"destination-response"},
+					"ce-time":                 {"2002-10-02T15:00:00Z"},
+					"ce-source":               {testCeSource},
+					"ce-type":                 {testCeType},
+					"ce-specversion":          {cloudevents.VersionV1},
 				},
 				Body: `"destination"`,
 			},
@@ -533,18 +540,19 @@ func TestDispatchMessage(t *testing.T) {
 			},
 			expectedDeadLetterRequest: &requestValidation{
 				Headers: map[string][]string{
-					"x-request-id":        {"id123"},
-					"knative-1":           {"knative-1-value"},
-					"knative-2":           {"knative-2-value"},
-					"traceparent":         {"ignored-value-header"},
-					"ce-abc":              {`"ce-abc-value"`},
-					"ce-id":               {"ignored-value-header"},
-					"ce-knativeerrorcode": {strconv.Itoa(http.StatusBadRequest)},
-					"ce-knativeerrordata": {"destination-response"},
-					"ce-time":             {"2002-10-02T15:00:00Z"},
-					"ce-source":           {testCeSource},
-					"ce-type":             {testCeType},
-					"ce-specversion":      {cloudevents.VersionV1},
+					"x-request-id":            {"id123"},
+					"knative-1":               {"knative-1-value"},
+					"knative-2":               {"knative-2-value"},
+					"traceparent":             {"ignored-value-header"},
+					"ce-abc":                  {`"ce-abc-value"`},
+					"ce-id":                   {"ignored-value-header"},
+					"ce-knativeerrorcode":     {strconv.Itoa(http.StatusBadRequest)},
+					"ce-knativeerrorattempts": {"1"},
+					"ce-knativeerrordata":     {"destination-response"},
+					"ce-time":                 {"2002-10-02T15:00:00Z"},
+					"ce-source":               {testCeSource},
+					"ce-type":                 {testCeType},
+					"ce-specversion":          {cloudevents.VersionV1},
 				},
 				Body: `"destination"`,
 			},
@@ -605,17 +613,18 @@ func TestDispatchMessage(t *testing.T) {
 			},
 			expectedDeadLetterRequest: &requestValidation{
 				Headers: map[string][]string{
-					"x-request-id":        {"altered-id"},
-					"knative-1":           {"new-knative-1-value"},
-					"traceparent":         {"ignored-value-header"},
-					"ce-abc":              {`"ce-abc-value"`},
-					"ce-id":               {"ignored-value-header"},
-					"ce-knativeerrorcode": {strconv.Itoa(http.StatusBadRequest)},
-					"ce-knativeerrordata": {"reply-response"},
-					"ce-time":             {"2002-10-02T15:00:00Z"},
-					"ce-source":           {testCeSource},
-					"ce-type":             {testCeType},
-					"ce-specversion":      {cloudevents.VersionV1},
+					"x-request-id":            {"altered-id"},
+					"knative-1":               {"new-knative-1-value"},
+					"traceparent":             {"ignored-value-header"},
+					"ce-abc":                  {`"ce-abc-value"`},
+					"ce-id":                   {"ignored-value-header"},
+					"ce-knativeerrorcode":     {strconv.Itoa(http.StatusBadRequest)},
+					"ce-knativeerrorattempts": {"1"},
+					"ce-knativeerrordata":     {"reply-response"},
+					"ce-time":                 {"2002-10-02T15:00:00Z"},
+					"ce-source":               {testCeSource},
+					"ce-type":                 {testCeType},
+					"ce-specversion":          {cloudevents.VersionV1},
 				},
 				Body: `"destination"`,
 			},
@@ -687,18 +696,19 @@ func TestDispatchMessage(t *testing.T) {
 			},
 			expectedDeadLetterRequest: &requestValidation{
 				Headers: map[string][]string{
-					"x-request-id":        {"id123"},
-					"knative-1":           {"knative-1-value"},
-					"knative-2":           {"knative-2-value"},
-					"traceparent":         {"ignored-value-header"},
-					"ce-abc":              {`"ce-abc-value"`},
-					"ce-knativeerrorcode": {strconv.Itoa(http.StatusBadRequest)},
-					"ce-knativeerrordata": {"destination multi-line response"},
-					"ce-id":               {"ignored-value-header"},
-					"ce-time":             {"2002-10-02T15:00:00Z"},
-					"ce-source":           {testCeSource},
-					"ce-type":             {testCeType},
-					"ce-specversion":      {cloudevents.VersionV1},
+					"x-request-id":            {"id123"},
+					"knative-1":               {"knative-1-value"},
+					"knative-2":               {"knative-2-value"},
+					"traceparent":             {"ignored-value-header"},
+					"ce-abc":                  {`"ce-abc-value"`},
+					"ce-knativeerrorcode":     {strconv.Itoa(http.StatusBadRequest)},
+					"ce-knativeerrorattempts": {"1"},
+					"ce-knativeerrordata":     {"destination multi-line response"},
+					"ce-id":                   {"ignored-value-header"},
+					"ce-time":                 {"2002-10-02T15:00:00Z"},
+					"ce-source":               {testCeSource},
+					"ce-type":                 {testCeType},
+					"ce-specversion":          {cloudevents.VersionV1},
 				},
 				Body: `"destination"`,
 			},
@@ -839,6 +849,11 @@ func TestDispatchMessage(t *testing.T) {
 					tc.expectedDeadLetterRequest.Headers.Set("ce-knativeerrordest", destServer.URL+"/")
 				}
 				rv := deadLetterSinkHandler.popRequest(t)
+				// The time of the first failure isn't predictable.
+				if ts, err := time.Parse(time.RFC3339Nano, rv.Headers.Get("ce-knativeerrortime")); err != nil || time.Since(ts) > time.Minute {
+					t.Errorf("Unexpected ce-knativeerrortime header %q", rv.Headers.Get("ce-knativeerrortime"))
+				}
+				rv.Headers.Del("ce-knativeerrortime")
 				assertEquality(t, deadLetterSinkServer.URL, *tc.expectedDeadLetterRequest, rv)
 			}
 			if len(destHandler.requests) != 0 {
@@ -873,6 +888,174 @@ type requestValidation struct {
 	Body    string
 }
 
+func TestDispatchMessageCircuitOpen(t *testing.T) {
+	var destinationRequests, deadLetterRequests int32
+	destServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&destinationRequests, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer destServer.Close()
+	deadLetterServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&deadLetterRequests, 1)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer deadLetterServer.Close()
+
+	sender, err := kncloudevents.NewHTTPMessageSenderWithTarget("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sender.CircuitBreakers = kncloudevents.NewCircuitBreakers(kncloudevents.CircuitBreakerConfig{
+		FailureRatio: 1,
+		MinRequests:  1,
+		CoolDown:     time.Hour,
+	})
+	md := NewMessageDispatcherFromSender(zaptest.NewLogger(t), sender)
+
+	destination := getOnlyDomainURL(t, true, destServer.URL)
+	deadLetterSink := getOnlyDomainURL(t, true, deadLetterServer.URL)
+	retryConfig := &kncloudevents.RetryConfig{
+		RetryMax:   5,
+		CheckRetry: kncloudevents.RetryIfGreaterThan300,
+		Backoff: func(attemptNum int, resp *http.Response) time.Duration {
+			return time.Millisecond
+		},
+	}
+
+	for i := 0; i < 2; i++ {
+		event := cloudevents.NewEvent(cloudevents.VersionV1)
+		event.SetID(uuid.New().String())
+		event.SetType(testCeType)
+		event.SetSource(testCeSource)
+		if _, err := md.DispatchMessageWithRetries(context.Background(), binding.ToMessage(&event), nil, destination, nil, deadLetterSink, retryConfig); err != nil {
+			t.Fatal("DispatchMessageWithRetries() =", err)
+		}
+	}
+
+	// The circuit opens on the first failure, so the retries are not attempted and the second
+	// event goes straight to the dead letter sink.
+	if got := atomic.LoadInt32(&destinationRequests); got != 1 {
+		t.Errorf("Unexpected destination requests. Expected 1. Actual %d.", got)
+	}
+	if got := atomic.LoadInt32(&deadLetterRequests); got != 2 {
+		t.Errorf("Unexpected dead letter sink requests. Expected 2. Actual %d.", got)
+	}
+}
+
+func TestDispatchMessageLimited(t *testing.T) {
+	var destinationRequests, deadLetterRequests int32
+	unblock := make(chan struct{})
+	destServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&destinationRequests, 1)
+		<-unblock
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer destServer.Close()
+	deadLetterServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&deadLetterRequests, 1)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer deadLetterServer.Close()
+
+	sender, err := kncloudevents.NewHTTPMessageSenderWithTarget("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sender.Limiters = kncloudevents.NewLimiters(kncloudevents.LimiterConfig{MaxWait: 50 * time.Millisecond})
+	md := NewMessageDispatcherFromSender(zaptest.NewLogger(t), sender)
+
+	destination := getOnlyDomainURL(t, true, destServer.URL)
+	deadLetterSink := getOnlyDomainURL(t, true, deadLetterServer.URL)
+	retryConfig := &kncloudevents.RetryConfig{CheckRetry: kncloudevents.RetryIfGreaterThan300, MaxInFlight: 1}
+	dispatch := func() (*DispatchExecutionInfo, error) {
+		event := cloudevents.NewEvent(cloudevents.VersionV1)
+		event.SetID(uuid.New().String())
+		event.SetType(testCeType)
+		event.SetSource(testCeSource)
+		return md.DispatchMessageWithRetries(context.Background(), binding.ToMessage(&event), nil, destination, nil, deadLetterSink, retryConfig)
+	}
+
+	first := make(chan error)
+	go func() {
+		_, err := dispatch()
+		first <- err
+	}()
+	for atomic.LoadInt32(&destinationRequests) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// The second event is rejected, without going to the dead letter sink, so that the sender
+	// retries it.
+	info, err := dispatch()
+	if !errors.Is(err, ErrOverloaded) {
+		t.Error("Expected ErrOverloaded, got", err)
+	}
+	if info.ResponseCode != http.StatusTooManyRequests {
+		t.Errorf("Unexpected response code. Expected %d. Actual %d.", http.StatusTooManyRequests, info.ResponseCode)
+	}
+	close(unblock)
+	if err := <-first; err != nil {
+		t.Fatal("DispatchMessageWithRetries() =", err)
+	}
+	if got := atomic.LoadInt32(&destinationRequests); got != 1 {
+		t.Errorf("Unexpected destination requests. Expected 1. Actual %d.", got)
+	}
+	if got := atomic.LoadInt32(&deadLetterRequests); got != 0 {
+		t.Errorf("Unexpected dead letter sink requests. Expected 0. Actual %d.", got)
+	}
+}
+
+func TestDispatchMessageDeadLetterDeliveryInfo(t *testing.T) {
+	destServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer destServer.Close()
+	deadLetters := make(chan http.Header, 1)
+	deadLetterServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deadLetters <- r.Header
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer deadLetterServer.Close()
+
+	md := NewMessageDispatcher(zaptest.NewLogger(t))
+	retryConfig := &kncloudevents.RetryConfig{
+		RetryMax:   2,
+		CheckRetry: kncloudevents.RetryIfGreaterThan300,
+		Backoff: func(attemptNum int, resp *http.Response) time.Duration {
+			return time.Millisecond
+		},
+	}
+
+	event := cloudevents.NewEvent(cloudevents.VersionV1)
+	event.SetID(uuid.New().String())
+	event.SetType(testCeType)
+	event.SetSource(testCeSource)
+	ctx := ContextWithDeliveryRef(context.Background(), attributes.KnativeErrorKindSubscription, "sub-uid")
+	before := time.Now()
+	destination := getOnlyDomainURL(t, true, destServer.URL)
+	deadLetterSink := getOnlyDomainURL(t, true, deadLetterServer.URL)
+	if _, err := md.DispatchMessageWithRetries(ctx, binding.ToMessage(&event), nil, destination, nil, deadLetterSink, retryConfig); err != nil {
+		t.Fatal("DispatchMessageWithRetries() =", err)
+	}
+
+	headers := <-deadLetters
+	want := map[string]string{
+		"Ce-Knativeerrorcode":     "503",
+		"Ce-Knativeerrorattempts": "3",
+		"Ce-Knativeerrorkind":     attributes.KnativeErrorKindSubscription,
+		"Ce-Knativeerroruid":      "sub-uid",
+	}
+	for k, v := range want {
+		if got := headers.Get(k); got != v {
+			t.Errorf("Unexpected %s header. Expected %q. Actual %q.", k, v, got)
+		}
+	}
+	firstFailure, err := time.Parse(time.RFC3339Nano, headers.Get("Ce-Knativeerrortime"))
+	if err != nil || firstFailure.Before(before) || firstFailure.After(time.Now()) {
+		t.Errorf("Unexpected Ce-Knativeerrortime header %q", headers.Get("Ce-Knativeerrortime"))
+	}
+}
+
 type fakeHandler struct {
 	t        *testing.T
 	response *http.Response
@@ -954,3 +1137,72 @@ func canonicalizeHeaders(rvs ...requestValidation) {
 		}
 	}
 }
+
+func TestDispatchBatch(t *testing.T) {
+	testCases := map[string]struct {
+		destinationStatus  int
+		expectedDeadLetter []string
+	}{
+		"delivered": {
+			destinationStatus: http.StatusAccepted,
+		},
+		"sent to the dead letter sink": {
+			destinationStatus:  http.StatusBadRequest,
+			expectedDeadLetter: []string{"1", "2"},
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			var batches int32
+			destServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&batches, 1)
+				events, err := kncloudevents.ReadBatch(r.Body)
+				if err != nil || len(events) != 2 {
+					t.Errorf("Unexpected batch %v, %v", events, err)
+				}
+				w.WriteHeader(tc.destinationStatus)
+			}))
+			defer destServer.Close()
+			deadLetters := make(chan string, 2)
+			deadLetterServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if got := r.Header.Get("ce-knativeerrorcode"); got != strconv.Itoa(tc.destinationStatus) {
+					t.Errorf("Unexpected knativeerrorcode %q", got)
+				}
+				deadLetters <- r.Header.Get("ce-id")
+				w.WriteHeader(http.StatusAccepted)
+			}))
+			defer deadLetterServer.Close()
+
+			md := NewMessageDispatcher(zaptest.NewLogger(t))
+			events := make([]*cloudevents.Event, 0, 2)
+			for _, id := range []string{"1", "2"} {
+				event := cloudevents.NewEvent(cloudevents.VersionV1)
+				event.SetID(id)
+				event.SetType(testCeType)
+				event.SetSource(testCeSource)
+				events = append(events, &event)
+			}
+
+			info, err := md.DispatchBatchWithRetries(context.Background(), events, nil,
+				getOnlyDomainURL(t, true, destServer.URL), getOnlyDomainURL(t, true, deadLetterServer.URL),
+				&kncloudevents.RetryConfig{CheckRetry: kncloudevents.RetryIfGreaterThan300})
+			if err != nil {
+				t.Fatal("DispatchBatchWithRetries() =", err)
+			}
+			if info.ResponseCode != http.StatusAccepted {
+				t.Errorf("Unexpected response code. Expected %d. Actual %d.", http.StatusAccepted, info.ResponseCode)
+			}
+			if got := atomic.LoadInt32(&batches); got != 1 {
+				t.Errorf("Unexpected batches. Expected 1. Actual %d.", got)
+			}
+			close(deadLetters)
+			var deadLettered []string
+			for id := range deadLetters {
+				deadLettered = append(deadLettered, id)
+			}
+			if diff := cmp.Diff(tc.expectedDeadLetter, deadLettered); diff != "" {
+				t.Error("Unexpected dead letters (-want, +got) =", diff)
+			}
+		})
+	}
+}
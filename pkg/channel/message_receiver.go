@@ -50,6 +50,11 @@ func (e UnknownHostError) Error() string {
 	return "cannot map host to channel: " + string(e)
 }
 
+// ErrOverloaded is returned by a receiver function rejecting an event because the channel can't
+// accept more events for now. The event is rejected with 429 Too Many Requests, so that the
+// sender retries it later.
+var ErrOverloaded = errors.New("the channel is overloaded")
+
 // MessageReceiver starts a server to receive new events for the channel dispatcher. The new
 // event is emitted via the receiver function.
 type MessageReceiver struct {
@@ -158,7 +163,9 @@ func (r *MessageReceiver) ServeHTTP(response nethttp.ResponseWriter, request *ne
 
 	// The response status codes:
 	//   202 - the event was sent to subscribers
+	//   207 - the events of a batch have different status codes, reported in the body
 	//   404 - the request was for an unknown channel
+	//   429 - the channel is overloaded
 	//   500 - an error occurred processing the request
 	host := request.Host
 	r.logger.Debug("Received request", zap.String("host", host))
@@ -178,6 +185,11 @@ func (r *MessageReceiver) ServeHTTP(response nethttp.ResponseWriter, request *ne
 
 	args.Ns = channel.Namespace
 
+	if kncloudevents.IsBatch(request.Header) {
+		r.serveBatch(response, request, channel, args)
+		return
+	}
+
 	message := http.NewMessageFromHttpRequest(request)
 	if message.ReadEncoding() == binding.EncodingUnknown {
 		r.logger.Info("Cannot determine the cloudevent message encoding")
@@ -186,16 +198,57 @@ func (r *MessageReceiver) ServeHTTP(response nethttp.ResponseWriter, request *ne
 		return
 	}
 	err = r.receiverFunc(request.Context(), channel, message, []binding.Transformer{}, utils.PassThroughHeaders(request.Header))
+	response.WriteHeader(r.receiverStatusCode(err))
+}
+
+// serveBatch handles a request in the CloudEvents batched content mode. Each event of the batch
+// is passed to the receiver function on its own, the response reporting the status of each event.
+func (r *MessageReceiver) serveBatch(response nethttp.ResponseWriter, request *nethttp.Request, channel ChannelReference, args ReportArgs) {
+	events, errs, err := kncloudevents.ReadBatchEntries(request.Body)
 	if err != nil {
-		if _, ok := err.(*UnknownChannelError); ok {
-			response.WriteHeader(nethttp.StatusNotFound)
-		} else {
-			r.logger.Info("Error in receiver", zap.Error(err))
-			response.WriteHeader(nethttp.StatusInternalServerError)
-		}
+		r.logger.Info("Cannot read the batch", zap.Error(err))
+		response.WriteHeader(nethttp.StatusBadRequest)
+		r.reporter.ReportEventCount(&args, nethttp.StatusBadRequest)
 		return
 	}
-	response.WriteHeader(nethttp.StatusAccepted)
+
+	additionalHeaders := utils.PassThroughHeaders(request.Header)
+	results := make([]kncloudevents.BatchResult, len(events))
+	for i, event := range events {
+		results[i].Index = i
+		if errs[i] != nil {
+			r.logger.Info("Invalid event in the batch", zap.Error(errs[i]))
+			results[i].Status = nethttp.StatusBadRequest
+			results[i].Error = errs[i].Error()
+			r.reporter.ReportEventCount(&args, nethttp.StatusBadRequest)
+			continue
+		}
+		results[i].ID = event.ID()
+		err := r.receiverFunc(request.Context(), channel, binding.ToMessage(event), []binding.Transformer{}, additionalHeaders)
+		results[i].Status = r.receiverStatusCode(err)
+		if err != nil {
+			results[i].Error = err.Error()
+		}
+	}
+
+	if err := kncloudevents.WriteBatchResponse(response, results); err != nil {
+		r.logger.Info("Cannot write the batch response", zap.Error(err))
+	}
+}
+
+// receiverStatusCode returns the response status code for the error of the receiver function.
+func (r *MessageReceiver) receiverStatusCode(err error) int {
+	if err == nil {
+		return nethttp.StatusAccepted
+	}
+	if _, ok := err.(*UnknownChannelError); ok {
+		return nethttp.StatusNotFound
+	} else if errors.Is(err, ErrOverloaded) {
+		r.logger.Debug("Channel overloaded", zap.Error(err))
+		return nethttp.StatusTooManyRequests
+	}
+	r.logger.Info("Error in receiver", zap.Error(err))
+	return nethttp.StatusInternalServerError
 }
 
 func ReportEventCountMetricsForDispatchError(err error, reporter StatsReporter, args *ReportArgs) {
@@ -19,6 +19,7 @@ package channel
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	nethttp "net/http"
@@ -73,6 +74,12 @@ func TestMessageReceiver_ServeHTTP(t *testing.T) {
 			},
 			expected: nethttp.StatusNotFound,
 		},
+		"overloaded channel error": {
+			receiverFunc: func(_ context.Context, _ ChannelReference, _ binding.Message, _ []binding.Transformer, _ nethttp.Header) error {
+				return fmt.Errorf("queue full: %w", ErrOverloaded)
+			},
+			expected: nethttp.StatusTooManyRequests,
+		},
 		"other receiver function error": {
 			receiverFunc: func(_ context.Context, _ ChannelReference, _ binding.Message, _ []binding.Transformer, _ nethttp.Header) error {
 				return errors.New("test induced receiver function error")
@@ -322,3 +329,95 @@ func TestMessageReceiver_UnknownHost(t *testing.T) {
 		t.Fatal("Unexpected status code. Expected 404. Actual", res.Code)
 	}
 }
+
+func TestMessageReceiver_Batch(t *testing.T) {
+	host := "http://test-channel.test-namespace.svc." + network.GetClusterDomainName() + "/"
+	reporter := NewStatsReporter("testcontainer", "testpod")
+
+	var received []string
+	f := func(ctx context.Context, _ ChannelReference, m binding.Message, _ []binding.Transformer, _ nethttp.Header) error {
+		event, err := binding.ToEvent(ctx, m)
+		if err != nil {
+			return err
+		}
+		received = append(received, event.ID())
+		if event.ID() == "overloaded" {
+			return ErrOverloaded
+		}
+		return nil
+	}
+	r, err := NewMessageReceiver(f, zaptest.NewLogger(t, zaptest.WrapOptions(zap.AddCaller())), reporter)
+	if err != nil {
+		t.Fatalf("Error creating new event receiver. Error:%s", err)
+	}
+
+	makeBatch := func(ids ...string) []byte {
+		events := make([]interface{}, 0, len(ids))
+		for _, id := range ids {
+			if id == "" {
+				events = append(events, map[string]string{"specversion": "1.0"})
+				continue
+			}
+			event := test.MinEvent()
+			event.SetID(id)
+			events = append(events, event)
+		}
+		body, err := json.Marshal(events)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return body
+	}
+
+	testCases := map[string]struct {
+		body         []byte
+		wantCode     int
+		wantReceived []string
+		wantResults  []kncloudevents.BatchResult
+	}{
+		"all accepted": {
+			body:         makeBatch("1", "2"),
+			wantCode:     nethttp.StatusAccepted,
+			wantReceived: []string{"1", "2"},
+			wantResults: []kncloudevents.BatchResult{
+				{Index: 0, ID: "1", Status: nethttp.StatusAccepted},
+				{Index: 1, ID: "2", Status: nethttp.StatusAccepted},
+			},
+		},
+		"per-event failures": {
+			body:         makeBatch("1", "", "overloaded"),
+			wantCode:     nethttp.StatusMultiStatus,
+			wantReceived: []string{"1", "overloaded"},
+			wantResults: []kncloudevents.BatchResult{
+				{Index: 0, ID: "1", Status: nethttp.StatusAccepted},
+				{Index: 1, Status: nethttp.StatusBadRequest},
+				{Index: 2, ID: "overloaded", Status: nethttp.StatusTooManyRequests},
+			},
+		},
+		"not a batch": {
+			body:     []byte(`{"specversion": "1.0"}`),
+			wantCode: nethttp.StatusBadRequest,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			received = nil
+			req := httptest.NewRequest(nethttp.MethodPost, host, bytes.NewReader(tc.body))
+			req.Header.Set("content-type", cloudevents.ApplicationCloudEventsBatchJSON)
+			res := httptest.NewRecorder()
+
+			r.ServeHTTP(res, req)
+			require.Equal(t, tc.wantCode, res.Code)
+			require.Equal(t, tc.wantReceived, received)
+			if tc.wantResults == nil {
+				return
+			}
+			var response kncloudevents.BatchResponse
+			require.NoError(t, json.NewDecoder(res.Body).Decode(&response))
+			for i := range response.Results {
+				response.Results[i].Error = ""
+			}
+			require.Equal(t, tc.wantResults, response.Results)
+		})
+	}
+}
@@ -27,9 +27,22 @@ const (
 
 	// LabelContainerName is the label for the immutable name of the container.
 	LabelContainerName = metrics.LabelContainerName
+
+	// LabelCircuitState is the label for the state a circuit breaker transitioned to.
+	LabelCircuitState = "circuit_state"
+
+	// LabelSubscription is the label for the subscription owning a queue.
+	LabelSubscription = "subscription"
+
+	// LabelDestination is the label for the URL of the destination of a limiter or a circuit
+	// breaker.
+	LabelDestination = "destination"
 )
 
 var (
-	ContainerTagKey = tag.MustNewKey(LabelContainerName)
-	UniqueTagKey    = tag.MustNewKey(LabelUniqueName)
+	ContainerTagKey    = tag.MustNewKey(LabelContainerName)
+	UniqueTagKey       = tag.MustNewKey(LabelUniqueName)
+	CircuitStateTagKey = tag.MustNewKey(LabelCircuitState)
+	SubscriptionTagKey = tag.MustNewKey(LabelSubscription)
+	DestinationTagKey  = tag.MustNewKey(LabelDestination)
 )
@@ -26,6 +26,7 @@ import (
 	"go.opencensus.io/stats/view"
 	"go.opencensus.io/tag"
 
+	"knative.dev/eventing/pkg/kncloudevents"
 	eventingmetrics "knative.dev/eventing/pkg/metrics"
 	"knative.dev/pkg/metrics"
 )
@@ -47,6 +48,43 @@ var (
 		stats.UnitMilliseconds,
 	)
 
+	// circuitBreakerTransitionCountM is a counter which records the state transitions of the
+	// circuit breakers of the destinations of the channel.
+	circuitBreakerTransitionCountM = stats.Int64(
+		"circuit_breaker_transition_count",
+		"Number of state transitions of the circuit breakers of the channel destinations",
+		stats.UnitDimensionless,
+	)
+
+	// queueDepthM records the number of events waiting in the queue of a subscription.
+	queueDepthM = stats.Int64(
+		"queue_depth",
+		"Number of events waiting in the queue of a subscription",
+		stats.UnitDimensionless,
+	)
+
+	// limiterInFlightM records the number of events being sent to a destination with a limiter.
+	limiterInFlightM = stats.Int64(
+		"limiter_in_flight",
+		"Number of events being sent to a destination with a limiter",
+		stats.UnitDimensionless,
+	)
+
+	// limiterWaitingM records the number of events waiting for the limiter of a destination.
+	limiterWaitingM = stats.Int64(
+		"limiter_waiting",
+		"Number of events waiting for the limiter of a destination",
+		stats.UnitDimensionless,
+	)
+
+	// limiterRejectionCountM is a counter which records the events rejected by the limiter of
+	// a destination.
+	limiterRejectionCountM = stats.Int64(
+		"limiter_rejection_count",
+		"Number of events rejected by the limiter of a destination",
+		stats.UnitDimensionless,
+	)
+
 	// Create the tag keys that will be used to add tags to our measurements.
 	// Tag keys must conform to the restrictions described in
 	// go.opencensus.io/tag/validate.go. Currently those restrictions are:
@@ -70,6 +108,10 @@ func init() {
 type StatsReporter interface {
 	ReportEventCount(args *ReportArgs, responseCode int) error
 	ReportEventDispatchTime(args *ReportArgs, responseCode int, d time.Duration) error
+	ReportCircuitBreakerStateChange(destination string, from, to kncloudevents.CircuitState) error
+	ReportQueueDepth(args *ReportArgs, subscription string, depth int) error
+	ReportLimiterState(destination string, state kncloudevents.LimiterState) error
+	ReportLimiterRejection(destination string) error
 }
 
 var _ StatsReporter = (*reporter)(nil)
@@ -113,6 +155,36 @@ func register() {
 			Aggregation: view.Distribution(metrics.Buckets125(1, 10000)...), // 1, 2, 5, 10, 20, 50, 100, 500, 1000, 5000, 10000
 			TagKeys:     tagKeys,
 		},
+		&view.View{
+			Description: circuitBreakerTransitionCountM.Description(),
+			Measure:     circuitBreakerTransitionCountM,
+			Aggregation: view.Count(),
+			TagKeys:     []tag.Key{DestinationTagKey, CircuitStateTagKey, UniqueTagKey, ContainerTagKey},
+		},
+		&view.View{
+			Description: queueDepthM.Description(),
+			Measure:     queueDepthM,
+			Aggregation: view.LastValue(),
+			TagKeys:     []tag.Key{namespaceKey, SubscriptionTagKey, UniqueTagKey, ContainerTagKey},
+		},
+		&view.View{
+			Description: limiterInFlightM.Description(),
+			Measure:     limiterInFlightM,
+			Aggregation: view.LastValue(),
+			TagKeys:     []tag.Key{DestinationTagKey, UniqueTagKey, ContainerTagKey},
+		},
+		&view.View{
+			Description: limiterWaitingM.Description(),
+			Measure:     limiterWaitingM,
+			Aggregation: view.LastValue(),
+			TagKeys:     []tag.Key{DestinationTagKey, UniqueTagKey, ContainerTagKey},
+		},
+		&view.View{
+			Description: limiterRejectionCountM.Description(),
+			Measure:     limiterRejectionCountM,
+			Aggregation: view.Count(),
+			TagKeys:     []tag.Key{DestinationTagKey, UniqueTagKey, ContainerTagKey},
+		},
 	)
 	if err != nil {
 		log.Print("failed to register opencensus views, " + err.Error())
@@ -140,6 +212,64 @@ func (r *reporter) ReportEventDispatchTime(args *ReportArgs, responseCode int, d
 	return nil
 }
 
+// ReportCircuitBreakerStateChange captures the state transitions of the circuit breaker of a destination.
+func (r *reporter) ReportCircuitBreakerStateChange(destination string, _, to kncloudevents.CircuitState) error {
+	ctx, err := tag.New(
+		emptyContext,
+		tag.Insert(DestinationTagKey, destination),
+		tag.Insert(CircuitStateTagKey, to.String()),
+		tag.Insert(ContainerTagKey, r.container),
+		tag.Insert(UniqueTagKey, r.uniqueName))
+	if err != nil {
+		return err
+	}
+	metrics.Record(ctx, circuitBreakerTransitionCountM.M(1))
+	return nil
+}
+
+// ReportQueueDepth captures the number of events waiting in the queue of a subscription.
+func (r *reporter) ReportQueueDepth(args *ReportArgs, subscription string, depth int) error {
+	ctx, err := tag.New(
+		emptyContext,
+		tag.Insert(namespaceKey, args.Ns),
+		tag.Insert(SubscriptionTagKey, subscription),
+		tag.Insert(ContainerTagKey, r.container),
+		tag.Insert(UniqueTagKey, r.uniqueName))
+	if err != nil {
+		return err
+	}
+	metrics.Record(ctx, queueDepthM.M(int64(depth)))
+	return nil
+}
+
+// ReportLimiterState captures the number of events in flight and waiting for the limiter of a destination.
+func (r *reporter) ReportLimiterState(destination string, state kncloudevents.LimiterState) error {
+	ctx, err := r.generateDestinationTag(destination)
+	if err != nil {
+		return err
+	}
+	metrics.RecordBatch(ctx, limiterInFlightM.M(int64(state.InFlight)), limiterWaitingM.M(int64(state.Waiting)))
+	return nil
+}
+
+// ReportLimiterRejection captures an event rejected by the limiter of a destination.
+func (r *reporter) ReportLimiterRejection(destination string) error {
+	ctx, err := r.generateDestinationTag(destination)
+	if err != nil {
+		return err
+	}
+	metrics.Record(ctx, limiterRejectionCountM.M(1))
+	return nil
+}
+
+func (r *reporter) generateDestinationTag(destination string) (context.Context, error) {
+	return tag.New(
+		emptyContext,
+		tag.Insert(DestinationTagKey, destination),
+		tag.Insert(ContainerTagKey, r.container),
+		tag.Insert(UniqueTagKey, r.uniqueName))
+}
+
 func (r *reporter) generateTag(args *ReportArgs, responseCode int) (context.Context, error) {
 	return tag.New(
 		emptyContext,
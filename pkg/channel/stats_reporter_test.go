@@ -21,6 +21,7 @@ import (
 	"testing"
 	"time"
 
+	"knative.dev/eventing/pkg/kncloudevents"
 	"knative.dev/eventing/pkg/metrics"
 	"knative.dev/pkg/metrics/metricstest"
 	_ "knative.dev/pkg/metrics/testing"
@@ -61,6 +62,47 @@ func TestStatsReporter(t *testing.T) {
 		return r.ReportEventDispatchTime(args, http.StatusAccepted, 9100*time.Millisecond)
 	})
 	metricstest.CheckDistributionData(t, "event_dispatch_latencies", wantTags, 2, 1100.0, 9100.0)
+
+	// test ReportCircuitBreakerStateChange
+	expectSuccess(t, func() error {
+		return r.ReportCircuitBreakerStateChange("http://subscriber.example.com/", kncloudevents.CircuitClosed, kncloudevents.CircuitOpen)
+	})
+	metricstest.CheckCountData(t, "circuit_breaker_transition_count", map[string]string{
+		LabelDestination:   "http://subscriber.example.com/",
+		LabelCircuitState:  "open",
+		LabelUniqueName:    "testpod",
+		LabelContainerName: "testcontainer",
+	}, 1)
+
+	// test ReportQueueDepth
+	expectSuccess(t, func() error {
+		return r.ReportQueueDepth(args, "sub-uid", 3)
+	})
+	expectSuccess(t, func() error {
+		return r.ReportQueueDepth(args, "sub-uid", 2)
+	})
+	metricstest.CheckLastValueData(t, "queue_depth", map[string]string{
+		metrics.LabelNamespaceName: "testns",
+		LabelSubscription:          "sub-uid",
+		LabelUniqueName:            "testpod",
+		LabelContainerName:         "testcontainer",
+	}, 2)
+
+	// test ReportLimiterState and ReportLimiterRejection
+	limiterTags := map[string]string{
+		LabelDestination:   "http://subscriber.example.com/",
+		LabelUniqueName:    "testpod",
+		LabelContainerName: "testcontainer",
+	}
+	expectSuccess(t, func() error {
+		return r.ReportLimiterState("http://subscriber.example.com/", kncloudevents.LimiterState{InFlight: 2, Waiting: 1})
+	})
+	metricstest.CheckLastValueData(t, "limiter_in_flight", limiterTags, 2)
+	metricstest.CheckLastValueData(t, "limiter_waiting", limiterTags, 1)
+	expectSuccess(t, func() error {
+		return r.ReportLimiterRejection("http://subscriber.example.com/")
+	})
+	metricstest.CheckCountData(t, "limiter_rejection_count", limiterTags, 1)
 }
 
 func expectSuccess(t *testing.T, f func() error) {
@@ -78,6 +120,11 @@ func resetMetrics() {
 	// OpenCensus metrics carry global state that need to be reset between unit tests.
 	metricstest.Unregister(
 		"event_count",
-		"event_dispatch_latencies")
+		"event_dispatch_latencies",
+		"circuit_breaker_transition_count",
+		"queue_depth",
+		"limiter_in_flight",
+		"limiter_waiting",
+		"limiter_rejection_count")
 	register()
 }
@@ -0,0 +1,500 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package wal provides a write-ahead log of records stored in rotated segment files, with
+// named cursors tracking which records each consumer acknowledged.
+//
+// Each record gets an offset, incremented by one for every appended record. A segment file is
+// named after the offset of its first record and holds the records one after the other, each
+// prefixed by its length and its CRC-32C checksum. A segment is removed once all the cursors
+// moved past its records.
+package wal
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultSegmentSize is the default size in bytes after which the active segment is rotated.
+	DefaultSegmentSize = 64 << 20
+
+	// DefaultCursorSaveInterval is the default minimum time between two saves of the cursors
+	// moved by the acknowledgements.
+	DefaultCursorSaveInterval = time.Second
+
+	segmentSuffix    = ".wal"
+	cursorsFile      = "cursors.json"
+	recordHeaderSize = 8
+)
+
+var (
+	// ErrClosed is returned when appending to a closed log.
+	ErrClosed = errors.New("the log is closed")
+
+	crcTable = crc32.MakeTable(crc32.Castagnoli)
+)
+
+// Options configures a Log.
+type Options struct {
+	// SegmentSize is the size in bytes after which the active segment is rotated.
+	// Defaults to DefaultSegmentSize.
+	SegmentSize int64
+	// CursorSaveInterval is the minimum time between two saves of the cursors moved by the
+	// acknowledgements. Defaults to DefaultCursorSaveInterval.
+	CursorSaveInterval time.Duration
+}
+
+// Log is a write-ahead log stored in a directory. It is safe for concurrent use.
+type Log struct {
+	dir                string
+	segmentSize        int64
+	cursorSaveInterval time.Duration
+
+	mutex sync.Mutex
+	// segments are sorted by offset, the last one is the active segment.
+	segments   []segment
+	active     *os.File
+	activeSize int64
+	next       uint64
+	cursors    map[string]*cursor
+	// savedAt is the last time the cursors were saved, dirty tells whether they moved since.
+	savedAt time.Time
+	dirty   bool
+	closed  bool
+}
+
+type segment struct {
+	first uint64
+	path  string
+}
+
+type cursor struct {
+	// committed is the offset of the first record which isn't acknowledged.
+	committed uint64
+	// acked holds the acknowledged offsets after committed.
+	acked map[uint64]struct{}
+}
+
+// Open opens the log stored in dir, creating it if it doesn't exist. A record partially
+// written at the end of the active segment, e.g. because the process crashed, is discarded.
+func Open(dir string, opts Options) (*Log, error) {
+	if opts.SegmentSize <= 0 {
+		opts.SegmentSize = DefaultSegmentSize
+	}
+	if opts.CursorSaveInterval <= 0 {
+		opts.CursorSaveInterval = DefaultCursorSaveInterval
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create the log directory: %w", err)
+	}
+	l := &Log{
+		dir:                dir,
+		segmentSize:        opts.SegmentSize,
+		cursorSaveInterval: opts.CursorSaveInterval,
+		cursors:            make(map[string]*cursor),
+	}
+
+	var err error
+	if l.segments, err = listSegments(dir); err != nil {
+		return nil, err
+	}
+	if len(l.segments) == 0 {
+		if err := l.createSegment(0); err != nil {
+			return nil, err
+		}
+	} else if err := l.recoverActiveSegment(); err != nil {
+		return nil, err
+	}
+
+	if err := l.loadCursors(); err != nil {
+		_ = l.active.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+func listSegments(dir string) ([]segment, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the segments: %w", err)
+	}
+	var segments []segment
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), segmentSuffix) {
+			continue
+		}
+		first, err := strconv.ParseUint(strings.TrimSuffix(f.Name(), segmentSuffix), 10, 64)
+		if err != nil {
+			continue
+		}
+		segments = append(segments, segment{first: first, path: filepath.Join(dir, f.Name())})
+	}
+	sort.Slice(segments, func(i, j int) bool {
+		return segments[i].first < segments[j].first
+	})
+	return segments, nil
+}
+
+func segmentPath(dir string, first uint64) string {
+	return filepath.Join(dir, fmt.Sprintf("%020d%s", first, segmentSuffix))
+}
+
+// createSegment creates a new active segment starting at first.
+func (l *Log) createSegment(first uint64) error {
+	path := segmentPath(l.dir, first)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to create the segment: %w", err)
+	}
+	l.segments = append(l.segments, segment{first: first, path: path})
+	l.active = f
+	l.activeSize = 0
+	l.next = first
+	return nil
+}
+
+// recoverActiveSegment counts the records of the last segment and truncates it after its
+// last valid record.
+func (l *Log) recoverActiveSegment() error {
+	last := l.segments[len(l.segments)-1]
+	f, err := os.OpenFile(last.path, os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open the active segment: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to open the active segment: %w", err)
+	}
+
+	r := bufio.NewReader(f)
+	var size int64
+	var count uint64
+	for {
+		data, err := readRecord(r, info.Size()-size)
+		if err != nil {
+			break
+		}
+		size += recordHeaderSize + int64(len(data))
+		count++
+	}
+	if size < info.Size() {
+		if err := f.Truncate(size); err != nil {
+			_ = f.Close()
+			return fmt.Errorf("failed to truncate the active segment: %w", err)
+		}
+	}
+
+	l.active = f
+	l.activeSize = size
+	l.next = last.first + count
+	return nil
+}
+
+// readRecord reads the next record, which can't be larger than limit bytes.
+func readRecord(r io.Reader, limit int64) ([]byte, error) {
+	var header [recordHeaderSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+	length := binary.BigEndian.Uint32(header[0:4])
+	if int64(length) > limit-recordHeaderSize {
+		return nil, io.ErrUnexpectedEOF
+	}
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	if crc32.Checksum(data, crcTable) != binary.BigEndian.Uint32(header[4:8]) {
+		return nil, errors.New("invalid record checksum")
+	}
+	return data, nil
+}
+
+// Append appends a record to the log, returning its offset once it's synced to the disk, along
+// with the names of the cursors which must acknowledge it.
+func (l *Log) Append(data []byte) (uint64, []string, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.closed {
+		return 0, nil, ErrClosed
+	}
+
+	if l.activeSize >= l.segmentSize {
+		if err := l.rotate(); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	record := make([]byte, recordHeaderSize+len(data))
+	binary.BigEndian.PutUint32(record[0:4], uint32(len(data)))
+	binary.BigEndian.PutUint32(record[4:8], crc32.Checksum(data, crcTable))
+	copy(record[recordHeaderSize:], data)
+	if _, err := l.active.Write(record); err != nil {
+		// Don't leave a partial record behind.
+		_ = l.active.Truncate(l.activeSize)
+		return 0, nil, fmt.Errorf("failed to write the record: %w", err)
+	}
+	if err := l.active.Sync(); err != nil {
+		_ = l.active.Truncate(l.activeSize)
+		return 0, nil, fmt.Errorf("failed to sync the record: %w", err)
+	}
+	l.activeSize += int64(len(record))
+
+	offset := l.next
+	l.next++
+	names := make([]string, 0, len(l.cursors))
+	for name := range l.cursors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return offset, names, nil
+}
+
+func (l *Log) rotate() error {
+	if err := l.active.Close(); err != nil {
+		return fmt.Errorf("failed to close the active segment: %w", err)
+	}
+	return l.createSegment(l.next)
+}
+
+// NextOffset returns the offset of the next appended record.
+func (l *Log) NextOffset() uint64 {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.next
+}
+
+// Read calls fn with the records from the offset from, included, to the offset to, excluded,
+// stopping at the first error returned by fn. The records which were removed are skipped.
+func (l *Log) Read(from, to uint64, fn func(offset uint64, data []byte) error) error {
+	l.mutex.Lock()
+	segments := make([]segment, len(l.segments))
+	copy(segments, l.segments)
+	if to > l.next {
+		to = l.next
+	}
+	l.mutex.Unlock()
+
+	for i, s := range segments {
+		if s.first >= to {
+			break
+		}
+		if i+1 < len(segments) && segments[i+1].first <= from {
+			continue
+		}
+		if err := readSegment(s, from, to, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readSegment(s segment, from, to uint64, fn func(offset uint64, data []byte) error) error {
+	f, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		// All the cursors moved past the segment since we listed it.
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open the segment: %w", err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to open the segment: %w", err)
+	}
+
+	r := bufio.NewReader(f)
+	var read int64
+	for offset := s.first; offset < to; offset++ {
+		data, err := readRecord(r, info.Size()-read)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read the record %d: %w", offset, err)
+		}
+		read += recordHeaderSize + int64(len(data))
+		if offset < from {
+			continue
+		}
+		if err := fn(offset, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Cursor returns the offset of the first record which isn't acknowledged by the cursor name.
+func (l *Log) Cursor(name string) (uint64, bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	c, ok := l.cursors[name]
+	if !ok {
+		return 0, false
+	}
+	return c.committed, true
+}
+
+// SetCursors sets the cursors of the log to names. The new cursors start at the next appended
+// record, the existing ones are kept and the others are removed.
+func (l *Log) SetCursors(names []string) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	cursors := make(map[string]*cursor, len(names))
+	for _, name := range names {
+		if c, ok := l.cursors[name]; ok {
+			cursors[name] = c
+		} else {
+			cursors[name] = &cursor{committed: l.next, acked: make(map[uint64]struct{})}
+		}
+	}
+	l.cursors = cursors
+	l.dirty = true
+	return l.commit(true)
+}
+
+// Ack acknowledges the record at offset for the cursor name. The records can be acknowledged
+// in any order: the cursor moves past a record once it and all the records before it are
+// acknowledged. Acknowledging a record for an unknown cursor is a no-op.
+func (l *Log) Ack(name string, offset uint64) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	c, ok := l.cursors[name]
+	if !ok || offset < c.committed || offset >= l.next {
+		return nil
+	}
+	c.acked[offset] = struct{}{}
+	if offset != c.committed {
+		return nil
+	}
+	for {
+		if _, ok := c.acked[c.committed]; !ok {
+			break
+		}
+		delete(c.acked, c.committed)
+		c.committed++
+	}
+	l.dirty = true
+	return l.commit(false)
+}
+
+// commit saves the cursors, unless they were saved less than the cursor save interval ago and
+// force is false, and removes the segments all the cursors moved past.
+func (l *Log) commit(force bool) error {
+	if l.closed {
+		return ErrClosed
+	}
+	if force || time.Since(l.savedAt) >= l.cursorSaveInterval {
+		if err := l.saveCursors(); err != nil {
+			return err
+		}
+	}
+
+	min := l.next
+	for _, c := range l.cursors {
+		if c.committed < min {
+			min = c.committed
+		}
+	}
+	for len(l.segments) > 1 && l.segments[1].first <= min {
+		if err := os.Remove(l.segments[0].path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove the segment: %w", err)
+		}
+		l.segments = l.segments[1:]
+	}
+	return nil
+}
+
+// saveCursors atomically replaces the cursors file when the cursors moved. It isn't synced to
+// the disk: losing the last cursor updates only means that some records are delivered again.
+func (l *Log) saveCursors() error {
+	if !l.dirty {
+		return nil
+	}
+	committed := make(map[string]uint64, len(l.cursors))
+	for name, c := range l.cursors {
+		committed[name] = c.committed
+	}
+	data, err := json.Marshal(committed)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(l.dir, cursorsFile)
+	if err := ioutil.WriteFile(path+".tmp", data, 0o644); err != nil {
+		return fmt.Errorf("failed to save the cursors: %w", err)
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return fmt.Errorf("failed to save the cursors: %w", err)
+	}
+	l.savedAt = time.Now()
+	l.dirty = false
+	return nil
+}
+
+func (l *Log) loadCursors() error {
+	data, err := ioutil.ReadFile(filepath.Join(l.dir, cursorsFile))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load the cursors: %w", err)
+	}
+	var committed map[string]uint64
+	if err := json.Unmarshal(data, &committed); err != nil {
+		return fmt.Errorf("failed to load the cursors: %w", err)
+	}
+	for name, offset := range committed {
+		if first := l.segments[0].first; offset < first {
+			offset = first
+		}
+		if offset > l.next {
+			offset = l.next
+		}
+		l.cursors[name] = &cursor{committed: offset, acked: make(map[uint64]struct{})}
+	}
+	return nil
+}
+
+// Close saves the cursors and closes the log.
+func (l *Log) Close() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.closed {
+		return nil
+	}
+	err := l.saveCursors()
+	l.closed = true
+	if cerr := l.active.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
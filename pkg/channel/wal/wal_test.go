@@ -0,0 +1,204 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wal
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+)
+
+func readAll(t *testing.T, l *Log, from uint64) map[uint64]string {
+	t.Helper()
+	got := make(map[uint64]string)
+	err := l.Read(from, l.NextOffset(), func(offset uint64, data []byte) error {
+		got[offset] = string(data)
+		return nil
+	})
+	require.NoError(t, err)
+	return got
+}
+
+func countSegments(t *testing.T, dir string) int {
+	t.Helper()
+	segments, err := filepath.Glob(filepath.Join(dir, "*"+segmentSuffix))
+	require.NoError(t, err)
+	return len(segments)
+}
+
+func TestLogAppendAndRead(t *testing.T) {
+	dir := t.TempDir()
+	// Rotate after every other record.
+	l, err := Open(dir, Options{SegmentSize: 2 * (recordHeaderSize + 7)})
+	require.NoError(t, err)
+
+	want := make(map[uint64]string)
+	for i := 0; i < 5; i++ {
+		data := fmt.Sprintf("event-%d", i)
+		offset, _, err := l.Append([]byte(data))
+		require.NoError(t, err)
+		require.Equal(t, uint64(i), offset)
+		want[offset] = data
+	}
+	require.Equal(t, 3, countSegments(t, dir))
+
+	if diff := cmp.Diff(want, readAll(t, l, 0)); diff != "" {
+		t.Error("Unexpected records (-want, +got) =", diff)
+	}
+	if diff := cmp.Diff(map[uint64]string{3: "event-3", 4: "event-4"}, readAll(t, l, 3)); diff != "" {
+		t.Error("Unexpected records from 3 (-want, +got) =", diff)
+	}
+
+	// The records are kept when the log is reopened.
+	require.NoError(t, l.Close())
+	_, _, err = l.Append([]byte("closed"))
+	require.Equal(t, ErrClosed, err)
+
+	l, err = Open(dir, Options{SegmentSize: 2 * (recordHeaderSize + 7)})
+	require.NoError(t, err)
+	defer l.Close()
+	require.Equal(t, uint64(5), l.NextOffset())
+	if diff := cmp.Diff(want, readAll(t, l, 0)); diff != "" {
+		t.Error("Unexpected records after reopening (-want, +got) =", diff)
+	}
+}
+
+func TestLogRecoverPartialRecord(t *testing.T) {
+	dir := t.TempDir()
+	l, err := Open(dir, Options{})
+	require.NoError(t, err)
+	_, _, err = l.Append([]byte("complete"))
+	require.NoError(t, err)
+	require.NoError(t, l.Close())
+
+	// Simulate a crash in the middle of writing a record.
+	f, err := os.OpenFile(segmentPath(dir, 0), os.O_WRONLY|os.O_APPEND, 0o644)
+	require.NoError(t, err)
+	_, err = f.Write([]byte{0, 0, 0, 42, 1, 2})
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	l, err = Open(dir, Options{})
+	require.NoError(t, err)
+	defer l.Close()
+	require.Equal(t, uint64(1), l.NextOffset())
+	offset, _, err := l.Append([]byte("next"))
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), offset)
+	if diff := cmp.Diff(map[uint64]string{0: "complete", 1: "next"}, readAll(t, l, 0)); diff != "" {
+		t.Error("Unexpected records (-want, +got) =", diff)
+	}
+}
+
+func TestLogCursors(t *testing.T) {
+	dir := t.TempDir()
+	opts := Options{SegmentSize: recordHeaderSize + 1}
+	l, err := Open(dir, opts)
+	require.NoError(t, err)
+
+	_, _, err = l.Append([]byte("0"))
+	require.NoError(t, err)
+	require.NoError(t, l.SetCursors([]string{"b", "a"}))
+	for i := 1; i < 5; i++ {
+		// The records are acknowledged by the cursors existing when they're appended.
+		_, cursors, err := l.Append([]byte(fmt.Sprint(i)))
+		require.NoError(t, err)
+		require.Equal(t, []string{"a", "b"}, cursors)
+	}
+
+	// The cursors start at the next appended record.
+	offset, ok := l.Cursor("a")
+	require.True(t, ok)
+	require.Equal(t, uint64(1), offset)
+	_, ok = l.Cursor("c")
+	require.False(t, ok)
+
+	// Acknowledging out of order only moves the cursor past contiguous records.
+	require.NoError(t, l.Ack("a", 2))
+	offset, _ = l.Cursor("a")
+	require.Equal(t, uint64(1), offset)
+	require.NoError(t, l.Ack("a", 1))
+	offset, _ = l.Cursor("a")
+	require.Equal(t, uint64(3), offset)
+	require.NoError(t, l.Ack("unknown", 1))
+
+	// The segments are kept until every cursor moved past them, the first one wasn't needed
+	// by any cursor.
+	require.Equal(t, 4, countSegments(t, dir))
+	require.NoError(t, l.Ack("b", 1))
+	require.NoError(t, l.Ack("b", 2))
+	require.Equal(t, 2, countSegments(t, dir))
+	if diff := cmp.Diff(map[uint64]string{3: "3", 4: "4"}, readAll(t, l, 0)); diff != "" {
+		t.Error("Unexpected records (-want, +got) =", diff)
+	}
+
+	// Removing the slowest cursor releases its segments.
+	require.NoError(t, l.Ack("b", 3))
+	require.NoError(t, l.SetCursors([]string{"b"}))
+	require.Equal(t, 1, countSegments(t, dir))
+
+	// The cursors are kept when the log is reopened, the acknowledgements after a gap aren't.
+	_, _, err = l.Append([]byte("5"))
+	require.NoError(t, err)
+	require.NoError(t, l.Ack("b", 5))
+	require.NoError(t, l.Close())
+	l, err = Open(dir, opts)
+	require.NoError(t, err)
+	defer l.Close()
+	offset, ok = l.Cursor("b")
+	require.True(t, ok)
+	require.Equal(t, uint64(4), offset)
+	_, ok = l.Cursor("a")
+	require.False(t, ok)
+}
+
+func TestLogCursorSaveInterval(t *testing.T) {
+	dir := t.TempDir()
+	opts := Options{CursorSaveInterval: time.Hour}
+	l, err := Open(dir, opts)
+	require.NoError(t, err)
+
+	require.NoError(t, l.SetCursors([]string{"a"}))
+	saved, err := ioutil.ReadFile(filepath.Join(dir, cursorsFile))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"a": 0}`, string(saved))
+
+	// The acknowledgements don't save the cursors until the interval elapsed.
+	for i := 0; i < 3; i++ {
+		offset, _, err := l.Append([]byte(fmt.Sprint(i)))
+		require.NoError(t, err)
+		require.NoError(t, l.Ack("a", offset))
+	}
+	saved, err = ioutil.ReadFile(filepath.Join(dir, cursorsFile))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"a": 0}`, string(saved))
+
+	// They're saved when the log is closed.
+	require.NoError(t, l.Close())
+	l, err = Open(dir, opts)
+	require.NoError(t, err)
+	defer l.Close()
+	offset, ok := l.Cursor("a")
+	require.True(t, ok)
+	require.Equal(t, uint64(3), offset)
+}
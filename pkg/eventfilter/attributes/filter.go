@@ -36,7 +36,7 @@ func NewAttributesFilter(attrs map[string]string) eventfilter.Filter {
 
 func (attrs attributesFilter) Filter(ctx context.Context, event cloudevents.Event) eventfilter.FilterResult {
 	for k, v := range attrs {
-		value, ok := lookup(event, k)
+		value, ok := LookupAttribute(event, k)
 		// If the attribute does not exist in the event (extension context attributes) or if the event attribute
 		// has an empty string value (optional attributes) - which means it was never set in the incoming event,
 		// return false.
@@ -53,7 +53,9 @@ func (attrs attributesFilter) Filter(ctx context.Context, event cloudevents.Even
 	return eventfilter.PassFilter
 }
 
-func lookup(event cloudevents.Event, attr string) (interface{}, bool) {
+// LookupAttribute returns the value of the provided context attribute or extension, and
+// whether the event has it.
+func LookupAttribute(event cloudevents.Event, attr string) (interface{}, bool) {
 	// Set standard context attributes. The attributes available may not be
 	// exactly the same as the attributes defined in the current version of the
 	// CloudEvents spec.
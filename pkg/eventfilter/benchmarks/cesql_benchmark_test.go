@@ -0,0 +1,62 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package benchmarks
+
+import (
+	"fmt"
+	"testing"
+
+	cetest "github.com/cloudevents/sdk-go/v2/test"
+
+	"knative.dev/eventing/pkg/eventfilter"
+	"knative.dev/eventing/pkg/eventfilter/cesql"
+)
+
+func BenchmarkCESQLFilter(b *testing.B) {
+	event := cetest.FullEvent()
+
+	RunFilterBenchmarks(b,
+		func(i interface{}) eventfilter.Filter {
+			f, err := cesql.NewCESQLFilter(i.(string))
+			if err != nil {
+				b.Fatal(err)
+			}
+			return f
+		},
+		FilterBenchmark{
+			name:  "Pass with exact match of id",
+			arg:   fmt.Sprintf("id = '%s'", event.ID()),
+			event: event,
+		},
+		FilterBenchmark{
+			name: "Pass with exact match of all context attributes (except time)",
+			arg: fmt.Sprintf("id = '%s' AND source = '%s' AND type = '%s' AND dataschema = '%s' AND datacontenttype = '%s' AND subject = '%s'",
+				event.ID(), event.Source(), event.Type(), event.DataSchema(), event.DataContentType(), event.Subject()),
+			event: event,
+		},
+		FilterBenchmark{
+			name:  "Pass with LIKE on type and source",
+			arg:   "type LIKE '%' AND source LIKE '%'",
+			event: event,
+		},
+		FilterBenchmark{
+			name:  "No pass with exact match of id and source",
+			arg:   "id = 'qwertyuiopasdfghjklzxcvbnm' AND source = 'qwertyuiopasdfghjklzxcvbnm'",
+			event: event,
+		},
+	)
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package benchmarks
+
+import (
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	cetest "github.com/cloudevents/sdk-go/v2/test"
+	"k8s.io/utils/pointer"
+
+	"knative.dev/eventing/pkg/eventfilter"
+	"knative.dev/eventing/pkg/eventfilter/jsonpath"
+)
+
+func BenchmarkDataFilter(b *testing.B) {
+	event := cetest.FullEvent()
+	_ = event.SetData(cloudevents.ApplicationJSON, []byte(`{"order": {"id": 42, "region": "eu-west", "items": [{"sku": "a1", "qty": 2}, {"sku": "b2", "qty": 5}]}}`))
+
+	RunFilterBenchmarks(b,
+		func(i interface{}) eventfilter.Filter {
+			f, err := jsonpath.NewDataFilter(i.([]jsonpath.Predicate)...)
+			if err != nil {
+				b.Fatal(err)
+			}
+			return f
+		},
+		FilterBenchmark{
+			name:  "Pass with path exists",
+			arg:   []jsonpath.Predicate{{Path: ".order.region"}},
+			event: event,
+		},
+		FilterBenchmark{
+			name:  "Pass with exact match of a nested value",
+			arg:   []jsonpath.Predicate{{Path: ".order.region", Value: pointer.StringPtr("eu-west")}},
+			event: event,
+		},
+		FilterBenchmark{
+			name:  "Pass with wildcard match of an array element",
+			arg:   []jsonpath.Predicate{{Path: ".order.items[*].sku", Value: pointer.StringPtr("b2")}},
+			event: event,
+		},
+		FilterBenchmark{
+			name: "No pass with exact match of id and region",
+			arg: []jsonpath.Predicate{
+				{Path: ".order.id", Value: pointer.StringPtr("43")},
+				{Path: ".order.region", Value: pointer.StringPtr("eu-west")},
+			},
+			event: event,
+		},
+	)
+}
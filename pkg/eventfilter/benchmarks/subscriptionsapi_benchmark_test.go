@@ -0,0 +1,201 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package benchmarks
+
+import (
+	"testing"
+
+	cetest "github.com/cloudevents/sdk-go/v2/test"
+
+	"knative.dev/eventing/pkg/eventfilter"
+	"knative.dev/eventing/pkg/eventfilter/subscriptionsapi"
+)
+
+func BenchmarkExactFilter(b *testing.B) {
+	event := cetest.FullEvent()
+
+	RunFilterBenchmarks(b,
+		func(i interface{}) eventfilter.Filter {
+			return subscriptionsapi.NewExactFilter(i.(map[string]string))
+		},
+		FilterBenchmark{
+			name:  "Pass with exact match of id",
+			arg:   map[string]string{"id": event.ID()},
+			event: event,
+		},
+		FilterBenchmark{
+			name: "Pass with exact match of all context attributes (except time)",
+			arg: map[string]string{
+				"id":              event.ID(),
+				"source":          event.Source(),
+				"type":            event.Type(),
+				"dataschema":      event.DataSchema(),
+				"datacontenttype": event.DataContentType(),
+				"subject":         event.Subject(),
+			},
+			event: event,
+		},
+		FilterBenchmark{
+			name: "No pass with exact match of id and source",
+			arg: map[string]string{
+				"id":     "qwertyuiopasdfghjklzxcvbnm",
+				"source": "qwertyuiopasdfghjklzxcvbnm",
+			},
+			event: event,
+		},
+	)
+}
+
+func BenchmarkPrefixFilter(b *testing.B) {
+	event := cetest.FullEvent()
+
+	RunFilterBenchmarks(b,
+		func(i interface{}) eventfilter.Filter {
+			return subscriptionsapi.NewPrefixFilter(i.(map[string]string))
+		},
+		FilterBenchmark{
+			name:  "Pass with prefix match of id",
+			arg:   map[string]string{"id": event.ID()[0:5]},
+			event: event,
+		},
+		FilterBenchmark{
+			name: "Pass with prefix match of type and source",
+			arg: map[string]string{
+				"type":   event.Type()[0:5],
+				"source": event.Source()[0:5],
+			},
+			event: event,
+		},
+		FilterBenchmark{
+			name:  "No pass with prefix match of id",
+			arg:   map[string]string{"id": "qwertyuiopasdfghjklzxcvbnm"},
+			event: event,
+		},
+	)
+}
+
+func BenchmarkSuffixFilter(b *testing.B) {
+	event := cetest.FullEvent()
+
+	RunFilterBenchmarks(b,
+		func(i interface{}) eventfilter.Filter {
+			return subscriptionsapi.NewSuffixFilter(i.(map[string]string))
+		},
+		FilterBenchmark{
+			name:  "Pass with suffix match of id",
+			arg:   map[string]string{"id": event.ID()[len(event.ID())-3:]},
+			event: event,
+		},
+		FilterBenchmark{
+			name: "Pass with suffix match of type and source",
+			arg: map[string]string{
+				"type":   event.Type()[len(event.Type())-3:],
+				"source": event.Source()[len(event.Source())-3:],
+			},
+			event: event,
+		},
+		FilterBenchmark{
+			name:  "No pass with suffix match of id",
+			arg:   map[string]string{"id": "qwertyuiopasdfghjklzxcvbnm"},
+			event: event,
+		},
+	)
+}
+
+func BenchmarkAllFilter(b *testing.B) {
+	event := cetest.FullEvent()
+	exactPass := subscriptionsapi.NewExactFilter(map[string]string{"id": event.ID()})
+	exactFail := subscriptionsapi.NewExactFilter(map[string]string{"id": "qwertyuiopasdfghjklzxcvbnm"})
+	prefixPass := subscriptionsapi.NewPrefixFilter(map[string]string{"type": event.Type()[0:5]})
+
+	RunFilterBenchmarks(b,
+		func(i interface{}) eventfilter.Filter {
+			return subscriptionsapi.NewAllFilter(i.([]eventfilter.Filter)...)
+		},
+		FilterBenchmark{
+			name:  "Pass with exact and prefix",
+			arg:   []eventfilter.Filter{exactPass, prefixPass},
+			event: event,
+		},
+		FilterBenchmark{
+			name:  "No pass with first failing",
+			arg:   []eventfilter.Filter{exactFail, prefixPass},
+			event: event,
+		},
+		FilterBenchmark{
+			name:  "No pass with last failing",
+			arg:   []eventfilter.Filter{exactPass, prefixPass, exactFail},
+			event: event,
+		},
+	)
+}
+
+func BenchmarkAnyFilter(b *testing.B) {
+	event := cetest.FullEvent()
+	exactPass := subscriptionsapi.NewExactFilter(map[string]string{"id": event.ID()})
+	exactFail := subscriptionsapi.NewExactFilter(map[string]string{"id": "qwertyuiopasdfghjklzxcvbnm"})
+	prefixFail := subscriptionsapi.NewPrefixFilter(map[string]string{"type": "qwertyuiopasdfghjklzxcvbnm"})
+
+	RunFilterBenchmarks(b,
+		func(i interface{}) eventfilter.Filter {
+			return subscriptionsapi.NewAnyFilter(i.([]eventfilter.Filter)...)
+		},
+		FilterBenchmark{
+			name:  "Pass with first passing",
+			arg:   []eventfilter.Filter{exactPass, prefixFail},
+			event: event,
+		},
+		FilterBenchmark{
+			name:  "Pass with last passing",
+			arg:   []eventfilter.Filter{exactFail, prefixFail, exactPass},
+			event: event,
+		},
+		FilterBenchmark{
+			name:  "No pass with all failing",
+			arg:   []eventfilter.Filter{exactFail, prefixFail},
+			event: event,
+		},
+	)
+}
+
+func BenchmarkNotFilter(b *testing.B) {
+	event := cetest.FullEvent()
+
+	RunFilterBenchmarks(b,
+		func(i interface{}) eventfilter.Filter {
+			return subscriptionsapi.NewNotFilter(i.(eventfilter.Filter))
+		},
+		FilterBenchmark{
+			name:  "Pass with exact not matching",
+			arg:   subscriptionsapi.NewExactFilter(map[string]string{"id": "qwertyuiopasdfghjklzxcvbnm"}),
+			event: event,
+		},
+		FilterBenchmark{
+			name:  "No pass with exact matching",
+			arg:   subscriptionsapi.NewExactFilter(map[string]string{"id": event.ID()}),
+			event: event,
+		},
+		FilterBenchmark{
+			name: "Pass with nested any not matching",
+			arg: subscriptionsapi.NewAnyFilter(
+				subscriptionsapi.NewPrefixFilter(map[string]string{"type": "qwertyuiopasdfghjklzxcvbnm"}),
+				subscriptionsapi.NewSuffixFilter(map[string]string{"source": "qwertyuiopasdfghjklzxcvbnm"}),
+			),
+			event: event,
+		},
+	)
+}
@@ -0,0 +1,317 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cesql
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/types"
+)
+
+// Expression is a parsed CESQL expression.
+type Expression interface {
+	// Evaluate computes the expression against the provided event. The result is a string, an int32
+	// or a bool. When the evaluation fails, the error is returned together with the zero value of
+	// the expression type.
+	Evaluate(event cloudevents.Event) (interface{}, error)
+}
+
+type literalExpression struct {
+	value interface{}
+}
+
+func (e literalExpression) Evaluate(cloudevents.Event) (interface{}, error) {
+	return e.value, nil
+}
+
+type attributeExpression struct {
+	name string
+}
+
+func (e attributeExpression) Evaluate(event cloudevents.Event) (interface{}, error) {
+	v, ok := lookupAttribute(event, e.name)
+	if !ok {
+		return "", fmt.Errorf("missing attribute %q", e.name)
+	}
+	return v, nil
+}
+
+type existsExpression struct {
+	name string
+}
+
+func (e existsExpression) Evaluate(event cloudevents.Event) (interface{}, error) {
+	_, ok := lookupAttribute(event, e.name)
+	return ok, nil
+}
+
+type notExpression struct {
+	operand Expression
+}
+
+func (e notExpression) Evaluate(event cloudevents.Event) (interface{}, error) {
+	b, err := evaluateAs(e.operand, event, booleanType)
+	if err != nil {
+		return false, err
+	}
+	return !b.(bool), nil
+}
+
+type negateExpression struct {
+	operand Expression
+}
+
+func (e negateExpression) Evaluate(event cloudevents.Event) (interface{}, error) {
+	i, err := evaluateAs(e.operand, event, integerType)
+	if err != nil {
+		return int32(0), err
+	}
+	return -i.(int32), nil
+}
+
+type arithmeticExpression struct {
+	op          tokenKind
+	left, right Expression
+}
+
+func (e arithmeticExpression) Evaluate(event cloudevents.Event) (interface{}, error) {
+	l, err := evaluateAs(e.left, event, integerType)
+	if err != nil {
+		return int32(0), err
+	}
+	r, err := evaluateAs(e.right, event, integerType)
+	if err != nil {
+		return int32(0), err
+	}
+	x, y := l.(int32), r.(int32)
+	switch e.op {
+	case tokenPlus:
+		return x + y, nil
+	case tokenMinus:
+		return x - y, nil
+	case tokenStar:
+		return x * y, nil
+	case tokenSlash:
+		if y == 0 {
+			return int32(0), errors.New("division by zero")
+		}
+		return x / y, nil
+	case tokenPercent:
+		if y == 0 {
+			return int32(0), errors.New("division by zero")
+		}
+		return x % y, nil
+	}
+	return int32(0), fmt.Errorf("unknown arithmetic operator %d", e.op)
+}
+
+type equalityExpression struct {
+	equal       bool
+	left, right Expression
+}
+
+func (e equalityExpression) Evaluate(event cloudevents.Event) (interface{}, error) {
+	r, err := e.right.Evaluate(event)
+	if err != nil {
+		return false, err
+	}
+	// The left operand is casted to the type of the right operand
+	l, err := evaluateAs(e.left, event, typeOf(r))
+	if err != nil {
+		return false, err
+	}
+	return (l == r) == e.equal, nil
+}
+
+type comparisonExpression struct {
+	op          tokenKind
+	left, right Expression
+}
+
+func (e comparisonExpression) Evaluate(event cloudevents.Event) (interface{}, error) {
+	l, err := evaluateAs(e.left, event, integerType)
+	if err != nil {
+		return false, err
+	}
+	r, err := evaluateAs(e.right, event, integerType)
+	if err != nil {
+		return false, err
+	}
+	x, y := l.(int32), r.(int32)
+	switch e.op {
+	case tokenLess:
+		return x < y, nil
+	case tokenLessOrEqual:
+		return x <= y, nil
+	case tokenGreater:
+		return x > y, nil
+	case tokenGreaterOrEqual:
+		return x >= y, nil
+	}
+	return false, fmt.Errorf("unknown comparison operator %d", e.op)
+}
+
+type logicOperator int
+
+const (
+	logicAnd logicOperator = iota
+	logicOr
+	logicXor
+)
+
+type logicExpression struct {
+	op          logicOperator
+	left, right Expression
+}
+
+func (e logicExpression) Evaluate(event cloudevents.Event) (interface{}, error) {
+	l, err := evaluateAs(e.left, event, booleanType)
+	if err != nil {
+		return false, err
+	}
+	// Short circuit, so expressions like "EXISTS a AND a = 'b'" don't fail when the attribute is missing
+	if e.op == logicAnd && !l.(bool) {
+		return false, nil
+	}
+	if e.op == logicOr && l.(bool) {
+		return true, nil
+	}
+	r, err := evaluateAs(e.right, event, booleanType)
+	if err != nil {
+		return false, err
+	}
+	if e.op == logicXor {
+		return l.(bool) != r.(bool), nil
+	}
+	return r.(bool), nil
+}
+
+type likeExpression struct {
+	operand Expression
+	pattern *regexp.Regexp
+	negate  bool
+}
+
+func (e likeExpression) Evaluate(event cloudevents.Event) (interface{}, error) {
+	s, err := evaluateAs(e.operand, event, stringType)
+	if err != nil {
+		return false, err
+	}
+	return e.pattern.MatchString(s.(string)) != e.negate, nil
+}
+
+type inExpression struct {
+	operand Expression
+	set     []Expression
+	negate  bool
+}
+
+func (e inExpression) Evaluate(event cloudevents.Event) (interface{}, error) {
+	v, err := e.operand.Evaluate(event)
+	if err != nil {
+		return false, err
+	}
+	for _, item := range e.set {
+		i, err := item.Evaluate(event)
+		if err != nil {
+			return false, err
+		}
+		// The operand is casted to the type of each set element
+		casted, err := cast(v, typeOf(i))
+		if err != nil {
+			continue
+		}
+		if casted == i {
+			return !e.negate, nil
+		}
+	}
+	return e.negate, nil
+}
+
+type functionExpression struct {
+	function *function
+	args     []Expression
+}
+
+func (e functionExpression) Evaluate(event cloudevents.Event) (interface{}, error) {
+	args := make([]interface{}, 0, len(e.args))
+	for i, arg := range e.args {
+		v, err := evaluateAs(arg, event, e.function.argType(i))
+		if err != nil {
+			return zeroValue(e.function.returnType), err
+		}
+		args = append(args, v)
+	}
+	return e.function.fn(args)
+}
+
+// evaluateAs evaluates the expression and casts the result to the provided type.
+func evaluateAs(e Expression, event cloudevents.Event, t valueType) (interface{}, error) {
+	v, err := e.Evaluate(event)
+	if err != nil {
+		return zeroValue(t), err
+	}
+	v, err = cast(v, t)
+	if err != nil {
+		return zeroValue(t), err
+	}
+	return v, nil
+}
+
+// lookupAttribute returns the value of the attribute, or false if the event doesn't have it.
+// Extensions keep their CloudEvents type when it maps to a CESQL type, otherwise they're
+// represented as strings.
+func lookupAttribute(event cloudevents.Event, name string) (interface{}, bool) {
+	switch name {
+	case "specversion":
+		return event.SpecVersion(), true
+	case "id":
+		return event.ID(), true
+	case "source":
+		return event.Source(), true
+	case "type":
+		return event.Type(), true
+	case "subject":
+		return event.Subject(), event.Subject() != ""
+	case "time":
+		if event.Time().IsZero() {
+			return nil, false
+		}
+		return types.FormatTime(event.Time()), true
+	case "dataschema":
+		return event.DataSchema(), event.DataSchema() != ""
+	case "datacontenttype":
+		return event.DataContentType(), event.DataContentType() != ""
+	}
+
+	ext, ok := event.Extensions()[name]
+	if !ok {
+		return nil, false
+	}
+	switch x := ext.(type) {
+	case int32, bool, string:
+		return x, true
+	}
+	s, err := types.Format(ext)
+	if err != nil {
+		return nil, false
+	}
+	return s, true
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cesql
+
+import (
+	"context"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"go.uber.org/zap"
+	"knative.dev/pkg/logging"
+
+	"knative.dev/eventing/pkg/eventfilter"
+)
+
+type cesqlFilter struct {
+	rawExpression string
+	expression    Expression
+}
+
+// NewCESQLFilter returns an event filter which passes if the provided CloudEvents SQL expression
+// evaluates to true. An expression failing the evaluation doesn't pass the filter.
+func NewCESQLFilter(expression string) (eventfilter.Filter, error) {
+	parsed, err := Parse(expression)
+	if err != nil {
+		return nil, err
+	}
+	return &cesqlFilter{rawExpression: expression, expression: parsed}, nil
+}
+
+func (f *cesqlFilter) Filter(ctx context.Context, event cloudevents.Event) eventfilter.FilterResult {
+	res, err := evaluateAs(f.expression, event, booleanType)
+	if err != nil {
+		logging.FromContext(ctx).Debug("Failed to evaluate expression", zap.String("expression", f.rawExpression), zap.Error(err))
+		return eventfilter.FailFilter
+	}
+	if !res.(bool) {
+		return eventfilter.FailFilter
+	}
+	return eventfilter.PassFilter
+}
+
+var _ eventfilter.Filter = &cesqlFilter{}
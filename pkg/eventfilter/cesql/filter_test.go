@@ -0,0 +1,77 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cesql
+
+import (
+	"context"
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+
+	"knative.dev/eventing/pkg/eventfilter"
+)
+
+func TestCESQLFilter_Filter(t *testing.T) {
+	tests := map[string]struct {
+		expression string
+		want       eventfilter.FilterResult
+	}{
+		"Pass": {
+			expression: "type LIKE 'com.acme.%' AND priority > 3",
+			want:       eventfilter.PassFilter,
+		},
+		"Fail": {
+			expression: "type LIKE 'com.acme.%' AND priority > 10",
+			want:       eventfilter.FailFilter,
+		},
+		"Missing attribute": {
+			expression: "missing = 'abc'",
+			want:       eventfilter.FailFilter,
+		},
+		"Non boolean result": {
+			expression: "type",
+			want:       eventfilter.FailFilter,
+		},
+		"String casted to boolean": {
+			expression: "'true'",
+			want:       eventfilter.PassFilter,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			e := cloudevents.NewEvent()
+			e.SetID("1234")
+			e.SetType("com.acme.order.created")
+			e.SetSource("/acme/orders")
+			e.SetExtension("priority", 5)
+
+			f, err := NewCESQLFilter(tt.expression)
+			if err != nil {
+				t.Fatalf("NewCESQLFilter(%q) = %v", tt.expression, err)
+			}
+			if got := f.Filter(context.TODO(), e); got != tt.want {
+				t.Errorf("Filter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewCESQLFilter_InvalidExpression(t *testing.T) {
+	if _, err := NewCESQLFilter("type = "); err == nil {
+		t.Error("Expected an error")
+	}
+}
@@ -0,0 +1,243 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cesql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// function is a CESQL built-in function. Arguments are casted to the declared types before
+// invoking fn.
+type function struct {
+	name       string
+	argTypes   []valueType
+	variadic   bool
+	returnType valueType
+	fn         func(args []interface{}) (interface{}, error)
+}
+
+// argType returns the type of the i-th argument. For variadic functions, the type of the last
+// declared argument applies to all the remaining ones.
+func (f *function) argType(i int) valueType {
+	if i >= len(f.argTypes) {
+		return f.argTypes[len(f.argTypes)-1]
+	}
+	return f.argTypes[i]
+}
+
+func (f *function) acceptsArity(n int) bool {
+	if f.variadic {
+		return n >= len(f.argTypes)-1
+	}
+	return n == len(f.argTypes)
+}
+
+// builtinFunctions contains the functions defined by the CESQL specification, indexed by name.
+// The same name can have several overloads with different arities.
+var builtinFunctions = map[string][]*function{
+	"LENGTH": {{
+		argTypes:   []valueType{stringType},
+		returnType: integerType,
+		fn: func(args []interface{}) (interface{}, error) {
+			return int32(len([]rune(args[0].(string)))), nil
+		},
+	}},
+	"CONCAT": {{
+		argTypes:   []valueType{stringType},
+		variadic:   true,
+		returnType: stringType,
+		fn: func(args []interface{}) (interface{}, error) {
+			var sb strings.Builder
+			for _, a := range args {
+				sb.WriteString(a.(string))
+			}
+			return sb.String(), nil
+		},
+	}},
+	"CONCAT_WS": {{
+		argTypes:   []valueType{stringType, stringType},
+		variadic:   true,
+		returnType: stringType,
+		fn: func(args []interface{}) (interface{}, error) {
+			parts := make([]string, 0, len(args)-1)
+			for _, a := range args[1:] {
+				parts = append(parts, a.(string))
+			}
+			return strings.Join(parts, args[0].(string)), nil
+		},
+	}},
+	"LOWER": {{
+		argTypes:   []valueType{stringType},
+		returnType: stringType,
+		fn: func(args []interface{}) (interface{}, error) {
+			return strings.ToLower(args[0].(string)), nil
+		},
+	}},
+	"UPPER": {{
+		argTypes:   []valueType{stringType},
+		returnType: stringType,
+		fn: func(args []interface{}) (interface{}, error) {
+			return strings.ToUpper(args[0].(string)), nil
+		},
+	}},
+	"TRIM": {{
+		argTypes:   []valueType{stringType},
+		returnType: stringType,
+		fn: func(args []interface{}) (interface{}, error) {
+			return strings.TrimSpace(args[0].(string)), nil
+		},
+	}},
+	"LEFT": {{
+		argTypes:   []valueType{stringType, integerType},
+		returnType: stringType,
+		fn: func(args []interface{}) (interface{}, error) {
+			s, n := []rune(args[0].(string)), int(args[1].(int32))
+			if n < 0 {
+				return "", fmt.Errorf("LEFT length must be positive, got %d", n)
+			}
+			if n > len(s) {
+				n = len(s)
+			}
+			return string(s[:n]), nil
+		},
+	}},
+	"RIGHT": {{
+		argTypes:   []valueType{stringType, integerType},
+		returnType: stringType,
+		fn: func(args []interface{}) (interface{}, error) {
+			s, n := []rune(args[0].(string)), int(args[1].(int32))
+			if n < 0 {
+				return "", fmt.Errorf("RIGHT length must be positive, got %d", n)
+			}
+			if n > len(s) {
+				n = len(s)
+			}
+			return string(s[len(s)-n:]), nil
+		},
+	}},
+	"SUBSTRING": {{
+		argTypes:   []valueType{stringType, integerType},
+		returnType: stringType,
+		fn: func(args []interface{}) (interface{}, error) {
+			s := []rune(args[0].(string))
+			start, err := substringStart(len(s), int(args[1].(int32)))
+			if err != nil {
+				return "", err
+			}
+			return string(s[start:]), nil
+		},
+	}, {
+		argTypes:   []valueType{stringType, integerType, integerType},
+		returnType: stringType,
+		fn: func(args []interface{}) (interface{}, error) {
+			s := []rune(args[0].(string))
+			start, err := substringStart(len(s), int(args[1].(int32)))
+			if err != nil {
+				return "", err
+			}
+			length := int(args[2].(int32))
+			if length < 0 {
+				return "", fmt.Errorf("SUBSTRING length must be positive, got %d", length)
+			}
+			end := start + length
+			if end > len(s) {
+				end = len(s)
+			}
+			return string(s[start:end]), nil
+		},
+	}},
+	"ABS": {{
+		argTypes:   []valueType{integerType},
+		returnType: integerType,
+		fn: func(args []interface{}) (interface{}, error) {
+			if i := args[0].(int32); i < 0 {
+				return -i, nil
+			}
+			return args[0], nil
+		},
+	}},
+	"INT": {{
+		argTypes:   []valueType{integerType},
+		returnType: integerType,
+		fn:         identity,
+	}},
+	"BOOL": {{
+		argTypes:   []valueType{booleanType},
+		returnType: booleanType,
+		fn:         identity,
+	}},
+	"STRING": {{
+		argTypes:   []valueType{stringType},
+		returnType: stringType,
+		fn:         identity,
+	}},
+	"IS_INT": {{
+		argTypes:   []valueType{anyType},
+		returnType: booleanType,
+		fn: func(args []interface{}) (interface{}, error) {
+			_, err := castToInteger(args[0])
+			return err == nil, nil
+		},
+	}},
+	"IS_BOOL": {{
+		argTypes:   []valueType{anyType},
+		returnType: booleanType,
+		fn: func(args []interface{}) (interface{}, error) {
+			_, err := castToBoolean(args[0])
+			return err == nil, nil
+		},
+	}},
+}
+
+func init() {
+	for name, overloads := range builtinFunctions {
+		for _, f := range overloads {
+			f.name = name
+		}
+	}
+}
+
+func identity(args []interface{}) (interface{}, error) {
+	return args[0], nil
+}
+
+// substringStart converts the 1-based position of SUBSTRING to a rune index.
+// Negative positions count from the end of the string.
+func substringStart(length, pos int) (int, error) {
+	switch {
+	case pos > 0 && pos <= length+1:
+		return pos - 1, nil
+	case pos < 0 && -pos <= length:
+		return length + pos, nil
+	}
+	return 0, fmt.Errorf("SUBSTRING position %d is out of bounds", pos)
+}
+
+// lookupFunction returns the built-in function matching the name, case insensitive, and the arity.
+func lookupFunction(name string, arity int) (*function, error) {
+	overloads, ok := builtinFunctions[strings.ToUpper(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %s", name)
+	}
+	for _, f := range overloads {
+		if f.acceptsArity(arity) {
+			return f, nil
+		}
+	}
+	return nil, fmt.Errorf("function %s doesn't accept %d arguments", strings.ToUpper(name), arity)
+}
@@ -0,0 +1,183 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cesql
+
+import (
+	"fmt"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdentifier
+	tokenString
+	tokenInteger
+	tokenLeftParen
+	tokenRightParen
+	tokenComma
+	tokenPlus
+	tokenMinus
+	tokenStar
+	tokenSlash
+	tokenPercent
+	tokenEqual
+	tokenNotEqual
+	tokenLess
+	tokenLessOrEqual
+	tokenGreater
+	tokenGreaterOrEqual
+)
+
+// token is a lexical unit of a CESQL expression. For identifiers and integers text holds the raw
+// input, for strings it holds the unquoted value.
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// isKeyword returns true if the token is an identifier matching the provided keyword, ignoring the case.
+func (t token) isKeyword(keyword string) bool {
+	return t.kind == tokenIdentifier && strings.EqualFold(t.text, keyword)
+}
+
+func (t token) String() string {
+	if t.kind == tokenEOF {
+		return "end of expression"
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+// lex splits the input expression in tokens. The returned slice always ends with a tokenEOF.
+func lex(input string) ([]token, error) {
+	var tokens []token
+	i := 0
+	for i < len(input) {
+		c := input[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case isLetter(c):
+			start := i
+			for i < len(input) && (isLetter(input[i]) || isDigit(input[i]) || input[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdentifier, text: input[start:i], pos: start})
+		case isDigit(c):
+			start := i
+			for i < len(input) && isDigit(input[i]) {
+				i++
+			}
+			if i < len(input) && isLetter(input[i]) {
+				return nil, fmt.Errorf("unexpected character %q at position %d", input[i], i)
+			}
+			tokens = append(tokens, token{kind: tokenInteger, text: input[start:i], pos: start})
+		case c == '\'' || c == '"':
+			value, end, err := lexString(input, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenString, text: value, pos: i})
+			i = end
+		default:
+			kind, length, err := lexOperator(input, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: kind, text: input[i : i+length], pos: i})
+			i += length
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(input)}), nil
+}
+
+// lexString reads a string literal starting at the provided quote, returning the unescaped value
+// and the position right after the closing quote.
+func lexString(input string, start int) (string, int, error) {
+	quote := input[start]
+	var sb strings.Builder
+	for i := start + 1; i < len(input); i++ {
+		switch input[i] {
+		case '\\':
+			// Only the quote is unescaped, other escape sequences are left to the consumer (e.g. LIKE patterns)
+			if i+1 < len(input) && input[i+1] == quote {
+				i++
+			}
+			sb.WriteByte(input[i])
+		case quote:
+			return sb.String(), i + 1, nil
+		default:
+			sb.WriteByte(input[i])
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string literal starting at position %d", start)
+}
+
+func lexOperator(input string, i int) (tokenKind, int, error) {
+	next := byte(0)
+	if i+1 < len(input) {
+		next = input[i+1]
+	}
+	switch input[i] {
+	case '(':
+		return tokenLeftParen, 1, nil
+	case ')':
+		return tokenRightParen, 1, nil
+	case ',':
+		return tokenComma, 1, nil
+	case '+':
+		return tokenPlus, 1, nil
+	case '-':
+		return tokenMinus, 1, nil
+	case '*':
+		return tokenStar, 1, nil
+	case '/':
+		return tokenSlash, 1, nil
+	case '%':
+		return tokenPercent, 1, nil
+	case '=':
+		return tokenEqual, 1, nil
+	case '!':
+		if next == '=' {
+			return tokenNotEqual, 2, nil
+		}
+	case '<':
+		switch next {
+		case '=':
+			return tokenLessOrEqual, 2, nil
+		case '>':
+			return tokenNotEqual, 2, nil
+		}
+		return tokenLess, 1, nil
+	case '>':
+		if next == '=' {
+			return tokenGreaterOrEqual, 2, nil
+		}
+		return tokenGreater, 1, nil
+	}
+	return tokenEOF, 0, fmt.Errorf("unexpected character %q at position %d", input[i], i)
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
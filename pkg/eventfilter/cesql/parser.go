@@ -0,0 +1,414 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cesql
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Parse parses a CloudEvents SQL expression.
+//
+// Operators precedence follows the CESQL grammar, from the highest to the lowest:
+//   - function invocations, unary NOT and -, EXISTS
+//   - [NOT] LIKE, [NOT] IN
+//   - *, /, %
+//   - +, -
+//   - =, !=, <>
+//   - <, <=, >, >=
+//   - AND, OR, XOR
+func Parse(expression string) (Expression, error) {
+	tokens, err := lex(expression)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	e, err := p.parseLogic()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %s at position %d", t, t.pos)
+	}
+	return e, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) expect(kind tokenKind, what string) (token, error) {
+	t := p.next()
+	if t.kind != kind {
+		return t, fmt.Errorf("expected %s at position %d, got %s", what, t.pos, t)
+	}
+	return t, nil
+}
+
+func (p *parser) parseLogic() (Expression, error) {
+	left, err := p.parseRelational()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		var op logicOperator
+		switch t := p.peek(); {
+		case t.isKeyword("AND"):
+			op = logicAnd
+		case t.isKeyword("OR"):
+			op = logicOr
+		case t.isKeyword("XOR"):
+			op = logicXor
+		default:
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseRelational()
+		if err != nil {
+			return nil, err
+		}
+		left = logicExpression{op: op, left: left, right: right}
+	}
+}
+
+func (p *parser) parseRelational() (Expression, error) {
+	left, err := p.parseEquality()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := p.peek().kind
+		if op != tokenLess && op != tokenLessOrEqual && op != tokenGreater && op != tokenGreaterOrEqual {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseEquality()
+		if err != nil {
+			return nil, err
+		}
+		left = comparisonExpression{op: op, left: left, right: right}
+	}
+}
+
+func (p *parser) parseEquality() (Expression, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := p.peek().kind
+		if op != tokenEqual && op != tokenNotEqual {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		left = equalityExpression{equal: op == tokenEqual, left: left, right: right}
+	}
+}
+
+func (p *parser) parseAdditive() (Expression, error) {
+	left, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := p.peek().kind
+		if op != tokenPlus && op != tokenMinus {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+		left = arithmeticExpression{op: op, left: left, right: right}
+	}
+}
+
+func (p *parser) parseMultiplicative() (Expression, error) {
+	left, err := p.parsePostfix()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := p.peek().kind
+		if op != tokenStar && op != tokenSlash && op != tokenPercent {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parsePostfix()
+		if err != nil {
+			return nil, err
+		}
+		left = arithmeticExpression{op: op, left: left, right: right}
+	}
+}
+
+// parsePostfix parses the LIKE and IN operators, optionally negated with NOT.
+func (p *parser) parsePostfix() (Expression, error) {
+	operand, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		negate := false
+		if p.peek().isKeyword("NOT") {
+			negate = true
+			p.next()
+		}
+		switch t := p.peek(); {
+		case t.isKeyword("LIKE"):
+			p.next()
+			pattern, err := p.expect(tokenString, "a string literal pattern")
+			if err != nil {
+				return nil, err
+			}
+			re, err := likePatternToRegexp(pattern.text)
+			if err != nil {
+				return nil, fmt.Errorf("invalid LIKE pattern at position %d: %w", pattern.pos, err)
+			}
+			operand = likeExpression{operand: operand, pattern: re, negate: negate}
+		case t.isKeyword("IN"):
+			p.next()
+			set, err := p.parseSet()
+			if err != nil {
+				return nil, err
+			}
+			operand = inExpression{operand: operand, set: set, negate: negate}
+		default:
+			if negate {
+				return nil, fmt.Errorf("expected LIKE or IN at position %d, got %s", t.pos, t)
+			}
+			return operand, nil
+		}
+	}
+}
+
+func (p *parser) parseSet() ([]Expression, error) {
+	if _, err := p.expect(tokenLeftParen, "("); err != nil {
+		return nil, err
+	}
+	var set []Expression
+	for {
+		e, err := p.parseLiteral()
+		if err != nil {
+			return nil, err
+		}
+		set = append(set, e)
+		t := p.next()
+		if t.kind == tokenRightParen {
+			return set, nil
+		}
+		if t.kind != tokenComma {
+			return nil, fmt.Errorf("expected , or ) at position %d, got %s", t.pos, t)
+		}
+	}
+}
+
+func (p *parser) parseUnary() (Expression, error) {
+	t := p.peek()
+	switch {
+	case t.isKeyword("NOT"):
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notExpression{operand: operand}, nil
+	case t.kind == tokenMinus:
+		p.next()
+		if p.peek().kind == tokenInteger {
+			// Parse negative literals directly, so the minimum integer doesn't overflow
+			return p.parseInteger("-")
+		}
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return negateExpression{operand: operand}, nil
+	case t.isKeyword("EXISTS"):
+		p.next()
+		name, err := p.expect(tokenIdentifier, "an attribute name")
+		if err != nil {
+			return nil, err
+		}
+		if err := validateAttributeName(name); err != nil {
+			return nil, err
+		}
+		return existsExpression{name: name.text}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (Expression, error) {
+	t := p.peek()
+	switch t.kind {
+	case tokenLeftParen:
+		p.next()
+		e, err := p.parseLogic()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokenRightParen, ")"); err != nil {
+			return nil, err
+		}
+		return e, nil
+	case tokenIdentifier:
+		if isReservedKeyword(t.text) {
+			return nil, fmt.Errorf("unexpected keyword %s at position %d", t, t.pos)
+		}
+		if t.isKeyword("TRUE") || t.isKeyword("FALSE") {
+			return p.parseLiteral()
+		}
+		p.next()
+		if p.peek().kind == tokenLeftParen {
+			return p.parseFunction(t)
+		}
+		if err := validateAttributeName(t); err != nil {
+			return nil, err
+		}
+		return attributeExpression{name: t.text}, nil
+	}
+	return p.parseLiteral()
+}
+
+func (p *parser) parseLiteral() (Expression, error) {
+	t := p.peek()
+	switch {
+	case t.kind == tokenString:
+		p.next()
+		return literalExpression{value: t.text}, nil
+	case t.kind == tokenInteger:
+		return p.parseInteger("")
+	case t.kind == tokenMinus:
+		p.next()
+		if p.peek().kind != tokenInteger {
+			return nil, fmt.Errorf("expected an integer literal at position %d, got %s", p.peek().pos, p.peek())
+		}
+		return p.parseInteger("-")
+	case t.isKeyword("TRUE"):
+		p.next()
+		return literalExpression{value: true}, nil
+	case t.isKeyword("FALSE"):
+		p.next()
+		return literalExpression{value: false}, nil
+	}
+	return nil, fmt.Errorf("unexpected %s at position %d", t, t.pos)
+}
+
+func (p *parser) parseInteger(sign string) (Expression, error) {
+	t := p.next()
+	i, err := strconv.ParseInt(sign+t.text, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("integer literal %s%s at position %d is out of range", sign, t.text, t.pos)
+	}
+	return literalExpression{value: int32(i)}, nil
+}
+
+func (p *parser) parseFunction(name token) (Expression, error) {
+	// Consume the left paren
+	p.next()
+	var args []Expression
+	if p.peek().kind == tokenRightParen {
+		p.next()
+	} else {
+		for {
+			arg, err := p.parseLogic()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			t := p.next()
+			if t.kind == tokenRightParen {
+				break
+			}
+			if t.kind != tokenComma {
+				return nil, fmt.Errorf("expected , or ) at position %d, got %s", t.pos, t)
+			}
+		}
+	}
+	f, err := lookupFunction(name.text, len(args))
+	if err != nil {
+		return nil, fmt.Errorf("%w at position %d", err, name.pos)
+	}
+	return functionExpression{function: f, args: args}, nil
+}
+
+// validateAttributeName checks the identifier is a valid CloudEvents attribute name.
+func validateAttributeName(t token) error {
+	for i := 0; i < len(t.text); i++ {
+		if c := t.text[i]; !(c >= 'a' && c <= 'z') && !isDigit(c) {
+			return fmt.Errorf("invalid attribute name %s at position %d", t, t.pos)
+		}
+	}
+	return nil
+}
+
+var reservedKeywords = []string{"AND", "OR", "XOR", "NOT", "LIKE", "IN", "EXISTS"}
+
+func isReservedKeyword(s string) bool {
+	for _, k := range reservedKeywords {
+		if strings.EqualFold(s, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// likePatternToRegexp converts a LIKE pattern to an anchored regular expression.
+// % matches any sequence of characters, _ matches a single character and \ escapes the next one.
+func likePatternToRegexp(pattern string) (*regexp.Regexp, error) {
+	var sb strings.Builder
+	sb.WriteString("(?s)^")
+	runes := []rune(pattern)
+	for i := 0; i < len(runes); i++ {
+		switch runes[i] {
+		case '\\':
+			if i+1 >= len(runes) {
+				return nil, fmt.Errorf("pattern %q ends with an escape character", pattern)
+			}
+			i++
+			sb.WriteString(regexp.QuoteMeta(string(runes[i])))
+		case '%':
+			sb.WriteString(".*")
+		case '_':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(runes[i])))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.Compile(sb.String())
+}
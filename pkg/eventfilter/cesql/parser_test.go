@@ -0,0 +1,148 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cesql
+
+import (
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+func TestParseErrors(t *testing.T) {
+	tests := map[string]string{
+		"Empty":                      "",
+		"Unterminated string":        "type = 'abc",
+		"Unknown character":          "type # 'abc'",
+		"Missing operand":            "type =",
+		"Dangling operator":          "type = 'a' AND",
+		"Unbalanced parens":          "(type = 'a'",
+		"Trailing tokens":            "type = 'a' 'b'",
+		"Uppercase attribute":        "TYPE = 'a'",
+		"Integer overflow":           "priority > 2147483648",
+		"Unknown function":           "FOO(type)",
+		"Wrong arity":                "LENGTH(type, source)",
+		"LIKE without pattern":       "type LIKE source",
+		"NOT without LIKE or IN":     "type NOT 'a'",
+		"IN with non literal":        "type IN (source)",
+		"EXISTS without identifier":  "EXISTS 'a'",
+		"Keyword used as attribute":  "and = 'a'",
+		"Identifier with underscore": "my_ext = 'a'",
+	}
+	for name, expression := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Parse(expression); err == nil {
+				t.Errorf("Parse(%q) expected an error", expression)
+			}
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	event := cloudevents.NewEvent()
+	event.SetID("1234")
+	event.SetType("com.acme.order.created")
+	event.SetSource("/acme/orders")
+	event.SetSubject("order-42")
+	event.SetExtension("priority", 5)
+	event.SetExtension("urgent", true)
+	event.SetExtension("region", "eu-west")
+	event.SetExtension("count", "10")
+
+	tests := map[string]struct {
+		expression string
+		want       interface{}
+		wantErr    bool
+	}{
+		"String literal":             {expression: "'abc'", want: "abc"},
+		"Double quoted literal":      {expression: `"abc"`, want: "abc"},
+		"Escaped quote":              {expression: `'it\'s'`, want: "it's"},
+		"Integer literal":            {expression: "42", want: int32(42)},
+		"Negative literal":           {expression: "-2147483648", want: int32(-2147483648)},
+		"Boolean literal":            {expression: "TrUe", want: true},
+		"Attribute":                  {expression: "type", want: "com.acme.order.created"},
+		"Integer extension":          {expression: "priority", want: int32(5)},
+		"Boolean extension":          {expression: "urgent", want: true},
+		"Missing attribute":          {expression: "missing", want: "", wantErr: true},
+		"Exists":                     {expression: "EXISTS subject", want: true},
+		"Not exists":                 {expression: "NOT EXISTS missing", want: true},
+		"Equal":                      {expression: "source = '/acme/orders'", want: true},
+		"Not equal":                  {expression: "source != '/acme/orders'", want: false},
+		"Not equal diamond":          {expression: "source <> '/other'", want: true},
+		"Equal casts left to right":  {expression: "count = 10", want: true},
+		"Comparison":                 {expression: "priority > 3", want: true},
+		"Comparison casts strings":   {expression: "count <= '10'", want: true},
+		"Comparison cast failure":    {expression: "type > 3", want: false, wantErr: true},
+		"Arithmetic precedence":      {expression: "1 + 2 * 3", want: int32(7)},
+		"Parens":                     {expression: "(1 + 2) * 3", want: int32(9)},
+		"Unary minus":                {expression: "-priority", want: int32(-5)},
+		"Modulo":                     {expression: "7 % 3", want: int32(1)},
+		"Division by zero":           {expression: "1 / 0", want: int32(0), wantErr: true},
+		"And":                        {expression: "type LIKE 'com.acme.%' AND priority > 3", want: true},
+		"And short circuit":          {expression: "EXISTS missing AND missing = 'a'", want: false},
+		"Or short circuit":           {expression: "urgent OR missing = 'a'", want: true},
+		"Xor":                        {expression: "TRUE XOR TRUE", want: false},
+		"Logic casts strings":        {expression: "'true' AND TRUE", want: true},
+		"Like":                       {expression: "type LIKE 'com.acme.%.created'", want: true},
+		"Like single character":      {expression: "subject LIKE 'order-_2'", want: true},
+		"Like escaped":               {expression: `'100%' LIKE '100\%'`, want: true},
+		"Like escaped mismatch":      {expression: `'100a' LIKE '100\%'`, want: false},
+		"Like is anchored":           {expression: "type LIKE 'acme%'", want: false},
+		"Like special characters":    {expression: "'a.c' LIKE 'a.c'", want: true},
+		"Not like":                   {expression: "type NOT LIKE 'com.acme.%'", want: false},
+		"In":                         {expression: "region IN ('us-east', 'eu-west')", want: true},
+		"In casts to element type":   {expression: "count IN (1, 10)", want: true},
+		"Not in":                     {expression: "region NOT IN ('us-east', 'eu-west')", want: false},
+		"Function":                   {expression: "UPPER(region)", want: "EU-WEST"},
+		"Function case insensitive":  {expression: "lower('ABC')", want: "abc"},
+		"Length":                     {expression: "LENGTH(subject)", want: int32(8)},
+		"Concat":                     {expression: "CONCAT(region, '-', 1)", want: "eu-west-1"},
+		"Concat ws":                  {expression: "CONCAT_WS('/', 'a', 'b', 'c')", want: "a/b/c"},
+		"Trim":                       {expression: "TRIM('  a  ')", want: "a"},
+		"Left":                       {expression: "LEFT(region, 2)", want: "eu"},
+		"Right":                      {expression: "RIGHT(region, 100)", want: "eu-west"},
+		"Substring":                  {expression: "SUBSTRING(region, 4)", want: "west"},
+		"Substring with length":      {expression: "SUBSTRING(region, 1, 2)", want: "eu"},
+		"Substring negative":         {expression: "SUBSTRING(region, -4)", want: "west"},
+		"Substring out of bounds":    {expression: "SUBSTRING(region, 100)", want: "", wantErr: true},
+		"Abs":                        {expression: "ABS(-3)", want: int32(3)},
+		"Int":                        {expression: "INT(count) + 1", want: int32(11)},
+		"Int failure":                {expression: "INT(region)", want: int32(0), wantErr: true},
+		"Bool":                       {expression: "BOOL('FALSE')", want: false},
+		"String":                     {expression: "STRING(priority)", want: "5"},
+		"Is int":                     {expression: "IS_INT(count)", want: true},
+		"Is bool":                    {expression: "IS_BOOL(region)", want: false},
+		"Nested functions":           {expression: "LENGTH(CONCAT(region, subject)) = 15", want: true},
+		"Not binds to the operand":   {expression: "NOT urgent = FALSE", want: true},
+		"Whitespaces and new lines":  {expression: "priority\n\t>=\r\n5", want: true},
+		"Keywords are case insensit": {expression: "type like 'com%' and not exists missing", want: true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			e, err := Parse(tt.expression)
+			if err != nil {
+				t.Fatalf("Parse(%q) = %v", tt.expression, err)
+			}
+			got, err := e.Evaluate(event)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Evaluate(%q) error = %v, wantErr %v", tt.expression, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Evaluate(%q) = %#v, want %#v", tt.expression, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cesql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// valueType is one of the CESQL types. Values are represented at runtime as string, int32 and bool.
+type valueType int
+
+const (
+	anyType valueType = iota
+	stringType
+	integerType
+	booleanType
+)
+
+func (t valueType) String() string {
+	switch t {
+	case stringType:
+		return "String"
+	case integerType:
+		return "Integer"
+	case booleanType:
+		return "Boolean"
+	default:
+		return "Any"
+	}
+}
+
+func typeOf(v interface{}) valueType {
+	switch v.(type) {
+	case string:
+		return stringType
+	case int32:
+		return integerType
+	case bool:
+		return booleanType
+	default:
+		return anyType
+	}
+}
+
+// zeroValue returns the value an expression of the given type evaluates to when it fails.
+func zeroValue(t valueType) interface{} {
+	switch t {
+	case integerType:
+		return int32(0)
+	case booleanType:
+		return false
+	default:
+		return ""
+	}
+}
+
+// cast converts v to the provided type, following the CESQL implicit casting rules.
+func cast(v interface{}, t valueType) (interface{}, error) {
+	switch t {
+	case stringType:
+		return castToString(v), nil
+	case integerType:
+		return castToInteger(v)
+	case booleanType:
+		return castToBoolean(v)
+	default:
+		return v, nil
+	}
+}
+
+func castToString(v interface{}) string {
+	switch x := v.(type) {
+	case string:
+		return x
+	case int32:
+		return strconv.FormatInt(int64(x), 10)
+	case bool:
+		return strconv.FormatBool(x)
+	default:
+		return fmt.Sprint(x)
+	}
+}
+
+func castToInteger(v interface{}) (int32, error) {
+	switch x := v.(type) {
+	case int32:
+		return x, nil
+	case string:
+		i, err := strconv.ParseInt(strings.TrimSpace(x), 10, 32)
+		if err != nil {
+			return 0, fmt.Errorf("cannot cast %q to %s", x, integerType)
+		}
+		return int32(i), nil
+	default:
+		return 0, fmt.Errorf("cannot cast %v of type %s to %s", v, typeOf(v), integerType)
+	}
+}
+
+func castToBoolean(v interface{}) (bool, error) {
+	switch x := v.(type) {
+	case bool:
+		return x, nil
+	case string:
+		switch strings.ToLower(x) {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+		return false, fmt.Errorf("cannot cast %q to %s", x, booleanType)
+	default:
+		return false, fmt.Errorf("cannot cast %v of type %s to %s", v, typeOf(v), booleanType)
+	}
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package jsonpath provides an event filter evaluating JSONPath predicates against the event data.
+package jsonpath
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"go.uber.org/zap"
+	k8sjsonpath "k8s.io/client-go/util/jsonpath"
+	"knative.dev/pkg/logging"
+
+	"knative.dev/eventing/pkg/eventfilter"
+)
+
+// Predicate is a JSONPath expression evaluated against the event data.
+type Predicate struct {
+	// Path is the JSONPath expression. Both the template syntax, e.g. {.order.region},
+	// and the relaxed syntax, e.g. .order.region or $.order.region, are accepted.
+	Path string
+	// Value, when not nil, must be equal to the string representation of at least one
+	// of the values selected by Path. When nil, Path must select at least one value.
+	Value *string
+}
+
+type compiledPredicate struct {
+	path  string
+	value *string
+	// k8s JSONPath is not safe for concurrent use, so every goroutine gets its own parsed copy.
+	parsed *sync.Pool
+}
+
+type dataFilter []compiledPredicate
+
+// NewDataFilter returns an event filter which passes if all the predicates match the event data.
+// The event data must be JSON, otherwise the filter doesn't pass.
+func NewDataFilter(predicates ...Predicate) (eventfilter.Filter, error) {
+	f := make(dataFilter, 0, len(predicates))
+	for _, p := range predicates {
+		template := normalize(p.Path)
+		if _, err := parse(template); err != nil {
+			return nil, fmt.Errorf("failed to parse JSONPath %q: %w", p.Path, err)
+		}
+		f = append(f, compiledPredicate{
+			path:  p.Path,
+			value: p.Value,
+			parsed: &sync.Pool{New: func() interface{} {
+				// The template was already successfully parsed above.
+				jp, _ := parse(template)
+				return jp
+			}},
+		})
+	}
+	return f, nil
+}
+
+// ValidatePath returns an error if the provided JSONPath expression cannot be parsed.
+func ValidatePath(path string) error {
+	_, err := parse(normalize(path))
+	return err
+}
+
+func (filter dataFilter) Filter(ctx context.Context, event cloudevents.Event) eventfilter.FilterResult {
+	if len(filter) == 0 {
+		return eventfilter.NoFilter
+	}
+	if !isJSON(event.DataMediaType()) || len(event.Data()) == 0 {
+		logging.FromContext(ctx).Debug("Event data is not JSON", zap.String("datacontenttype", event.DataContentType()))
+		return eventfilter.FailFilter
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(event.Data(), &data); err != nil {
+		logging.FromContext(ctx).Debug("Failed to unmarshal event data", zap.Error(err))
+		return eventfilter.FailFilter
+	}
+
+	for _, p := range filter {
+		if !p.matches(ctx, data) {
+			return eventfilter.FailFilter
+		}
+	}
+	return eventfilter.PassFilter
+}
+
+func (p *compiledPredicate) matches(ctx context.Context, data interface{}) bool {
+	jp := p.parsed.Get().(*k8sjsonpath.JSONPath)
+	defer p.parsed.Put(jp)
+
+	results, err := jp.FindResults(data)
+	if err != nil {
+		logging.FromContext(ctx).Debug("Failed to evaluate JSONPath", zap.String("path", p.path), zap.Error(err))
+		return false
+	}
+	for _, values := range results {
+		for _, v := range values {
+			if p.value == nil {
+				return true
+			}
+			if s, ok := toString(v); ok && s == *p.value {
+				return true
+			}
+		}
+	}
+	logging.FromContext(ctx).Debug("JSONPath had no matching value", zap.String("path", p.path))
+	return false
+}
+
+func parse(template string) (*k8sjsonpath.JSONPath, error) {
+	jp := k8sjsonpath.New("").AllowMissingKeys(true)
+	if err := jp.Parse(template); err != nil {
+		return nil, err
+	}
+	return jp, nil
+}
+
+// normalize converts the relaxed syntax to the template syntax accepted by the k8s JSONPath parser.
+func normalize(path string) string {
+	path = strings.TrimSpace(path)
+	if strings.HasPrefix(path, "{") && strings.HasSuffix(path, "}") {
+		return path
+	}
+	path = strings.TrimPrefix(path, "$")
+	if !strings.HasPrefix(path, ".") && !strings.HasPrefix(path, "[") {
+		path = "." + path
+	}
+	return "{" + path + "}"
+}
+
+func isJSON(mediaType string) bool {
+	// As per CloudEvents spec, an event without datacontenttype has JSON data
+	return mediaType == "" ||
+		mediaType == cloudevents.ApplicationJSON ||
+		mediaType == "text/json" ||
+		strings.HasSuffix(mediaType, "+json")
+}
+
+func toString(v reflect.Value) (string, bool) {
+	if v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return "null", true
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), true
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), true
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64), true
+	}
+	return "", false
+}
+
+var _ eventfilter.Filter = dataFilter{}
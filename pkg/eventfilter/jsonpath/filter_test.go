@@ -0,0 +1,173 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jsonpath
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"k8s.io/utils/pointer"
+
+	"knative.dev/eventing/pkg/eventfilter"
+)
+
+const data = `{"order": {"id": 42, "region": "eu-west", "express": true, "items": [{"sku": "a1", "qty": 2}, {"sku": "b2", "qty": 5}]}}`
+
+func TestDataFilter_Filter(t *testing.T) {
+	tests := map[string]struct {
+		predicates  []Predicate
+		contentType string
+		data        string
+		want        eventfilter.FilterResult
+	}{
+		"No predicates": {
+			want: eventfilter.NoFilter,
+		},
+		"Path exists": {
+			predicates: []Predicate{{Path: ".order.region"}},
+			want:       eventfilter.PassFilter,
+		},
+		"Path doesn't exist": {
+			predicates: []Predicate{{Path: ".order.customer"}},
+			want:       eventfilter.FailFilter,
+		},
+		"Template syntax": {
+			predicates: []Predicate{{Path: "{.order.region}", Value: pointer.StringPtr("eu-west")}},
+			want:       eventfilter.PassFilter,
+		},
+		"Dollar syntax": {
+			predicates: []Predicate{{Path: "$.order.region", Value: pointer.StringPtr("eu-west")}},
+			want:       eventfilter.PassFilter,
+		},
+		"Without leading dot": {
+			predicates: []Predicate{{Path: "order.region", Value: pointer.StringPtr("eu-west")}},
+			want:       eventfilter.PassFilter,
+		},
+		"Wrong value": {
+			predicates: []Predicate{{Path: ".order.region", Value: pointer.StringPtr("us-east")}},
+			want:       eventfilter.FailFilter,
+		},
+		"Number value": {
+			predicates: []Predicate{{Path: ".order.id", Value: pointer.StringPtr("42")}},
+			want:       eventfilter.PassFilter,
+		},
+		"Boolean value": {
+			predicates: []Predicate{{Path: ".order.express", Value: pointer.StringPtr("true")}},
+			want:       eventfilter.PassFilter,
+		},
+		"Any of the selected values": {
+			predicates: []Predicate{{Path: ".order.items[*].sku", Value: pointer.StringPtr("b2")}},
+			want:       eventfilter.PassFilter,
+		},
+		"Filter expression": {
+			predicates: []Predicate{{Path: `.order.items[?(@.sku=="b2")].qty`, Value: pointer.StringPtr("5")}},
+			want:       eventfilter.PassFilter,
+		},
+		"Filter expression without matches": {
+			predicates: []Predicate{{Path: `.order.items[?(@.sku=="c3")]`}},
+			want:       eventfilter.FailFilter,
+		},
+		"All predicates must match": {
+			predicates: []Predicate{
+				{Path: ".order.region", Value: pointer.StringPtr("eu-west")},
+				{Path: ".order.id", Value: pointer.StringPtr("43")},
+			},
+			want: eventfilter.FailFilter,
+		},
+		"JSON suffix content type": {
+			predicates:  []Predicate{{Path: ".order.region"}},
+			contentType: "application/vnd.acme+json; charset=utf-8",
+			want:        eventfilter.PassFilter,
+		},
+		"Not JSON": {
+			predicates:  []Predicate{{Path: ".order.region"}},
+			contentType: "text/plain",
+			want:        eventfilter.FailFilter,
+		},
+		"Malformed JSON": {
+			predicates: []Predicate{{Path: ".order.region"}},
+			data:       `{"order": `,
+			want:       eventfilter.FailFilter,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			e := cloudevents.NewEvent()
+			e.SetID("1234")
+			e.SetType("com.acme.order")
+			e.SetSource("/acme")
+			d := tt.data
+			if d == "" {
+				d = data
+			}
+			ct := tt.contentType
+			if ct == "" {
+				ct = cloudevents.ApplicationJSON
+			}
+			if err := e.SetData(ct, []byte(d)); err != nil {
+				t.Fatal(err)
+			}
+
+			f, err := NewDataFilter(tt.predicates...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := f.Filter(context.TODO(), e); got != tt.want {
+				t.Errorf("Filter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDataFilter_Concurrent(t *testing.T) {
+	e := cloudevents.NewEvent()
+	e.SetID("1234")
+	e.SetType("com.acme.order")
+	e.SetSource("/acme")
+	_ = e.SetData(cloudevents.ApplicationJSON, []byte(data))
+
+	f, err := NewDataFilter(Predicate{Path: ".order.items[*].sku", Value: pointer.StringPtr("b2")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if got := f.Filter(context.TODO(), e); got != eventfilter.PassFilter {
+				t.Errorf("Filter() = %v, want %v", got, eventfilter.PassFilter)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestValidatePath(t *testing.T) {
+	for _, valid := range []string{".a.b", "{.a.b}", "$.a[0]", "a.b", ".a[?(@.b==\"c\")]"} {
+		if err := ValidatePath(valid); err != nil {
+			t.Errorf("ValidatePath(%q) = %v", valid, err)
+		}
+	}
+	for _, invalid := range []string{".a[", "{.a.b", ".a[?(@.b=="} {
+		if err := ValidatePath(invalid); err == nil {
+			t.Errorf("ValidatePath(%q) expected an error", invalid)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package subscriptionsapi
+
+import (
+	"context"
+	"strings"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/types"
+	"go.uber.org/zap"
+	"knative.dev/pkg/logging"
+
+	"knative.dev/eventing/pkg/eventfilter"
+	"knative.dev/eventing/pkg/eventfilter/attributes"
+)
+
+// attributesFilter matches each attribute of the event with the configured value, using the match function.
+// All the attributes must match for the event to pass the filter.
+type attributesFilter struct {
+	dialect string
+	attrs   map[string]string
+	match   func(value, expected string) bool
+}
+
+// NewExactFilter returns an event filter which passes if the value of each attribute is equal to the provided one.
+func NewExactFilter(attrs map[string]string) eventfilter.Filter {
+	return &attributesFilter{
+		dialect: "exact",
+		attrs:   attrs,
+		match: func(value, expected string) bool {
+			return value == expected
+		},
+	}
+}
+
+// NewPrefixFilter returns an event filter which passes if the value of each attribute starts with the provided one.
+func NewPrefixFilter(attrs map[string]string) eventfilter.Filter {
+	return &attributesFilter{
+		dialect: "prefix",
+		attrs:   attrs,
+		match:   strings.HasPrefix,
+	}
+}
+
+// NewSuffixFilter returns an event filter which passes if the value of each attribute ends with the provided one.
+func NewSuffixFilter(attrs map[string]string) eventfilter.Filter {
+	return &attributesFilter{
+		dialect: "suffix",
+		attrs:   attrs,
+		match:   strings.HasSuffix,
+	}
+}
+
+func (f *attributesFilter) Filter(ctx context.Context, event cloudevents.Event) eventfilter.FilterResult {
+	if len(f.attrs) == 0 {
+		return eventfilter.NoFilter
+	}
+	for k, v := range f.attrs {
+		value, ok := lookupString(event, k)
+		if !ok {
+			logging.FromContext(ctx).Debug("Attribute not found", zap.String("dialect", f.dialect), zap.String("attribute", k))
+			return eventfilter.FailFilter
+		}
+		if !f.match(value, v) {
+			logging.FromContext(ctx).Debug("Attribute had non-matching value", zap.String("dialect", f.dialect), zap.String("attribute", k), zap.String("filter", v), zap.String("received", value))
+			return eventfilter.FailFilter
+		}
+	}
+	return eventfilter.PassFilter
+}
+
+// lookupString returns the value of the attribute in its canonical string representation.
+// Optional attributes with an empty value are considered missing.
+func lookupString(event cloudevents.Event, attr string) (string, bool) {
+	value, ok := attributes.LookupAttribute(event, attr)
+	if !ok {
+		return "", false
+	}
+	s, err := types.Format(value)
+	if err != nil || s == "" {
+		return "", false
+	}
+	return s, true
+}
+
+var _ eventfilter.Filter = &attributesFilter{}
@@ -0,0 +1,110 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package subscriptionsapi
+
+import (
+	"context"
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+
+	"knative.dev/eventing/pkg/eventfilter"
+)
+
+const (
+	eventType      = `com.example.someevent`
+	eventSource    = `/mycontext`
+	extensionName  = `myextension`
+	extensionValue = `my-extension-value`
+)
+
+func TestAttributesFilters(t *testing.T) {
+	tests := map[string]struct {
+		filter eventfilter.Filter
+		want   eventfilter.FilterResult
+	}{
+		"Exact match": {
+			filter: NewExactFilter(map[string]string{"type": eventType, "source": eventSource}),
+			want:   eventfilter.PassFilter,
+		},
+		"Exact no match": {
+			filter: NewExactFilter(map[string]string{"type": eventType, "source": "/other"}),
+			want:   eventfilter.FailFilter,
+		},
+		"Exact with extension": {
+			filter: NewExactFilter(map[string]string{extensionName: extensionValue}),
+			want:   eventfilter.PassFilter,
+		},
+		"Exact with integer extension": {
+			filter: NewExactFilter(map[string]string{"priority": "5"}),
+			want:   eventfilter.PassFilter,
+		},
+		"Exact with missing attribute": {
+			filter: NewExactFilter(map[string]string{"missing": ""}),
+			want:   eventfilter.FailFilter,
+		},
+		"Exact with empty optional attribute": {
+			filter: NewExactFilter(map[string]string{"subject": ""}),
+			want:   eventfilter.FailFilter,
+		},
+		"Exact without attributes": {
+			filter: NewExactFilter(map[string]string{}),
+			want:   eventfilter.NoFilter,
+		},
+		"Prefix match": {
+			filter: NewPrefixFilter(map[string]string{"type": "com.example.", extensionName: "my-"}),
+			want:   eventfilter.PassFilter,
+		},
+		"Prefix no match": {
+			filter: NewPrefixFilter(map[string]string{"type": "com.other."}),
+			want:   eventfilter.FailFilter,
+		},
+		"Prefix with missing attribute": {
+			filter: NewPrefixFilter(map[string]string{"missing": "com"}),
+			want:   eventfilter.FailFilter,
+		},
+		"Suffix match": {
+			filter: NewSuffixFilter(map[string]string{"type": ".someevent", "source": "context"}),
+			want:   eventfilter.PassFilter,
+		},
+		"Suffix no match": {
+			filter: NewSuffixFilter(map[string]string{"type": ".otherevent"}),
+			want:   eventfilter.FailFilter,
+		},
+		"Suffix with missing attribute": {
+			filter: NewSuffixFilter(map[string]string{"missing": "event"}),
+			want:   eventfilter.FailFilter,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := tt.filter.Filter(context.TODO(), makeEvent()); got != tt.want {
+				t.Errorf("Filter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func makeEvent() cloudevents.Event {
+	e := cloudevents.NewEvent()
+	e.SetType(eventType)
+	e.SetSource(eventSource)
+	e.SetID("1234")
+	e.SetExtension(extensionName, extensionValue)
+	e.SetExtension("priority", 5)
+	return e
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package subscriptionsapi
+
+import (
+	"context"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+
+	"knative.dev/eventing/pkg/eventfilter"
+)
+
+// NewAllFilter returns an event filter which passes if all the provided filters pass.
+func NewAllFilter(filters ...eventfilter.Filter) eventfilter.Filter {
+	return eventfilter.Filters(filters)
+}
+
+type anyFilter []eventfilter.Filter
+
+// NewAnyFilter returns an event filter which passes if at least one of the provided filters passes.
+func NewAnyFilter(filters ...eventfilter.Filter) eventfilter.Filter {
+	return anyFilter(filters)
+}
+
+func (filters anyFilter) Filter(ctx context.Context, event cloudevents.Event) eventfilter.FilterResult {
+	res := eventfilter.NoFilter
+	for _, f := range filters {
+		res = res.Or(f.Filter(ctx, event))
+		// Short circuit to optimize it
+		if res == eventfilter.PassFilter {
+			return eventfilter.PassFilter
+		}
+	}
+	return res
+}
+
+type notFilter struct {
+	filter eventfilter.Filter
+}
+
+// NewNotFilter returns an event filter which passes if the provided filter doesn't pass.
+// If the provided filter is a no-op, the returned filter is a no-op too.
+func NewNotFilter(filter eventfilter.Filter) eventfilter.Filter {
+	return &notFilter{filter: filter}
+}
+
+func (f *notFilter) Filter(ctx context.Context, event cloudevents.Event) eventfilter.FilterResult {
+	switch f.filter.Filter(ctx, event) {
+	case eventfilter.PassFilter:
+		return eventfilter.FailFilter
+	case eventfilter.FailFilter:
+		return eventfilter.PassFilter
+	}
+	return eventfilter.NoFilter
+}
+
+var (
+	_ eventfilter.Filter = anyFilter{}
+	_ eventfilter.Filter = &notFilter{}
+)
@@ -0,0 +1,90 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package subscriptionsapi
+
+import (
+	"context"
+	"testing"
+
+	"knative.dev/eventing/pkg/eventfilter"
+)
+
+var (
+	pass     = NewExactFilter(map[string]string{"type": eventType})
+	fail     = NewExactFilter(map[string]string{"type": "some-other-type"})
+	noFilter = NewExactFilter(map[string]string{})
+)
+
+func TestLogicFilters(t *testing.T) {
+	tests := map[string]struct {
+		filter eventfilter.Filter
+		want   eventfilter.FilterResult
+	}{
+		"All empty": {
+			filter: NewAllFilter(),
+			want:   eventfilter.NoFilter,
+		},
+		"All pass": {
+			filter: NewAllFilter(pass, pass, noFilter),
+			want:   eventfilter.PassFilter,
+		},
+		"All with one failing": {
+			filter: NewAllFilter(pass, fail),
+			want:   eventfilter.FailFilter,
+		},
+		"Any empty": {
+			filter: NewAnyFilter(),
+			want:   eventfilter.NoFilter,
+		},
+		"Any with one passing": {
+			filter: NewAnyFilter(fail, noFilter, pass),
+			want:   eventfilter.PassFilter,
+		},
+		"Any all failing": {
+			filter: NewAnyFilter(fail, fail),
+			want:   eventfilter.FailFilter,
+		},
+		"Not pass": {
+			filter: NewNotFilter(pass),
+			want:   eventfilter.FailFilter,
+		},
+		"Not fail": {
+			filter: NewNotFilter(fail),
+			want:   eventfilter.PassFilter,
+		},
+		"Not no filter": {
+			filter: NewNotFilter(noFilter),
+			want:   eventfilter.NoFilter,
+		},
+		"Nested": {
+			// type == eventType AND (NOT (type == other) OR type == other)
+			filter: NewAllFilter(pass, NewAnyFilter(NewNotFilter(fail), fail)),
+			want:   eventfilter.PassFilter,
+		},
+		"Nested failing": {
+			filter: NewNotFilter(NewAnyFilter(fail, NewAllFilter(pass, NewNotFilter(fail)))),
+			want:   eventfilter.FailFilter,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := tt.filter.Filter(context.TODO(), makeEvent()); got != tt.want {
+				t.Errorf("Filter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
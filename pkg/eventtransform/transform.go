@@ -0,0 +1,224 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package eventtransform provides a binding.Transformer rewriting the context attributes of an event.
+package eventtransform
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/binding/spec"
+	"github.com/cloudevents/sdk-go/v2/types"
+)
+
+var (
+	// Only allow lowercase alphanumeric, starting with letters.
+	validAttributeName = regexp.MustCompile(`^[a-z][a-z0-9]*$`)
+)
+
+// Transform describes how to rewrite the context attributes of an event.
+//
+// Type, Source and the values of Extensions are templates which can reference the
+// attributes of the original event as ${name}, e.g. "${source}/orders".
+type Transform struct {
+	// Type, when not empty, overrides the type attribute.
+	Type string
+	// Source, when not empty, overrides the source attribute.
+	Source string
+	// Extensions sets extension attributes, overriding the existing values.
+	// An extension whose value evaluates to an empty string is removed.
+	Extensions map[string]string
+	// Rename renames extension attributes, from the key to the value.
+	Rename map[string]string
+	// Remove removes extension attributes.
+	Remove []string
+}
+
+type transformer struct {
+	eventType  template
+	source     template
+	extensions map[string]template
+	rename     map[string]string
+	remove     []string
+}
+
+// NewTransformer returns a binding.Transformer applying the transform. All the templates
+// are evaluated against the original attributes of the event, then extensions are removed,
+// renamed and set, and finally type and source are overridden.
+func NewTransformer(t Transform) (binding.Transformer, error) {
+	tr := &transformer{
+		extensions: make(map[string]template, len(t.Extensions)),
+		rename:     t.Rename,
+		remove:     t.Remove,
+	}
+	var err error
+	if tr.eventType, err = parseTemplate(t.Type); err != nil {
+		return nil, fmt.Errorf("invalid type template: %w", err)
+	}
+	if tr.source, err = parseTemplate(t.Source); err != nil {
+		return nil, fmt.Errorf("invalid source template: %w", err)
+	}
+	for name, value := range t.Extensions {
+		if tr.extensions[name], err = parseTemplate(value); err != nil {
+			return nil, fmt.Errorf("invalid template of extension %q: %w", name, err)
+		}
+	}
+	return tr, nil
+}
+
+func (t *transformer) Transform(reader binding.MessageMetadataReader, writer binding.MessageMetadataWriter) error {
+	// Evaluate everything against the original attributes first.
+	eventType := t.eventType.execute(reader)
+	if t.eventType != nil && eventType == "" {
+		return errors.New("the transformed type is empty")
+	}
+	source := t.source.execute(reader)
+	if t.source != nil {
+		if source == "" {
+			return errors.New("the transformed source is empty")
+		}
+		if _, err := url.Parse(source); err != nil {
+			return fmt.Errorf("the transformed source is not a valid URI-reference: %w", err)
+		}
+	}
+	extensions := make(map[string]string, len(t.extensions))
+	for name, tpl := range t.extensions {
+		extensions[name] = tpl.execute(reader)
+	}
+	renamed := make(map[string]interface{}, len(t.rename))
+	for from, to := range t.rename {
+		if v := reader.GetExtension(from); !types.IsZero(v) {
+			renamed[to] = v
+		}
+	}
+
+	for _, name := range t.remove {
+		if err := writer.SetExtension(name, nil); err != nil {
+			return err
+		}
+	}
+	for from := range t.rename {
+		if err := writer.SetExtension(from, nil); err != nil {
+			return err
+		}
+	}
+	for name, value := range renamed {
+		if err := writer.SetExtension(name, value); err != nil {
+			return err
+		}
+	}
+	for name, value := range extensions {
+		var v interface{}
+		if value != "" {
+			v = value
+		}
+		if err := writer.SetExtension(name, v); err != nil {
+			return err
+		}
+	}
+	if t.eventType != nil {
+		if err := setAttribute(reader, writer, spec.Type, eventType); err != nil {
+			return err
+		}
+	}
+	if t.source != nil {
+		if err := setAttribute(reader, writer, spec.Source, source); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func setAttribute(reader binding.MessageMetadataReader, writer binding.MessageMetadataWriter, kind spec.Kind, value string) error {
+	attr, _ := reader.GetAttribute(kind)
+	if attr == nil {
+		return fmt.Errorf("the event doesn't support the %s attribute", kind)
+	}
+	return writer.SetAttribute(attr, value)
+}
+
+// ValidateTemplate returns an error if the template is malformed or references invalid attribute names.
+func ValidateTemplate(s string) error {
+	_, err := parseTemplate(s)
+	return err
+}
+
+// IsStatic returns true if the template doesn't reference any attribute.
+func IsStatic(s string) bool {
+	return !strings.Contains(s, "${")
+}
+
+// templatePart is either a literal or, when attribute is true, the name of an attribute.
+type templatePart struct {
+	value     string
+	attribute bool
+}
+
+// template is a parsed template. A nil template means that the template is empty.
+type template []templatePart
+
+func parseTemplate(s string) (template, error) {
+	var t template
+	for s != "" {
+		start := strings.Index(s, "${")
+		if start < 0 {
+			t = append(t, templatePart{value: s})
+			break
+		}
+		if start > 0 {
+			t = append(t, templatePart{value: s[:start]})
+		}
+		end := strings.IndexByte(s[start:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated reference in %q", s)
+		}
+		name := s[start+2 : start+end]
+		if !validAttributeName.MatchString(name) {
+			return nil, fmt.Errorf("invalid attribute name %q", name)
+		}
+		t = append(t, templatePart{value: name, attribute: true})
+		s = s[start+end+1:]
+	}
+	return t, nil
+}
+
+func (t template) execute(reader binding.MessageMetadataReader) string {
+	var sb strings.Builder
+	for _, p := range t {
+		if !p.attribute {
+			sb.WriteString(p.value)
+			continue
+		}
+		var v interface{}
+		if attr := spec.V1.Attribute(p.value); attr != nil {
+			_, v = reader.GetAttribute(attr.Kind())
+		} else {
+			v = reader.GetExtension(p.value)
+		}
+		if types.IsZero(v) {
+			continue
+		}
+		if s, err := types.Format(v); err == nil {
+			sb.WriteString(s)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,182 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eventtransform
+
+import (
+	"context"
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTransformer(t *testing.T) {
+	tests := map[string]struct {
+		transform Transform
+		want      func(e *cloudevents.Event)
+		wantErr   bool
+	}{
+		"empty transform": {
+			want: func(e *cloudevents.Event) {},
+		},
+		"override type and source": {
+			transform: Transform{
+				Type:   "dev.knative.transformed",
+				Source: "/transformed",
+			},
+			want: func(e *cloudevents.Event) {
+				e.SetType("dev.knative.transformed")
+				e.SetSource("/transformed")
+			},
+		},
+		"templated type and source": {
+			transform: Transform{
+				Type:   "${type}.${region}",
+				Source: "${source}/${subject}",
+			},
+			want: func(e *cloudevents.Event) {
+				e.SetType("dev.knative.example.eu")
+				e.SetSource("/example/orders")
+			},
+		},
+		"set extensions": {
+			transform: Transform{
+				Extensions: map[string]string{
+					"region":   "us",
+					"origin":   "${source}",
+					"priority": "${missing}",
+				},
+			},
+			want: func(e *cloudevents.Event) {
+				e.SetExtension("region", "us")
+				e.SetExtension("origin", "/example")
+				e.SetExtension("priority", nil)
+			},
+		},
+		"rename and remove extensions": {
+			transform: Transform{
+				Rename: map[string]string{"region": "zone", "missing": "other"},
+				Remove: []string{"priority"},
+			},
+			want: func(e *cloudevents.Event) {
+				e.SetExtension("region", nil)
+				e.SetExtension("zone", "eu")
+				e.SetExtension("priority", nil)
+			},
+		},
+		"templates see the original attributes": {
+			transform: Transform{
+				Type:       "${region}",
+				Rename:     map[string]string{"region": "zone"},
+				Extensions: map[string]string{"previoustype": "${type}"},
+			},
+			want: func(e *cloudevents.Event) {
+				e.SetType("eu")
+				e.SetExtension("region", nil)
+				e.SetExtension("zone", "eu")
+				e.SetExtension("previoustype", "dev.knative.example")
+			},
+		},
+		"empty type": {
+			transform: Transform{Type: "${missing}"},
+			wantErr:   true,
+		},
+		"empty source": {
+			transform: Transform{Source: "${missing}"},
+			wantErr:   true,
+		},
+	}
+	for n, tc := range tests {
+		t.Run(n, func(t *testing.T) {
+			tr, err := NewTransformer(tc.transform)
+			if err != nil {
+				t.Fatal("NewTransformer() =", err)
+			}
+
+			got, err := binding.ToEvent(context.Background(), binding.ToMessage(makeEvent()), tr)
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("ToEvent() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+
+			want := makeEvent()
+			tc.want(want)
+			if diff := cmp.Diff(want.Context.AsV1(), got.Context.AsV1()); diff != "" {
+				t.Error("Unexpected event context (-want, +got) =", diff)
+			}
+		})
+	}
+}
+
+func TestValidateTemplate(t *testing.T) {
+	tests := map[string]struct {
+		template string
+		wantErr  bool
+		static   bool
+	}{
+		"empty": {
+			static: true,
+		},
+		"literal": {
+			template: "dev.knative.example",
+			static:   true,
+		},
+		"references": {
+			template: "${type}.${region}-suffix",
+		},
+		"dollar without brace": {
+			template: "$type",
+			static:   true,
+		},
+		"unterminated reference": {
+			template: "${type",
+			wantErr:  true,
+		},
+		"invalid attribute name": {
+			template: "${Type}",
+			wantErr:  true,
+		},
+		"empty attribute name": {
+			template: "${}",
+			wantErr:  true,
+		},
+	}
+	for n, tc := range tests {
+		t.Run(n, func(t *testing.T) {
+			if err := ValidateTemplate(tc.template); tc.wantErr != (err != nil) {
+				t.Errorf("ValidateTemplate() = %v, wantErr %v", err, tc.wantErr)
+			}
+			if got := IsStatic(tc.template); !tc.wantErr && got != tc.static {
+				t.Errorf("IsStatic() = %v, want %v", got, tc.static)
+			}
+		})
+	}
+}
+
+func makeEvent() *cloudevents.Event {
+	e := cloudevents.NewEvent()
+	e.SetID("1234")
+	e.SetType("dev.knative.example")
+	e.SetSource("/example")
+	e.SetSubject("orders")
+	e.SetExtension("region", "eu")
+	e.SetExtension("priority", "high")
+	return &e
+}
@@ -0,0 +1,811 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package jsonschema validates JSON documents against a JSON Schema.
+//
+// It supports the validation keywords of the draft 7 of the specification, and the references
+// to the definitions of the schema itself. The format, content and remote reference keywords
+// are ignored.
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Schema is a compiled JSON Schema.
+type Schema struct {
+	root *schema
+}
+
+// ValidationError is the error returned when a document doesn't match its JSON Schema.
+type ValidationError struct {
+	// Path is the JSON pointer of the invalid value in the document.
+	Path    string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	path := e.Path
+	if path == "" {
+		path = "/"
+	}
+	return fmt.Sprintf("%s: %s", path, e.Message)
+}
+
+type patternSchema struct {
+	pattern *regexp.Regexp
+	schema  *schema
+}
+
+type schema struct {
+	// always is set for the boolean schemas.
+	always *bool
+
+	types    []string
+	enum     []interface{}
+	constant *interface{}
+
+	minimum          *float64
+	maximum          *float64
+	exclusiveMinimum *float64
+	exclusiveMaximum *float64
+	multipleOf       *float64
+
+	minLength *int
+	maxLength *int
+	pattern   *regexp.Regexp
+
+	items           *schema
+	tupleItems      []*schema
+	additionalItems *schema
+	minItems        *int
+	maxItems        *int
+	uniqueItems     bool
+	contains        *schema
+
+	properties           map[string]*schema
+	patternProperties    []patternSchema
+	additionalProperties *schema
+	required             []string
+	minProperties        *int
+	maxProperties        *int
+	propertyNames        *schema
+	dependencies         map[string]*schema
+	dependentRequired    map[string][]string
+
+	allOf []*schema
+	anyOf []*schema
+	oneOf []*schema
+	not   *schema
+
+	ifSchema   *schema
+	thenSchema *schema
+	elseSchema *schema
+}
+
+// maxDepth is the maximum number of nested schemas applied to a document, bounding the
+// recursion of the validation.
+const maxDepth = 512
+
+// compiler compiles a JSON Schema document, resolving its references.
+type compiler struct {
+	document  interface{}
+	refs      map[string]*schema
+	locations map[*schema]string
+}
+
+// Compile compiles the JSON Schema.
+func Compile(data []byte) (*Schema, error) {
+	var document interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("the schema isn't valid JSON: %w", err)
+	}
+	c := &compiler{document: document, refs: make(map[string]*schema), locations: make(map[*schema]string)}
+	root, err := c.compile(document, "#")
+	if err != nil {
+		return nil, err
+	}
+	if err := c.checkLeftRecursion(root); err != nil {
+		return nil, err
+	}
+	return &Schema{root: root}, nil
+}
+
+// Validate validates the JSON document against the schema, returning a *ValidationError when
+// it doesn't match.
+func (s *Schema) Validate(data []byte) error {
+	var document interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return &ValidationError{Message: fmt.Sprintf("the document isn't valid JSON: %v", err)}
+	}
+	return s.ValidateValue(document)
+}
+
+// ValidateValue validates a document decoded by encoding/json against the schema, returning
+// a *ValidationError when it doesn't match.
+func (s *Schema) ValidateValue(document interface{}) error {
+	return s.root.validate(document, "", 0)
+}
+
+func (c *compiler) compile(v interface{}, location string) (*schema, error) {
+	if b, ok := v.(bool); ok {
+		return &schema{always: &b}, nil
+	}
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s: a schema must be an object or a boolean", location)
+	}
+	if ref, ok := m["$ref"]; ok {
+		// The other keywords next to a reference are ignored.
+		r, ok := ref.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s/$ref: must be a string", location)
+		}
+		return c.resolve(r, location)
+	}
+
+	s := &schema{}
+	var err error
+	p := &parser{m: m, location: location}
+
+	switch t := m["type"].(type) {
+	case nil:
+	case string:
+		s.types = []string{t}
+	case []interface{}:
+		for _, tt := range t {
+			name, ok := tt.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s/type: must be a string or an array of strings", location)
+			}
+			s.types = append(s.types, name)
+		}
+	default:
+		return nil, fmt.Errorf("%s/type: must be a string or an array of strings", location)
+	}
+	for _, t := range s.types {
+		switch t {
+		case "null", "boolean", "object", "array", "number", "integer", "string":
+		default:
+			return nil, fmt.Errorf("%s/type: unknown type %q", location, t)
+		}
+	}
+	if enum, ok := m["enum"]; ok {
+		if s.enum, ok = enum.([]interface{}); !ok {
+			return nil, fmt.Errorf("%s/enum: must be an array", location)
+		}
+	}
+	if constant, ok := m["const"]; ok {
+		s.constant = &constant
+	}
+
+	s.minimum = p.number("minimum")
+	s.maximum = p.number("maximum")
+	s.exclusiveMinimum = p.number("exclusiveMinimum")
+	s.exclusiveMaximum = p.number("exclusiveMaximum")
+	s.multipleOf = p.number("multipleOf")
+	s.minLength = p.integer("minLength")
+	s.maxLength = p.integer("maxLength")
+	s.minItems = p.integer("minItems")
+	s.maxItems = p.integer("maxItems")
+	s.minProperties = p.integer("minProperties")
+	s.maxProperties = p.integer("maxProperties")
+	s.uniqueItems = p.boolean("uniqueItems")
+	s.required = p.strings("required")
+	if p.err != nil {
+		return nil, p.err
+	}
+	if s.multipleOf != nil && *s.multipleOf <= 0 {
+		return nil, fmt.Errorf("%s/multipleOf: must be strictly positive", location)
+	}
+
+	if pattern, ok := m["pattern"]; ok {
+		if s.pattern, err = compilePattern(pattern, location+"/pattern"); err != nil {
+			return nil, err
+		}
+	}
+
+	if s.additionalItems, err = c.optional(m, "additionalItems", location); err != nil {
+		return nil, err
+	}
+	switch items := m["items"].(type) {
+	case nil:
+	case []interface{}:
+		if s.tupleItems, err = c.list(items, location+"/items"); err != nil {
+			return nil, err
+		}
+	default:
+		if s.items, err = c.compile(items, location+"/items"); err != nil {
+			return nil, err
+		}
+	}
+	if s.contains, err = c.optional(m, "contains", location); err != nil {
+		return nil, err
+	}
+
+	if s.properties, err = c.object(m, "properties", location); err != nil {
+		return nil, err
+	}
+	if patternProperties, ok := m["patternProperties"]; ok {
+		pm, ok := patternProperties.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s/patternProperties: must be an object", location)
+		}
+		for pattern, sub := range pm {
+			re, err := compilePattern(pattern, location+"/patternProperties")
+			if err != nil {
+				return nil, err
+			}
+			compiled, err := c.compile(sub, location+"/patternProperties/"+escape(pattern))
+			if err != nil {
+				return nil, err
+			}
+			s.patternProperties = append(s.patternProperties, patternSchema{pattern: re, schema: compiled})
+		}
+	}
+	if s.additionalProperties, err = c.optional(m, "additionalProperties", location); err != nil {
+		return nil, err
+	}
+	if s.propertyNames, err = c.optional(m, "propertyNames", location); err != nil {
+		return nil, err
+	}
+	if dependencies, ok := m["dependencies"]; ok {
+		dm, ok := dependencies.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s/dependencies: must be an object", location)
+		}
+		for name, dependency := range dm {
+			if names, ok := dependency.([]interface{}); ok {
+				if s.dependentRequired == nil {
+					s.dependentRequired = make(map[string][]string)
+				}
+				for _, n := range names {
+					str, ok := n.(string)
+					if !ok {
+						return nil, fmt.Errorf("%s/dependencies/%s: must be an array of strings", location, escape(name))
+					}
+					s.dependentRequired[name] = append(s.dependentRequired[name], str)
+				}
+				continue
+			}
+			compiled, err := c.compile(dependency, location+"/dependencies/"+escape(name))
+			if err != nil {
+				return nil, err
+			}
+			if s.dependencies == nil {
+				s.dependencies = make(map[string]*schema)
+			}
+			s.dependencies[name] = compiled
+		}
+	}
+
+	for keyword, target := range map[string]*[]*schema{"allOf": &s.allOf, "anyOf": &s.anyOf, "oneOf": &s.oneOf} {
+		sub, ok := m[keyword]
+		if !ok {
+			continue
+		}
+		list, ok := sub.([]interface{})
+		if !ok || len(list) == 0 {
+			return nil, fmt.Errorf("%s/%s: must be a non-empty array", location, keyword)
+		}
+		if *target, err = c.list(list, location+"/"+keyword); err != nil {
+			return nil, err
+		}
+	}
+	if s.not, err = c.optional(m, "not", location); err != nil {
+		return nil, err
+	}
+	if s.ifSchema, err = c.optional(m, "if", location); err != nil {
+		return nil, err
+	}
+	if s.thenSchema, err = c.optional(m, "then", location); err != nil {
+		return nil, err
+	}
+	if s.elseSchema, err = c.optional(m, "else", location); err != nil {
+		return nil, err
+	}
+	c.locations[s] = location
+	return s, nil
+}
+
+// resolve compiles the schema referenced by ref, a JSON pointer in the document. The
+// referenced schemas are compiled once, so that they can be recursive.
+func (c *compiler) resolve(ref, location string) (*schema, error) {
+	if s, ok := c.refs[ref]; ok {
+		return s, nil
+	}
+	if ref != "#" && !strings.HasPrefix(ref, "#/") {
+		return nil, fmt.Errorf("%s/$ref: only the references to the schema itself are supported, got %q", location, ref)
+	}
+	target := c.document
+	if ref != "#" {
+		for _, token := range strings.Split(ref[2:], "/") {
+			token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+			switch t := target.(type) {
+			case map[string]interface{}:
+				var ok bool
+				if target, ok = t[token]; !ok {
+					return nil, fmt.Errorf("%s/$ref: %q not found", location, ref)
+				}
+			default:
+				return nil, fmt.Errorf("%s/$ref: %q not found", location, ref)
+			}
+		}
+	}
+	s := &schema{}
+	c.refs[ref] = s
+	compiled, err := c.compile(target, ref)
+	if err != nil {
+		return nil, err
+	}
+	*s = *compiled
+	c.locations[s] = ref
+	return s, nil
+}
+
+// checkLeftRecursion rejects the schemas that reference themselves through the keywords
+// applying to the same value, like allOf or not, as validating them would never end.
+func (c *compiler) checkLeftRecursion(root *schema) error {
+	const (
+		visiting = iota + 1
+		visited
+	)
+	state := make(map[*schema]int)
+	var visit func(s *schema) error
+	visit = func(s *schema) error {
+		switch state[s] {
+		case visiting:
+			return fmt.Errorf("%s: the schema references itself without validating a nested value", c.locations[s])
+		case visited:
+			return nil
+		}
+		state[s] = visiting
+		for _, sub := range s.sameValue() {
+			if err := visit(sub); err != nil {
+				return err
+			}
+		}
+		state[s] = visited
+		return nil
+	}
+	// The schemas applying to nested values start new chains, so all the compiled schemas are
+	// visited.
+	for s := range c.locations {
+		if err := visit(s); err != nil {
+			return err
+		}
+	}
+	return visit(root)
+}
+
+// sameValue returns the subschemas applying to the value validated by the schema itself.
+func (s *schema) sameValue() []*schema {
+	subs := make([]*schema, 0, len(s.allOf)+len(s.anyOf)+len(s.oneOf)+len(s.dependencies)+4)
+	subs = append(subs, s.allOf...)
+	subs = append(subs, s.anyOf...)
+	subs = append(subs, s.oneOf...)
+	for _, dependency := range s.dependencies {
+		subs = append(subs, dependency)
+	}
+	for _, sub := range []*schema{s.not, s.ifSchema, s.thenSchema, s.elseSchema} {
+		if sub != nil {
+			subs = append(subs, sub)
+		}
+	}
+	return subs
+}
+
+func (c *compiler) optional(m map[string]interface{}, keyword, location string) (*schema, error) {
+	v, ok := m[keyword]
+	if !ok {
+		return nil, nil
+	}
+	return c.compile(v, location+"/"+keyword)
+}
+
+func (c *compiler) list(l []interface{}, location string) ([]*schema, error) {
+	schemas := make([]*schema, 0, len(l))
+	for i, v := range l {
+		s, err := c.compile(v, fmt.Sprintf("%s/%d", location, i))
+		if err != nil {
+			return nil, err
+		}
+		schemas = append(schemas, s)
+	}
+	return schemas, nil
+}
+
+func (c *compiler) object(m map[string]interface{}, keyword, location string) (map[string]*schema, error) {
+	v, ok := m[keyword]
+	if !ok {
+		return nil, nil
+	}
+	om, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s/%s: must be an object", location, keyword)
+	}
+	schemas := make(map[string]*schema, len(om))
+	for name, sub := range om {
+		s, err := c.compile(sub, location+"/"+keyword+"/"+escape(name))
+		if err != nil {
+			return nil, err
+		}
+		schemas[name] = s
+	}
+	return schemas, nil
+}
+
+// parser reads the keywords of a schema holding numbers, booleans and strings, keeping the
+// first error.
+type parser struct {
+	m        map[string]interface{}
+	location string
+	err      error
+}
+
+func (p *parser) number(keyword string) *float64 {
+	v, ok := p.m[keyword]
+	if !ok {
+		return nil
+	}
+	n, ok := v.(float64)
+	if !ok && p.err == nil {
+		p.err = fmt.Errorf("%s/%s: must be a number", p.location, keyword)
+	}
+	return &n
+}
+
+func (p *parser) integer(keyword string) *int {
+	n := p.number(keyword)
+	if n == nil {
+		return nil
+	}
+	if (*n < 0 || *n != math.Trunc(*n)) && p.err == nil {
+		p.err = fmt.Errorf("%s/%s: must be a non-negative integer", p.location, keyword)
+	}
+	i := int(*n)
+	return &i
+}
+
+func (p *parser) boolean(keyword string) bool {
+	v, ok := p.m[keyword]
+	if !ok {
+		return false
+	}
+	b, ok := v.(bool)
+	if !ok && p.err == nil {
+		p.err = fmt.Errorf("%s/%s: must be a boolean", p.location, keyword)
+	}
+	return b
+}
+
+func (p *parser) strings(keyword string) []string {
+	v, ok := p.m[keyword]
+	if !ok {
+		return nil
+	}
+	l, ok := v.([]interface{})
+	if !ok {
+		if p.err == nil {
+			p.err = fmt.Errorf("%s/%s: must be an array of strings", p.location, keyword)
+		}
+		return nil
+	}
+	strs := make([]string, 0, len(l))
+	for _, s := range l {
+		str, ok := s.(string)
+		if !ok && p.err == nil {
+			p.err = fmt.Errorf("%s/%s: must be an array of strings", p.location, keyword)
+		}
+		strs = append(strs, str)
+	}
+	return strs
+}
+
+func compilePattern(pattern interface{}, location string) (*regexp.Regexp, error) {
+	str, ok := pattern.(string)
+	if !ok {
+		return nil, fmt.Errorf("%s: must be a string", location)
+	}
+	re, err := regexp.Compile(str)
+	if err != nil {
+		return nil, fmt.Errorf("%s: invalid regular expression: %w", location, err)
+	}
+	return re, nil
+}
+
+// escape escapes a reference token of a JSON pointer.
+func escape(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+func invalid(path, format string, args ...interface{}) error {
+	return &ValidationError{Path: path, Message: fmt.Sprintf(format, args...)}
+}
+
+// typeOf returns the JSON type of a value decoded by encoding/json.
+func typeOf(v interface{}) string {
+	switch n := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case float64:
+		if n == math.Trunc(n) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func (s *schema) validate(v interface{}, path string, depth int) error {
+	if depth > maxDepth {
+		return invalid(path, "the value is nested in more than %d schemas", maxDepth)
+	}
+
+	if s.always != nil {
+		if !*s.always {
+			return invalid(path, "no value is allowed")
+		}
+		return nil
+	}
+
+	if len(s.types) > 0 {
+		t := typeOf(v)
+		matches := false
+		for _, want := range s.types {
+			if want == t || (want == "number" && t == "integer") {
+				matches = true
+				break
+			}
+		}
+		if !matches {
+			return invalid(path, "expected %s, got %s", strings.Join(s.types, " or "), t)
+		}
+	}
+	if s.enum != nil {
+		found := false
+		for _, e := range s.enum {
+			if reflect.DeepEqual(e, v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return invalid(path, "the value isn't one of the enumerated values")
+		}
+	}
+	if s.constant != nil && !reflect.DeepEqual(*s.constant, v) {
+		return invalid(path, "the value isn't the constant value")
+	}
+
+	var err error
+	switch value := v.(type) {
+	case float64:
+		err = s.validateNumber(value, path)
+	case string:
+		err = s.validateString(value, path)
+	case []interface{}:
+		err = s.validateArray(value, path, depth)
+	case map[string]interface{}:
+		err = s.validateObject(value, path, depth)
+	}
+	if err != nil {
+		return err
+	}
+	return s.validateCombinators(v, path, depth)
+}
+
+func (s *schema) validateNumber(n float64, path string) error {
+	switch {
+	case s.minimum != nil && n < *s.minimum:
+		return invalid(path, "%v is lower than the minimum %v", n, *s.minimum)
+	case s.maximum != nil && n > *s.maximum:
+		return invalid(path, "%v is greater than the maximum %v", n, *s.maximum)
+	case s.exclusiveMinimum != nil && n <= *s.exclusiveMinimum:
+		return invalid(path, "%v isn't greater than the exclusive minimum %v", n, *s.exclusiveMinimum)
+	case s.exclusiveMaximum != nil && n >= *s.exclusiveMaximum:
+		return invalid(path, "%v isn't lower than the exclusive maximum %v", n, *s.exclusiveMaximum)
+	}
+	if s.multipleOf != nil {
+		if q := n / *s.multipleOf; q != math.Trunc(q) {
+			return invalid(path, "%v isn't a multiple of %v", n, *s.multipleOf)
+		}
+	}
+	return nil
+}
+
+func (s *schema) validateString(str, path string) error {
+	length := utf8.RuneCountInString(str)
+	switch {
+	case s.minLength != nil && length < *s.minLength:
+		return invalid(path, "the string is shorter than %d characters", *s.minLength)
+	case s.maxLength != nil && length > *s.maxLength:
+		return invalid(path, "the string is longer than %d characters", *s.maxLength)
+	case s.pattern != nil && !s.pattern.MatchString(str):
+		return invalid(path, "the string doesn't match the pattern %q", s.pattern.String())
+	}
+	return nil
+}
+
+func (s *schema) validateArray(items []interface{}, path string, depth int) error {
+	switch {
+	case s.minItems != nil && len(items) < *s.minItems:
+		return invalid(path, "the array has less than %d items", *s.minItems)
+	case s.maxItems != nil && len(items) > *s.maxItems:
+		return invalid(path, "the array has more than %d items", *s.maxItems)
+	}
+	if s.uniqueItems {
+		for i := range items {
+			for j := 0; j < i; j++ {
+				if reflect.DeepEqual(items[i], items[j]) {
+					return invalid(path, "the items %d and %d are equal", j, i)
+				}
+			}
+		}
+	}
+	for i, item := range items {
+		sub := s.items
+		if s.tupleItems != nil {
+			sub = s.additionalItems
+			if i < len(s.tupleItems) {
+				sub = s.tupleItems[i]
+			}
+		}
+		if sub == nil {
+			continue
+		}
+		if err := sub.validate(item, fmt.Sprintf("%s/%d", path, i), depth+1); err != nil {
+			return err
+		}
+	}
+	if s.contains != nil {
+		found := false
+		for i, item := range items {
+			if s.contains.validate(item, fmt.Sprintf("%s/%d", path, i), depth+1) == nil {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return invalid(path, "no item matches the contains schema")
+		}
+	}
+	return nil
+}
+
+func (s *schema) validateObject(object map[string]interface{}, path string, depth int) error {
+	switch {
+	case s.minProperties != nil && len(object) < *s.minProperties:
+		return invalid(path, "the object has less than %d properties", *s.minProperties)
+	case s.maxProperties != nil && len(object) > *s.maxProperties:
+		return invalid(path, "the object has more than %d properties", *s.maxProperties)
+	}
+	for _, name := range s.required {
+		if _, ok := object[name]; !ok {
+			return invalid(path, "the property %q is required", name)
+		}
+	}
+	for name, required := range s.dependentRequired {
+		if _, ok := object[name]; !ok {
+			continue
+		}
+		for _, r := range required {
+			if _, ok := object[r]; !ok {
+				return invalid(path, "the property %q is required by %q", r, name)
+			}
+		}
+	}
+	for name, dependency := range s.dependencies {
+		if _, ok := object[name]; !ok {
+			continue
+		}
+		if err := dependency.validate(object, path, depth+1); err != nil {
+			return err
+		}
+	}
+	for name, value := range object {
+		propertyPath := path + "/" + escape(name)
+		if s.propertyNames != nil {
+			if err := s.propertyNames.validate(name, propertyPath, depth+1); err != nil {
+				return err
+			}
+		}
+		matched := false
+		if sub, ok := s.properties[name]; ok {
+			matched = true
+			if err := sub.validate(value, propertyPath, depth+1); err != nil {
+				return err
+			}
+		}
+		for _, ps := range s.patternProperties {
+			if !ps.pattern.MatchString(name) {
+				continue
+			}
+			matched = true
+			if err := ps.schema.validate(value, propertyPath, depth+1); err != nil {
+				return err
+			}
+		}
+		if !matched && s.additionalProperties != nil {
+			if err := s.additionalProperties.validate(value, propertyPath, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *schema) validateCombinators(v interface{}, path string, depth int) error {
+	for _, sub := range s.allOf {
+		if err := sub.validate(v, path, depth+1); err != nil {
+			return err
+		}
+	}
+	if s.anyOf != nil {
+		var firstErr error
+		for _, sub := range s.anyOf {
+			err := sub.validate(v, path, depth+1)
+			if err == nil {
+				firstErr = nil
+				break
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+		if firstErr != nil {
+			return invalid(path, "the value doesn't match any of the anyOf schemas: %v", firstErr)
+		}
+	}
+	if s.oneOf != nil {
+		matches := 0
+		for _, sub := range s.oneOf {
+			if sub.validate(v, path, depth+1) == nil {
+				matches++
+			}
+		}
+		if matches != 1 {
+			return invalid(path, "the value matches %d of the oneOf schemas instead of 1", matches)
+		}
+	}
+	if s.not != nil && s.not.validate(v, path, depth+1) == nil {
+		return invalid(path, "the value matches the not schema")
+	}
+	if s.ifSchema != nil {
+		if s.ifSchema.validate(v, path, depth+1) == nil {
+			if s.thenSchema != nil {
+				return s.thenSchema.validate(v, path, depth+1)
+			}
+		} else if s.elseSchema != nil {
+			return s.elseSchema.validate(v, path, depth+1)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jsonschema
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCompile(t *testing.T) {
+	for name, schema := range map[string]string{
+		"not json":                `{"type":`,
+		"not an object":           `"string"`,
+		"unknown type":            `{"type": "date"}`,
+		"invalid minLength":       `{"minLength": -1}`,
+		"invalid pattern":         `{"pattern": "("}`,
+		"empty anyOf":             `{"anyOf": []}`,
+		"invalid nested schema":   `{"properties": {"a": 1}}`,
+		"remote reference":        `{"$ref": "http://example.com/schema.json"}`,
+		"missing local reference": `{"$ref": "#/definitions/missing"}`,
+		"left recursion":          `{"allOf": [{"$ref": "#"}]}`,
+		"nested left recursion": `{
+			"definitions": {"a": {"anyOf": [{"type": "string"}, {"not": {"$ref": "#/definitions/a"}}]}},
+			"properties": {"a": {"$ref": "#/definitions/a"}}
+		}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Compile([]byte(schema)); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	testCases := map[string]struct {
+		schema   string
+		document string
+		wantPath string
+	}{
+		"true": {
+			schema:   `true`,
+			document: `{"a": 1}`,
+		},
+		"false": {
+			schema:   `false`,
+			document: `{}`,
+			wantPath: "/",
+		},
+		"type": {
+			schema:   `{"type": "object"}`,
+			document: `[]`,
+			wantPath: "/",
+		},
+		"integer is a number": {
+			schema:   `{"type": "number"}`,
+			document: `1`,
+		},
+		"number isn't an integer": {
+			schema:   `{"type": ["integer", "null"]}`,
+			document: `1.5`,
+			wantPath: "/",
+		},
+		"enum": {
+			schema:   `{"enum": ["a", {"b": 1}]}`,
+			document: `{"b": 1}`,
+		},
+		"not in enum": {
+			schema:   `{"enum": ["a", "b"]}`,
+			document: `"c"`,
+			wantPath: "/",
+		},
+		"const": {
+			schema:   `{"const": 1}`,
+			document: `2`,
+			wantPath: "/",
+		},
+		"number range": {
+			schema:   `{"minimum": 1, "exclusiveMaximum": 3, "multipleOf": 0.5}`,
+			document: `2.5`,
+		},
+		"above exclusive maximum": {
+			schema:   `{"exclusiveMaximum": 3}`,
+			document: `3`,
+			wantPath: "/",
+		},
+		"not a multiple": {
+			schema:   `{"multipleOf": 2}`,
+			document: `3`,
+			wantPath: "/",
+		},
+		"string length counts characters": {
+			schema:   `{"maxLength": 2}`,
+			document: `"éé"`,
+		},
+		"pattern": {
+			schema:   `{"properties": {"id": {"pattern": "^[a-z]+$"}}}`,
+			document: `{"id": "A1"}`,
+			wantPath: "/id",
+		},
+		"required": {
+			schema:   `{"type": "object", "required": ["id"]}`,
+			document: `{"name": "a"}`,
+			wantPath: "/",
+		},
+		"nested properties": {
+			schema:   `{"properties": {"user": {"properties": {"age": {"type": "integer"}}}}}`,
+			document: `{"user": {"age": "old"}}`,
+			wantPath: "/user/age",
+		},
+		"additional properties": {
+			schema:   `{"properties": {"a": {}}, "patternProperties": {"^x-": {}}, "additionalProperties": false}`,
+			document: `{"a": 1, "x-b": 2, "c": 3}`,
+			wantPath: "/c",
+		},
+		"property names": {
+			schema:   `{"propertyNames": {"maxLength": 3}}`,
+			document: `{"abcd": 1}`,
+			wantPath: "/abcd",
+		},
+		"dependencies": {
+			schema:   `{"dependencies": {"a": ["b"]}}`,
+			document: `{"a": 1}`,
+			wantPath: "/",
+		},
+		"items": {
+			schema:   `{"items": {"type": "string"}, "minItems": 1, "uniqueItems": true}`,
+			document: `["a", 1]`,
+			wantPath: "/1",
+		},
+		"unique items": {
+			schema:   `{"uniqueItems": true}`,
+			document: `[{"a": 1}, {"a": 1}]`,
+			wantPath: "/",
+		},
+		"tuple items": {
+			schema:   `{"items": [{"type": "string"}], "additionalItems": {"type": "integer"}}`,
+			document: `["a", 1, 2]`,
+		},
+		"contains": {
+			schema:   `{"contains": {"const": 2}}`,
+			document: `[1, 3]`,
+			wantPath: "/",
+		},
+		"anyOf": {
+			schema:   `{"anyOf": [{"type": "string"}, {"type": "integer"}]}`,
+			document: `1`,
+		},
+		"oneOf matching both": {
+			schema:   `{"oneOf": [{"type": "number"}, {"type": "integer"}]}`,
+			document: `1`,
+			wantPath: "/",
+		},
+		"not": {
+			schema:   `{"not": {"type": "null"}}`,
+			document: `null`,
+			wantPath: "/",
+		},
+		"if then else": {
+			schema:   `{"if": {"properties": {"kind": {"const": "a"}}}, "then": {"required": ["a"]}, "else": {"required": ["b"]}}`,
+			document: `{"kind": "b", "b": 1}`,
+		},
+		"recursive reference": {
+			schema: `{
+				"definitions": {"node": {"type": "object", "properties": {"children": {"type": "array", "items": {"$ref": "#/definitions/node"}}}}},
+				"$ref": "#/definitions/node"
+			}`,
+			document: `{"children": [{"children": [{"children": 1}]}]}`,
+			wantPath: "/children/0/children/0/children",
+		},
+		"not json": {
+			schema:   `{}`,
+			document: `{`,
+			wantPath: "/",
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			schema, err := Compile([]byte(tc.schema))
+			if err != nil {
+				t.Fatal("Failed to compile the schema:", err)
+			}
+			err = schema.Validate([]byte(tc.document))
+			if tc.wantPath == "" {
+				if err != nil {
+					t.Error("Unexpected error:", err)
+				}
+				return
+			}
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("Expected a ValidationError, got %v", err)
+			}
+			if got := validationErr.Path; got != tc.wantPath && !(got == "" && tc.wantPath == "/") {
+				t.Errorf("Unexpected path of %q, want %s", err, tc.wantPath)
+			}
+		})
+	}
+}
+
+func TestValidateMaxDepth(t *testing.T) {
+	schema, err := Compile([]byte(`{"type": "array", "items": {"$ref": "#"}}`))
+	if err != nil {
+		t.Fatal("Failed to compile the schema:", err)
+	}
+	if err := schema.Validate([]byte(strings.Repeat("[", maxDepth) + strings.Repeat("]", maxDepth))); err != nil {
+		t.Error("Unexpected error:", err)
+	}
+	err = schema.Validate([]byte(strings.Repeat("[", maxDepth+2) + strings.Repeat("]", maxDepth+2)))
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Expected a ValidationError, got %v", err)
+	}
+}
@@ -0,0 +1,282 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kncloudevents
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	nethttp "net/http"
+	"sync"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/rickb777/date/period"
+
+	v1 "knative.dev/eventing/pkg/apis/duck/v1"
+)
+
+// defaultMaxBatchDelay is the default maximum time an event waits for its batch to be full.
+const defaultMaxBatchDelay = time.Second
+
+// ErrBatcherClosed is the error of the events added to a closed Batcher.
+var ErrBatcherClosed = errors.New("batcher is closed")
+
+// BatchConfig configures the batches of events sent to a destination.
+type BatchConfig struct {
+	// MaxSize is the maximum number of events in a batch.
+	MaxSize int `json:"maxSize"`
+	// MaxDelay is the maximum time an event waits for its batch to be full.
+	MaxDelay time.Duration `json:"maxDelay"`
+}
+
+// BatchConfigFromDeliverySpec returns the batch configuration of the DeliverySpec, nil when the
+// events are sent one by one.
+func BatchConfigFromDeliverySpec(spec v1.DeliverySpec) (*BatchConfig, error) {
+	if spec.MaxBatchSize == nil {
+		return nil, nil
+	}
+	config := &BatchConfig{
+		MaxSize:  int(*spec.MaxBatchSize),
+		MaxDelay: defaultMaxBatchDelay,
+	}
+	if spec.MaxBatchDelay != nil {
+		delay, err := period.Parse(*spec.MaxBatchDelay)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse Spec.MaxBatchDelay: %w", err)
+		}
+		config.MaxDelay, _ = delay.Duration()
+	}
+	return config, nil
+}
+
+// IsBatch returns true if the headers are the ones of a request in the CloudEvents batched
+// content mode.
+func IsBatch(header nethttp.Header) bool {
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	return err == nil && mediaType == cloudevents.ApplicationCloudEventsBatchJSON
+}
+
+// WriteHTTPRequestWithBatch writes the events to the request in the CloudEvents batched content
+// mode, adding the additional headers.
+func WriteHTTPRequestWithBatch(ctx context.Context, events []*cloudevents.Event, req *nethttp.Request, additionalHeaders nethttp.Header) error {
+	body, err := json.Marshal(events)
+	if err != nil {
+		return fmt.Errorf("failed to encode the batch: %w", err)
+	}
+	for k, v := range additionalHeaders {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", cloudevents.ApplicationCloudEventsBatchJSON)
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	return nil
+}
+
+// ReadBatch reads the events of a body in the CloudEvents batched content mode, failing if any
+// of them isn't a valid event.
+func ReadBatch(body io.Reader) ([]*cloudevents.Event, error) {
+	events, errs, err := ReadBatchEntries(body)
+	if err != nil {
+		return nil, err
+	}
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return events, nil
+}
+
+// ReadBatchEntries reads the events of a body in the CloudEvents batched content mode. The
+// entries of the batch which aren't valid events are nil, with their error at the same index,
+// so that the other events can be handled. The returned error is set when the body isn't a
+// batch.
+func ReadBatchEntries(body io.Reader) ([]*cloudevents.Event, []error, error) {
+	var entries []json.RawMessage
+	if err := json.NewDecoder(body).Decode(&entries); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode the batch: %w", err)
+	}
+	events := make([]*cloudevents.Event, len(entries))
+	errs := make([]error, len(entries))
+	for i, entry := range entries {
+		event := cloudevents.NewEvent()
+		if err := json.Unmarshal(entry, &event); err != nil {
+			errs[i] = fmt.Errorf("the event %d of the batch is invalid: %w", i, err)
+			continue
+		}
+		if err := event.Validate(); err != nil {
+			errs[i] = fmt.Errorf("the event %d of the batch is invalid: %w", i, err)
+			continue
+		}
+		events[i] = &event
+	}
+	return events, errs, nil
+}
+
+// BatchResult is the result of an event of a batch, in the response to a batch request.
+type BatchResult struct {
+	// Index is the position of the event in the batch.
+	Index int `json:"index"`
+	// ID is the id of the event, empty when the event is invalid.
+	ID string `json:"id,omitempty"`
+	// Status is the HTTP status code of the event, as if it was sent on its own.
+	Status int `json:"status"`
+	// Error describes why the event wasn't accepted.
+	Error string `json:"error,omitempty"`
+}
+
+// BatchResponse is the body of the response to a batch request.
+type BatchResponse struct {
+	Results []BatchResult `json:"results"`
+}
+
+// WriteBatchResponse writes the results of the events of a batch. The status code of the
+// response is the one of the events when they all share it, 207 Multi-Status otherwise.
+func WriteBatchResponse(writer nethttp.ResponseWriter, results []BatchResult) error {
+	statusCode := nethttp.StatusAccepted
+	for i, result := range results {
+		if i == 0 {
+			statusCode = result.Status
+		} else if result.Status != statusCode {
+			statusCode = nethttp.StatusMultiStatus
+			break
+		}
+	}
+	body, err := json.Marshal(BatchResponse{Results: results})
+	if err != nil {
+		writer.WriteHeader(nethttp.StatusInternalServerError)
+		return fmt.Errorf("failed to encode the batch response: %w", err)
+	}
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(statusCode)
+	_, err = writer.Write(body)
+	return err
+}
+
+// Batcher accumulates events and sends them in batches, once a batch is full or its first event
+// waited for the maximum delay. The batches are sent concurrently.
+type Batcher struct {
+	config BatchConfig
+	send   func(events []*cloudevents.Event) error
+
+	mutex   sync.Mutex
+	pending []batchedEvent
+	// generation identifies the pending batch, so that the timer of a batch which was already
+	// sent doesn't send the next one.
+	generation uint64
+	timer      *time.Timer
+	closed     bool
+	sending    sync.WaitGroup
+}
+
+type batchedEvent struct {
+	event *cloudevents.Event
+	done  func(error)
+}
+
+// NewBatcher returns a Batcher sending the batches with send.
+func NewBatcher(config BatchConfig, send func(events []*cloudevents.Event) error) *Batcher {
+	if config.MaxSize < 1 {
+		config.MaxSize = 1
+	}
+	if config.MaxDelay <= 0 {
+		config.MaxDelay = defaultMaxBatchDelay
+	}
+	return &Batcher{
+		config: config,
+		send:   send,
+	}
+}
+
+// Config returns the configuration of the Batcher.
+func (b *Batcher) Config() BatchConfig {
+	return b.config
+}
+
+// Add adds the event to the pending batch. done, when not nil, is called with the result of the
+// batch once it's sent.
+func (b *Batcher) Add(event *cloudevents.Event, done func(error)) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.closed {
+		if done != nil {
+			done(ErrBatcherClosed)
+		}
+		return
+	}
+	b.pending = append(b.pending, batchedEvent{event: event, done: done})
+	if len(b.pending) >= b.config.MaxSize {
+		b.flushLocked()
+		return
+	}
+	if len(b.pending) == 1 {
+		generation := b.generation
+		b.timer = time.AfterFunc(b.config.MaxDelay, func() {
+			b.mutex.Lock()
+			defer b.mutex.Unlock()
+			if b.generation == generation {
+				b.flushLocked()
+			}
+		})
+	}
+}
+
+// Close sends the pending batch and waits for the batches being sent. The events added
+// afterwards fail with ErrBatcherClosed.
+func (b *Batcher) Close() {
+	b.mutex.Lock()
+	b.closed = true
+	b.flushLocked()
+	b.mutex.Unlock()
+	b.sending.Wait()
+}
+
+func (b *Batcher) flushLocked() {
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	b.generation++
+	if len(b.pending) == 0 {
+		return
+	}
+	batch := b.pending
+	b.pending = nil
+
+	b.sending.Add(1)
+	go func() {
+		defer b.sending.Done()
+		events := make([]*cloudevents.Event, len(batch))
+		for i, e := range batch {
+			events[i] = e.event
+		}
+		err := b.send(events)
+		for _, e := range batch {
+			if e.done != nil {
+				e.done(err)
+			}
+		}
+	}()
+}
@@ -0,0 +1,164 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kncloudevents
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/stretchr/testify/require"
+	"knative.dev/pkg/ptr"
+
+	v1 "knative.dev/eventing/pkg/apis/duck/v1"
+)
+
+func makeBatchEvents(n int) []*cloudevents.Event {
+	events := make([]*cloudevents.Event, n)
+	for i := range events {
+		e := cloudevents.NewEvent()
+		e.SetID(fmt.Sprint(i))
+		e.SetType("example.type")
+		e.SetSource("example/source")
+		events[i] = &e
+	}
+	return events
+}
+
+func TestBatchConfigFromDeliverySpec(t *testing.T) {
+	testcases := []struct {
+		name          string
+		maxBatchSize  *int32
+		maxBatchDelay *string
+		want          *BatchConfig
+		wantErr       bool
+	}{{
+		name: "Not batched",
+	}, {
+		name:         "Default delay",
+		maxBatchSize: ptr.Int32(10),
+		want:         &BatchConfig{MaxSize: 10, MaxDelay: time.Second},
+	}, {
+		name:          "Delay",
+		maxBatchSize:  ptr.Int32(10),
+		maxBatchDelay: ptr.String("PT0.5S"),
+		want:          &BatchConfig{MaxSize: 10, MaxDelay: 500 * time.Millisecond},
+	}, {
+		name:          "Invalid delay",
+		maxBatchSize:  ptr.Int32(10),
+		maxBatchDelay: ptr.String("half a second"),
+		wantErr:       true,
+	}}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := BatchConfigFromDeliverySpec(v1.DeliverySpec{
+				MaxBatchSize:  tc.maxBatchSize,
+				MaxBatchDelay: tc.maxBatchDelay,
+			})
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestWriteAndReadBatch(t *testing.T) {
+	events := makeBatchEvents(2)
+	req, err := http.NewRequest(http.MethodPost, "http://subscriber.example.com/", nil)
+	require.NoError(t, err)
+	require.NoError(t, WriteHTTPRequestWithBatch(context.Background(), events, req, http.Header{"Traceparent": {"value"}}))
+	require.True(t, IsBatch(req.Header))
+	require.Equal(t, "value", req.Header.Get("Traceparent"))
+
+	got, err := ReadBatch(req.Body)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	for i := range events {
+		require.Equal(t, events[i].ID(), got[i].ID())
+	}
+
+	// The body can be read again for the retries.
+	body, err := req.GetBody()
+	require.NoError(t, err)
+	_, err = ReadBatch(body)
+	require.NoError(t, err)
+
+	_, err = ReadBatch(http.NoBody)
+	require.Error(t, err)
+}
+
+func TestBatcher(t *testing.T) {
+	var mutex sync.Mutex
+	var batches [][]string
+	sendErr := errors.New("send error")
+	b := NewBatcher(BatchConfig{MaxSize: 2, MaxDelay: 50 * time.Millisecond}, func(events []*cloudevents.Event) error {
+		ids := make([]string, 0, len(events))
+		for _, e := range events {
+			ids = append(ids, e.ID())
+		}
+		mutex.Lock()
+		defer mutex.Unlock()
+		batches = append(batches, ids)
+		return sendErr
+	})
+	sent := func() [][]string {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return append([][]string(nil), batches...)
+	}
+
+	results := make(chan error, 4)
+	done := func(err error) {
+		results <- err
+	}
+	events := makeBatchEvents(4)
+
+	// The batch is sent once full.
+	b.Add(events[0], done)
+	b.Add(events[1], done)
+	require.Equal(t, sendErr, <-results)
+	require.Equal(t, sendErr, <-results)
+	require.Equal(t, [][]string{{"0", "1"}}, sent())
+
+	// The batch is sent after the maximum delay.
+	b.Add(events[2], done)
+	select {
+	case <-results:
+		t.Fatal("The batch was sent before the maximum delay")
+	case <-time.After(10 * time.Millisecond):
+	}
+	require.Equal(t, sendErr, <-results)
+	require.Equal(t, [][]string{{"0", "1"}, {"2"}}, sent())
+
+	// Closing the batcher sends the pending batch.
+	b.Add(events[3], done)
+	b.Close()
+	require.Equal(t, sendErr, <-results)
+	require.Equal(t, [][]string{{"0", "1"}, {"2"}, {"3"}}, sent())
+
+	b.Add(events[0], done)
+	require.Equal(t, ErrBatcherClosed, <-results)
+}
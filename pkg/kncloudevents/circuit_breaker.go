@@ -0,0 +1,238 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kncloudevents
+
+import (
+	"errors"
+	nethttp "net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned by HTTPMessageSender when a request isn't sent because the circuit
+// of its destination is open.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitState is the state of the circuit of a destination.
+type CircuitState int
+
+const (
+	// CircuitClosed lets the requests through, counting their failures.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen fails the requests fast until the cool-down elapses.
+	CircuitOpen
+	// CircuitHalfOpen lets a single probe request through, closing the circuit if it succeeds
+	// and opening it again if it fails.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreakerConfig configures the circuit breakers of the destinations.
+type CircuitBreakerConfig struct {
+	// FailureRatio is the ratio of failed requests, between 0 and 1, opening the circuit.
+	FailureRatio float64
+	// MinRequests is the minimum number of requests in an interval before the failure ratio
+	// is evaluated.
+	MinRequests int
+	// Interval is the period after which the counts of a closed circuit are reset.
+	// When 0, the counts are only reset when the circuit closes.
+	Interval time.Duration
+	// CoolDown is how long the circuit stays open before letting a probe request through.
+	CoolDown time.Duration
+	// OnStateChange, when not nil, is called on every state transition of the circuit of a
+	// destination URL.
+	OnStateChange func(destination string, from, to CircuitState)
+}
+
+// circuitIdleTimeout is how long a closed circuit without requests is kept, so that the circuits
+// of the removed destinations are eventually dropped.
+const circuitIdleTimeout = 5 * time.Minute
+
+// CircuitBreakers holds the circuit breaker of each destination URL, like Limiters, so that the
+// destinations sharing a host, like the Triggers behind the broker filter, don't share a circuit.
+// A request is a failure when it can't be sent or the destination answers with 429 or 5xx.
+type CircuitBreakers struct {
+	config CircuitBreakerConfig
+	now    func() time.Time
+
+	mutex    sync.Mutex
+	circuits map[string]*circuit
+	// sweptAt is the last time the idle circuits were dropped.
+	sweptAt time.Time
+}
+
+type circuit struct {
+	state    CircuitState
+	requests int
+	failures int
+	// expiry is when the counts of a closed circuit are reset or when an open circuit
+	// becomes half-open.
+	expiry time.Time
+	// probing is true while the probe request of a half-open circuit is in flight.
+	probing bool
+	// usedAt is the last time a request went through the circuit.
+	usedAt time.Time
+}
+
+type transition struct {
+	destination string
+	from, to    CircuitState
+}
+
+// NewCircuitBreakers returns the circuit breakers of the destinations configured by config.
+func NewCircuitBreakers(config CircuitBreakerConfig) *CircuitBreakers {
+	return &CircuitBreakers{
+		config:   config,
+		now:      time.Now,
+		circuits: make(map[string]*circuit),
+	}
+}
+
+// State returns the state of the circuit of the destination URL.
+func (cb *CircuitBreakers) State(destination string) CircuitState {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+	c, ok := cb.circuits[destination]
+	if !ok {
+		return CircuitClosed
+	}
+	if c.state == CircuitOpen && !cb.now().Before(c.expiry) {
+		return CircuitHalfOpen
+	}
+	return c.state
+}
+
+// allow returns true if a request can be sent to the destination URL.
+func (cb *CircuitBreakers) allow(destination string) bool {
+	cb.mutex.Lock()
+	c := cb.circuit(destination)
+	var t *transition
+	allowed := true
+	switch c.state {
+	case CircuitClosed:
+		if cb.config.Interval > 0 && !cb.now().Before(c.expiry) {
+			c.requests, c.failures = 0, 0
+			c.expiry = cb.now().Add(cb.config.Interval)
+		}
+	case CircuitOpen:
+		if cb.now().Before(c.expiry) {
+			allowed = false
+			break
+		}
+		t = cb.setState(destination, c, CircuitHalfOpen)
+		c.probing = true
+	case CircuitHalfOpen:
+		allowed = !c.probing
+		c.probing = true
+	}
+	cb.mutex.Unlock()
+
+	cb.notify(t)
+	return allowed
+}
+
+// record records the outcome of a request to the destination URL.
+func (cb *CircuitBreakers) record(destination string, failed bool) {
+	cb.mutex.Lock()
+	c := cb.circuit(destination)
+	var t *transition
+	switch c.state {
+	case CircuitClosed:
+		c.requests++
+		if failed {
+			c.failures++
+		}
+		if failed && c.requests >= cb.config.MinRequests && float64(c.failures)/float64(c.requests) >= cb.config.FailureRatio {
+			t = cb.setState(destination, c, CircuitOpen)
+		}
+	case CircuitHalfOpen:
+		if failed {
+			t = cb.setState(destination, c, CircuitOpen)
+		} else {
+			t = cb.setState(destination, c, CircuitClosed)
+		}
+	case CircuitOpen:
+		// Outcome of a request sent before the circuit opened.
+	}
+	cb.mutex.Unlock()
+
+	cb.notify(t)
+}
+
+// circuit returns the circuit of the destination URL, dropping the idle circuits at most once per
+// idle timeout. cb.mutex must be held.
+func (cb *CircuitBreakers) circuit(destination string) *circuit {
+	now := cb.now()
+	if now.Sub(cb.sweptAt) >= circuitIdleTimeout {
+		cb.sweep(now)
+	}
+	c, ok := cb.circuits[destination]
+	if !ok {
+		c = &circuit{state: CircuitClosed, expiry: now.Add(cb.config.Interval)}
+		cb.circuits[destination] = c
+	}
+	c.usedAt = now
+	return c
+}
+
+// sweep drops the closed circuits without requests for the idle timeout, cb.mutex must be held.
+// Dropping a closed circuit only resets its counts.
+func (cb *CircuitBreakers) sweep(now time.Time) {
+	cb.sweptAt = now
+	for destination, c := range cb.circuits {
+		if c.state == CircuitClosed && now.Sub(c.usedAt) >= circuitIdleTimeout {
+			delete(cb.circuits, destination)
+		}
+	}
+}
+
+func (cb *CircuitBreakers) setState(destination string, c *circuit, state CircuitState) *transition {
+	t := &transition{destination: destination, from: c.state, to: state}
+	c.state = state
+	c.requests, c.failures = 0, 0
+	c.probing = false
+	switch state {
+	case CircuitClosed:
+		c.expiry = cb.now().Add(cb.config.Interval)
+	case CircuitOpen:
+		c.expiry = cb.now().Add(cb.config.CoolDown)
+	}
+	return t
+}
+
+func (cb *CircuitBreakers) notify(t *transition) {
+	if t != nil && cb.config.OnStateChange != nil {
+		cb.config.OnStateChange(t.destination, t.from, t.to)
+	}
+}
+
+// isCircuitFailure returns true if the outcome of a request counts as a failure of its destination.
+func isCircuitFailure(resp *nethttp.Response, err error) bool {
+	return err != nil || resp == nil || resp.StatusCode == nethttp.StatusTooManyRequests || resp.StatusCode >= nethttp.StatusInternalServerError
+}
@@ -0,0 +1,198 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kncloudevents
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitBreakers(t *testing.T) {
+	const destination = "http://subscriber.example.com/"
+	now := time.Now()
+	var transitions []string
+	cb := NewCircuitBreakers(CircuitBreakerConfig{
+		FailureRatio: 0.5,
+		MinRequests:  4,
+		Interval:     time.Minute,
+		CoolDown:     10 * time.Second,
+		OnStateChange: func(d string, from, to CircuitState) {
+			transitions = append(transitions, d+": "+from.String()+" -> "+to.String())
+		},
+	})
+	cb.now = func() time.Time { return now }
+
+	send := func(failed bool) bool {
+		if !cb.allow(destination) {
+			return false
+		}
+		cb.record(destination, failed)
+		return true
+	}
+
+	// Failures below the minimum number of requests don't open the circuit.
+	for i := 0; i < 3; i++ {
+		require.True(t, send(true))
+	}
+	require.Equal(t, CircuitClosed, cb.State(destination))
+
+	// The counts are reset after the interval.
+	now = now.Add(time.Minute)
+	require.True(t, send(false))
+	require.True(t, send(false))
+	require.True(t, send(true))
+	require.Equal(t, CircuitClosed, cb.State(destination))
+
+	// The failure ratio is reached.
+	require.True(t, send(true))
+	require.Equal(t, CircuitOpen, cb.State(destination))
+	require.False(t, send(false), "requests must fail fast while the circuit is open")
+	require.Equal(t, CircuitClosed, cb.State("http://subscriber.example.com/other"), "circuits are per destination URL")
+
+	// After the cool-down a single probe request is let through.
+	now = now.Add(10 * time.Second)
+	require.Equal(t, CircuitHalfOpen, cb.State(destination))
+	require.True(t, cb.allow(destination))
+	require.False(t, cb.allow(destination), "only one probe request is allowed")
+	cb.record(destination, true)
+	require.Equal(t, CircuitOpen, cb.State(destination))
+
+	// A successful probe closes the circuit.
+	now = now.Add(10 * time.Second)
+	require.True(t, send(false))
+	require.Equal(t, CircuitClosed, cb.State(destination))
+
+	want := []string{
+		destination + ": closed -> open",
+		destination + ": open -> half-open",
+		destination + ": half-open -> open",
+		destination + ": open -> half-open",
+		destination + ": half-open -> closed",
+	}
+	if diff := cmp.Diff(want, transitions); diff != "" {
+		t.Error("Unexpected transitions (-want, +got) =", diff)
+	}
+}
+
+func TestCircuitBreakersSweep(t *testing.T) {
+	now := time.Now()
+	cb := NewCircuitBreakers(CircuitBreakerConfig{
+		FailureRatio: 1,
+		MinRequests:  1,
+		CoolDown:     time.Hour,
+	})
+	cb.now = func() time.Time { return now }
+
+	require.True(t, cb.allow("http://closed.example.com/"))
+	cb.record("http://closed.example.com/", false)
+	require.True(t, cb.allow("http://open.example.com/"))
+	cb.record("http://open.example.com/", true)
+	require.Len(t, cb.circuits, 2)
+
+	// Only the idle closed circuits are dropped, the open ones keep failing fast.
+	now = now.Add(circuitIdleTimeout)
+	require.False(t, cb.allow("http://open.example.com/"))
+	require.Len(t, cb.circuits, 1)
+	require.Equal(t, CircuitOpen, cb.State("http://open.example.com/"))
+}
+
+func TestHTTPMessageSenderSendWithRetriesCircuitBreaker(t *testing.T) {
+	t.Parallel()
+
+	var n int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		atomic.AddInt32(&n, 1)
+		writer.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	sender := &HTTPMessageSender{
+		Client: getClient(),
+		CircuitBreakers: NewCircuitBreakers(CircuitBreakerConfig{
+			FailureRatio: 1,
+			MinRequests:  3,
+			CoolDown:     time.Hour,
+		}),
+	}
+	config := &RetryConfig{
+		RetryMax:   10,
+		CheckRetry: RetryIfGreaterThan300,
+		Backoff: func(attemptNum int, resp *http.Response) time.Duration {
+			return time.Millisecond
+		},
+	}
+
+	request, err := http.NewRequest("POST", server.URL, nil)
+	require.NoError(t, err)
+	got, err := sender.SendWithRetries(request, config)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusServiceUnavailable, got.StatusCode)
+	require.Equal(t, 3, int(atomic.LoadInt32(&n)), "retries must stop once the circuit opens")
+
+	request, err = http.NewRequest("POST", server.URL, nil)
+	require.NoError(t, err)
+	_, err = sender.SendWithRetries(request, config)
+	require.True(t, errors.Is(err, ErrCircuitOpen), "expected ErrCircuitOpen, got %v", err)
+	require.Equal(t, 3, int(atomic.LoadInt32(&n)), "requests must fail fast while the circuit is open")
+
+	_, err = sender.SendWithRetries(request.WithContext(context.Background()), nil)
+	require.True(t, errors.Is(err, ErrCircuitOpen), "expected ErrCircuitOpen without retries, got %v", err)
+}
+
+func TestHTTPMessageSenderSendWithRetriesCircuitBreakerSharedHost(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.Path == "/failing" {
+			writer.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		writer.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	sender := &HTTPMessageSender{
+		Client: getClient(),
+		CircuitBreakers: NewCircuitBreakers(CircuitBreakerConfig{
+			FailureRatio: 1,
+			MinRequests:  1,
+			CoolDown:     time.Hour,
+		}),
+	}
+
+	request, err := http.NewRequest("POST", server.URL+"/failing", nil)
+	require.NoError(t, err)
+	got, err := sender.SendWithRetries(request, nil)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusServiceUnavailable, got.StatusCode)
+	_, err = sender.SendWithRetries(request, nil)
+	require.True(t, errors.Is(err, ErrCircuitOpen), "expected ErrCircuitOpen, got %v", err)
+
+	request, err = http.NewRequest("POST", server.URL+"/healthy", nil)
+	require.NoError(t, err)
+	got, err = sender.SendWithRetries(request, nil)
+	require.NoError(t, err, "the destinations sharing the host of a failing one must not fail fast")
+	require.Equal(t, http.StatusAccepted, got.StatusCode)
+}
@@ -31,9 +31,11 @@ const (
 )
 
 type holder struct {
-	clientMutex    sync.Mutex
-	connectionArgs *ConnectionArgs
-	client         **nethttp.Client
+	clientMutex     sync.Mutex
+	connectionArgs  *ConnectionArgs
+	client          **nethttp.Client
+	circuitBreakers *CircuitBreakers
+	limiters        *Limiters
 }
 
 var clientHolder = holder{}
@@ -88,6 +90,48 @@ func ConfigureConnectionArgs(ca *ConnectionArgs) {
 	clientHolder.connectionArgs = ca
 }
 
+// ConfigureCircuitBreakers enables the circuit breakers of the destinations, or disables them when
+// config is nil. The circuit breakers are shared by the HTTPMessageSenders created afterwards.
+func ConfigureCircuitBreakers(config *CircuitBreakerConfig) {
+	clientHolder.clientMutex.Lock()
+	defer clientHolder.clientMutex.Unlock()
+
+	if config == nil {
+		clientHolder.circuitBreakers = nil
+		return
+	}
+	clientHolder.circuitBreakers = NewCircuitBreakers(*config)
+}
+
+func getCircuitBreakers() *CircuitBreakers {
+	clientHolder.clientMutex.Lock()
+	defer clientHolder.clientMutex.Unlock()
+
+	return clientHolder.circuitBreakers
+}
+
+// ConfigureLimiters configures the limiters of the destinations, enforcing the rate limit and the
+// maximum number of events in flight of the RetryConfigs. The limiters are shared by the
+// HTTPMessageSenders created afterwards.
+func ConfigureLimiters(config LimiterConfig) {
+	clientHolder.clientMutex.Lock()
+	defer clientHolder.clientMutex.Unlock()
+
+	clientHolder.limiters = NewLimiters(config)
+}
+
+// getLimiters returns the configured limiters, or limiters with the default configuration when
+// ConfigureLimiters wasn't called.
+func getLimiters() *Limiters {
+	clientHolder.clientMutex.Lock()
+	defer clientHolder.clientMutex.Unlock()
+
+	if clientHolder.limiters == nil {
+		clientHolder.limiters = NewLimiters(LimiterConfig{})
+	}
+	return clientHolder.limiters
+}
+
 // ConnectionArgs allow to configure connection parameters to the underlying
 // HTTP Client transport.
 type ConnectionArgs struct {
@@ -0,0 +1,236 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kncloudevents
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+// ErrLimited is returned by Limiters when an event can't be sent to its destination within the
+// maximum wait because of its rate limit or its maximum number of events in flight.
+var ErrLimited = errors.New("destination limit exceeded")
+
+// defaultLimiterMaxWait is the default maximum time an event waits for the limiter of its
+// destination.
+const defaultLimiterMaxWait = 10 * time.Second
+
+// limiterIdleTimeout is how long a limiter without events is kept, so that the limiters of the
+// removed destinations and limits are eventually dropped.
+const limiterIdleTimeout = 5 * time.Minute
+
+type unboundedLimiterWaitKey struct{}
+
+// ContextWithUnboundedLimiterWait returns a copy of ctx for the events which were already accepted
+// and can't be rejected anymore. They wait for the limiter of their destination as long as ctx
+// isn't done, rather than for the maximum wait.
+func ContextWithUnboundedLimiterWait(ctx context.Context) context.Context {
+	return context.WithValue(ctx, unboundedLimiterWaitKey{}, true)
+}
+
+// LimiterState is the state of the limiter of a destination.
+type LimiterState struct {
+	// InFlight is the number of events being sent to the destination.
+	InFlight int
+	// Waiting is the number of events waiting for the limiter.
+	Waiting int
+}
+
+// LimiterConfig configures the limiters of the destinations.
+type LimiterConfig struct {
+	// MaxWait is the maximum time an event waits for the limiter of its destination before
+	// being rejected. When 0, it defaults to 10 seconds.
+	MaxWait time.Duration
+	// OnStateChange, when not nil, is called every time the state of the limiter of a
+	// destination changes.
+	OnStateChange func(destination string, state LimiterState)
+	// OnReject, when not nil, is called every time an event is rejected by the limiter of a
+	// destination.
+	OnReject func(destination string)
+}
+
+// Limiters holds the limiter of each destination URL and limits, enforcing the RateLimit and
+// MaxInFlight of a RetryConfig. The destinations sent to with distinct limits, like by the
+// subscriptions of distinct channels, have distinct limiters.
+type Limiters struct {
+	config LimiterConfig
+	clock  clock.PassiveClock
+
+	mutex    sync.Mutex
+	limiters map[limiterKey]*limiter
+	// sweptAt is the last time the idle limiters were dropped.
+	sweptAt time.Time
+}
+
+type limiterKey struct {
+	destination string
+	rate        float64
+	burst       int
+	maxInFlight int
+}
+
+type limiter struct {
+	// idleTimeout is how long the limiter is kept without events, long enough for its rate
+	// limiter to be back to its full burst.
+	idleTimeout time.Duration
+
+	// rateLimiter is nil when the rate isn't limited.
+	rateLimiter *rate.Limiter
+	// slots holds a value for each event in flight, it's nil when the number of events in
+	// flight isn't limited.
+	slots chan struct{}
+
+	mutex sync.Mutex
+	state LimiterState
+	// usedAt is the last time an event went through the limiter.
+	usedAt time.Time
+}
+
+// NewLimiters returns the limiters of the destinations configured by config.
+func NewLimiters(config LimiterConfig) *Limiters {
+	if config.MaxWait <= 0 {
+		config.MaxWait = defaultLimiterMaxWait
+	}
+	return &Limiters{
+		config:   config,
+		clock:    clock.RealClock{},
+		limiters: make(map[limiterKey]*limiter),
+	}
+}
+
+// Acquire waits until an event can be sent to the destination URL according to the limits of
+// config, for at most the configured maximum wait unless ctx comes from
+// ContextWithUnboundedLimiterWait. The returned function must be called once the
+// event was sent. It returns an error wrapping ErrLimited when the wait times out.
+func (l *Limiters) Acquire(ctx context.Context, destination string, config *RetryConfig) (func(), error) {
+	if l == nil || config == nil || (config.RateLimit <= 0 && config.MaxInFlight <= 0) {
+		return func() {}, nil
+	}
+	lim := l.limiter(destination, config)
+
+	if unbounded, _ := ctx.Value(unboundedLimiterWaitKey{}).(bool); !unbounded {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, l.config.MaxWait)
+		defer cancel()
+	}
+	l.update(destination, lim, 0, 1)
+	if lim.slots != nil {
+		select {
+		case lim.slots <- struct{}{}:
+		case <-ctx.Done():
+			l.update(destination, lim, 0, -1)
+			return nil, l.reject(destination, ctx.Err())
+		}
+	}
+	if lim.rateLimiter != nil {
+		if err := lim.rateLimiter.Wait(ctx); err != nil {
+			if lim.slots != nil {
+				<-lim.slots
+			}
+			l.update(destination, lim, 0, -1)
+			return nil, l.reject(destination, err)
+		}
+	}
+	l.update(destination, lim, 1, -1)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			if lim.slots != nil {
+				<-lim.slots
+			}
+			l.update(destination, lim, -1, 0)
+		})
+	}, nil
+}
+
+// limiter returns the limiter of the destination with the limits of config, dropping the idle
+// limiters at most once per idle timeout.
+func (l *Limiters) limiter(destination string, config *RetryConfig) *limiter {
+	key := limiterKey{
+		destination: destination,
+		rate:        config.RateLimit,
+		burst:       config.RateLimitBurst,
+		maxInFlight: config.MaxInFlight,
+	}
+	if key.burst < 1 {
+		key.burst = 1
+	}
+	now := l.clock.Now()
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if now.Sub(l.sweptAt) >= limiterIdleTimeout {
+		l.sweep(now)
+	}
+	lim, ok := l.limiters[key]
+	if !ok {
+		lim = &limiter{idleTimeout: limiterIdleTimeout}
+		if key.rate > 0 {
+			lim.rateLimiter = rate.NewLimiter(rate.Limit(key.rate), key.burst)
+			if refill := time.Duration(float64(key.burst) / key.rate * float64(time.Second)); refill > lim.idleTimeout {
+				lim.idleTimeout = refill
+			}
+		}
+		if key.maxInFlight > 0 {
+			lim.slots = make(chan struct{}, key.maxInFlight)
+		}
+		l.limiters[key] = lim
+	}
+	// The limiter isn't idle while the event waits for it.
+	lim.mutex.Lock()
+	lim.usedAt = now
+	lim.mutex.Unlock()
+	return lim
+}
+
+// sweep drops the limiters without events for their idle timeout, l.mutex must be held.
+func (l *Limiters) sweep(now time.Time) {
+	l.sweptAt = now
+	for key, lim := range l.limiters {
+		lim.mutex.Lock()
+		idle := lim.state == LimiterState{} && now.Sub(lim.usedAt) >= lim.idleTimeout
+		lim.mutex.Unlock()
+		if idle {
+			delete(l.limiters, key)
+		}
+	}
+}
+
+func (l *Limiters) update(destination string, lim *limiter, inFlight, waiting int) {
+	lim.mutex.Lock()
+	lim.state.InFlight += inFlight
+	lim.state.Waiting += waiting
+	lim.usedAt = l.clock.Now()
+	state := lim.state
+	lim.mutex.Unlock()
+	if l.config.OnStateChange != nil {
+		l.config.OnStateChange(destination, state)
+	}
+}
+
+func (l *Limiters) reject(destination string, err error) error {
+	if l.config.OnReject != nil {
+		l.config.OnReject(destination)
+	}
+	return fmt.Errorf("failed to send the event to %s: %v: %w", destination, err, ErrLimited)
+}
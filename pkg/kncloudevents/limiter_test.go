@@ -0,0 +1,154 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kncloudevents
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+func TestLimitersMaxInFlight(t *testing.T) {
+	const destination = "http://subscriber.example.com/"
+	var mutex sync.Mutex
+	var states []LimiterState
+	var rejected []string
+	l := NewLimiters(LimiterConfig{
+		MaxWait: 50 * time.Millisecond,
+		OnStateChange: func(_ string, state LimiterState) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			states = append(states, state)
+		},
+		OnReject: func(d string) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			rejected = append(rejected, d)
+		},
+	})
+	config := &RetryConfig{MaxInFlight: 1}
+
+	release, err := l.Acquire(context.Background(), destination, config)
+	require.NoError(t, err)
+
+	// The second event waits for the first one, then is rejected.
+	_, err = l.Acquire(context.Background(), destination, config)
+	require.True(t, errors.Is(err, ErrLimited), "expected ErrLimited, got %v", err)
+	require.Equal(t, []string{destination}, rejected)
+
+	// Another destination has its own limiter.
+	other, err := l.Acquire(context.Background(), "http://other.example.com/", config)
+	require.NoError(t, err)
+	other()
+
+	// The waiting event gets the slot released by the first one.
+	acquired := make(chan error)
+	go func() {
+		release, err := l.Acquire(context.Background(), destination, config)
+		if err == nil {
+			release()
+		}
+		acquired <- err
+	}()
+	require.Eventually(t, func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return states[len(states)-1] == LimiterState{InFlight: 1, Waiting: 1}
+	}, time.Second, time.Millisecond)
+	release()
+	release()
+	require.NoError(t, <-acquired)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	require.Equal(t, LimiterState{}, states[len(states)-1])
+}
+
+func TestLimitersRateLimit(t *testing.T) {
+	const destination = "http://subscriber.example.com/"
+	l := NewLimiters(LimiterConfig{MaxWait: 50 * time.Millisecond})
+	config := &RetryConfig{RateLimit: 1, RateLimitBurst: 2}
+
+	// The burst goes through, the next event would wait a second.
+	for i := 0; i < 2; i++ {
+		release, err := l.Acquire(context.Background(), destination, config)
+		require.NoError(t, err)
+		release()
+	}
+	_, err := l.Acquire(context.Background(), destination, config)
+	require.True(t, errors.Is(err, ErrLimited), "expected ErrLimited, got %v", err)
+
+	// Distinct limits have distinct limiters.
+	config = &RetryConfig{RateLimit: 1000, RateLimitBurst: 1}
+	release, err := l.Acquire(context.Background(), destination, config)
+	require.NoError(t, err)
+	release()
+}
+
+func TestLimitersUnlimited(t *testing.T) {
+	var l *Limiters
+	release, err := l.Acquire(context.Background(), "http://subscriber.example.com/", &RetryConfig{MaxInFlight: 1})
+	require.NoError(t, err)
+	release()
+
+	l = NewLimiters(LimiterConfig{})
+	for _, config := range []*RetryConfig{nil, {RetryMax: 3}} {
+		release, err := l.Acquire(context.Background(), "http://subscriber.example.com/", config)
+		require.NoError(t, err)
+		release()
+	}
+	require.Empty(t, l.limiters)
+}
+
+func TestLimitersIdle(t *testing.T) {
+	const destination = "http://subscriber.example.com/"
+	c := clock.NewFakeClock(time.Now())
+	l := NewLimiters(LimiterConfig{})
+	l.clock = c
+
+	inFlight, err := l.Acquire(context.Background(), destination, &RetryConfig{MaxInFlight: 1})
+	require.NoError(t, err)
+	for _, config := range []*RetryConfig{{MaxInFlight: 2}, {RateLimit: 0.001}} {
+		release, err := l.Acquire(context.Background(), destination, config)
+		require.NoError(t, err)
+		release()
+	}
+	require.Len(t, l.limiters, 3)
+
+	// The idle limiters are dropped, unless an event is in flight or their rate limiter isn't
+	// full again yet.
+	c.Step(limiterIdleTimeout)
+	release, err := l.Acquire(context.Background(), "http://other.example.com/", &RetryConfig{MaxInFlight: 1})
+	require.NoError(t, err)
+	release()
+	require.Len(t, l.limiters, 3)
+	for key := range l.limiters {
+		require.NotEqual(t, 2, key.maxInFlight)
+	}
+
+	inFlight()
+	c.Step(time.Hour)
+	release, err = l.Acquire(context.Background(), "http://other.example.com/", &RetryConfig{MaxInFlight: 1})
+	require.NoError(t, err)
+	release()
+	require.Len(t, l.limiters, 1)
+}
@@ -18,6 +18,7 @@ package kncloudevents
 
 import (
 	"context"
+	"fmt"
 	nethttp "net/http"
 	"time"
 
@@ -28,6 +29,12 @@ import (
 type HTTPMessageSender struct {
 	Client *nethttp.Client
 	Target string
+	// CircuitBreakers, when not nil, fail fast the requests sent with SendWithRetries to the
+	// destinations whose circuit is open.
+	CircuitBreakers *CircuitBreakers
+	// Limiters, when not nil, enforce the rate limit and the maximum number of events in
+	// flight of the destinations.
+	Limiters *Limiters
 }
 
 // Deprecated: Don't use this anymore, now it has the same effect of NewHTTPMessageSenderWithTarget
@@ -37,7 +44,7 @@ func NewHTTPMessageSender(ca *ConnectionArgs, target string) (*HTTPMessageSender
 }
 
 func NewHTTPMessageSenderWithTarget(target string) (*HTTPMessageSender, error) {
-	return &HTTPMessageSender{Client: getClient(), Target: target}, nil
+	return &HTTPMessageSender{Client: getClient(), Target: target, CircuitBreakers: getCircuitBreakers(), Limiters: getLimiters()}, nil
 }
 
 func (s *HTTPMessageSender) NewCloudEventRequest(ctx context.Context) (*nethttp.Request, error) {
@@ -52,9 +59,53 @@ func (s *HTTPMessageSender) Send(req *nethttp.Request) (*nethttp.Response, error
 	return s.Client.Do(req)
 }
 
+// DeliveryAttempts records the requests sent by SendWithRetriesAndAttempts.
+type DeliveryAttempts struct {
+	// Count is the number of requests sent, including the retries.
+	Count int
+	// FirstFailure is the time of the first failure, zero if none happened.
+	FirstFailure time.Time
+}
+
+// attempt records a sent request and its outcome.
+func (a *DeliveryAttempts) attempt(resp *nethttp.Response, err error) {
+	if a == nil {
+		return
+	}
+	a.Count++
+	if err != nil || resp == nil || resp.StatusCode < nethttp.StatusOK || resp.StatusCode >= nethttp.StatusMultipleChoices {
+		a.fail()
+	}
+}
+
+// fail records a failure.
+func (a *DeliveryAttempts) fail() {
+	if a != nil && a.FirstFailure.IsZero() {
+		a.FirstFailure = time.Now()
+	}
+}
+
 func (s *HTTPMessageSender) SendWithRetries(req *nethttp.Request, config *RetryConfig) (*nethttp.Response, error) {
+	return s.SendWithRetriesAndAttempts(req, config, nil)
+}
+
+// SendWithRetriesAndAttempts is like SendWithRetries, recording the requests sent in attempts
+// when not nil.
+func (s *HTTPMessageSender) SendWithRetriesAndAttempts(req *nethttp.Request, config *RetryConfig, attempts *DeliveryAttempts) (*nethttp.Response, error) {
+	cb := s.CircuitBreakers
+	destination := req.URL.String()
+	if cb != nil && !cb.allow(destination) {
+		attempts.fail()
+		return nil, fmt.Errorf("failed to send the request to %s: %w", destination, ErrCircuitOpen)
+	}
+
 	if config == nil {
-		return s.Send(req)
+		resp, err := s.Send(req)
+		attempts.attempt(resp, err)
+		if cb != nil {
+			cb.record(destination, isCircuitFailure(resp, err))
+		}
+		return resp, err
 	}
 
 	client := s.Client
@@ -67,14 +118,32 @@ func (s *HTTPMessageSender) SendWithRetries(req *nethttp.Request, config *RetryC
 		}
 	}
 
+	checkRetry := config.CheckRetry
+	recorded := false
+	if cb != nil {
+		checkRetry = func(ctx context.Context, resp *nethttp.Response, err error) (bool, error) {
+			recorded = true
+			failed := isCircuitFailure(resp, err)
+			cb.record(destination, failed)
+			if failed && cb.State(destination) == CircuitOpen {
+				// Don't spend the retry budget on a destination which is down.
+				return false, err
+			}
+			return config.CheckRetry(ctx, resp, err)
+		}
+	}
+
 	retryableClient := retryablehttp.Client{
 		HTTPClient:   client,
 		RetryWaitMin: defaultRetryWaitMin,
 		RetryWaitMax: defaultRetryWaitMax,
 		RetryMax:     config.RetryMax,
-		CheckRetry:   retryablehttp.CheckRetry(config.CheckRetry),
+		CheckRetry: func(ctx context.Context, resp *nethttp.Response, err error) (bool, error) {
+			attempts.attempt(resp, err)
+			return checkRetry(ctx, resp, err)
+		},
 		Backoff: func(_, _ time.Duration, attemptNum int, resp *nethttp.Response) time.Duration {
-			return config.Backoff(attemptNum, resp)
+			return config.backoff(attemptNum, resp)
 		},
 		ErrorHandler: func(resp *nethttp.Response, err error, numTries int) (*nethttp.Response, error) {
 			return resp, err
@@ -83,8 +152,17 @@ func (s *HTTPMessageSender) SendWithRetries(req *nethttp.Request, config *RetryC
 
 	retryableReq, err := retryablehttp.FromRequest(req)
 	if err != nil {
+		attempts.fail()
+		if cb != nil {
+			cb.record(destination, true)
+		}
 		return nil, err
 	}
 
-	return retryableClient.Do(retryableReq)
+	resp, err := retryableClient.Do(retryableReq)
+	if cb != nil && !recorded {
+		// The request failed before reaching the destination, release the circuit anyway.
+		cb.record(destination, isCircuitFailure(resp, err))
+	}
+	return resp, err
 }
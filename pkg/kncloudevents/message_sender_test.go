@@ -194,6 +194,124 @@ func TestHTTPMessageSenderSendWithRetriesWithSingleRequestTimeout(t *testing.T)
 	require.Equal(t, http.StatusOK, got.StatusCode)
 }
 
+func TestHTTPMessageSenderSendWithRetriesHonoringRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	var n int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if atomic.AddInt32(&n, 1) == 1 {
+			writer.Header().Set("Retry-After", "60")
+			writer.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		writer.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	retryAfterMax := 200 * time.Millisecond
+	sender := &HTTPMessageSender{
+		Client: getClient(),
+	}
+	config := &RetryConfig{
+		RetryMax:   1,
+		CheckRetry: RetryIfGreaterThan300,
+		Backoff: func(attemptNum int, resp *http.Response) time.Duration {
+			return time.Millisecond
+		},
+		RetryAfterMaxDuration: &retryAfterMax,
+	}
+
+	request, err := http.NewRequest("POST", server.URL, nil)
+	require.NoError(t, err)
+
+	start := time.Now()
+	got, err := sender.SendWithRetries(request, config)
+	elapsed := time.Since(start)
+
+	require.NoError(t, err)
+	require.Equal(t, http.StatusAccepted, got.StatusCode)
+	require.Equal(t, 2, int(atomic.LoadInt32(&n)))
+	require.GreaterOrEqual(t, int64(elapsed), int64(retryAfterMax))
+	require.Less(t, int64(elapsed), int64(10*time.Second))
+}
+
+func TestHTTPMessageSenderSendWithRetriesAndAttempts(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		failures        int32
+		config          *RetryConfig
+		wantCount       int
+		wantFirstFailed bool
+	}{{
+		name:      "no retries",
+		wantCount: 1,
+	}, {
+		name:            "failure without retries",
+		failures:        1,
+		wantCount:       1,
+		wantFirstFailed: true,
+	}, {
+		name:     "success after retries",
+		failures: 2,
+		config: &RetryConfig{
+			RetryMax:   5,
+			CheckRetry: RetryIfGreaterThan300,
+			Backoff: func(attemptNum int, resp *http.Response) time.Duration {
+				return time.Millisecond
+			},
+		},
+		wantCount:       3,
+		wantFirstFailed: true,
+	}, {
+		name:     "retries exhausted",
+		failures: 10,
+		config: &RetryConfig{
+			RetryMax:   2,
+			CheckRetry: RetryIfGreaterThan300,
+			Backoff: func(attemptNum int, resp *http.Response) time.Duration {
+				return time.Millisecond
+			},
+		},
+		wantCount:       3,
+		wantFirstFailed: true,
+	}}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var n int32
+			server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				if atomic.AddInt32(&n, 1) <= tt.failures {
+					writer.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				writer.WriteHeader(http.StatusAccepted)
+			}))
+			defer server.Close()
+
+			sender := &HTTPMessageSender{
+				Client: getClient(),
+			}
+			request, err := http.NewRequest("POST", server.URL, nil)
+			require.NoError(t, err)
+
+			start := time.Now()
+			var attempts DeliveryAttempts
+			_, err = sender.SendWithRetriesAndAttempts(request, tt.config, &attempts)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantCount, attempts.Count)
+			require.Equal(t, tt.wantFirstFailed, !attempts.FirstFailure.IsZero())
+			if tt.wantFirstFailed {
+				require.False(t, attempts.FirstFailure.Before(start))
+			}
+		})
+	}
+}
+
 func TestRetriesOnNetworkErrors(t *testing.T) {
 
 	n := int32(10)
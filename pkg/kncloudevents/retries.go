@@ -20,7 +20,10 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"math/rand"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/rickb777/date/period"
@@ -55,17 +58,32 @@ type Backoff func(attemptNum int, resp *http.Response) time.Duration
 type RetryConfig struct {
 	// Maximum number of retries
 	RetryMax int
-	// These next two variables are just copied from the original DeliverySpec so
+	// These next variables are just copied from the original DeliverySpec so
 	// we can detect if anything has changed. We can not do that with the CheckRetry
 	// Backoff (at least not easily).
-	BackoffDelay  *string
-	BackoffPolicy *v1.BackoffPolicyType
+	BackoffDelay    *string
+	BackoffMaxDelay *string
+	BackoffPolicy   *v1.BackoffPolicyType
+	RetryOn         []string
+	DoNotRetryOn    []string
 
 	CheckRetry CheckRetry
 	Backoff    Backoff
 
 	// RequestTimeout represents the timeout of the single request
 	RequestTimeout time.Duration
+
+	// RetryAfterMaxDuration, when not nil, makes the sender honor the Retry-After header of 429
+	// and 503 responses, waiting at most this duration before retrying.
+	RetryAfterMaxDuration *time.Duration
+
+	// RateLimit, when greater than 0, is the maximum rate of the events sent to the destination
+	// per second, with bursts of at most RateLimitBurst events.
+	RateLimit      float64
+	RateLimitBurst int
+	// MaxInFlight, when greater than 0, is the maximum number of events sent to the destination
+	// at the same time. An event holds its slot through all its retries, backoff delays included.
+	MaxInFlight int
 }
 
 func NoRetries() RetryConfig {
@@ -83,6 +101,32 @@ func RetryConfigFromDeliverySpec(spec v1.DeliverySpec) (RetryConfig, error) {
 	}
 	retryConfig.BackoffPolicy = spec.BackoffPolicy
 	retryConfig.BackoffDelay = spec.BackoffDelay
+	retryConfig.BackoffMaxDelay = spec.BackoffMaxDelay
+	retryConfig.RetryOn = spec.RetryOn
+	retryConfig.DoNotRetryOn = spec.DoNotRetryOn
+
+	if spec.RateLimit != nil {
+		retryConfig.RateLimit = float64(spec.RateLimit.EventsPerSecond)
+		retryConfig.RateLimitBurst = int(spec.RateLimit.EventsPerSecond)
+		if spec.RateLimit.Burst != nil {
+			retryConfig.RateLimitBurst = int(*spec.RateLimit.Burst)
+		}
+	}
+	if spec.MaxInFlight != nil {
+		retryConfig.MaxInFlight = int(*spec.MaxInFlight)
+	}
+
+	if len(spec.RetryOn) > 0 || len(spec.DoNotRetryOn) > 0 {
+		retryOn, err := parseStatusCodeRanges(spec.RetryOn)
+		if err != nil {
+			return retryConfig, fmt.Errorf("failed to parse Spec.RetryOn: %w", err)
+		}
+		doNotRetryOn, err := parseStatusCodeRanges(spec.DoNotRetryOn)
+		if err != nil {
+			return retryConfig, fmt.Errorf("failed to parse Spec.DoNotRetryOn: %w", err)
+		}
+		retryConfig.CheckRetry = retryOnStatusCodes(retryOn, doNotRetryOn)
+	}
 
 	if spec.BackoffPolicy != nil && spec.BackoffDelay != nil {
 
@@ -92,14 +136,33 @@ func RetryConfigFromDeliverySpec(spec v1.DeliverySpec) (RetryConfig, error) {
 		}
 
 		delayDuration, _ := delay.Duration()
+
+		var maxDelayDuration time.Duration
+		if spec.BackoffMaxDelay != nil {
+			maxDelay, err := period.Parse(*spec.BackoffMaxDelay)
+			if err != nil {
+				return retryConfig, fmt.Errorf("failed to parse Spec.BackoffMaxDelay: %w", err)
+			}
+			maxDelayDuration, _ = maxDelay.Duration()
+		}
+
 		switch *spec.BackoffPolicy {
 		case v1.BackoffPolicyExponential:
 			retryConfig.Backoff = func(attemptNum int, resp *http.Response) time.Duration {
-				return delayDuration * time.Duration(math.Exp2(float64(attemptNum)))
+				return capDelay(exponentialDelay(delayDuration, attemptNum), maxDelayDuration)
+			}
+		case v1.BackoffPolicyExponentialJitter:
+			retryConfig.Backoff = func(attemptNum int, resp *http.Response) time.Duration {
+				return randomDelay(capDelay(exponentialDelay(delayDuration, attemptNum), maxDelayDuration))
+			}
+		case v1.BackoffPolicyExponentialEqualJitter:
+			retryConfig.Backoff = func(attemptNum int, resp *http.Response) time.Duration {
+				d := capDelay(exponentialDelay(delayDuration, attemptNum), maxDelayDuration)
+				return d/2 + randomDelay(d-d/2)
 			}
 		case v1.BackoffPolicyLinear:
 			retryConfig.Backoff = func(attemptNum int, resp *http.Response) time.Duration {
-				return delayDuration * time.Duration(attemptNum)
+				return capDelay(delayDuration*time.Duration(attemptNum), maxDelayDuration)
 			}
 		}
 	}
@@ -112,9 +175,146 @@ func RetryConfigFromDeliverySpec(spec v1.DeliverySpec) (RetryConfig, error) {
 		retryConfig.RequestTimeout, _ = timeout.Duration()
 	}
 
+	if spec.RetryAfterMax != nil {
+		retryAfterMax, err := period.Parse(*spec.RetryAfterMax)
+		if err != nil {
+			return retryConfig, fmt.Errorf("failed to parse Spec.RetryAfterMax: %w", err)
+		}
+		retryAfterMaxDuration, _ := retryAfterMax.Duration()
+		retryConfig.RetryAfterMaxDuration = &retryAfterMaxDuration
+	}
+
 	return retryConfig, nil
 }
 
+// exponentialDelay returns delay*2^attemptNum, saturating instead of overflowing.
+func exponentialDelay(delay time.Duration, attemptNum int) time.Duration {
+	d := float64(delay) * math.Exp2(float64(attemptNum))
+	if d >= math.MaxInt64 {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(d)
+}
+
+// capDelay returns delay capped at max, unless max is 0.
+func capDelay(delay, max time.Duration) time.Duration {
+	if max > 0 && delay > max {
+		return max
+	}
+	return delay
+}
+
+// randomDelay returns a random delay between 0 and max, included.
+func randomDelay(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+	if max == math.MaxInt64 {
+		return time.Duration(rand.Int63())
+	}
+	return time.Duration(rand.Int63n(int64(max) + 1))
+}
+
+// backoff returns how long to wait before the given retry attempt. When RetryAfterMaxDuration
+// is set, the Retry-After header of 429 and 503 responses is honored if it asks for a longer
+// delay than the Backoff policy, up to RetryAfterMaxDuration.
+func (rc *RetryConfig) backoff(attemptNum int, resp *http.Response) time.Duration {
+	backoff := rc.Backoff(attemptNum, resp)
+	if rc.RetryAfterMaxDuration == nil || resp == nil {
+		return backoff
+	}
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return backoff
+	}
+	retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	if !ok {
+		return backoff
+	}
+	if retryAfter > *rc.RetryAfterMaxDuration {
+		retryAfter = *rc.RetryAfterMaxDuration
+	}
+	if retryAfter > backoff {
+		return retryAfter
+	}
+	return backoff
+}
+
+// parseRetryAfter parses the value of a Retry-After header, in either the delay-seconds or the
+// HTTP-date form (https://datatracker.ietf.org/doc/html/rfc7231#section-7.1.3), returning how
+// long to wait from now.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		if seconds > int64(math.MaxInt64/time.Second) {
+			return time.Duration(math.MaxInt64), true
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	if wait := date.Sub(now); wait > 0 {
+		return wait, true
+	}
+	return 0, true
+}
+
+// statusCodeRange is an inclusive range of status codes.
+type statusCodeRange struct {
+	min, max int
+}
+
+type statusCodeRanges []statusCodeRange
+
+func parseStatusCodeRanges(values []string) (statusCodeRanges, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	ranges := make(statusCodeRanges, 0, len(values))
+	for _, v := range values {
+		min, max, err := v1.ParseStatusCodeRange(v)
+		if err != nil {
+			return nil, err
+		}
+		ranges = append(ranges, statusCodeRange{min: min, max: max})
+	}
+	return ranges, nil
+}
+
+func (r statusCodeRanges) contains(statusCode int) bool {
+	for _, sr := range r {
+		if statusCode >= sr.min && statusCode <= sr.max {
+			return true
+		}
+	}
+	return false
+}
+
+// retryOnStatusCodes returns a CheckRetry retrying the requests failing without a response
+// and the responses whose status code isn't in doNotRetryOn and is in retryOn, or isn't
+// 2xx when retryOn is empty.
+func retryOnStatusCodes(retryOn, doNotRetryOn statusCodeRanges) CheckRetry {
+	return func(ctx context.Context, response *http.Response, err error) (bool, error) {
+		if response == nil || response.StatusCode == -1 {
+			return true, err
+		}
+		if doNotRetryOn.contains(response.StatusCode) {
+			return false, err
+		}
+		if retryOn == nil {
+			return RetryIfGreaterThan300(ctx, response, err)
+		}
+		return retryOn.contains(response.StatusCode), err
+	}
+}
+
 // RetryIfGreaterThan300 is a simple default implementation
 func RetryIfGreaterThan300(_ context.Context, response *http.Response, err error) (bool, error) {
 	return !(response != nil && (response.StatusCode < 300 && response.StatusCode != -1)), err
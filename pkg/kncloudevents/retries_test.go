@@ -19,6 +19,7 @@ package kncloudevents
 import (
 	"context"
 	"errors"
+	"math"
 	"net/http"
 	"testing"
 	"time"
@@ -36,6 +37,7 @@ func TestRetryConfigFromDeliverySpec(t *testing.T) {
 		name                     string
 		backoffPolicy            v1.BackoffPolicyType
 		backoffDelay             string
+		backoffMaxDelay          *string
 		expectedBackoffDurations []time.Duration
 		wantErr                  bool
 	}{{
@@ -71,21 +73,52 @@ func TestRetryConfigFromDeliverySpec(t *testing.T) {
 			8 * time.Second,
 			16 * time.Second,
 		},
+	}, {
+		name:            "Successful Linear Backoff 2500ms capped at 6s, 5 retries",
+		backoffPolicy:   v1.BackoffPolicyLinear,
+		backoffDelay:    "PT2.5S",
+		backoffMaxDelay: pointer.StringPtr("PT6S"),
+		expectedBackoffDurations: []time.Duration{
+			2500 * time.Millisecond,
+			5 * time.Second,
+			6 * time.Second,
+			6 * time.Second,
+			6 * time.Second,
+		},
+	}, {
+		name:            "Successful Exponential Backoff 500ms capped at 5s, 5 retries",
+		backoffPolicy:   v1.BackoffPolicyExponential,
+		backoffDelay:    "PT0.5S",
+		backoffMaxDelay: pointer.StringPtr("PT5S"),
+		expectedBackoffDurations: []time.Duration{
+			1 * time.Second,
+			2 * time.Second,
+			4 * time.Second,
+			5 * time.Second,
+			5 * time.Second,
+		},
 	}, {
 		name:          "Invalid Backoff Delay",
 		backoffPolicy: v1.BackoffPolicyLinear,
 		backoffDelay:  "FOO",
 		wantErr:       true,
+	}, {
+		name:            "Invalid Backoff Max Delay",
+		backoffPolicy:   v1.BackoffPolicyExponential,
+		backoffDelay:    "PT1S",
+		backoffMaxDelay: pointer.StringPtr("FOO"),
+		wantErr:         true,
 	}}
 
 	for _, tc := range testcases {
 		t.Run(tc.name, func(t *testing.T) {
 			// Create The DeliverySpec To Test
 			deliverySpec := v1.DeliverySpec{
-				DeadLetterSink: nil,
-				Retry:          ptr.Int32(retry),
-				BackoffPolicy:  &tc.backoffPolicy,
-				BackoffDelay:   &tc.backoffDelay,
+				DeadLetterSink:  nil,
+				Retry:           ptr.Int32(retry),
+				BackoffPolicy:   &tc.backoffPolicy,
+				BackoffDelay:    &tc.backoffDelay,
+				BackoffMaxDelay: tc.backoffMaxDelay,
 			}
 
 			// Create the RetryConfig from the deliverySpec
@@ -105,6 +138,113 @@ func TestRetryConfigFromDeliverySpec(t *testing.T) {
 	}
 }
 
+func TestRetryConfigFromDeliverySpecJitter(t *testing.T) {
+	testcases := []struct {
+		name            string
+		backoffPolicy   v1.BackoffPolicyType
+		backoffMaxDelay *string
+		attemptNum      int
+		min             time.Duration
+		max             time.Duration
+	}{{
+		name:          "full jitter",
+		backoffPolicy: v1.BackoffPolicyExponentialJitter,
+		attemptNum:    3,
+		min:           0,
+		max:           8 * time.Second,
+	}, {
+		name:            "full jitter capped",
+		backoffPolicy:   v1.BackoffPolicyExponentialJitter,
+		backoffMaxDelay: pointer.StringPtr("PT5S"),
+		attemptNum:      10,
+		min:             0,
+		max:             5 * time.Second,
+	}, {
+		name:          "equal jitter",
+		backoffPolicy: v1.BackoffPolicyExponentialEqualJitter,
+		attemptNum:    3,
+		min:           4 * time.Second,
+		max:           8 * time.Second,
+	}, {
+		name:            "equal jitter capped",
+		backoffPolicy:   v1.BackoffPolicyExponentialEqualJitter,
+		backoffMaxDelay: pointer.StringPtr("PT5S"),
+		attemptNum:      10,
+		min:             2500 * time.Millisecond,
+		max:             5 * time.Second,
+	}, {
+		name:          "equal jitter without overflow",
+		backoffPolicy: v1.BackoffPolicyExponentialEqualJitter,
+		attemptNum:    100,
+		min:           time.Duration(math.MaxInt64 / 2),
+		max:           time.Duration(math.MaxInt64),
+	}}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			retryConfig, err := RetryConfigFromDeliverySpec(v1.DeliverySpec{
+				Retry:           ptr.Int32(int32(tc.attemptNum)),
+				BackoffPolicy:   &tc.backoffPolicy,
+				BackoffDelay:    pointer.StringPtr("PT1S"),
+				BackoffMaxDelay: tc.backoffMaxDelay,
+			})
+			if err != nil {
+				t.Fatal("RetryConfigFromDeliverySpec() =", err)
+			}
+
+			distinct := make(map[time.Duration]struct{})
+			for i := 0; i < 100; i++ {
+				got := retryConfig.Backoff(tc.attemptNum, nil)
+				if got < tc.min || got > tc.max {
+					t.Fatalf("Backoff() = %v, want between %v and %v", got, tc.min, tc.max)
+				}
+				distinct[got] = struct{}{}
+			}
+			if len(distinct) < 2 {
+				t.Error("Backoff() must be randomized")
+			}
+		})
+	}
+}
+
+func TestRetryConfigFromDeliverySpecLimits(t *testing.T) {
+	testcases := []struct {
+		name            string
+		rateLimit       *v1.RateLimit
+		maxInFlight     *int32
+		wantRateLimit   float64
+		wantBurst       int
+		wantMaxInFlight int
+	}{{
+		name: "Unlimited",
+	}, {
+		name:          "Rate limit without burst",
+		rateLimit:     &v1.RateLimit{EventsPerSecond: 10},
+		wantRateLimit: 10,
+		wantBurst:     10,
+	}, {
+		name:            "Rate limit with burst and max in flight",
+		rateLimit:       &v1.RateLimit{EventsPerSecond: 10, Burst: ptr.Int32(20)},
+		maxInFlight:     ptr.Int32(5),
+		wantRateLimit:   10,
+		wantBurst:       20,
+		wantMaxInFlight: 5,
+	}}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			retryConfig, err := RetryConfigFromDeliverySpec(v1.DeliverySpec{
+				RateLimit:   tc.rateLimit,
+				MaxInFlight: tc.maxInFlight,
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantRateLimit, retryConfig.RateLimit)
+			assert.Equal(t, tc.wantBurst, retryConfig.RateLimitBurst)
+			assert.Equal(t, tc.wantMaxInFlight, retryConfig.MaxInFlight)
+		})
+	}
+}
+
 func TestRetryIfGreaterThan300(t *testing.T) {
 
 	// Define The TestCase Type
@@ -362,6 +502,27 @@ func TestRetryConfigFromDeliverySpecCheckRetry(t *testing.T) {
 			Timeout: pointer.StringPtr("PP1"),
 		},
 		wantErr: true,
+	}, {
+		name: "invalid retry on",
+		spec: v1.DeliverySpec{
+			Retry:   pointer.Int32Ptr(10),
+			RetryOn: []string{"5xx"},
+		},
+		wantErr: true,
+	}, {
+		name: "invalid do not retry on",
+		spec: v1.DeliverySpec{
+			Retry:        pointer.Int32Ptr(10),
+			DoNotRetryOn: []string{"499-400"},
+		},
+		wantErr: true,
+	}, {
+		name: "retry after max not ISO8601",
+		spec: v1.DeliverySpec{
+			Retry:         pointer.Int32Ptr(10),
+			RetryAfterMax: pointer.StringPtr("PP1"),
+		},
+		wantErr: true,
 	}}
 
 	for _, tt := range tests {
@@ -388,3 +549,182 @@ func TestRetryConfigFromDeliverySpecCheckRetry(t *testing.T) {
 		})
 	}
 }
+
+func TestRetryConfigFromDeliverySpecRetryOn(t *testing.T) {
+	tests := []struct {
+		name         string
+		retryOn      []string
+		doNotRetryOn []string
+		want         map[int]bool
+	}{{
+		name:    "retry on",
+		retryOn: []string{"409", "500-599"},
+		want: map[int]bool{
+			-1:  true,
+			200: false,
+			404: false,
+			409: true,
+			422: false,
+			500: true,
+			503: true,
+		},
+	}, {
+		name:         "do not retry on",
+		doNotRetryOn: []string{"422", "501-503"},
+		want: map[int]bool{
+			-1:  true,
+			200: false,
+			404: true,
+			409: true,
+			422: false,
+			500: true,
+			502: false,
+		},
+	}, {
+		name:         "do not retry on takes precedence",
+		retryOn:      []string{"400-599"},
+		doNotRetryOn: []string{"422"},
+		want: map[int]bool{
+			200: false,
+			409: true,
+			422: false,
+			500: true,
+		},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := RetryConfigFromDeliverySpec(v1.DeliverySpec{
+				Retry:        pointer.Int32Ptr(1),
+				RetryOn:      tt.retryOn,
+				DoNotRetryOn: tt.doNotRetryOn,
+			})
+			if err != nil {
+				t.Fatal("RetryConfigFromDeliverySpec() =", err)
+			}
+			for statusCode, want := range tt.want {
+				got, err := config.CheckRetry(context.Background(), &http.Response{StatusCode: statusCode}, nil)
+				if err != nil {
+					t.Error("CheckRetry() =", err)
+				}
+				if got != want {
+					t.Errorf("CheckRetry(%d) = %v, want %v", statusCode, got, want)
+				}
+			}
+
+			sendErr := errors.New("connection refused")
+			got, err := config.CheckRetry(context.Background(), nil, sendErr)
+			if !got || err != sendErr {
+				t.Errorf("CheckRetry(nil) = %v, %v, want true, %v", got, err, sendErr)
+			}
+		})
+	}
+}
+
+func TestRetryConfigBackoffRetryAfter(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name          string
+		retryAfterMax *string
+		statusCode    int
+		retryAfter    string
+		want          time.Duration
+	}{{
+		name:       "Retry-After ignored without retryAfterMax",
+		statusCode: http.StatusTooManyRequests,
+		retryAfter: "10",
+		want:       time.Second,
+	}, {
+		name:          "delay-seconds",
+		retryAfterMax: pointer.StringPtr("PT1M"),
+		statusCode:    http.StatusTooManyRequests,
+		retryAfter:    "10",
+		want:          10 * time.Second,
+	}, {
+		name:          "delay-seconds capped",
+		retryAfterMax: pointer.StringPtr("PT5S"),
+		statusCode:    http.StatusServiceUnavailable,
+		retryAfter:    "10",
+		want:          5 * time.Second,
+	}, {
+		name:          "delay-seconds shorter than the backoff",
+		retryAfterMax: pointer.StringPtr("PT1M"),
+		statusCode:    http.StatusServiceUnavailable,
+		retryAfter:    "0",
+		want:          time.Second,
+	}, {
+		name:          "HTTP-date",
+		retryAfterMax: pointer.StringPtr("PT1H"),
+		statusCode:    http.StatusServiceUnavailable,
+		retryAfter:    now.Add(time.Hour).UTC().Format(http.TimeFormat),
+		want:          time.Hour,
+	}, {
+		name:          "HTTP-date in the past",
+		retryAfterMax: pointer.StringPtr("PT1H"),
+		statusCode:    http.StatusServiceUnavailable,
+		retryAfter:    now.Add(-time.Hour).UTC().Format(http.TimeFormat),
+		want:          time.Second,
+	}, {
+		name:          "invalid Retry-After",
+		retryAfterMax: pointer.StringPtr("PT1M"),
+		statusCode:    http.StatusTooManyRequests,
+		retryAfter:    "soon",
+		want:          time.Second,
+	}, {
+		name:          "other status codes",
+		retryAfterMax: pointer.StringPtr("PT1M"),
+		statusCode:    http.StatusInternalServerError,
+		retryAfter:    "10",
+		want:          time.Second,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			linear := v1.BackoffPolicyLinear
+			config, err := RetryConfigFromDeliverySpec(v1.DeliverySpec{
+				Retry:         pointer.Int32Ptr(1),
+				BackoffPolicy: &linear,
+				BackoffDelay:  pointer.StringPtr("PT1S"),
+				RetryAfterMax: tt.retryAfterMax,
+			})
+			if err != nil {
+				t.Fatal("RetryConfigFromDeliverySpec() =", err)
+			}
+			resp := &http.Response{
+				StatusCode: tt.statusCode,
+				Header:     http.Header{"Retry-After": []string{tt.retryAfter}},
+			}
+			// The HTTP-date form has a second resolution.
+			if got := config.backoff(1, resp); got < tt.want-time.Second || got > tt.want {
+				t.Errorf("backoff() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOk bool
+	}{
+		{value: "", wantOk: false},
+		{value: "120", want: 2 * time.Minute, wantOk: true},
+		{value: " 5 ", want: 5 * time.Second, wantOk: true},
+		{value: "-1", wantOk: false},
+		{value: "99999999999999999", want: time.Duration(math.MaxInt64), wantOk: true},
+		{value: "Tue, 01 Jun 2021 12:00:30 GMT", want: 30 * time.Second, wantOk: true},
+		{value: "Tuesday, 01-Jun-21 12:01:00 GMT", want: time.Minute, wantOk: true},
+		{value: "Tue, 01 Jun 2021 11:00:00 GMT", want: 0, wantOk: true},
+		{value: "tomorrow", wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, ok := parseRetryAfter(tt.value, now)
+			if ok != tt.wantOk || got != tt.want {
+				t.Errorf("parseRetryAfter() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
@@ -30,6 +30,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/kubernetes"
 
 	duckv1 "knative.dev/pkg/apis/duck/v1"
@@ -49,10 +50,18 @@ const (
 	// Name of the corev1.Events emitted from the reconciliation process
 	apiserversourceDeploymentCreated = "ApiServerSourceDeploymentCreated"
 	apiserversourceDeploymentUpdated = "ApiServerSourceDeploymentUpdated"
+	apiserversourceBindingCreated    = "ApiServerSourceClusterRoleBindingCreated"
 
 	component = "apiserversource"
 )
 
+// operationEventTypes are the CloudEvent types sent for each operation.
+var operationEventTypes = map[string]sets.String{
+	v1.AddOperation:    sets.NewString(apisources.ApiServerSourceAddEventType, apisources.ApiServerSourceAddRefEventType),
+	v1.UpdateOperation: sets.NewString(apisources.ApiServerSourceUpdateEventType, apisources.ApiServerSourceUpdateRefEventType),
+	v1.DeleteOperation: sets.NewString(apisources.ApiServerSourceDeleteEventType, apisources.ApiServerSourceDeleteRefEventType),
+}
+
 func newWarningSinkNotFound(sink *duckv1.Destination) pkgreconciler.Event {
 	b, _ := json.Marshal(sink)
 	return pkgreconciler.NewEvent(corev1.EventTypeWarning, "SinkNotFound", "Sink not found: %s", string(b))
@@ -71,6 +80,7 @@ type Reconciler struct {
 }
 
 var _ apiserversourcereconciler.Interface = (*Reconciler)(nil)
+var _ apiserversourcereconciler.Finalizer = (*Reconciler)(nil)
 
 func (r *Reconciler) ReconcileKind(ctx context.Context, source *v1.ApiServerSource) pkgreconciler.Event {
 	// This Source attempts to reconcile three things.
@@ -104,6 +114,11 @@ func (r *Reconciler) ReconcileKind(ctx context.Context, source *v1.ApiServerSour
 		return err
 	}
 
+	if err := r.reconcileNamespacesBinding(ctx, source); err != nil {
+		logging.FromContext(ctx).Errorw("Unable to bind the receive adapter to the namespaces ClusterRole", zap.Error(err))
+		return err
+	}
+
 	ra, err := r.createReceiveAdapter(ctx, source, sinkURI.String())
 	if err != nil {
 		logging.FromContext(ctx).Errorw("Unable to create the receive adapter", zap.Error(err))
@@ -121,6 +136,44 @@ func (r *Reconciler) ReconcileKind(ctx context.Context, source *v1.ApiServerSour
 	return nil
 }
 
+// FinalizeKind deletes the ClusterRoleBinding of the receive adapter of source, which can't be
+// garbage collected with it.
+func (r *Reconciler) FinalizeKind(ctx context.Context, source *v1.ApiServerSource) pkgreconciler.Event {
+	name := resources.NamespacesClusterRoleBindingName(source)
+	err := r.kubeClientSet.RbacV1().ClusterRoleBindings().Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("error deleting ClusterRoleBinding %q: %w", name, err)
+	}
+	return nil
+}
+
+// reconcileNamespacesBinding allows the receive adapter of src to watch the namespaces when it
+// selects them, removing the binding otherwise.
+func (r *Reconciler) reconcileNamespacesBinding(ctx context.Context, src *v1.ApiServerSource) error {
+	expected := resources.MakeNamespacesClusterRoleBinding(src)
+
+	crb, err := r.kubeClientSet.RbacV1().ClusterRoleBindings().Get(ctx, expected.Name, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err) && src.Spec.NamespaceSelector == nil:
+		return nil
+	case apierrors.IsNotFound(err):
+		if _, err := r.kubeClientSet.RbacV1().ClusterRoleBindings().Create(ctx, expected, metav1.CreateOptions{}); err != nil {
+			return err
+		}
+		controller.GetEventRecorder(ctx).Eventf(src, corev1.EventTypeNormal, apiserversourceBindingCreated, "ClusterRoleBinding %q created", expected.Name)
+		return nil
+	case err != nil:
+		return fmt.Errorf("error getting ClusterRoleBinding: %v", err)
+	case src.Spec.NamespaceSelector == nil:
+		return r.FinalizeKind(ctx, src)
+	case !equality.Semantic.DeepEqual(crb.Subjects, expected.Subjects):
+		crb.Subjects = expected.Subjects
+		_, err = r.kubeClientSet.RbacV1().ClusterRoleBindings().Update(ctx, crb, metav1.UpdateOptions{})
+		return err
+	}
+	return nil
+}
+
 func (r *Reconciler) createReceiveAdapter(ctx context.Context, src *v1.ApiServerSource, sinkURI string) (*appsv1.Deployment, error) {
 	// TODO: missing.
 	// if err := checkResourcesStatus(src); err != nil {
@@ -196,6 +249,12 @@ func (r *Reconciler) runAccessCheck(ctx context.Context, src *v1.ApiServerSource
 	verbs := []string{"get", "list", "watch"}
 	lastReason := ""
 
+	// The receive adapter watches the resources of all the namespaces when it selects them.
+	namespace := src.Namespace
+	if src.Spec.NamespaceSelector != nil {
+		namespace = metav1.NamespaceAll
+	}
+
 	// Collect all missing permissions.
 	missing := ""
 	sep := ""
@@ -212,7 +271,7 @@ func (r *Reconciler) runAccessCheck(ctx context.Context, src *v1.ApiServerSource
 			sar := &authorizationv1.SubjectAccessReview{
 				Spec: authorizationv1.SubjectAccessReviewSpec{
 					ResourceAttributes: &authorizationv1.ResourceAttributes{
-						Namespace: src.Namespace,
+						Namespace: namespace,
 						Verb:      verb,
 						Group:     gv.Group,
 						Resource:  gvr.Resource,
@@ -233,6 +292,9 @@ func (r *Reconciler) runAccessCheck(ctx context.Context, src *v1.ApiServerSource
 		}
 		if missingVerbs != "" {
 			missing += sep + missingVerbs + ` resource "` + gvr.Resource + `" in API group "` + gv.Group + `"`
+			if namespace == metav1.NamespaceAll {
+				missing += " in all the namespaces"
+			}
 			sep = ", "
 		}
 	}
@@ -257,6 +319,9 @@ func (r *Reconciler) createCloudEventAttributes(src *v1.ApiServerSource) ([]duck
 	}
 	ceAttributes := make([]duckv1.CloudEventAttributes, 0, len(eventTypes))
 	for _, apiServerSourceType := range eventTypes {
+		if !emits(src, apiServerSourceType) {
+			continue
+		}
 		ceAttributes = append(ceAttributes, duckv1.CloudEventAttributes{
 			Type:   apiServerSourceType,
 			Source: r.ceSource,
@@ -264,3 +329,16 @@ func (r *Reconciler) createCloudEventAttributes(src *v1.ApiServerSource) ([]duck
 	}
 	return ceAttributes, nil
 }
+
+// emits tells whether src sends the events of type eventType, as told by its operations.
+func emits(src *v1.ApiServerSource, eventType string) bool {
+	if len(src.Spec.Operations) == 0 {
+		return true
+	}
+	for _, op := range src.Spec.Operations {
+		if operationEventTypes[op].Has(eventType) {
+			return true
+		}
+	}
+	return false
+}
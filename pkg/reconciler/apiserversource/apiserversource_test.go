@@ -25,10 +25,13 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	authorizationv1 "k8s.io/api/authorization/v1"
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	clientgotesting "k8s.io/client-go/testing"
 
+	apisources "knative.dev/eventing/pkg/apis/sources"
 	sourcesv1 "knative.dev/eventing/pkg/apis/sources/v1"
 	fakeeventingclient "knative.dev/eventing/pkg/client/injection/client/fake"
 	"knative.dev/eventing/pkg/client/injection/reconciler/sources/v1/apiserversource"
@@ -110,6 +113,9 @@ func TestReconcile(t *testing.T) {
 			makeAvailableReceiveAdapter(t),
 		},
 		Key: testNS + "/" + sourceName,
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchFinalizers(testNS, sourceName),
+		},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
 			Object: rttestingv1.NewApiServerSource(sourceName, testNS,
 				rttestingv1.WithApiServerSourceSpec(sourcesv1.ApiServerSourceSpec{
@@ -135,6 +141,7 @@ func TestReconcile(t *testing.T) {
 		},
 		WantErr: true,
 		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", sourceName),
 			Eventf(corev1.EventTypeWarning, "InternalError", `Insufficient permission: user system:serviceaccount:testnamespace:default cannot get, list, watch resource "namespaces" in API group ""`),
 		},
 		WithReactors:            []clientgotesting.ReactionFunc{subjectAccessReviewCreateReactor(false)},
@@ -160,6 +167,12 @@ func TestReconcile(t *testing.T) {
 			makeAvailableReceiveAdapter(t),
 		},
 		Key: testNS + "/" + sourceName,
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchFinalizers(testNS, sourceName),
+		},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", sourceName),
+		},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
 			Object: rttestingv1.NewApiServerSource(sourceName, testNS,
 				rttestingv1.WithApiServerSourceSpec(sourcesv1.ApiServerSourceSpec{
@@ -209,6 +222,12 @@ func TestReconcile(t *testing.T) {
 			makeAvailableReceiveAdapterWithEventMode(t, sourcesv1.ResourceMode),
 		},
 		Key: testNS + "/" + sourceName,
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchFinalizers(testNS, sourceName),
+		},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", sourceName),
+		},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
 			Object: rttestingv1.NewApiServerSource(sourceName, testNS,
 				rttestingv1.WithApiServerSourceSpec(sourcesv1.ApiServerSourceSpec{
@@ -258,6 +277,12 @@ func TestReconcile(t *testing.T) {
 			makeAvailableReceiveAdapter(t),
 		},
 		Key: testNS + "/" + sourceName,
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchFinalizers(testNS, sourceName),
+		},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", sourceName),
+		},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
 			Object: rttestingv1.NewApiServerSource(sourceName, testNS,
 				rttestingv1.WithApiServerSourceSpec(sourcesv1.ApiServerSourceSpec{
@@ -301,7 +326,11 @@ func TestReconcile(t *testing.T) {
 			),
 		},
 		Key: testNS + "/" + sourceName,
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchFinalizers(testNS, sourceName),
+		},
 		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", sourceName),
 			Eventf(corev1.EventTypeWarning, "SinkNotFound",
 				`Sink not found: {"ref":{"kind":"Channel","namespace":"testnamespace","name":"testsink","apiVersion":"messaging.knative.dev/v1"}}`),
 		},
@@ -343,9 +372,13 @@ func TestReconcile(t *testing.T) {
 				rttestingv1.WithChannelAddress(sinkDNS),
 			),
 		},
-		Key:     testNS + "/" + sourceName,
+		Key: testNS + "/" + sourceName,
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchFinalizers(testNS, sourceName),
+		},
 		WantErr: true,
 		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", sourceName),
 			Eventf(corev1.EventTypeNormal, apiserversourceDeploymentCreated,
 				"Deployment created, error:inducing failure for create deployments"),
 			Eventf(corev1.EventTypeWarning, "InternalError",
@@ -407,6 +440,12 @@ func TestReconcile(t *testing.T) {
 			makeAvailableReceiveAdapterWithTargetURI(t),
 		},
 		Key: testNS + "/" + sourceName,
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchFinalizers(testNS, sourceName),
+		},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", sourceName),
+		},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
 			Object: rttestingv1.NewApiServerSource(sourceName, testNS,
 				rttestingv1.WithApiServerSourceSpec(sourcesv1.ApiServerSourceSpec{
@@ -460,7 +499,11 @@ func TestReconcile(t *testing.T) {
 			makeReceiveAdapterWithDifferentEnv(t),
 		},
 		Key: testNS + "/" + sourceName,
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchFinalizers(testNS, sourceName),
+		},
 		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", sourceName),
 			Eventf(corev1.EventTypeNormal, "ApiServerSourceDeploymentUpdated", `Deployment "apiserversource-test-apiserver-source-1234" updated`),
 		},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
@@ -517,7 +560,11 @@ func TestReconcile(t *testing.T) {
 			makeReceiveAdapterWithDifferentServiceAccount(t, "morgan"),
 		},
 		Key: testNS + "/" + sourceName,
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchFinalizers(testNS, sourceName),
+		},
 		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", sourceName),
 			Eventf(corev1.EventTypeNormal, "ApiServerSourceDeploymentUpdated", `Deployment "apiserversource-test-apiserver-source-1234" updated`),
 		},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
@@ -574,7 +621,11 @@ func TestReconcile(t *testing.T) {
 			makeReceiveAdapterWithDifferentContainerCount(t),
 		},
 		Key: testNS + "/" + sourceName,
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchFinalizers(testNS, sourceName),
+		},
 		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", sourceName),
 			Eventf(corev1.EventTypeNormal, "ApiServerSourceDeploymentUpdated", `Deployment "apiserversource-test-apiserver-source-1234" updated`),
 		},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
@@ -628,6 +679,12 @@ func TestReconcile(t *testing.T) {
 			makeAvailableReceiveAdapter(t),
 		},
 		Key: testNS + "/" + sourceName,
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchFinalizers(testNS, sourceName),
+		},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", sourceName),
+		},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
 			Object: rttestingv1.NewApiServerSource(sourceName, testNS,
 				rttestingv1.WithApiServerSourceSpec(sourcesv1.ApiServerSourceSpec{
@@ -655,6 +712,216 @@ func TestReconcile(t *testing.T) {
 		},
 		WithReactors:            []clientgotesting.ReactionFunc{subjectAccessReviewCreateReactor(true)},
 		SkipNamespaceValidation: true, // SubjectAccessReview objects are cluster-scoped.
+	}, {
+		Name: "not enough permissions in all the namespaces",
+		Objects: []runtime.Object{
+			rttestingv1.NewApiServerSource(sourceName, testNS,
+				rttestingv1.WithApiServerSourceSpec(sourcesv1.ApiServerSourceSpec{
+					Resources: []sourcesv1.APIVersionKindSelector{{
+						APIVersion: "v1",
+						Kind:       "Pod",
+					}},
+					NamespaceSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{"env": "test"},
+					},
+					Operations: []string{sourcesv1.AddOperation, sourcesv1.DeleteOperation},
+					SourceSpec: duckv1.SourceSpec{Sink: sinkDest},
+				}),
+				rttestingv1.WithApiServerSourceUID(sourceUID),
+				rttestingv1.WithApiServerSourceObjectMetaGeneration(generation),
+			),
+			rttestingv1.NewChannel(sinkName, testNS,
+				rttestingv1.WithInitChannelConditions,
+				rttestingv1.WithChannelAddress(sinkDNS),
+			),
+		},
+		Key: testNS + "/" + sourceName,
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchFinalizers(testNS, sourceName),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: rttestingv1.NewApiServerSource(sourceName, testNS,
+				rttestingv1.WithApiServerSourceSpec(sourcesv1.ApiServerSourceSpec{
+					Resources: []sourcesv1.APIVersionKindSelector{{
+						APIVersion: "v1",
+						Kind:       "Pod",
+					}},
+					NamespaceSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{"env": "test"},
+					},
+					Operations: []string{sourcesv1.AddOperation, sourcesv1.DeleteOperation},
+					SourceSpec: duckv1.SourceSpec{Sink: sinkDest},
+				}),
+				rttestingv1.WithApiServerSourceUID(sourceUID),
+				rttestingv1.WithApiServerSourceObjectMetaGeneration(generation),
+				// Status Update:
+				rttestingv1.WithInitApiServerSourceConditions,
+				rttestingv1.WithApiServerSourceStatusObservedGeneration(generation),
+				rttestingv1.WithApiServerSourceSink(sinkURI),
+				rttestingv1.WithApiServerSourceNoSufficientPermissionsFor(`get, list, watch resource "pods" in API group "" in all the namespaces`),
+			),
+		}},
+		WantCreates: []runtime.Object{
+			makeSubjectAccessReviewInNamespace("pods", "get", "default", metav1.NamespaceAll),
+			makeSubjectAccessReviewInNamespace("pods", "list", "default", metav1.NamespaceAll),
+			makeSubjectAccessReviewInNamespace("pods", "watch", "default", metav1.NamespaceAll),
+		},
+		WantErr: true,
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", sourceName),
+			Eventf(corev1.EventTypeWarning, "InternalError", `Insufficient permission: user system:serviceaccount:testnamespace:default cannot get, list, watch resource "pods" in API group "" in all the namespaces`),
+		},
+		WithReactors:            []clientgotesting.ReactionFunc{subjectAccessReviewCreateReactor(false)},
+		SkipNamespaceValidation: true, // SubjectAccessReview objects are cluster-scoped.
+	}, {
+		Name: "valid with namespace selector and operations",
+		Objects: []runtime.Object{
+			rttestingv1.NewApiServerSource(sourceName, testNS,
+				rttestingv1.WithApiServerSourceSpec(sourcesv1.ApiServerSourceSpec{
+					Resources: []sourcesv1.APIVersionKindSelector{{
+						APIVersion: "v1",
+						Kind:       "Pod",
+					}},
+					NamespaceSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{"env": "test"},
+					},
+					Operations: []string{sourcesv1.AddOperation, sourcesv1.DeleteOperation},
+					SourceSpec: duckv1.SourceSpec{Sink: sinkDest},
+				}),
+				rttestingv1.WithApiServerSourceUID(sourceUID),
+				rttestingv1.WithApiServerSourceObjectMetaGeneration(generation),
+			),
+			rttestingv1.NewChannel(sinkName, testNS,
+				rttestingv1.WithInitChannelConditions,
+				rttestingv1.WithChannelAddress(sinkDNS),
+			),
+			makeAvailableReceiveAdapterWithNamespaceSelector(t),
+		},
+		Key: testNS + "/" + sourceName,
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchFinalizers(testNS, sourceName),
+		},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", sourceName),
+			Eventf(corev1.EventTypeNormal, apiserversourceBindingCreated, `ClusterRoleBinding "apiserversource-testnamespace-test-apiserver-source-1234" created`),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: rttestingv1.NewApiServerSource(sourceName, testNS,
+				rttestingv1.WithApiServerSourceSpec(sourcesv1.ApiServerSourceSpec{
+					Resources: []sourcesv1.APIVersionKindSelector{{
+						APIVersion: "v1",
+						Kind:       "Pod",
+					}},
+					NamespaceSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{"env": "test"},
+					},
+					Operations: []string{sourcesv1.AddOperation, sourcesv1.DeleteOperation},
+					SourceSpec: duckv1.SourceSpec{Sink: sinkDest},
+				}),
+				rttestingv1.WithApiServerSourceUID(sourceUID),
+				rttestingv1.WithApiServerSourceObjectMetaGeneration(generation),
+				// Status Update:
+				rttestingv1.WithInitApiServerSourceConditions,
+				rttestingv1.WithApiServerSourceDeployed,
+				rttestingv1.WithApiServerSourceSink(sinkURI),
+				rttestingv1.WithApiServerSourceSufficientPermissions,
+				rttestingv1.WithApiServerSourceEventTypes(source, apisources.ApiServerSourceAddRefEventType, apisources.ApiServerSourceDeleteRefEventType),
+				rttestingv1.WithApiServerSourceStatusObservedGeneration(generation),
+			),
+		}},
+		WantCreates: []runtime.Object{
+			makeSubjectAccessReviewInNamespace("pods", "get", "default", metav1.NamespaceAll),
+			makeSubjectAccessReviewInNamespace("pods", "list", "default", metav1.NamespaceAll),
+			makeSubjectAccessReviewInNamespace("pods", "watch", "default", metav1.NamespaceAll),
+			makeNamespacesClusterRoleBinding(),
+		},
+		WithReactors:            []clientgotesting.ReactionFunc{subjectAccessReviewCreateReactor(true)},
+		SkipNamespaceValidation: true, // SubjectAccessReview objects are cluster-scoped.
+	}, {
+		Name: "namespace selector removed",
+		Objects: []runtime.Object{
+			rttestingv1.NewApiServerSource(sourceName, testNS,
+				rttestingv1.WithApiServerSourceSpec(sourcesv1.ApiServerSourceSpec{
+					Resources: []sourcesv1.APIVersionKindSelector{{
+						APIVersion: "v1",
+						Kind:       "Namespace",
+					}},
+					SourceSpec: duckv1.SourceSpec{Sink: sinkDest},
+				}),
+				rttestingv1.WithApiServerSourceUID(sourceUID),
+				rttestingv1.WithApiServerSourceObjectMetaGeneration(generation),
+				rttestingv1.WithApiServerSourceFinalizers("apiserversources.sources.knative.dev"),
+			),
+			rttestingv1.NewChannel(sinkName, testNS,
+				rttestingv1.WithInitChannelConditions,
+				rttestingv1.WithChannelAddress(sinkDNS),
+			),
+			makeAvailableReceiveAdapter(t),
+			makeNamespacesClusterRoleBinding(),
+		},
+		Key: testNS + "/" + sourceName,
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: rttestingv1.NewApiServerSource(sourceName, testNS,
+				rttestingv1.WithApiServerSourceSpec(sourcesv1.ApiServerSourceSpec{
+					Resources: []sourcesv1.APIVersionKindSelector{{
+						APIVersion: "v1",
+						Kind:       "Namespace",
+					}},
+					SourceSpec: duckv1.SourceSpec{Sink: sinkDest},
+				}),
+				rttestingv1.WithApiServerSourceUID(sourceUID),
+				rttestingv1.WithApiServerSourceObjectMetaGeneration(generation),
+				rttestingv1.WithApiServerSourceFinalizers("apiserversources.sources.knative.dev"),
+				// Status Update:
+				rttestingv1.WithInitApiServerSourceConditions,
+				rttestingv1.WithApiServerSourceDeployed,
+				rttestingv1.WithApiServerSourceSink(sinkURI),
+				rttestingv1.WithApiServerSourceSufficientPermissions,
+				rttestingv1.WithApiServerSourceReferenceModeEventTypes(source),
+				rttestingv1.WithApiServerSourceStatusObservedGeneration(generation),
+			),
+		}},
+		WantCreates: []runtime.Object{
+			makeSubjectAccessReview("namespaces", "get", "default"),
+			makeSubjectAccessReview("namespaces", "list", "default"),
+			makeSubjectAccessReview("namespaces", "watch", "default"),
+		},
+		WantDeletes: []clientgotesting.DeleteActionImpl{
+			deleteNamespacesClusterRoleBinding(),
+		},
+		WithReactors:            []clientgotesting.ReactionFunc{subjectAccessReviewCreateReactor(true)},
+		SkipNamespaceValidation: true, // SubjectAccessReview objects are cluster-scoped.
+	}, {
+		Name: "deleted",
+		Objects: []runtime.Object{
+			rttestingv1.NewApiServerSource(sourceName, testNS,
+				rttestingv1.WithApiServerSourceSpec(sourcesv1.ApiServerSourceSpec{
+					Resources: []sourcesv1.APIVersionKindSelector{{
+						APIVersion: "v1",
+						Kind:       "Pod",
+					}},
+					NamespaceSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{"env": "test"},
+					},
+					SourceSpec: duckv1.SourceSpec{Sink: sinkDest},
+				}),
+				rttestingv1.WithApiServerSourceUID(sourceUID),
+				rttestingv1.WithApiServerSourceFinalizers("apiserversources.sources.knative.dev"),
+				rttestingv1.WithApiServerSourceDeleted,
+			),
+			makeNamespacesClusterRoleBinding(),
+		},
+		Key: testNS + "/" + sourceName,
+		WantDeletes: []clientgotesting.DeleteActionImpl{
+			deleteNamespacesClusterRoleBinding(),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchRemoveFinalizers(testNS, sourceName),
+		},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", sourceName),
+		},
+		SkipNamespaceValidation: true, // ClusterRoleBinding objects are cluster-scoped.
 	}}
 
 	logger := logtesting.TestLogger(t)
@@ -785,6 +1052,58 @@ func makeAvailableReceiveAdapterWithEventMode(t *testing.T, eventMode string) *a
 	return ra
 }
 
+func makeAvailableReceiveAdapterWithNamespaceSelector(t *testing.T) *appsv1.Deployment {
+	t.Helper()
+
+	src := rttestingv1.NewApiServerSource(sourceName, testNS,
+		rttestingv1.WithApiServerSourceSpec(sourcesv1.ApiServerSourceSpec{
+			Resources: []sourcesv1.APIVersionKindSelector{{
+				APIVersion: "v1",
+				Kind:       "Pod",
+			}},
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"env": "test"},
+			},
+			Operations: []string{sourcesv1.AddOperation, sourcesv1.DeleteOperation},
+			SourceSpec: duckv1.SourceSpec{Sink: sinkDest},
+		}),
+		rttestingv1.WithApiServerSourceUID(sourceUID),
+		// Status Update:
+		rttestingv1.WithInitApiServerSourceConditions,
+		rttestingv1.WithApiServerSourceDeployed,
+		rttestingv1.WithApiServerSourceSink(sinkURI),
+	)
+
+	args := resources.ReceiveAdapterArgs{
+		Image:   image,
+		Source:  src,
+		Labels:  resources.Labels(sourceName),
+		SinkURI: sinkURI.String(),
+		Configs: &reconcilersource.EmptyVarsGenerator{},
+	}
+
+	ra, err := resources.MakeReceiveAdapter(&args)
+	require.NoError(t, err)
+
+	rttesting.WithDeploymentAvailable()(ra)
+	return ra
+}
+
+func makeNamespacesClusterRoleBinding() *rbacv1.ClusterRoleBinding {
+	return resources.MakeNamespacesClusterRoleBinding(rttestingv1.NewApiServerSource(sourceName, testNS,
+		rttestingv1.WithApiServerSourceUID(sourceUID),
+	))
+}
+
+func deleteNamespacesClusterRoleBinding() clientgotesting.DeleteActionImpl {
+	return clientgotesting.DeleteActionImpl{
+		ActionImpl: clientgotesting.ActionImpl{
+			Resource: rbacv1.SchemeGroupVersion.WithResource("clusterrolebindings"),
+		},
+		Name: makeNamespacesClusterRoleBinding().Name,
+	}
+}
+
 func makeReceiveAdapterWithDifferentEnv(t *testing.T) *appsv1.Deployment {
 	ra := makeReceiveAdapter(t)
 	ra.Spec.Template.Spec.Containers[0].Env = append(ra.Spec.Template.Spec.Containers[0].Env, corev1.EnvVar{
@@ -806,11 +1125,33 @@ func makeReceiveAdapterWithDifferentContainerCount(t *testing.T) *appsv1.Deploym
 	return ra
 }
 
+func patchFinalizers(namespace, name string) clientgotesting.PatchActionImpl {
+	action := clientgotesting.PatchActionImpl{}
+	action.Name = name
+	action.Namespace = namespace
+	patch := `{"metadata":{"finalizers":["apiserversources.sources.knative.dev"],"resourceVersion":""}}`
+	action.Patch = []byte(patch)
+	return action
+}
+
+func patchRemoveFinalizers(namespace, name string) clientgotesting.PatchActionImpl {
+	action := clientgotesting.PatchActionImpl{}
+	action.Name = name
+	action.Namespace = namespace
+	patch := `{"metadata":{"finalizers":[],"resourceVersion":""}}`
+	action.Patch = []byte(patch)
+	return action
+}
+
 func makeSubjectAccessReview(resource, verb, sa string) *authorizationv1.SubjectAccessReview {
+	return makeSubjectAccessReviewInNamespace(resource, verb, sa, testNS)
+}
+
+func makeSubjectAccessReviewInNamespace(resource, verb, sa, ns string) *authorizationv1.SubjectAccessReview {
 	return &authorizationv1.SubjectAccessReview{
 		Spec: authorizationv1.SubjectAccessReviewSpec{
 			ResourceAttributes: &authorizationv1.ResourceAttributes{
-				Namespace: testNS,
+				Namespace: ns,
 				Verb:      verb,
 				Group:     "",
 				Resource:  resource,
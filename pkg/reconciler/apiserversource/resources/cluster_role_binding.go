@@ -0,0 +1,62 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"fmt"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/kmeta"
+
+	v1 "knative.dev/eventing/pkg/apis/sources/v1"
+)
+
+// NamespacesClusterRoleName is the ClusterRole allowing the receive adapters of the sources
+// selecting namespaces to watch them.
+const NamespacesClusterRoleName = "knative-eventing-apiserversource-namespaces"
+
+// NamespacesClusterRoleBindingName returns the name of the ClusterRoleBinding of the service
+// account of the receive adapter of src to the NamespacesClusterRoleName ClusterRole.
+func NamespacesClusterRoleBindingName(src *v1.ApiServerSource) string {
+	return kmeta.ChildName(fmt.Sprintf("apiserversource-%s-%s-", src.Namespace, src.Name), string(src.GetUID()))
+}
+
+// MakeNamespacesClusterRoleBinding generates (but does not insert into K8s) the ClusterRoleBinding
+// allowing the receive adapter of src to watch the namespaces matching its namespace selector.
+func MakeNamespacesClusterRoleBinding(src *v1.ApiServerSource) *rbacv1.ClusterRoleBinding {
+	sa := src.Spec.ServiceAccountName
+	if sa == "" {
+		sa = "default"
+	}
+	return &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   NamespacesClusterRoleBindingName(src),
+			Labels: Labels(src.Name),
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: "rbac.authorization.k8s.io",
+			Kind:     "ClusterRole",
+			Name:     NamespacesClusterRoleName,
+		},
+		Subjects: []rbacv1.Subject{{
+			Kind:      "ServiceAccount",
+			Namespace: src.Namespace,
+			Name:      sa,
+		}},
+	}
+}
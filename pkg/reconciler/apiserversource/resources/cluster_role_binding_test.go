@@ -0,0 +1,71 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "knative.dev/eventing/pkg/apis/sources/v1"
+)
+
+func TestMakeNamespacesClusterRoleBinding(t *testing.T) {
+	src := &v1.ApiServerSource{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "source-name",
+			Namespace: "source-namespace",
+			UID:       "1234",
+		},
+		Spec: v1.ApiServerSourceSpec{
+			ServiceAccountName: "source-svc-acct",
+		},
+	}
+
+	want := &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "apiserversource-source-namespace-source-name-1234",
+			Labels: map[string]string{
+				"eventing.knative.dev/source":     controllerAgentName,
+				"eventing.knative.dev/sourceName": "source-name",
+			},
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: "rbac.authorization.k8s.io",
+			Kind:     "ClusterRole",
+			Name:     NamespacesClusterRoleName,
+		},
+		Subjects: []rbacv1.Subject{{
+			Kind:      "ServiceAccount",
+			Namespace: "source-namespace",
+			Name:      "source-svc-acct",
+		}},
+	}
+
+	got := MakeNamespacesClusterRoleBinding(src)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Error("unexpected ClusterRoleBinding (-want, +got) =", diff)
+	}
+
+	// The default ServiceAccount runs the receive adapter without ServiceAccountName.
+	src.Spec.ServiceAccountName = ""
+	if got := MakeNamespacesClusterRoleBinding(src).Subjects[0].Name; got != "default" {
+		t.Errorf("unexpected ServiceAccount %q, want default", got)
+	}
+}
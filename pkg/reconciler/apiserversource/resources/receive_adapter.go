@@ -114,6 +114,15 @@ func makeEnv(args *ReceiveAdapterArgs) ([]corev1.EnvVar, error) {
 		Resources:     make([]apiserver.ResourceWatch, 0, len(args.Source.Spec.Resources)),
 		ResourceOwner: args.Source.Spec.ResourceOwner,
 		EventMode:     args.Source.Spec.EventMode,
+		Operations:    args.Source.Spec.Operations,
+	}
+
+	if args.Source.Spec.NamespaceSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(args.Source.Spec.NamespaceSelector)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse namespaceSelector: %w", err)
+		}
+		cfg.NamespaceSelector = selector.String()
 	}
 
 	for _, r := range args.Source.Spec.Resources {
@@ -123,7 +132,7 @@ func makeEnv(args *ReceiveAdapterArgs) ([]corev1.EnvVar, error) {
 		}
 		gvr, _ := meta.UnsafeGuessKindToResource(gv.WithKind(r.Kind))
 
-		rw := apiserver.ResourceWatch{GVR: gvr}
+		rw := apiserver.ResourceWatch{GVR: gvr, FieldSelector: r.FieldSelector}
 
 		if r.LabelSelector != nil {
 			selector, _ := metav1.LabelSelectorAsSelector(r.LabelSelector)
@@ -178,6 +178,13 @@ func TestMakeReceiveAdapters(t *testing.T) {
 		Value: `{"extensions":{"1":"one"}}`,
 	})
 
+	selectorSrc := src.DeepCopy()
+	selectorSrc.Spec.Resources[1].FieldSelector = "status.successful=1"
+	selectorSrc.Spec.Operations = []string{"add", "delete"}
+	selectorSrc.Spec.NamespaceSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"env": "test"}}
+	selectorWant := want.DeepCopy()
+	selectorWant.Spec.Template.Spec.Containers[0].Env[1].Value = `{"namespace":"source-namespace","namespaceSelector":"env=test","resources":[{"gvr":{"Group":"","Version":"","Resource":"namespaces"}},{"gvr":{"Group":"batch","Version":"v1","Resource":"jobs"},"fieldSelector":"status.successful=1"},{"gvr":{"Group":"","Version":"","Resource":"pods"},"selector":"test-key1=test-value1"}],"owner":{"apiVersion":"custom/v1","kind":"Parent"},"mode":"Resource","operations":["add","delete"]}`
+
 	testCases := map[string]struct {
 		want *appsv1.Deployment
 		src  *v1.ApiServerSource
@@ -189,6 +196,9 @@ func TestMakeReceiveAdapters(t *testing.T) {
 		}, "TestMakeReceiveAdapterWithExtensionOverride": {
 			src:  ceSrc,
 			want: ceWant,
+		}, "TestMakeReceiveAdapterWithSelectors": {
+			src:  selectorSrc,
+			want: selectorWant,
 		},
 	}
 	for n, tc := range testCases {
@@ -51,6 +51,7 @@ import (
 	ducklib "knative.dev/eventing/pkg/duck"
 	"knative.dev/eventing/pkg/reconciler/broker/resources"
 	"knative.dev/eventing/pkg/reconciler/names"
+	"knative.dev/eventing/pkg/reconciler/sugar/trigger/path"
 )
 
 type Reconciler struct {
@@ -189,6 +190,11 @@ func (r *Reconciler) ReconcileKind(ctx context.Context, b *eventingv1.Broker) pk
 		Path:   fmt.Sprintf("/%s/%s", b.Namespace, b.Name),
 	})
 
+	if err := r.reconcileBrokerSubscription(ctx, b, &chanMan.ref); err != nil {
+		logging.FromContext(ctx).Errorw("Problem reconciling the broker subscription", zap.Error(err))
+		return fmt.Errorf("failed to reconcile broker subscription: %w", err)
+	}
+
 	// So, at this point the Broker is ready and everything should be solid
 	// for the triggers to act upon.
 	return nil
@@ -353,6 +359,53 @@ func (r *Reconciler) reconcileChannel(ctx context.Context, channelResourceInterf
 	return channelable, nil
 }
 
+// reconcileBrokerSubscription makes sure that the Broker is subscribed to its trigger channel
+// when it's in the broker dispatch mode, and that it isn't otherwise. In the broker dispatch
+// mode the filter fans the events out to the matching Triggers, which don't subscribe to the
+// trigger channel themselves.
+func (r *Reconciler) reconcileBrokerSubscription(ctx context.Context, b *eventingv1.Broker, channelRef *corev1.ObjectReference) error {
+	sub, err := r.subscriptionLister.Subscriptions(b.Namespace).Get(resources.BrokerSubscriptionName(b))
+	if err != nil && !apierrs.IsNotFound(err) {
+		return err
+	}
+	exists := err == nil
+
+	if !resources.BrokerDispatchEnabled(b) {
+		if exists && metav1.IsControlledBy(sub, b) {
+			logging.FromContext(ctx).Infow("Deleting broker subscription", zap.String("namespace", sub.Namespace), zap.String("name", sub.Name))
+			return r.eventingClientSet.MessagingV1().Subscriptions(b.Namespace).Delete(ctx, sub.Name, metav1.DeleteOptions{})
+		}
+		return nil
+	}
+
+	expected := resources.NewBrokerSubscription(b, channelRef, &apis.URL{
+		Scheme: "http",
+		Host:   network.GetServiceHostname(names.BrokerFilterName, system.Namespace()),
+		Path:   path.GenerateBroker(b),
+	})
+
+	if !exists {
+		logging.FromContext(ctx).Info("Creating broker subscription")
+		_, err = r.eventingClientSet.MessagingV1().Subscriptions(b.Namespace).Create(ctx, expected, metav1.CreateOptions{})
+		return err
+	}
+	if !metav1.IsControlledBy(sub, b) {
+		return fmt.Errorf("broker %q does not own subscription %q", b.Name, sub.Name)
+	}
+	if equality.Semantic.DeepDerivative(expected.Spec, sub.Spec) {
+		return nil
+	}
+
+	// Given that spec.channel is immutable, we cannot just update the Subscription. We delete
+	// it and re-create it instead.
+	logging.FromContext(ctx).Infow("Differing broker subscription", zap.Any("expected", expected.Spec), zap.Any("actual", sub.Spec))
+	if err := r.eventingClientSet.MessagingV1().Subscriptions(b.Namespace).Delete(ctx, sub.Name, metav1.DeleteOptions{}); err != nil {
+		return err
+	}
+	_, err = r.eventingClientSet.MessagingV1().Subscriptions(b.Namespace).Create(ctx, expected, metav1.CreateOptions{})
+	return err
+}
+
 // TriggerChannelLabels are all the labels placed on the Trigger Channel for the given brokerName. This
 // should only be used by Broker and Trigger code.
 func TriggerChannelLabels(brokerName string) map[string]string {
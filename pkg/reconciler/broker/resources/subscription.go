@@ -37,7 +37,7 @@ func NewSubscription(t *eventingv1.Trigger, brokerTrigger, brokerRef *corev1.Obj
 	return &messagingv1.Subscription{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: t.Namespace,
-			Name:      kmeta.ChildName(fmt.Sprintf("%s-%s-", t.Spec.Broker, t.Name), string(t.GetUID())),
+			Name:      SubscriptionName(t),
 			OwnerReferences: []metav1.OwnerReference{
 				*kmeta.NewControllerRef(t),
 			},
@@ -65,6 +65,53 @@ func NewSubscription(t *eventingv1.Trigger, brokerTrigger, brokerRef *corev1.Obj
 	}
 }
 
+// SubscriptionName returns the name of the Subscription linking the Trigger to the Broker's Channels.
+func SubscriptionName(t *eventingv1.Trigger) string {
+	return kmeta.ChildName(fmt.Sprintf("%s-%s-", t.Spec.Broker, t.Name), string(t.GetUID()))
+}
+
+// NewBrokerSubscription returns a placeholder subscription for broker 'b', from brokerTrigger to 'uri'.
+// It's used in the broker dispatch mode, where the filter fans the events out to the matching Triggers
+// and sends their replies back to the Broker itself, so the Subscription has no reply. The channel
+// redelivers the events the filter rejects according to the delivery spec of the Broker.
+func NewBrokerSubscription(b *eventingv1.Broker, brokerTrigger *corev1.ObjectReference, uri *apis.URL) *messagingv1.Subscription {
+	return &messagingv1.Subscription{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: b.Namespace,
+			Name:      BrokerSubscriptionName(b),
+			OwnerReferences: []metav1.OwnerReference{
+				*kmeta.NewControllerRef(b),
+			},
+			Labels: map[string]string{
+				eventing.BrokerLabelKey: b.Name,
+			},
+		},
+		Spec: messagingv1.SubscriptionSpec{
+			Channel: duckv1.KReference{
+				APIVersion: brokerTrigger.APIVersion,
+				Kind:       brokerTrigger.Kind,
+				Name:       brokerTrigger.Name,
+			},
+			Subscriber: &duckv1.Destination{
+				URI: uri,
+			},
+			Delivery: b.Spec.Delivery,
+		},
+	}
+}
+
+// BrokerSubscriptionName returns the name of the Subscription linking the Broker to its Channels in the
+// broker dispatch mode.
+func BrokerSubscriptionName(b *eventingv1.Broker) string {
+	return kmeta.ChildName(b.Name+"-", string(b.GetUID()))
+}
+
+// BrokerDispatchEnabled returns true if the Broker dispatches the events to its Triggers itself, instead
+// of having every Trigger subscribe to its Channels.
+func BrokerDispatchEnabled(b *eventingv1.Broker) bool {
+	return b.GetAnnotations()[eventing.BrokerDispatchModeAnnotationKey] == eventing.BrokerDispatchModeBroker
+}
+
 // SubscriptionLabels generates the labels present on the Subscription linking this Trigger to the
 // Broker's Channels.
 func SubscriptionLabels(t *eventingv1.Trigger) map[string]string {
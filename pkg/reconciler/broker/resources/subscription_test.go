@@ -103,3 +103,85 @@ func TestNewSubscription(t *testing.T) {
 		t.Error("unexpected diff (-want, +got) =", diff)
 	}
 }
+
+func TestNewBrokerSubscription(t *testing.T) {
+	var TrueValue = true
+	broker := &eventingv1.Broker{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "b-namespace",
+			Name:      "b-name",
+		},
+		Spec: eventingv1.BrokerSpec{
+			Delivery: &eventingduckv1.DeliverySpec{
+				DeadLetterSink: &duckv1.Destination{
+					URI: apis.HTTP("dls.example.com"),
+				},
+			},
+		},
+	}
+	triggerChannelRef := &corev1.ObjectReference{
+		Name:       "tc-name",
+		Kind:       "tc-kind",
+		APIVersion: "tc-apiVersion",
+	}
+	got := NewBrokerSubscription(broker, triggerChannelRef, apis.HTTP("example.com"))
+	want := &messagingv1.Subscription{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "b-namespace",
+			Name:      "b-name-",
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion:         "eventing.knative.dev/v1",
+				Kind:               "Broker",
+				Name:               "b-name",
+				Controller:         &TrueValue,
+				BlockOwnerDeletion: &TrueValue,
+			}},
+			Labels: map[string]string{
+				eventing.BrokerLabelKey: "b-name",
+			},
+		},
+		Spec: messagingv1.SubscriptionSpec{
+			Channel: duckv1.KReference{
+				Name:       "tc-name",
+				Kind:       "tc-kind",
+				APIVersion: "tc-apiVersion",
+			},
+			Subscriber: &duckv1.Destination{
+				URI: apis.HTTP("example.com"),
+			},
+			Delivery: &eventingduckv1.DeliverySpec{
+				DeadLetterSink: &duckv1.Destination{
+					URI: apis.HTTP("dls.example.com"),
+				},
+			},
+		},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Error("unexpected diff (-want, +got) =", diff)
+	}
+}
+
+func TestBrokerDispatchEnabled(t *testing.T) {
+	tests := map[string]struct {
+		annotations map[string]string
+		want        bool
+	}{
+		"no annotation": {},
+		"trigger dispatch mode": {
+			annotations: map[string]string{eventing.BrokerDispatchModeAnnotationKey: eventing.BrokerDispatchModeTrigger},
+		},
+		"broker dispatch mode": {
+			annotations: map[string]string{eventing.BrokerDispatchModeAnnotationKey: eventing.BrokerDispatchModeBroker},
+			want:        true,
+		},
+	}
+	for n, tc := range tests {
+		t.Run(n, func(t *testing.T) {
+			b := &eventingv1.Broker{ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations}}
+			if got := BrokerDispatchEnabled(b); got != tc.want {
+				t.Errorf("BrokerDispatchEnabled() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
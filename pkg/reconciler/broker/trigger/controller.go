@@ -20,6 +20,7 @@ import (
 	"context"
 
 	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/tools/cache"
 	"knative.dev/eventing/pkg/apis/eventing"
@@ -37,6 +38,7 @@ import (
 	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/controller"
 	"knative.dev/pkg/injection/clients/dynamicclient"
+	"knative.dev/pkg/kmeta"
 	"knative.dev/pkg/logging"
 	pkgreconciler "knative.dev/pkg/reconciler"
 	"knative.dev/pkg/resolver"
@@ -90,6 +92,23 @@ func NewController(
 		Handler:    controller.HandleAll(impl.EnqueueControllerOf),
 	})
 
+	// Reconcile the Triggers of a Broker when its Subscription changes, in the broker dispatch mode
+	subscriptionInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: controller.FilterController(&eventingv1.Broker{}),
+		Handler: controller.HandleAll(func(obj interface{}) {
+			sub, err := kmeta.DeletionHandlingAccessor(obj)
+			if err != nil {
+				return
+			}
+			if owner := metav1.GetControllerOf(sub); owner != nil {
+				broker := &eventingv1.Broker{ObjectMeta: metav1.ObjectMeta{Namespace: sub.GetNamespace(), Name: owner.Name}}
+				for _, t := range getTriggersForBroker(logger, triggerLister, broker) {
+					impl.Enqueue(t)
+				}
+			}
+		}),
+	})
+
 	return impl
 }
 
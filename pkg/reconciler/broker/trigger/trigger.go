@@ -135,7 +135,12 @@ func (r *Reconciler) ReconcileKind(ctx context.Context, t *eventingv1.Trigger) p
 		return err
 	}
 
-	sub, err := r.subscribeToBrokerChannel(ctx, b, t, brokerTrigger)
+	var sub *messagingv1.Subscription
+	if resources.BrokerDispatchEnabled(b) {
+		sub, err = r.useBrokerSubscription(ctx, b, t)
+	} else {
+		sub, err = r.subscribeToBrokerChannel(ctx, b, t, brokerTrigger)
+	}
 	if err != nil {
 		logging.FromContext(ctx).Errorw("Unable to Subscribe", zap.Error(err))
 		t.Status.MarkNotSubscribed("NotSubscribed", "%v", err)
@@ -231,6 +236,33 @@ func (r *Reconciler) subscribeToBrokerChannel(ctx context.Context, b *eventingv1
 	return sub, nil
 }
 
+// useBrokerSubscription deletes the Subscription of the Trigger, if any, and returns the one of the Broker.
+// In the broker dispatch mode the Broker subscribes once to its channels, and the filter sends the events
+// to the matching Triggers only.
+func (r *Reconciler) useBrokerSubscription(ctx context.Context, b *eventingv1.Broker, t *eventingv1.Trigger) (*messagingv1.Subscription, error) {
+	recorder := controller.GetEventRecorder(ctx)
+
+	sub, err := r.subscriptionLister.Subscriptions(t.Namespace).Get(resources.SubscriptionName(t))
+	if err != nil && !apierrs.IsNotFound(err) {
+		logging.FromContext(ctx).Errorw("Failed to get subscription", zap.Error(err))
+		recorder.Eventf(t, corev1.EventTypeWarning, subscriptionGetFailed, "Getting the Trigger's Subscription failed: %v", err)
+		return nil, err
+	} else if err == nil && metav1.IsControlledBy(sub, t) {
+		logging.FromContext(ctx).Infow("Deleting subscription", zap.String("namespace", sub.Namespace), zap.String("name", sub.Name))
+		if err := r.eventingClientSet.MessagingV1().Subscriptions(t.Namespace).Delete(ctx, sub.Name, metav1.DeleteOptions{}); err != nil {
+			logging.FromContext(ctx).Info("Cannot delete subscription", zap.Error(err))
+			recorder.Eventf(t, corev1.EventTypeWarning, subscriptionDeleteFailed, "Delete Trigger's subscription failed: %v", err)
+			return nil, err
+		}
+	}
+
+	sub, err = r.subscriptionLister.Subscriptions(b.Namespace).Get(resources.BrokerSubscriptionName(b))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the broker subscription: %w", err)
+	}
+	return sub, nil
+}
+
 func (r *Reconciler) reconcileSubscription(ctx context.Context, t *eventingv1.Trigger, expected, actual *messagingv1.Subscription) (*messagingv1.Subscription, error) {
 	// Update Subscription if it has changed.
 	if equality.Semantic.DeepDerivative(expected.Spec, actual.Spec) {
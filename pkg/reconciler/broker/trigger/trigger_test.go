@@ -881,6 +881,65 @@ func TestReconcile(t *testing.T) {
 					WithTriggerDeadLetterSinkNotConfigured(),
 				),
 			}},
+		}, {
+			Name: "Broker dispatch mode, Trigger subscription deleted, broker subscription ready",
+			Key:  testKey,
+			Objects: allBrokerDispatchObjectsReadyPlus([]runtime.Object{
+				makeReadySubscription(testNS),
+				makeReadyBrokerSubscription(),
+				NewTrigger(triggerName, testNS, brokerName,
+					WithTriggerUID(triggerUID),
+					WithTriggerSubscriberURI(subscriberURI),
+					WithInitTriggerConditions,
+				)}...),
+			WantErr: false,
+			WantDeletes: []clientgotesting.DeleteActionImpl{{
+				ActionImpl: clientgotesting.ActionImpl{
+					Namespace: testNS,
+					Resource:  eventingduckv1.SchemeGroupVersion.WithResource("subscriptions"),
+				},
+				Name: subscriptionName,
+			}},
+			WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+				Object: NewTrigger(triggerName, testNS, brokerName,
+					WithTriggerUID(triggerUID),
+					WithTriggerSubscriberURI(subscriberURI),
+					WithTriggerBrokerReady(),
+					// The first reconciliation will initialize the status conditions.
+					WithInitTriggerConditions,
+					WithTriggerDependencyReady(),
+					WithTriggerSubscribed(),
+					WithTriggerStatusSubscriberURI(subscriberURI),
+					WithTriggerSubscriberResolvedSucceeded(),
+					WithTriggerDeadLetterSinkNotConfigured(),
+				),
+			}},
+		}, {
+			Name: "Broker dispatch mode, broker subscription doesn't exist",
+			Key:  testKey,
+			Objects: allBrokerDispatchObjectsReadyPlus([]runtime.Object{
+				NewTrigger(triggerName, testNS, brokerName,
+					WithTriggerUID(triggerUID),
+					WithTriggerSubscriberURI(subscriberURI),
+					WithInitTriggerConditions,
+				)}...),
+			WantErr: true,
+			WantEvents: []string{
+				Eventf(corev1.EventTypeWarning, "InternalError", `failed to get the broker subscription: subscription.messaging.knative.dev "%s" not found`, makeReadyBrokerSubscription().Name),
+			},
+			WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+				Object: NewTrigger(triggerName, testNS, brokerName,
+					WithTriggerUID(triggerUID),
+					WithTriggerSubscriberURI(subscriberURI),
+					WithTriggerBrokerReady(),
+					// The first reconciliation will initialize the status conditions.
+					WithInitTriggerConditions,
+					WithTriggerStatusSubscriberURI(subscriberURI),
+					WithTriggerSubscriberResolvedSucceeded(),
+					WithTriggerDeadLetterSinkNotConfigured(),
+					WithTriggerNotSubscribed("NotSubscribed", fmt.Sprintf(`failed to get the broker subscription: subscription.messaging.knative.dev "%s" not found`, makeReadyBrokerSubscription().Name)),
+				),
+			}},
 		}, {
 			Name: "Dependency doesn't exist",
 			Key:  testKey,
@@ -1298,6 +1357,22 @@ func allBrokerObjectsReadyPlus(objs ...runtime.Object) []runtime.Object {
 	return append(brokerObjs[:], objs...)
 }
 
+func allBrokerDispatchObjectsReadyPlus(objs ...runtime.Object) []runtime.Object {
+	brokerObjs := allBrokerObjectsReadyPlus(objs...)
+	WithBrokerDispatchMode(eventing.BrokerDispatchModeBroker)(brokerObjs[0].(*eventingv1.Broker))
+	return brokerObjs
+}
+
+func makeReadyBrokerSubscription() *messagingv1.Subscription {
+	s := resources.NewBrokerSubscription(ReadyBroker(), createTriggerChannelRef(), &apis.URL{
+		Scheme: "http",
+		Host:   network.GetServiceHostname(filterServiceName, systemNS),
+		Path:   fmt.Sprintf("/brokers/%s/%s", testNS, brokerName),
+	})
+	s.Status = *eventingv1.TestHelper.ReadySubscriptionStatus()
+	return s
+}
+
 // Just so we can test subscription updates
 func makeDifferentReadySubscription() *messagingv1.Subscription {
 	s := makeFilterSubscription(testNS)
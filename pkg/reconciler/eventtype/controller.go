@@ -18,16 +18,29 @@ package eventtype
 
 import (
 	"context"
+	"time"
 
+	"github.com/kelseyhightower/envconfig"
 	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/controller"
+	"knative.dev/pkg/logging"
 
 	v1 "knative.dev/eventing/pkg/apis/eventing/v1"
+	eventingclient "knative.dev/eventing/pkg/client/injection/client"
 	brokerinformer "knative.dev/eventing/pkg/client/injection/informers/eventing/v1/broker"
 	eventtypeinformer "knative.dev/eventing/pkg/client/injection/informers/eventing/v1beta1/eventtype"
 	eventtypereconciler "knative.dev/eventing/pkg/client/injection/reconciler/eventing/v1beta1/eventtype"
 )
 
+// envConfig will be used to extract the required environment variables using
+// github.com/kelseyhightower/envconfig. If this configuration cannot be extracted, then
+// NewController will panic.
+type envConfig struct {
+	// IdlePeriod is the period after which the auto-discovered EventTypes which weren't seen
+	// by the broker ingress are deleted. They're never deleted when it's 0.
+	IdlePeriod time.Duration `envconfig:"EVENTTYPE_IDLE_PERIOD" default:"24h"`
+}
+
 // NewController initializes the controller and is called by the generated code
 // Registers event handlers to enqueue events
 // TODO remove https://github.com/knative/eventing/issues/2750
@@ -38,9 +51,16 @@ func NewController(
 	brokerInformer := brokerinformer.Get(ctx)
 	eventTypeInformer := eventtypeinformer.Get(ctx)
 
+	env := &envConfig{}
+	if err := envconfig.Process("", env); err != nil {
+		logging.FromContext(ctx).Panicf("unable to process EventType's required environment variables: %v", err)
+	}
+
 	r := &Reconciler{
-		eventTypeLister: eventTypeInformer.Lister(),
-		brokerLister:    brokerInformer.Lister(),
+		eventingClientSet: eventingclient.Get(ctx),
+		eventTypeLister:   eventTypeInformer.Lister(),
+		brokerLister:      brokerInformer.Lister(),
+		idlePeriod:        env.IdlePeriod,
 	}
 	impl := eventtypereconciler.NewImpl(ctx, r)
 
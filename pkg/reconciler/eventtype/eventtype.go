@@ -18,26 +18,38 @@ package eventtype
 
 import (
 	"context"
+	"time"
 
 	"go.uber.org/zap"
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"knative.dev/pkg/controller"
 	"knative.dev/pkg/logging"
 	pkgreconciler "knative.dev/pkg/reconciler"
 	"knative.dev/pkg/tracker"
 
+	"knative.dev/eventing/pkg/apis/eventing"
 	v1 "knative.dev/eventing/pkg/apis/eventing/v1"
 	"knative.dev/eventing/pkg/apis/eventing/v1beta1"
+	"knative.dev/eventing/pkg/broker"
+	clientset "knative.dev/eventing/pkg/client/clientset/versioned"
 	eventtypereconciler "knative.dev/eventing/pkg/client/injection/reconciler/eventing/v1beta1/eventtype"
 	listersv1 "knative.dev/eventing/pkg/client/listers/eventing/v1"
 	listersv1beta1 "knative.dev/eventing/pkg/client/listers/eventing/v1beta1"
 )
 
 type Reconciler struct {
+	eventingClientSet clientset.Interface
+
 	// listers index properties about resources
 	eventTypeLister listersv1beta1.EventTypeLister
 	brokerLister    listersv1.BrokerLister
 	tracker         tracker.Interface
+
+	// idlePeriod is the period after which the auto-discovered EventTypes which weren't seen
+	// are deleted, 0 to keep them.
+	idlePeriod time.Duration
 }
 
 var brokerGVK = v1.SchemeGroupVersion.WithKind("Broker")
@@ -46,10 +58,33 @@ var brokerGVK = v1.SchemeGroupVersion.WithKind("Broker")
 var _ eventtypereconciler.Interface = (*Reconciler)(nil)
 
 // ReconcileKind implements Interface.ReconcileKind.
-// 1. Verify the Broker exists.
-// 2. Verify the Broker is ready.
+// 1. Delete the EventType if it's auto-discovered and idle.
+// 2. Verify its JSON schema compiles.
+// 3. Verify the Broker exists.
+// 4. Verify the Broker is ready.
 // TODO remove https://github.com/knative/eventing/issues/2750
 func (r *Reconciler) ReconcileKind(ctx context.Context, et *v1beta1.EventType) pkgreconciler.Event {
+	idleIn, idle := r.idleIn(et)
+	if idle {
+		logging.FromContext(ctx).Infow("Deleting the idle auto-discovered EventType",
+			zap.String("lastSeen", et.Annotations[eventing.LastSeenAnnotationKey]))
+		err := r.eventingClientSet.EventingV1beta1().EventTypes(et.Namespace).Delete(ctx, et.Name, metav1.DeleteOptions{})
+		if err != nil && !apierrs.IsNotFound(err) {
+			return err
+		}
+		return nil
+	}
+
+	schema, err := broker.CompileEventTypeSchema(et)
+	switch {
+	case err != nil:
+		et.Status.MarkSchemaInvalid("SchemaInvalid", "Failed to compile the JSON schema: %v", err)
+	case schema != nil:
+		et.Status.MarkSchemaValid()
+	default:
+		et.Status.MarkNoSchema()
+	}
+
 	b, err := r.getBroker(et)
 	if err != nil {
 		if apierrs.IsNotFound(err) {
@@ -78,9 +113,28 @@ func (r *Reconciler) ReconcileKind(ctx context.Context, et *v1beta1.EventType) p
 
 	et.Status.PropagateBrokerStatus(&b.Status)
 
+	if idleIn > 0 {
+		// Check again once the EventType would become idle.
+		return controller.NewRequeueAfter(idleIn)
+	}
 	return nil
 }
 
+// idleIn returns how long until an auto-discovered EventType becomes idle, and whether it's
+// idle already. It returns 0 and false for the other EventTypes.
+func (r *Reconciler) idleIn(et *v1beta1.EventType) (time.Duration, bool) {
+	if r.idlePeriod <= 0 || et.Labels[eventing.AutoDiscoveredLabelKey] != "true" {
+		return 0, false
+	}
+	lastSeen, err := time.Parse(time.RFC3339, et.Annotations[eventing.LastSeenAnnotationKey])
+	if err != nil {
+		// Without a valid last seen time, the EventType is idle since its creation.
+		lastSeen = et.CreationTimestamp.Time
+	}
+	idleIn := time.Until(lastSeen.Add(r.idlePeriod))
+	return idleIn, idleIn <= 0
+}
+
 // getBroker returns the Broker for EventType 'et' if it exists, otherwise it returns an error.
 func (r *Reconciler) getBroker(et *v1beta1.EventType) (*v1.Broker, error) {
 	return r.brokerLister.Brokers(et.Namespace).Get(et.Spec.Broker)
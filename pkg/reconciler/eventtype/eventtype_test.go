@@ -20,11 +20,13 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	clientgotesting "k8s.io/client-go/testing"
+	"knative.dev/eventing/pkg/apis/eventing/v1beta1"
 	fakeeventingclient "knative.dev/eventing/pkg/client/injection/client/fake"
 	"knative.dev/eventing/pkg/client/injection/reconciler/eventing/v1beta1/eventtype"
 	. "knative.dev/eventing/pkg/reconciler/testing/v1"
@@ -41,6 +43,7 @@ const (
 	eventTypeName   = "test-eventtype"
 	eventTypeType   = "test-type"
 	eventTypeBroker = "test-broker"
+	idlePeriod      = time.Hour
 )
 
 var (
@@ -161,14 +164,114 @@ func TestReconcile(t *testing.T) {
 				WithEventTypeBrokerReady,
 			),
 		}},
+	}, {
+		Name: "Successful reconcile, valid schema",
+		Key:  testKey,
+		Objects: []runtime.Object{
+			NewEventType(eventTypeName, testNS,
+				WithEventTypeType(eventTypeType),
+				WithEventTypeSource(eventTypeSource),
+				WithEventTypeBroker(eventTypeBroker),
+				WithEventTypeSchemaData(`{"type": "object"}`),
+			),
+			NewBroker(eventTypeBroker, testNS,
+				WithBrokerReady,
+			),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: NewEventType(eventTypeName, testNS,
+				WithEventTypeType(eventTypeType),
+				WithEventTypeSource(eventTypeSource),
+				WithEventTypeBroker(eventTypeBroker),
+				WithEventTypeSchemaData(`{"type": "object"}`),
+				WithEventTypeSchemaValid,
+				WithEventTypeBrokerExists,
+				WithEventTypeBrokerReady,
+			),
+		}},
+	}, {
+		Name: "Successful reconcile, invalid schema",
+		Key:  testKey,
+		Objects: []runtime.Object{
+			NewEventType(eventTypeName, testNS,
+				WithEventTypeType(eventTypeType),
+				WithEventTypeSource(eventTypeSource),
+				WithEventTypeBroker(eventTypeBroker),
+				WithEventTypeSchemaData(`{"type": "date"}`),
+			),
+			NewBroker(eventTypeBroker, testNS,
+				WithBrokerReady,
+			),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: NewEventType(eventTypeName, testNS,
+				WithEventTypeType(eventTypeType),
+				WithEventTypeSource(eventTypeSource),
+				WithEventTypeBroker(eventTypeBroker),
+				WithEventTypeSchemaData(`{"type": "date"}`),
+				WithEventTypeSchemaInvalid(`Failed to compile the JSON schema: #/type: unknown type "date"`),
+				WithEventTypeBrokerExists,
+				WithEventTypeBrokerReady,
+			),
+		}},
+	}, {
+		Name: "Auto-discovered EventType seen recently",
+		Key:  testKey,
+		Objects: []runtime.Object{
+			NewEventType(eventTypeName, testNS,
+				WithEventTypeType(eventTypeType),
+				WithEventTypeSource(eventTypeSource),
+				WithEventTypeBroker(eventTypeBroker),
+				WithEventTypeAutoDiscovered(time.Now()),
+			),
+			NewBroker(eventTypeBroker, testNS,
+				WithBrokerReady,
+			),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: NewEventType(eventTypeName, testNS,
+				WithEventTypeType(eventTypeType),
+				WithEventTypeSource(eventTypeSource),
+				WithEventTypeBroker(eventTypeBroker),
+				WithEventTypeAutoDiscovered(time.Now()),
+				WithEventTypeBrokerExists,
+				WithEventTypeBrokerReady,
+			),
+		}},
+		// Requeued to be checked again once idle.
+		WantErr: true,
+	}, {
+		Name: "Auto-discovered EventType idle",
+		Key:  testKey,
+		Objects: []runtime.Object{
+			NewEventType(eventTypeName, testNS,
+				WithEventTypeType(eventTypeType),
+				WithEventTypeSource(eventTypeSource),
+				WithEventTypeBroker(eventTypeBroker),
+				WithEventTypeAutoDiscovered(time.Now().Add(-2*idlePeriod)),
+				WithInitEventTypeConditions,
+			),
+			NewBroker(eventTypeBroker, testNS,
+				WithBrokerReady,
+			),
+		},
+		WantDeletes: []clientgotesting.DeleteActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: testNS,
+				Resource:  v1beta1.SchemeGroupVersion.WithResource("eventtypes"),
+			},
+			Name: eventTypeName,
+		}},
 	}}
 
 	logger := logtesting.TestLogger(t)
 	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
 		r := &Reconciler{
-			eventTypeLister: listers.GetEventTypeLister(),
-			brokerLister:    listers.GetBrokerLister(),
-			tracker:         tracker.New(func(types.NamespacedName) {}, 0),
+			eventingClientSet: fakeeventingclient.Get(ctx),
+			eventTypeLister:   listers.GetEventTypeLister(),
+			brokerLister:      listers.GetBrokerLister(),
+			tracker:           tracker.New(func(types.NamespacedName) {}, 0),
+			idlePeriod:        idlePeriod,
 		}
 		return eventtype.NewReconciler(ctx, logger,
 			fakeeventingclient.Get(ctx), listers.GetEventTypeLister(),
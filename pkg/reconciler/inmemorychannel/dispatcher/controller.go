@@ -28,6 +28,7 @@ import (
 	"github.com/kelseyhightower/envconfig"
 	"knative.dev/pkg/kmeta"
 
+	"knative.dev/eventing/pkg/channel/fanout"
 	"knative.dev/eventing/pkg/channel/multichannelfanout"
 	"knative.dev/eventing/pkg/kncloudevents"
 
@@ -66,6 +67,28 @@ type envConfig struct {
 	MaxIdleConns int `envconfig:"MAX_IDLE_CONNS" required:"true"`
 	// MaxIdleConnsPerHost refers to the max idle connections per host, as in net/http/transport.
 	MaxIdleConnsPerHost int `envconfig:"MAX_IDLE_CONNS_PER_HOST" required:"true"`
+
+	// Circuit breaker of the subscribers, enabled when the failure ratio is greater than 0.
+	CircuitBreakerFailureRatio float64       `envconfig:"CIRCUIT_BREAKER_FAILURE_RATIO" default:"0"`
+	CircuitBreakerMinRequests  int           `envconfig:"CIRCUIT_BREAKER_MIN_REQUESTS" default:"10"`
+	CircuitBreakerInterval     time.Duration `envconfig:"CIRCUIT_BREAKER_INTERVAL" default:"1m"`
+	CircuitBreakerCoolDown     time.Duration `envconfig:"CIRCUIT_BREAKER_COOL_DOWN" default:"30s"`
+
+	// LimiterMaxWait is the maximum time an event waits for the rate limit or the maximum number
+	// of events in flight of its subscriber before being rejected.
+	LimiterMaxWait time.Duration `envconfig:"LIMITER_MAX_WAIT" default:"10s"`
+
+	// PersistenceDir is the directory holding the logs of the channels with disk persistence.
+	PersistenceDir string `envconfig:"PERSISTENCE_DIR" default:"/var/lib/imc-dispatcher"`
+
+	// Queue of each subscription, enabled when the size is greater than 0.
+	QueueSize     int    `envconfig:"QUEUE_SIZE" default:"0"`
+	QueueWorkers  int    `envconfig:"QUEUE_WORKERS" default:"10"`
+	QueueOverflow string `envconfig:"QUEUE_OVERFLOW" default:"reject"`
+
+	// Events retained by each channel for the replays, enabled when the size is greater than 0.
+	ReplayBufferSize   int           `envconfig:"REPLAY_BUFFER_SIZE" default:"0"`
+	ReplayBufferMaxAge time.Duration `envconfig:"REPLAY_BUFFER_MAX_AGE" default:"1h"`
 }
 
 // NewController initializes the controller and is called by the generated code.
@@ -100,6 +123,61 @@ func NewController(
 
 	reporter := channel.NewStatsReporter(env.ContainerName, kmeta.ChildName(env.PodName, uuid.New().String()))
 
+	// Setup the circuit breakers before creating the dispatcher, which shares them.
+	if env.CircuitBreakerFailureRatio > 1 {
+		logger.Panicf("CIRCUIT_BREAKER_FAILURE_RATIO = %v. It must be between 0 and 1", env.CircuitBreakerFailureRatio)
+	}
+	if env.CircuitBreakerFailureRatio > 0 {
+		kncloudevents.ConfigureCircuitBreakers(&kncloudevents.CircuitBreakerConfig{
+			FailureRatio: env.CircuitBreakerFailureRatio,
+			MinRequests:  env.CircuitBreakerMinRequests,
+			Interval:     env.CircuitBreakerInterval,
+			CoolDown:     env.CircuitBreakerCoolDown,
+			OnStateChange: func(destination string, from, to kncloudevents.CircuitState) {
+				logger.Infow("Circuit breaker state changed", zap.String("destination", destination), zap.Stringer("from", from), zap.Stringer("to", to))
+				_ = reporter.ReportCircuitBreakerStateChange(destination, from, to)
+			},
+		})
+	}
+
+	// Setup the limiters of the subscribers before creating the dispatcher, which shares them.
+	if env.LimiterMaxWait <= 0 {
+		logger.Panicf("LIMITER_MAX_WAIT = %v. It must be greater than 0", env.LimiterMaxWait)
+	}
+	kncloudevents.ConfigureLimiters(kncloudevents.LimiterConfig{
+		MaxWait: env.LimiterMaxWait,
+		OnStateChange: func(destination string, state kncloudevents.LimiterState) {
+			_ = reporter.ReportLimiterState(destination, state)
+		},
+		OnReject: func(destination string) {
+			_ = reporter.ReportLimiterRejection(destination)
+		},
+	})
+
+	var queueConfig *fanout.QueueConfig
+	if env.QueueSize > 0 {
+		if env.QueueWorkers <= 0 {
+			logger.Panicf("QUEUE_WORKERS = %d. It must be greater than 0", env.QueueWorkers)
+		}
+		switch overflow := fanout.OverflowPolicy(env.QueueOverflow); overflow {
+		case fanout.OverflowReject, fanout.OverflowDeadLetter, fanout.OverflowBlock:
+		default:
+			logger.Panicf("QUEUE_OVERFLOW = %q. It must be one of %q, %q or %q", overflow, fanout.OverflowReject, fanout.OverflowDeadLetter, fanout.OverflowBlock)
+		}
+		queueConfig = &fanout.QueueConfig{
+			Size:     env.QueueSize,
+			Workers:  env.QueueWorkers,
+			Overflow: fanout.OverflowPolicy(env.QueueOverflow),
+		}
+	}
+
+	if env.ReplayBufferSize < 0 {
+		logger.Panicf("REPLAY_BUFFER_SIZE = %d. It must not be negative", env.ReplayBufferSize)
+	}
+	if env.ReplayBufferMaxAge < 0 {
+		logger.Panicf("REPLAY_BUFFER_MAX_AGE = %v. It must not be negative", env.ReplayBufferMaxAge)
+	}
+
 	sh := multichannelfanout.NewMessageHandler(ctx, logger.Desugar(), channel.NewMessageDispatcher(logger.Desugar()), reporter)
 
 	readinessChecker := &DispatcherReadyChecker{
@@ -126,6 +204,10 @@ func NewController(
 		multiChannelMessageHandler: sh,
 		reporter:                   reporter,
 		messagingClientSet:         eventingclient.Get(ctx).MessagingV1(),
+		persistenceDir:             env.PersistenceDir,
+		queueConfig:                queueConfig,
+		replayBufferSize:           env.ReplayBufferSize,
+		replayBufferMaxAge:         env.ReplayBufferMaxAge,
 	}
 	impl := inmemorychannelreconciler.NewImpl(ctx, r, func(impl *controller.Impl) controller.Options {
 		return controller.Options{SkipStatusUpdates: true, FinalizerName: finalizerName}
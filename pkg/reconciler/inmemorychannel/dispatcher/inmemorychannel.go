@@ -19,6 +19,10 @@ package dispatcher
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -38,6 +42,7 @@ import (
 	"knative.dev/eventing/pkg/channel"
 	"knative.dev/eventing/pkg/channel/fanout"
 	"knative.dev/eventing/pkg/channel/multichannelfanout"
+	"knative.dev/eventing/pkg/channel/wal"
 	messagingv1 "knative.dev/eventing/pkg/client/clientset/versioned/typed/messaging/v1"
 	reconcilerv1 "knative.dev/eventing/pkg/client/injection/reconciler/messaging/v1/inmemorychannel"
 	"knative.dev/eventing/pkg/kncloudevents"
@@ -48,6 +53,28 @@ type Reconciler struct {
 	multiChannelMessageHandler multichannelfanout.MultiChannelMessageHandler
 	reporter                   channel.StatsReporter
 	messagingClientSet         messagingv1.MessagingV1Interface
+
+	// queueConfig, when not nil, configures the queue of each subscription.
+	queueConfig *fanout.QueueConfig
+
+	// persistenceDir is the directory holding the logs of the channels with disk persistence.
+	persistenceDir string
+	logsMutex      sync.Mutex
+	// logs are the logs of the channels with disk persistence, by host name.
+	logs map[string]*channelLog
+
+	// replayBufferSize, when greater than 0, is the number of events retained by each channel
+	// for the replays, during at most replayBufferMaxAge when it's not 0.
+	replayBufferSize   int
+	replayBufferMaxAge time.Duration
+	buffersMutex       sync.Mutex
+	// buffers are the replay buffers of the channels, by host name.
+	buffers map[string]*fanout.ReplayBuffer
+}
+
+type channelLog struct {
+	log *wal.Log
+	dir string
 }
 
 // Check the interfaces Reconciler should implement
@@ -87,8 +114,19 @@ func (r *Reconciler) reconcile(ctx context.Context, imc *v1.InMemoryChannel) rec
 
 	// First grab the MultiChannelFanoutMessage handler
 	handler := r.multiChannelMessageHandler.GetChannelHandler(config.HostName)
-	if handler == nil {
-		// No handler yet, create one.
+	persistent := imc.Spec.Persistence == v1.InMemoryChannelPersistenceDisk
+	if handler == nil || persistent != r.hasLog(config.HostName) {
+		// No handler yet or the persistence changed, create one.
+		if persistent {
+			log, err := r.openLog(imc, config.HostName)
+			if err != nil {
+				logging.FromContext(ctx).Error("Failed to open the log", zap.Error(err))
+				return err
+			}
+			config.FanoutConfig.Log = log
+		}
+		config.FanoutConfig.Queue = r.queueConfig
+		config.FanoutConfig.Buffer = r.replayBuffer(config.HostName)
 		fanoutHandler, err := fanout.NewFanoutMessageHandler(
 			logging.FromContext(ctx).Desugar(),
 			channel.NewMessageDispatcher(logging.FromContext(ctx).Desugar()),
@@ -100,12 +138,15 @@ func (r *Reconciler) reconcile(ctx context.Context, imc *v1.InMemoryChannel) rec
 			return err
 		}
 		r.multiChannelMessageHandler.SetChannelHandler(config.HostName, fanoutHandler)
+		if !persistent {
+			r.removeLog(ctx, config.HostName)
+		}
 	} else {
 		// Just update the config if necessary.
 		haveSubs := handler.GetSubscriptions(ctx)
 
 		// Ignore the closures, we stash the values that we can tell from if the values have actually changed.
-		if diff := cmp.Diff(config.FanoutConfig.Subscriptions, haveSubs, cmpopts.IgnoreFields(kncloudevents.RetryConfig{}, "Backoff", "CheckRetry")); diff != "" {
+		if diff := cmp.Diff(config.FanoutConfig.Subscriptions, haveSubs, cmpopts.IgnoreFields(kncloudevents.RetryConfig{}, "Backoff", "CheckRetry"), cmpopts.IgnoreFields(fanout.Subscription{}, "Filter")); diff != "" {
 			logging.FromContext(ctx).Info("Updating fanout config: ", zap.String("Diff", diff))
 			handler.SetSubscriptions(ctx, config.FanoutConfig.Subscriptions)
 		}
@@ -185,6 +226,78 @@ func (r *Reconciler) deleteFunc(obj interface{}) {
 	if imc.Status.Address != nil && imc.Status.Address.URL != nil {
 		if hostName := imc.Status.Address.URL.Host; hostName != "" {
 			r.multiChannelMessageHandler.DeleteChannelHandler(hostName)
+			r.removeLog(context.Background(), hostName)
+			r.removeReplayBuffer(hostName)
 		}
 	}
 }
+
+func (r *Reconciler) hasLog(hostName string) bool {
+	r.logsMutex.Lock()
+	defer r.logsMutex.Unlock()
+	_, ok := r.logs[hostName]
+	return ok
+}
+
+// openLog opens the log of the channel, keeping the messages it already holds.
+func (r *Reconciler) openLog(imc *v1.InMemoryChannel, hostName string) (*wal.Log, error) {
+	r.logsMutex.Lock()
+	defer r.logsMutex.Unlock()
+	if cl, ok := r.logs[hostName]; ok {
+		return cl.log, nil
+	}
+	dir := filepath.Join(r.persistenceDir, imc.Namespace, imc.Name)
+	log, err := wal.Open(dir, wal.Options{})
+	if err != nil {
+		return nil, err
+	}
+	if r.logs == nil {
+		r.logs = make(map[string]*channelLog)
+	}
+	r.logs[hostName] = &channelLog{log: log, dir: dir}
+	return log, nil
+}
+
+// removeLog closes and removes the log of the channel, if any, dropping the messages which
+// weren't delivered.
+func (r *Reconciler) removeLog(ctx context.Context, hostName string) {
+	r.logsMutex.Lock()
+	cl, ok := r.logs[hostName]
+	delete(r.logs, hostName)
+	r.logsMutex.Unlock()
+	if !ok {
+		return
+	}
+	if err := cl.log.Close(); err != nil {
+		logging.FromContext(ctx).Warnw("Failed to close the log", zap.String("dir", cl.dir), zap.Error(err))
+	}
+	if err := os.RemoveAll(cl.dir); err != nil {
+		logging.FromContext(ctx).Warnw("Failed to remove the log", zap.String("dir", cl.dir), zap.Error(err))
+	}
+}
+
+// replayBuffer returns the replay buffer of the channel, creating it if needed, or nil when
+// the channels don't retain events.
+func (r *Reconciler) replayBuffer(hostName string) *fanout.ReplayBuffer {
+	if r.replayBufferSize <= 0 {
+		return nil
+	}
+	r.buffersMutex.Lock()
+	defer r.buffersMutex.Unlock()
+	if b, ok := r.buffers[hostName]; ok {
+		return b
+	}
+	if r.buffers == nil {
+		r.buffers = make(map[string]*fanout.ReplayBuffer)
+	}
+	b := fanout.NewReplayBuffer(r.replayBufferSize, r.replayBufferMaxAge)
+	r.buffers[hostName] = b
+	return b
+}
+
+// removeReplayBuffer drops the events retained by the channel.
+func (r *Reconciler) removeReplayBuffer(hostName string) {
+	r.buffersMutex.Lock()
+	defer r.buffersMutex.Unlock()
+	delete(r.buffers, hostName)
+}
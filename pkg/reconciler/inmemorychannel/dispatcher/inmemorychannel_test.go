@@ -19,6 +19,8 @@ package dispatcher
 import (
 	"context"
 	"net/http"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -343,9 +345,9 @@ func TestReconciler_ReconcileKind(t *testing.T) {
 				WithInMemoryChannelAddress(channelServiceAddress),
 				WithInMemoryChannelDLSUnknown()),
 			wantSubs: []fanout.Subscription{
-				{Subscriber: apis.HTTP("call1").URL(),
+				{UID: subscriber1UID, Subscriber: apis.HTTP("call1").URL(),
 					Reply: apis.HTTP("sink2").URL()},
-				{Subscriber: apis.HTTP("call2").URL(),
+				{UID: subscriber2UID, Subscriber: apis.HTTP("call2").URL(),
 					Reply: apis.HTTP("sink2").URL()},
 			},
 		},
@@ -361,9 +363,9 @@ func TestReconciler_ReconcileKind(t *testing.T) {
 				WithInMemoryChannelDLSUnknown()),
 			subs: []fanout.Subscription{*subscription1},
 			wantSubs: []fanout.Subscription{
-				{Subscriber: apis.HTTP("call1").URL(),
+				{UID: subscriber1UID, Subscriber: apis.HTTP("call1").URL(),
 					Reply: apis.HTTP("sink2").URL()},
-				{Subscriber: apis.HTTP("call2").URL(),
+				{UID: subscriber2UID, Subscriber: apis.HTTP("call2").URL(),
 					Reply: apis.HTTP("sink2").URL()},
 			},
 		},
@@ -379,9 +381,9 @@ func TestReconciler_ReconcileKind(t *testing.T) {
 				WithInMemoryChannelDLSUnknown()),
 			subs: []fanout.Subscription{*subscription1, *subscription2},
 			wantSubs: []fanout.Subscription{
-				{Subscriber: apis.HTTP("call1").URL(),
+				{UID: subscriber1UID, Subscriber: apis.HTTP("call1").URL(),
 					Reply: apis.HTTP("sink2").URL()},
-				{Subscriber: apis.HTTP("call2").URL(),
+				{UID: subscriber2UID, Subscriber: apis.HTTP("call2").URL(),
 					Reply: apis.HTTP("sink2").URL()},
 			},
 		},
@@ -397,7 +399,7 @@ func TestReconciler_ReconcileKind(t *testing.T) {
 				WithInMemoryChannelDLSUnknown()),
 			subs: []fanout.Subscription{*subscription1, *subscription2},
 			wantSubs: []fanout.Subscription{
-				{Subscriber: apis.HTTP("call1").URL(),
+				{UID: subscriber1UID, Subscriber: apis.HTTP("call1").URL(),
 					Reply: apis.HTTP("sink2").URL()},
 			},
 		},
@@ -413,9 +415,9 @@ func TestReconciler_ReconcileKind(t *testing.T) {
 				WithInMemoryChannelDLSUnknown()),
 			subs: []fanout.Subscription{*subscription1, *subscription2},
 			wantSubs: []fanout.Subscription{
-				{Subscriber: apis.HTTP("call1").URL(),
+				{UID: subscriber1UID, Subscriber: apis.HTTP("call1").URL(),
 					Reply: apis.HTTP("sink2").URL()},
-				{Subscriber: apis.HTTP("call3").URL(),
+				{UID: subscriber3UID, Subscriber: apis.HTTP("call3").URL(),
 					Reply: apis.HTTP("sink2").URL()},
 			},
 		},
@@ -433,7 +435,7 @@ func TestReconciler_ReconcileKind(t *testing.T) {
 				Reply:       apis.HTTP("sink2").URL(),
 				RetryConfig: &kncloudevents.RetryConfig{RetryMax: 2, BackoffPolicy: &exponential}}},
 			wantSubs: []fanout.Subscription{
-				{Subscriber: apis.HTTP("call1").URL(),
+				{UID: subscriber1UID, Subscriber: apis.HTTP("call1").URL(),
 					Reply:       apis.HTTP("sink2").URL(),
 					RetryConfig: &kncloudevents.RetryConfig{RetryMax: 3, BackoffPolicy: &linear}},
 			},
@@ -539,6 +541,62 @@ func TestReconciler_Deletion(t *testing.T) {
 	}
 }
 
+func TestReconciler_Persistence(t *testing.T) {
+	imc := NewInMemoryChannel(imcName, testNS,
+		WithInitInMemoryChannelConditions,
+		WithInMemoryChannelDeploymentReady(),
+		WithInMemoryChannelServiceReady(),
+		WithInMemoryChannelEndpointsReady(),
+		WithInMemoryChannelChannelServiceReady(),
+		WithInMemoryChannelSubscribers(subscribers),
+		WithInMemoryChannelAddress(channelServiceAddress),
+		WithInMemoryChannelDLSUnknown())
+	imc.Spec.Persistence = v1.InMemoryChannelPersistenceDisk
+	ctx, fakeEventingClient := fakeeventingclient.With(context.Background(), imc)
+
+	handler := newFakeMultiChannelHandler()
+	r := &Reconciler{
+		multiChannelMessageHandler: handler,
+		messagingClientSet:         fakeEventingClient.MessagingV1(),
+		persistenceDir:             t.TempDir(),
+	}
+	logDir := filepath.Join(r.persistenceDir, testNS, imcName)
+
+	if err := r.ReconcileKind(ctx, imc); err != nil {
+		t.Fatal("ReconcileKind() =", err)
+	}
+	persistentHandler := handler.GetChannelHandler(channelServiceAddress)
+	if !r.hasLog(channelServiceAddress) {
+		t.Error("The channel has no log")
+	}
+	if _, err := os.Stat(logDir); err != nil {
+		t.Error("The log directory wasn't created:", err)
+	}
+
+	// The handler is kept while the persistence doesn't change.
+	if err := r.ReconcileKind(ctx, imc); err != nil {
+		t.Fatal("ReconcileKind() =", err)
+	}
+	if handler.GetChannelHandler(channelServiceAddress) != persistentHandler {
+		t.Error("The handler was replaced")
+	}
+
+	// Going back to memory replaces the handler and removes the log.
+	imc.Spec.Persistence = v1.InMemoryChannelPersistenceMemory
+	if err := r.ReconcileKind(ctx, imc); err != nil {
+		t.Fatal("ReconcileKind() =", err)
+	}
+	if handler.GetChannelHandler(channelServiceAddress) == persistentHandler {
+		t.Error("The handler wasn't replaced")
+	}
+	if r.hasLog(channelServiceAddress) {
+		t.Error("The channel still has a log")
+	}
+	if _, err := os.Stat(logDir); !os.IsNotExist(err) {
+		t.Error("The log directory wasn't removed:", err)
+	}
+}
+
 func makePatch(namespace, name, patch string) clientgotesting.PatchActionImpl {
 	return clientgotesting.PatchActionImpl{
 		ActionImpl: clientgotesting.ActionImpl{
@@ -35,6 +35,7 @@ import (
 	clientgotesting "k8s.io/client-go/testing"
 
 	eventingduckv1 "knative.dev/eventing/pkg/apis/duck/v1"
+	"knative.dev/eventing/pkg/apis/feature"
 	"knative.dev/eventing/pkg/client/injection/ducks/duck/v1/channelable"
 	"knative.dev/eventing/pkg/duck"
 	"knative.dev/pkg/apis"
@@ -179,6 +180,45 @@ func TestAllBranches(t *testing.T) {
 						SubscriptionStatus:       createParallelSubscriptionStatus(parallelName, 0, corev1.ConditionFalse),
 					}})),
 			}},
+		}, {
+			Name: "single branch, with data filter",
+			Key:  pKey,
+			Ctx: feature.ToContext(context.TODO(), feature.Flags{
+				feature.DataFilter: feature.Enabled,
+			}),
+			Objects: []runtime.Object{
+				NewFlowsParallel(parallelName, testNS,
+					WithInitFlowsParallelConditions,
+					WithFlowsParallelChannelTemplateSpec(imc),
+					WithFlowsParallelBranches([]v1.ParallelBranch{
+						{DataFilter: createDataFilter(), Subscriber: createSubscriber(0)},
+					}))},
+			WantErr: false,
+			WantCreates: []runtime.Object{
+				createChannel(parallelName),
+				createBranchChannel(parallelName, 0),
+				withSubscriptionDataFilter(resources.NewFilterSubscription(0, NewFlowsParallel(parallelName, testNS, WithFlowsParallelChannelTemplateSpec(imc), WithFlowsParallelBranches([]v1.ParallelBranch{
+					{Subscriber: createSubscriber(0)},
+				}))), createDataFilter()),
+				resources.NewSubscription(0, NewFlowsParallel(parallelName, testNS, WithFlowsParallelChannelTemplateSpec(imc), WithFlowsParallelBranches([]v1.ParallelBranch{
+					{Subscriber: createSubscriber(0)},
+				}))),
+			},
+			WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+				Object: NewFlowsParallel(parallelName, testNS,
+					WithInitFlowsParallelConditions,
+					WithFlowsParallelChannelTemplateSpec(imc),
+					WithFlowsParallelBranches([]v1.ParallelBranch{{DataFilter: createDataFilter(), Subscriber: createSubscriber(0)}}),
+					WithFlowsParallelChannelsNotReady("ChannelsNotReady", "Channels are not ready yet, or there are none"),
+					WithFlowsParallelAddressableNotReady("emptyAddress", "addressable is nil"),
+					WithFlowsParallelSubscriptionsNotReady("SubscriptionsNotReady", "Subscriptions are not ready yet, or there are none"),
+					WithFlowsParallelIngressChannelStatus(createParallelChannelStatus(parallelName, corev1.ConditionFalse)),
+					WithFlowsParallelBranchStatuses([]v1.ParallelBranchStatus{{
+						FilterSubscriptionStatus: createParallelFilterSubscriptionStatus(parallelName, 0, corev1.ConditionFalse),
+						FilterChannelStatus:      createParallelBranchChannelStatus(parallelName, 0, corev1.ConditionFalse),
+						SubscriptionStatus:       createParallelSubscriptionStatus(parallelName, 0, corev1.ConditionFalse),
+					}})),
+			}},
 		}, {
 			Name: "single branch, with filter, with delivery",
 			Key:  pKey,
@@ -717,6 +757,16 @@ func createFilter(caseNumber int) *duckv1.Destination {
 	}
 }
 
+func createDataFilter() []eventingduckv1.DataFilter {
+	region := "eu-west"
+	return []eventingduckv1.DataFilter{{Path: ".order.region", Value: &region}}
+}
+
+func withSubscriptionDataFilter(sub *messagingv1.Subscription, dataFilter []eventingduckv1.DataFilter) *messagingv1.Subscription {
+	sub.Spec.DataFilter = dataFilter
+	return sub
+}
+
 func apiVersion(gvk metav1.GroupVersionKind) string {
 	groupVersion := gvk.Version
 	if gvk.Group != "" {
@@ -55,6 +55,7 @@ func NewFilterSubscription(branchNumber int, p *v1.Parallel) *messagingv1.Subscr
 				Kind:       p.Spec.ChannelTemplate.Kind,
 				Name:       ParallelChannelName(p.Name),
 			},
+			DataFilter: p.Spec.Branches[branchNumber].DataFilter,
 		},
 	}
 	if p.Spec.Branches[branchNumber].Filter != nil {
@@ -20,6 +20,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"go.uber.org/zap"
 
@@ -133,6 +134,13 @@ func (r *Reconciler) ReconcileKind(ctx context.Context, source *sourcesv1.PingSo
 		Source: sourcesv1.PingSourceSource(source.Namespace, source.Name),
 	}}
 
+	// One-shot PingSources are completed once the adapter reports that their event was sent.
+	if source.Spec.At != nil && source.Status.CompletionTime == nil {
+		if firedAt, err := time.Parse(time.RFC3339, source.Annotations[sourcesv1.PingSourceFiredAtAnnotationKey]); err == nil {
+			source.Status.MarkCompleted(metav1.NewTime(firedAt))
+		}
+	}
+
 	return nil
 }
 
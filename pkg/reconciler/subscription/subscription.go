@@ -26,6 +26,7 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/dynamic"
 
@@ -38,6 +39,7 @@ import (
 	"knative.dev/pkg/tracker"
 
 	eventingduckv1 "knative.dev/eventing/pkg/apis/duck/v1"
+	"knative.dev/eventing/pkg/apis/eventing"
 	"knative.dev/eventing/pkg/apis/feature"
 	v1 "knative.dev/eventing/pkg/apis/messaging/v1"
 	subscriptionreconciler "knative.dev/eventing/pkg/client/injection/reconciler/messaging/v1/subscription"
@@ -484,6 +486,9 @@ func (r *Reconciler) updateChannelAddSubscription(channel *eventingduckv1.Channe
 			channel.Spec.Subscribers[i].SubscriberURI = sub.Status.PhysicalSubscription.SubscriberURI
 			channel.Spec.Subscribers[i].ReplyURI = sub.Status.PhysicalSubscription.ReplyURI
 			channel.Spec.Subscribers[i].Delivery = deliverySpec(sub, channel)
+			channel.Spec.Subscribers[i].DataFilter = sub.Spec.DataFilter
+			channel.Spec.Subscribers[i].Replay = sub.Spec.Replay
+			channel.Spec.Subscribers[i].TriggerUID = triggerUID(sub)
 			return
 		}
 	}
@@ -494,12 +499,27 @@ func (r *Reconciler) updateChannelAddSubscription(channel *eventingduckv1.Channe
 		SubscriberURI: sub.Status.PhysicalSubscription.SubscriberURI,
 		ReplyURI:      sub.Status.PhysicalSubscription.ReplyURI,
 		Delivery:      deliverySpec(sub, channel),
+		DataFilter:    sub.Spec.DataFilter,
+		Replay:        sub.Spec.Replay,
+		TriggerUID:    triggerUID(sub),
 	}
 
 	// Must not have been found. Add it.
 	channel.Spec.Subscribers = append(channel.Spec.Subscribers, toAdd)
 }
 
+// triggerUID returns the UID of the Trigger controlling sub, if any.
+func triggerUID(sub *v1.Subscription) types.UID {
+	owner := metav1.GetControllerOf(sub)
+	if owner == nil || owner.Kind != "Trigger" {
+		return ""
+	}
+	if gv, err := schema.ParseGroupVersion(owner.APIVersion); err != nil || gv.Group != eventing.GroupName {
+		return ""
+	}
+	return owner.UID
+}
+
 func deliverySpec(sub *v1.Subscription, channel *eventingduckv1.Channelable) (delivery *eventingduckv1.DeliverySpec) {
 	if sub.Spec.Delivery == nil && channel.Spec.Delivery != nil {
 		// Default to the channel spec
@@ -510,14 +530,23 @@ func deliverySpec(sub *v1.Subscription, channel *eventingduckv1.Channelable) (de
 				},
 			}
 		}
-		if channel.Spec.Delivery.BackoffDelay != nil || channel.Spec.Delivery.Retry != nil || channel.Spec.Delivery.BackoffPolicy != nil || channel.Spec.Delivery.Timeout != nil {
+		if channel.Spec.Delivery.BackoffDelay != nil || channel.Spec.Delivery.BackoffMaxDelay != nil || channel.Spec.Delivery.Retry != nil || channel.Spec.Delivery.BackoffPolicy != nil || channel.Spec.Delivery.Timeout != nil || channel.Spec.Delivery.RetryAfterMax != nil || len(channel.Spec.Delivery.RetryOn) > 0 || len(channel.Spec.Delivery.DoNotRetryOn) > 0 || channel.Spec.Delivery.OrderingKey != nil || channel.Spec.Delivery.RateLimit != nil || channel.Spec.Delivery.MaxInFlight != nil || channel.Spec.Delivery.MaxBatchSize != nil || channel.Spec.Delivery.MaxBatchDelay != nil {
 			if delivery == nil {
 				delivery = &eventingduckv1.DeliverySpec{}
 			}
 			delivery.BackoffPolicy = channel.Spec.Delivery.BackoffPolicy
 			delivery.Retry = channel.Spec.Delivery.Retry
 			delivery.BackoffDelay = channel.Spec.Delivery.BackoffDelay
+			delivery.BackoffMaxDelay = channel.Spec.Delivery.BackoffMaxDelay
 			delivery.Timeout = channel.Spec.Delivery.Timeout
+			delivery.RetryAfterMax = channel.Spec.Delivery.RetryAfterMax
+			delivery.RetryOn = channel.Spec.Delivery.RetryOn
+			delivery.DoNotRetryOn = channel.Spec.Delivery.DoNotRetryOn
+			delivery.OrderingKey = channel.Spec.Delivery.OrderingKey
+			delivery.RateLimit = channel.Spec.Delivery.RateLimit
+			delivery.MaxInFlight = channel.Spec.Delivery.MaxInFlight
+			delivery.MaxBatchSize = channel.Spec.Delivery.MaxBatchSize
+			delivery.MaxBatchDelay = channel.Spec.Delivery.MaxBatchDelay
 		}
 		return
 	}
@@ -531,14 +560,23 @@ func deliverySpec(sub *v1.Subscription, channel *eventingduckv1.Channelable) (de
 			},
 		}
 	}
-	if sub.Spec.Delivery != nil && (sub.Spec.Delivery.BackoffDelay != nil || sub.Spec.Delivery.Retry != nil || sub.Spec.Delivery.BackoffPolicy != nil || sub.Spec.Delivery.Timeout != nil) {
+	if sub.Spec.Delivery != nil && (sub.Spec.Delivery.BackoffDelay != nil || sub.Spec.Delivery.BackoffMaxDelay != nil || sub.Spec.Delivery.Retry != nil || sub.Spec.Delivery.BackoffPolicy != nil || sub.Spec.Delivery.Timeout != nil || sub.Spec.Delivery.RetryAfterMax != nil || len(sub.Spec.Delivery.RetryOn) > 0 || len(sub.Spec.Delivery.DoNotRetryOn) > 0 || sub.Spec.Delivery.OrderingKey != nil || sub.Spec.Delivery.RateLimit != nil || sub.Spec.Delivery.MaxInFlight != nil || sub.Spec.Delivery.MaxBatchSize != nil || sub.Spec.Delivery.MaxBatchDelay != nil) {
 		if delivery == nil {
 			delivery = &eventingduckv1.DeliverySpec{}
 		}
 		delivery.BackoffPolicy = sub.Spec.Delivery.BackoffPolicy
 		delivery.Retry = sub.Spec.Delivery.Retry
 		delivery.BackoffDelay = sub.Spec.Delivery.BackoffDelay
+		delivery.BackoffMaxDelay = sub.Spec.Delivery.BackoffMaxDelay
 		delivery.Timeout = sub.Spec.Delivery.Timeout
+		delivery.RetryAfterMax = sub.Spec.Delivery.RetryAfterMax
+		delivery.RetryOn = sub.Spec.Delivery.RetryOn
+		delivery.DoNotRetryOn = sub.Spec.Delivery.DoNotRetryOn
+		delivery.OrderingKey = sub.Spec.Delivery.OrderingKey
+		delivery.RateLimit = sub.Spec.Delivery.RateLimit
+		delivery.MaxInFlight = sub.Spec.Delivery.MaxInFlight
+		delivery.MaxBatchSize = sub.Spec.Delivery.MaxBatchSize
+		delivery.MaxBatchDelay = sub.Spec.Delivery.MaxBatchDelay
 	}
 	return
 }
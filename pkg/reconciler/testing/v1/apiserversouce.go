@@ -93,6 +93,19 @@ func WithApiServerSourceReferenceModeEventTypes(source string) ApiServerSourceOp
 	}
 }
 
+func WithApiServerSourceEventTypes(source string, eventTypes ...string) ApiServerSourceOption {
+	return func(s *v1.ApiServerSource) {
+		ceAttributes := make([]duckv1.CloudEventAttributes, 0, len(eventTypes))
+		for _, apiServerSourceType := range eventTypes {
+			ceAttributes = append(ceAttributes, duckv1.CloudEventAttributes{
+				Type:   apiServerSourceType,
+				Source: source,
+			})
+		}
+		s.Status.CloudEventAttributes = ceAttributes
+	}
+}
+
 func WithApiServerSourceResourceModeEventTypes(source string) ApiServerSourceOption {
 	return func(s *v1.ApiServerSource) {
 		ceAttributes := make([]duckv1.CloudEventAttributes, 0, len(apisources.ApiServerSourceEventResourceModeTypes))
@@ -114,6 +127,18 @@ func WithApiServerSourceNoSufficientPermissions(s *v1.ApiServerSource) {
 	s.Status.MarkNoSufficientPermissions("", `User system:serviceaccount:testnamespace:default cannot get, list, watch resource "namespaces" in API group ""`)
 }
 
+func WithApiServerSourceNoSufficientPermissionsFor(missing string) ApiServerSourceOption {
+	return func(s *v1.ApiServerSource) {
+		s.Status.MarkNoSufficientPermissions("", "User system:serviceaccount:testnamespace:default cannot %s", missing)
+	}
+}
+
+func WithApiServerSourceFinalizers(finalizers ...string) ApiServerSourceOption {
+	return func(c *v1.ApiServerSource) {
+		c.Finalizers = finalizers
+	}
+}
+
 func WithApiServerSourceDeleted(c *v1.ApiServerSource) {
 	t := metav1.NewTime(time.Unix(1e9, 0))
 	c.ObjectMeta.SetDeletionTimestamp(&t)
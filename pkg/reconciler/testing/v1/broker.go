@@ -164,6 +164,17 @@ func WithBrokerClass(bc string) BrokerOption {
 	}
 }
 
+func WithBrokerDispatchMode(mode string) BrokerOption {
+	return func(b *v1.Broker) {
+		annotations := b.GetAnnotations()
+		if annotations == nil {
+			annotations = make(map[string]string, 1)
+		}
+		annotations[eventing.BrokerDispatchModeAnnotationKey] = mode
+		b.SetAnnotations(annotations)
+	}
+}
+
 func WithChannelAddressAnnotation(address string) BrokerOption {
 	return func(b *v1.Broker) {
 		if b.Status.Annotations == nil {
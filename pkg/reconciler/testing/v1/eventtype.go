@@ -21,6 +21,7 @@ import (
 	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/eventing/pkg/apis/eventing"
 	"knative.dev/eventing/pkg/apis/eventing/v1beta1"
 	"knative.dev/pkg/apis"
 )
@@ -66,6 +67,24 @@ func WithEventTypeBroker(broker string) EventTypeOption {
 	}
 }
 
+func WithEventTypeSchemaData(data string) EventTypeOption {
+	return func(et *v1beta1.EventType) {
+		et.Spec.SchemaData = data
+	}
+}
+
+// WithEventTypeSchemaValid calls .Status.MarkSchemaValid on the EventType.
+func WithEventTypeSchemaValid(et *v1beta1.EventType) {
+	et.Status.MarkSchemaValid()
+}
+
+// WithEventTypeSchemaInvalid calls .Status.MarkSchemaInvalid on the EventType.
+func WithEventTypeSchemaInvalid(message string) EventTypeOption {
+	return func(et *v1beta1.EventType) {
+		et.Status.MarkSchemaInvalid("SchemaInvalid", message)
+	}
+}
+
 func WithEventTypeDescription(description string) EventTypeOption {
 	return func(et *v1beta1.EventType) {
 		et.Spec.Description = description
@@ -78,6 +97,14 @@ func WithEventTypeLabels(labels map[string]string) EventTypeOption {
 	}
 }
 
+// WithEventTypeAutoDiscovered labels the EventType as auto-discovered, last seen at the given time.
+func WithEventTypeAutoDiscovered(lastSeen time.Time) EventTypeOption {
+	return func(et *v1beta1.EventType) {
+		et.ObjectMeta.Labels = map[string]string{eventing.AutoDiscoveredLabelKey: "true"}
+		et.ObjectMeta.Annotations = map[string]string{eventing.LastSeenAnnotationKey: lastSeen.UTC().Format(time.RFC3339)}
+	}
+}
+
 func WithEventTypeOwnerReference(ownerRef metav1.OwnerReference) EventTypeOption {
 	return func(et *v1beta1.EventType) {
 		et.ObjectMeta.OwnerReferences = []metav1.OwnerReference{
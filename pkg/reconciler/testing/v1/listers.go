@@ -112,6 +112,21 @@ func (l *Listers) GetEventTypeLister() eventingv1beta1listers.EventTypeLister {
 	return eventingv1beta1listers.NewEventTypeLister(l.indexerFor(&eventingv1beta1.EventType{}))
 }
 
+// GetEventTypeIndexer returns an indexer of the EventTypes having the indexers on top of the
+// namespace one.
+func (l *Listers) GetEventTypeIndexer(indexers cache.Indexers) cache.Indexer {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	if err := indexer.AddIndexers(indexers); err != nil {
+		panic(err)
+	}
+	for _, obj := range l.indexerFor(&eventingv1beta1.EventType{}).List() {
+		if err := indexer.Add(obj); err != nil {
+			panic(err)
+		}
+	}
+	return indexer
+}
+
 func (l *Listers) GetPingSourceLister() sourcelisters.PingSourceLister {
 	return sourcelisters.NewPingSourceLister(l.indexerFor(&sourcesv1.PingSource{}))
 }
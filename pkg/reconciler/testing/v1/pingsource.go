@@ -85,6 +85,23 @@ func WithPingSourceSpec(spec v1.PingSourceSpec) PingSourceOption {
 	}
 }
 
+func WithPingSourceCompletionTime(t metav1.Time) PingSourceOption {
+	return func(c *v1.PingSource) {
+		c.Status.MarkCompleted(t)
+	}
+}
+
+// WithPingSourceFiredAt sets the annotation reporting that the event of a one-shot PingSource
+// was sent at t.
+func WithPingSourceFiredAt(t metav1.Time) PingSourceOption {
+	return func(c *v1.PingSource) {
+		if c.Annotations == nil {
+			c.Annotations = make(map[string]string, 1)
+		}
+		c.Annotations[v1.PingSourceFiredAtAnnotationKey] = t.UTC().Format(time.RFC3339)
+	}
+}
+
 func WithPingSourceStatusObservedGeneration(generation int64) PingSourceOption {
 	return func(c *v1.PingSource) {
 		c.Status.ObservedGeneration = generation